@@ -7,6 +7,7 @@ require (
 	github.com/pixie-sh/database-helpers-go v0.2.20
 	github.com/pixie-sh/errors-go v0.3.7
 	github.com/spf13/cobra v1.10.2
+	golang.org/x/tools v0.42.0
 	gopkg.in/yaml.v3 v3.0.1
 	modernc.org/sqlite v1.48.0
 )
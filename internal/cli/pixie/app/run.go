@@ -6,9 +6,15 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config_cmd"
 	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/db_shell_cmd"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/doctor_cmd"
 	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd"
 	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/templates_cmd"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/upgrade_cmd"
 	"github.com/pixie-sh/pixie-cli/internal/version"
 )
 
@@ -26,14 +32,28 @@ func NewRootCmd() *cobra.Command {
 		Short:   "Pixie CLI - Multi-Stack Project Generator",
 		Long:    "Pixie CLI generates complete projects for Go backend, Angular frontend, and Expo mobile applications.",
 		Version: version.Info(),
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			config.SetOptions(config.OptionsFromCommand(cmd))
+			initshared.SetWriteMode(initshared.WriteModeFromCommand(cmd))
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return initshared.WritePlan(cmd.OutOrStdout())
+		},
 	}
 
 	rootCmd.SetVersionTemplate("pixie version {{.Version}}\n")
 	rootCmd.PersistentFlags().String("config", "", "Path to configuration file")
 	rootCmd.PersistentFlags().String("env", "", "Path to environment file")
+	rootCmd.PersistentFlags().StringArray("set", nil, "Override a config setting (key=value, e.g. generate.models_dir=pkg/entities)")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Print the files generators would write without writing them")
+	rootCmd.PersistentFlags().Bool("diff", false, "Print unified diffs of the files generators would write without writing them")
 	rootCmd.AddCommand(init_cmd.InitCmd())
 	rootCmd.AddCommand(generate_cmd.GenerateCmd())
 	rootCmd.AddCommand(db_shell_cmd.Cmd())
+	rootCmd.AddCommand(config_cmd.ConfigCmd())
+	rootCmd.AddCommand(doctor_cmd.DoctorCmd())
+	rootCmd.AddCommand(upgrade_cmd.UpgradeCmd())
+	rootCmd.AddCommand(templates_cmd.TemplatesCmd())
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
@@ -17,3 +17,51 @@ func TestNewRootCmdIncludesDBShell(t *testing.T) {
 		t.Fatal("db-shell command not registered on root command")
 	}
 }
+
+func TestNewRootCmdIncludesConfig(t *testing.T) {
+	root := NewRootCmd()
+
+	for _, command := range root.Commands() {
+		if command.Name() == "config" {
+			return
+		}
+	}
+
+	t.Fatal("config command not registered on root command")
+}
+
+func TestNewRootCmdIncludesDoctor(t *testing.T) {
+	root := NewRootCmd()
+
+	for _, command := range root.Commands() {
+		if command.Name() == "doctor" {
+			return
+		}
+	}
+
+	t.Fatal("doctor command not registered on root command")
+}
+
+func TestNewRootCmdIncludesUpgrade(t *testing.T) {
+	root := NewRootCmd()
+
+	for _, command := range root.Commands() {
+		if command.Name() == "upgrade" {
+			return
+		}
+	}
+
+	t.Fatal("upgrade command not registered on root command")
+}
+
+func TestNewRootCmdIncludesTemplates(t *testing.T) {
+	root := NewRootCmd()
+
+	for _, command := range root.Commands() {
+		if command.Name() == "templates" {
+			return
+		}
+	}
+
+	t.Fatal("templates command not registered on root command")
+}
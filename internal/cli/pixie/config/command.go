@@ -0,0 +1,20 @@
+package config
+
+import "github.com/spf13/cobra"
+
+// OptionsFromCommand reads the --config, --env and --set flags visible to cmd.
+// Flags that are not defined (e.g. when commands are embedded in another CLI) are ignored.
+func OptionsFromCommand(cmd *cobra.Command) Options {
+	var opts Options
+	if flag := cmd.Flags().Lookup("config"); flag != nil {
+		opts.ConfigPath = flag.Value.String()
+	}
+	if flag := cmd.Flags().Lookup("env"); flag != nil {
+		opts.EnvPath = flag.Value.String()
+	}
+	if cmd.Flags().Lookup("set") != nil {
+		opts.Overrides, _ = cmd.Flags().GetStringArray("set")
+	}
+
+	return opts
+}
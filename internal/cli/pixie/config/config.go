@@ -0,0 +1,302 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pixie-sh/errors-go"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/models"
+)
+
+// Config is the typed project configuration shared by every pixie command.
+// It is loaded from .pixie.yaml or pixie.yaml at the project root; each top-level
+// key maps to one section so new commands can add their own without touching the loader.
+type Config struct {
+	Generate GeneratorConfig  `yaml:"generate"`
+	DB       DBConfig         `yaml:"db"`
+	Runtime  models.CLIConfig `yaml:"runtime"`
+
+	// Root is the discovered project root (directory holding the config file or go.mod).
+	Root string `yaml:"-"`
+	// Path is the config file that was loaded, empty when running on defaults.
+	Path string `yaml:"-"`
+	// Env holds the values read from the --env file.
+	Env map[string]string `yaml:"-"`
+	// Sources records where each non-default setting came from, keyed by dotted key.
+	Sources map[string]Source `yaml:"-"`
+}
+
+// GeneratorConfig holds all configurable paths and conventions for code generation.
+// Paths are relative to the project root.
+type GeneratorConfig struct {
+	// Directory conventions (relative to project root)
+	MicroserviceDir string `yaml:"microservice_dir"` // e.g. "internal/ms"
+	DomainDir       string `yaml:"domain_dir"`       // e.g. "internal/domain"
+	ModelsDir       string `yaml:"models_dir"`       // e.g. "pkg/models"
+	ConfigsDir      string `yaml:"configs_dir"`      // e.g. "misc/configs"
+	CmdDir          string `yaml:"cmd_dir"`          // e.g. "cmd/ms"
+	BundlesDir      string `yaml:"bundles_dir"`      // e.g. "bundles"
+	InfraDir        string `yaml:"infra_dir"`        // e.g. "infra"
+	AdaptersDir     string `yaml:"adapters_dir"`     // e.g. "internal/adapters"
+	ContextDir      string `yaml:"context_dir"`      // e.g. "pkg/context"
+
+	// Naming conventions
+	MicroservicePrefix  string `yaml:"microservice_prefix"`   // e.g. "ms_"
+	BusinessLayerSuffix string `yaml:"business_layer_suffix"` // e.g. "_business_layer"
+
+	// OpenAPI defaults
+	OpenAPITitle   string   `yaml:"openapi_title"`   // default title for OpenAPI spec
+	OpenAPIServers []string `yaml:"openapi_servers"` // default server URLs
+	OAuthAuthorize string   `yaml:"oauth_authorize"` // OAuth authorize URL
+	OAuthToken     string   `yaml:"oauth_token"`     // OAuth token URL
+
+	// Module name (auto-detected from go.mod if empty)
+	ModuleName string `yaml:"module_name"`
+
+	// Root is the project root the directories above are relative to.
+	Root string `yaml:"-"`
+}
+
+// DBConfig holds the db: section used by db-shell.
+// Empty fields fall through to environment variables and built-in defaults.
+type DBConfig struct {
+	Driver   string `yaml:"driver"`
+	DSN      string `yaml:"dsn"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Name     string `yaml:"name"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	SSLMode  string `yaml:"sslmode"`
+}
+
+// Options controls where configuration is read from.
+type Options struct {
+	ConfigPath string            // explicit config file (--config); discovered when empty
+	EnvPath    string            // env file (--env) used for ${VAR} expansion
+	WorkDir    string            // directory discovery starts from; defaults to the working directory
+	Lookup     EnvironmentLookup // process environment; defaults to os.Getenv
+	Overrides  []string          // key=value pairs (--set) applied last
+}
+
+var (
+	globalMu      sync.RWMutex
+	globalOptions Options
+)
+
+// SetOptions records the options resolved from the root command flags so
+// commands that call Load(CurrentOptions()) honour --config and --env.
+func SetOptions(opts Options) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	globalOptions = opts
+}
+
+// CurrentOptions returns the options recorded by SetOptions.
+func CurrentOptions() Options {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return globalOptions
+}
+
+// Default returns a Config with built-in defaults and no file applied.
+func Default() Config {
+	return Config{
+		Generate: DefaultGeneratorConfig(),
+		DB:       DefaultDBConfig(),
+		Runtime:  models.DefaultCLIConfig(),
+		Env:      map[string]string{},
+		Sources:  map[string]Source{},
+	}
+}
+
+// DefaultGeneratorConfig returns a GeneratorConfig with sensible defaults.
+func DefaultGeneratorConfig() GeneratorConfig {
+	return GeneratorConfig{
+		MicroserviceDir:     "internal/ms",
+		DomainDir:           "internal/domain",
+		ModelsDir:           "pkg/models",
+		ConfigsDir:          "misc/configs",
+		CmdDir:              "cmd/ms",
+		BundlesDir:          "bundles",
+		InfraDir:            "infra",
+		AdaptersDir:         "internal/adapters",
+		ContextDir:          "pkg/context",
+		MicroservicePrefix:  "ms_",
+		BusinessLayerSuffix: "_business_layer",
+		OpenAPITitle:        "API",
+		OpenAPIServers:      []string{},
+		OAuthAuthorize:      "",
+		OAuthToken:          "",
+	}
+}
+
+// DefaultDBConfig returns the db-shell connection defaults.
+func DefaultDBConfig() DBConfig {
+	return DBConfig{
+		Driver:  "postgres",
+		Host:    "localhost",
+		Port:    5432,
+		Name:    "postgres",
+		User:    "postgres",
+		SSLMode: "disable",
+	}
+}
+
+// Load discovers and parses the project configuration.
+// The project root is found by walking up from WorkDir. Without an explicit ConfigPath
+// .pixie.yaml or pixie.yaml is read there, and a missing file is not an error; an
+// explicit ConfigPath is read wherever it is, and must exist. ${VAR} references are
+// expanded from the process environment first and the env file second.
+// Settings are then overridden by environment variables (env file, then process
+// environment) and finally by Overrides; Sources records the winner for each key.
+func Load(opts Options) (Config, error) {
+	cfg := Default()
+
+	workDir, err := resolveWorkDir(opts.WorkDir)
+	if err != nil {
+		return cfg, err
+	}
+
+	lookup := opts.Lookup
+	if lookup == nil {
+		lookup = os.Getenv
+	}
+
+	envValues, err := LoadEnvFile(opts.EnvPath)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Env = envValues
+
+	var configPath string
+	cfg.Root, configPath = locate(opts.ConfigPath, workDir)
+	cfg.Generate.Root = cfg.Root
+
+	if configPath != "" {
+		if err := loadFile(&cfg, configPath, chainLookup(lookup, envValues)); err != nil {
+			return cfg, err
+		}
+	}
+
+	applyEnvOverrides(&cfg, func(key string) string { return envValues[key] }, opts.EnvPath)
+	applyEnvOverrides(&cfg, lookup, "")
+
+	for _, override := range opts.Overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return cfg, errors.New("invalid --set value %q, expected key=value", override)
+		}
+		if err := cfg.Set(strings.TrimSpace(key), value, Source{Kind: SourceFlag, Detail: "--set"}); err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// loadFile parses configPath into cfg and records which settings it provided.
+func loadFile(cfg *Config, configPath string, lookup EnvironmentLookup) error {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read config file: %s", configPath)
+	}
+
+	expanded := ExpandEnv(string(content), lookup)
+	if err := yaml.Unmarshal([]byte(expanded), cfg); err != nil {
+		return errors.Wrap(err, "failed to parse config file: %s", configPath)
+	}
+
+	cfg.Path = configPath
+	cfg.Generate.Root = cfg.Root
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return errors.Wrap(err, "failed to parse config file: %s", configPath)
+	}
+	for key, node := range leafNodes(&document) {
+		if _, ok := LookupField(key); !ok {
+			continue
+		}
+
+		source := Source{Kind: SourceFile, Detail: filepath.Base(configPath)}
+		if refs := envReferences(node); len(refs) > 0 {
+			source = Source{Kind: SourceEnv, Detail: strings.Join(refs, ", ") + " via " + filepath.Base(configPath)}
+		}
+		cfg.Sources[key] = source
+	}
+
+	return nil
+}
+
+// Path joins elem onto the project root and returns it relative to the working
+// directory when possible, so generated paths read naturally from any subdirectory.
+func (c GeneratorConfig) Path(elem ...string) string {
+	joined := filepath.Join(elem...)
+	if c.Root == "" || filepath.IsAbs(joined) {
+		return joined
+	}
+
+	full := filepath.Join(c.Root, joined)
+	cwd, err := os.Getwd()
+	if err != nil {
+		return full
+	}
+
+	rel, err := filepath.Rel(cwd, full)
+	if err != nil {
+		return full
+	}
+
+	return rel
+}
+
+// locate returns the project root, found from workDir, and the config file: the explicit
+// config path or, when it is empty, the one discovered at the root. The file path is
+// empty when none exists. An explicit config path only selects the file to read; files
+// are still generated into the project of workDir.
+func locate(configPath, workDir string) (string, string) {
+	root := FindProjectRoot(workDir)
+	if configPath != "" {
+		if !filepath.IsAbs(configPath) {
+			configPath = filepath.Join(workDir, configPath)
+		}
+		return root, configPath
+	}
+
+	return root, findConfigFile(root)
+}
+
+func resolveWorkDir(workDir string) (string, error) {
+	if workDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get current working directory")
+		}
+		workDir = cwd
+	}
+
+	abs, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve directory: %s", workDir)
+	}
+
+	return abs, nil
+}
+
+func chainLookup(lookup EnvironmentLookup, fileValues map[string]string) EnvironmentLookup {
+	if lookup == nil {
+		lookup = os.Getenv
+	}
+
+	return func(key string) string {
+		if value := lookup(key); value != "" {
+			return value
+		}
+		return fileValues[key]
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FileNames lists the config file names looked up at the project root, in priority order.
+var FileNames = []string{".pixie.yaml", "pixie.yaml"}
+
+// FindProjectRoot walks up from start and returns the nearest directory that holds a
+// pixie config file or a go.mod. When neither is found it returns start unchanged.
+func FindProjectRoot(start string) string {
+	dir := filepath.Clean(start)
+	for {
+		if findConfigFile(dir) != "" || fileExists(filepath.Join(dir, "go.mod")) {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return filepath.Clean(start)
+		}
+		dir = parent
+	}
+}
+
+// findConfigFile returns the first config file present in dir, or an empty string.
+func findConfigFile(dir string) string {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if fileExists(path) {
+			return path
+		}
+	}
+
+	return ""
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
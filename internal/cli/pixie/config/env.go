@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// EnvironmentLookup resolves a variable name to its value, returning "" when unset.
+type EnvironmentLookup func(string) string
+
+// envReference matches ${NAME} and ${NAME:-default}. Other ${...} forms, such as the
+// ${env.NAME} placeholders used in service configs, are left untouched.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// ExpandEnv replaces ${NAME} references in content using lookup.
+// Unset variables expand to their default, or to an empty string without one.
+func ExpandEnv(content string, lookup EnvironmentLookup) string {
+	if lookup == nil {
+		lookup = os.Getenv
+	}
+
+	return envReference.ReplaceAllStringFunc(content, func(match string) string {
+		parts := envReference.FindStringSubmatch(match)
+		if value := lookup(parts[1]); value != "" {
+			return value
+		}
+		return parts[3]
+	})
+}
+
+// envVars lists, per setting, the environment variables that override it in priority
+// order. The unprefixed names match the env files of generated backend services.
+var envVars = map[string][]string{
+	"db.driver":   {"PIXIE_DB_DRIVER", "DB_DRIVER"},
+	"db.dsn":      {"PIXIE_DB_DSN", "DB_DSN", "DATABASE_URL"},
+	"db.host":     {"PIXIE_DB_HOST", "DB_HOST", "PGHOST"},
+	"db.port":     {"PIXIE_DB_PORT", "DB_PORT", "PGPORT"},
+	"db.name":     {"PIXIE_DB_NAME", "DB_NAME", "PGDATABASE"},
+	"db.user":     {"PIXIE_DB_USER", "DB_USERNAME", "DB_USER", "PGUSER"},
+	"db.password": {"PIXIE_DB_PASSWORD", "DB_PASSWORD", "PGPASSWORD"},
+	"db.sslmode":  {"PIXIE_DB_SSLMODE", "DB_SSL_MODE", "DB_SSLMODE", "PGSSLMODE"},
+
+	"runtime.database.host":                    {"DATABASE_HOST"},
+	"runtime.database.port":                    {"DATABASE_PORT"},
+	"runtime.database.name":                    {"DATABASE_NAME"},
+	"runtime.database.username":                {"DATABASE_USERNAME"},
+	"runtime.database.password":                {"DATABASE_PASSWORD"},
+	"runtime.database.ssl_mode":                {"DATABASE_SSL_MODE"},
+	"runtime.database.max_open_connections":    {"DATABASE_MAX_OPEN_CONNECTIONS"},
+	"runtime.database.max_idle_connections":    {"DATABASE_MAX_IDLE_CONNECTIONS"},
+	"runtime.database.connection_max_lifetime": {"DATABASE_CONNECTION_MAX_LIFETIME"},
+	"runtime.redis.host":                       {"REDIS_HOST"},
+	"runtime.redis.port":                       {"REDIS_PORT"},
+	"runtime.redis.password":                   {"REDIS_PASSWORD"},
+	"runtime.redis.database":                   {"REDIS_DATABASE"},
+	"runtime.security.jwt_secret_key":          {"JWT_SECRET_KEY"},
+	"runtime.security.admin_api_key":           {"ADMIN_API_KEY"},
+	"runtime.environment.environment":          {"PIXIE_ENV", "APP_ENV"},
+	"runtime.environment.debug":                {"DEBUG"},
+	"runtime.environment.log_level":            {"LOG_LEVEL"},
+}
+
+// EnvVars returns the environment variables that override key, in priority order.
+func EnvVars(key string) []string {
+	return envVars[key]
+}
+
+// applyEnvOverrides sets every setting that has an environment override present in lookup.
+// The first non-empty variable wins; values that fail to parse are ignored.
+func applyEnvOverrides(cfg *Config, lookup EnvironmentLookup, origin string) {
+	for _, key := range FieldKeys() {
+		for _, name := range envVars[key] {
+			value := lookup(name)
+			if value == "" {
+				continue
+			}
+
+			detail := name
+			if origin != "" {
+				detail = fmt.Sprintf("%s in %s", name, origin)
+			}
+			_ = cfg.Set(key, value, Source{Kind: SourceEnv, Detail: detail})
+			break
+		}
+	}
+}
+
+// LoadEnvFile parses a KEY=VALUE env file. Blank lines and # comments are ignored and
+// surrounding quotes are stripped. An empty path yields an empty map.
+func LoadEnvFile(envPath string) (map[string]string, error) {
+	if envPath == "" {
+		return map[string]string{}, nil
+	}
+
+	content, err := os.ReadFile(envPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read env file: %s", envPath)
+	}
+
+	values := make(map[string]string)
+	for index, rawLine := range strings.Split(string(content), "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("invalid env file line %d in %s", index+1, envPath)
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		value = strings.Trim(value, `"'`)
+		values[key] = value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// SourceKind identifies where an effective config value came from.
+type SourceKind string
+
+const (
+	SourceDefault SourceKind = "default"
+	SourceFile    SourceKind = "file"
+	SourceEnv     SourceKind = "env"
+	SourceFlag    SourceKind = "flag"
+)
+
+// Source records the origin of a config value, e.g. "env (DB_HOST)".
+type Source struct {
+	Kind   SourceKind
+	Detail string
+}
+
+func (s Source) String() string {
+	if s.Detail == "" {
+		return string(s.Kind)
+	}
+
+	return fmt.Sprintf("%s (%s)", s.Kind, s.Detail)
+}
+
+// Field is one leaf setting of Config addressed by its dotted key, e.g. "generate.models_dir".
+type Field struct {
+	Key    string
+	Schema *SchemaNode
+	index  []int
+}
+
+// Fields lists every setting of Config in declaration order.
+func Fields() []Field {
+	var fields []Field
+	collectFields(reflect.TypeOf(Config{}), "", nil, &fields)
+
+	return fields
+}
+
+func collectFields(t reflect.Type, prefix string, index []int, fields *[]Field) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+
+		key := joinKey(prefix, name)
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			collectFields(field.Type, key, fieldIndex, fields)
+			continue
+		}
+		if prefix == "" {
+			continue
+		}
+
+		*fields = append(*fields, Field{
+			Key:    key,
+			Schema: RootSchema().Lookup(key),
+			index:  fieldIndex,
+		})
+	}
+}
+
+// FieldKeys returns the dotted keys of every setting.
+func FieldKeys() []string {
+	fields := Fields()
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		keys = append(keys, field.Key)
+	}
+
+	return keys
+}
+
+// LookupField returns the field for key.
+func LookupField(key string) (Field, bool) {
+	for _, field := range Fields() {
+		if field.Key == key {
+			return field, true
+		}
+	}
+
+	return Field{}, false
+}
+
+// Source returns where the value of key came from.
+func (c Config) Source(key string) Source {
+	if source, ok := c.Sources[key]; ok {
+		return source
+	}
+
+	return Source{Kind: SourceDefault}
+}
+
+// Get returns the value of key formatted for display. Secrets are not masked.
+func (c Config) Get(key string) (string, bool) {
+	field, ok := LookupField(key)
+	if !ok {
+		return "", false
+	}
+
+	return formatValue(reflect.ValueOf(c).FieldByIndex(field.index)), true
+}
+
+// Display returns the value of key formatted for display with secrets masked.
+func (c Config) Display(key string) string {
+	value, _ := c.Get(key)
+
+	field, _ := LookupField(key)
+	if field.Schema.Secret() {
+		return MaskSecret(value)
+	}
+
+	return value
+}
+
+// Set parses raw into the field addressed by key and records source as its origin.
+func (c *Config) Set(key, raw string, source Source) error {
+	field, ok := LookupField(key)
+	if !ok {
+		return unknownKeyError(key)
+	}
+
+	target := reflect.ValueOf(c).Elem().FieldByIndex(field.index)
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(raw)
+	case reflect.Int:
+		value, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.Wrap(err, "invalid integer for %s: %q", key, raw)
+		}
+		target.SetInt(int64(value))
+	case reflect.Bool:
+		value, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.Wrap(err, "invalid boolean for %s: %q", key, raw)
+		}
+		target.SetBool(value)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		target.Set(reflect.ValueOf(items))
+	default:
+		return errors.New("unsupported config value type for %s", key)
+	}
+
+	if c.Sources == nil {
+		c.Sources = map[string]Source{}
+	}
+	c.Sources[key] = source
+
+	return nil
+}
+
+var dsnPassword = regexp.MustCompile(`(password=)\S+`)
+
+// MaskSecret hides a secret value while keeping enough shape to recognise it.
+// Connection strings keep everything but the password.
+func MaskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	if strings.Contains(value, "://") {
+		if parsed, err := url.Parse(value); err == nil {
+			return parsed.Redacted()
+		}
+	}
+	if dsnPassword.MatchString(value) {
+		return dsnPassword.ReplaceAllString(value, "${1}xxxxx")
+	}
+
+	return "********"
+}
+
+// Suggest returns the candidate closest to target, or an empty string when nothing is close.
+func Suggest(target string, candidates []string) string {
+	best := ""
+	bestDistance := len(target)/2 + 1
+	for _, candidate := range candidates {
+		if distance := levenshtein(target, candidate); distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
+
+func unknownKeyError(key string) error {
+	if suggestion := Suggest(key, FieldKeys()); suggestion != "" {
+		return errors.New("unknown config key %q (did you mean %q?)", key, suggestion)
+	}
+
+	return errors.New("unknown config key %q", key)
+}
+
+func formatValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Int:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Slice:
+		items := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, fmt.Sprint(value.Index(i).Interface()))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
+
+func yamlName(field reflect.StructField) string {
+	tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if tag == "-" {
+		return ""
+	}
+
+	return tag
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
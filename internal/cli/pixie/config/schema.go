@@ -0,0 +1,66 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+//go:embed pixie.schema.json
+var schemaJSON []byte
+
+// SchemaNode is the subset of JSON Schema used to describe pixie config files.
+type SchemaNode struct {
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*SchemaNode `json:"properties,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *SchemaNode            `json:"items,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Maximum              *int                   `json:"maximum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty"`
+}
+
+var (
+	schemaOnce sync.Once
+	schemaRoot *SchemaNode
+)
+
+// Schema returns the published JSON Schema for pixie config files.
+func Schema() []byte {
+	return schemaJSON
+}
+
+// RootSchema returns the parsed config schema.
+func RootSchema() *SchemaNode {
+	schemaOnce.Do(func() {
+		schemaRoot = &SchemaNode{}
+		if err := json.Unmarshal(schemaJSON, schemaRoot); err != nil {
+			panic("invalid embedded pixie.schema.json: " + err.Error())
+		}
+	})
+
+	return schemaRoot
+}
+
+// Lookup returns the schema node for a dotted key such as "generate.models_dir".
+func (s *SchemaNode) Lookup(key string) *SchemaNode {
+	node := s
+	for _, part := range strings.Split(key, ".") {
+		if node == nil || node.Properties == nil {
+			return nil
+		}
+		node = node.Properties[part]
+	}
+
+	return node
+}
+
+// Secret reports whether values of this node must be masked when displayed.
+func (s *SchemaNode) Secret() bool {
+	return s != nil && s.WriteOnly
+}
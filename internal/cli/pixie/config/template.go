@@ -0,0 +1,73 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template renders a commented config file listing every setting with its default value.
+func Template() []byte {
+	var buf bytes.Buffer
+	defaults := reflect.ValueOf(Default())
+
+	buf.WriteString("# Pixie CLI project configuration.\n")
+	buf.WriteString("# Run `pixie config explain <key>` for details and `pixie config validate` to check this file.\n")
+	buf.WriteString("# ${VAR} and ${VAR:-default} references are expanded from the environment.\n")
+
+	var previous []string
+	for _, field := range Fields() {
+		parts := strings.Split(field.Key, ".")
+		sections := parts[:len(parts)-1]
+
+		// Open every section that differs from the previous field's path.
+		shared := 0
+		for shared < len(sections) && shared < len(previous) && sections[shared] == previous[shared] {
+			shared++
+		}
+		for depth := shared; depth < len(sections); depth++ {
+			indent := strings.Repeat("  ", depth)
+			if depth == 0 {
+				buf.WriteString("\n")
+			}
+			if node := RootSchema().Lookup(strings.Join(sections[:depth+1], ".")); node != nil && node.Description != "" {
+				fmt.Fprintf(&buf, "%s# %s\n", indent, node.Description)
+			}
+			fmt.Fprintf(&buf, "%s%s:\n", indent, sections[depth])
+		}
+		previous = sections
+
+		indent := strings.Repeat("  ", len(sections))
+		if field.Schema != nil {
+			if field.Schema.Description != "" {
+				fmt.Fprintf(&buf, "%s# %s\n", indent, field.Schema.Description)
+			}
+			if len(field.Schema.Enum) > 0 {
+				fmt.Fprintf(&buf, "%s# One of: %s.\n", indent, strings.Join(field.Schema.Enum, ", "))
+			}
+		}
+		if vars := EnvVars(field.Key); len(vars) > 0 {
+			fmt.Fprintf(&buf, "%s# Overridden by %s.\n", indent, strings.Join(vars, ", "))
+		}
+
+		fmt.Fprintf(&buf, "%s%s: %s\n", indent, parts[len(parts)-1], yamlScalar(defaults.FieldByIndex(field.index).Interface()))
+	}
+
+	return buf.Bytes()
+}
+
+func yamlScalar(value any) string {
+	if items, ok := value.([]string); ok && len(items) == 0 {
+		return "[]"
+	}
+
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,275 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Issue is a problem found while validating a config file against the schema.
+type Issue struct {
+	Line    int
+	Key     string
+	Message string
+	Warning bool
+}
+
+func (i Issue) String() string {
+	level := "error"
+	if i.Warning {
+		level = "warning"
+	}
+
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", level, i.Key, i.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s: %s", i.Line, level, i.Key, i.Message)
+}
+
+// HasErrors reports whether issues contains anything other than warnings.
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if !issue.Warning {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateFile validates the config file selected by opts against the schema and, when
+// the schema finds no errors, the loaded runtime configuration against the rules of
+// models.CLIConfig.Problems, as generators apply them. It returns the path that was
+// checked, or an empty path when no config file exists and built-in defaults apply.
+func ValidateFile(opts Options) (string, []Issue, error) {
+	workDir, err := resolveWorkDir(opts.WorkDir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, path := locate(opts.ConfigPath, workDir)
+	if path == "" {
+		return "", nil, nil
+	}
+
+	envValues, err := LoadEnvFile(opts.EnvPath)
+	if err != nil {
+		return path, nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return path, nil, errors.Wrap(err, "failed to read config file: %s", path)
+	}
+
+	issues, err := Validate(content, chainLookup(opts.Lookup, envValues))
+	if err != nil || HasErrors(issues) {
+		return path, issues, err
+	}
+
+	cfg, err := Load(opts)
+	if err != nil {
+		return path, issues, err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return path, issues, errors.Wrap(err, "failed to parse config file")
+	}
+	leaves := leafNodes(&document)
+	for _, problem := range cfg.Runtime.Problems() {
+		issue := Issue{Key: problem.Key, Message: problem.Message}
+		if node, ok := leaves[problem.Key]; ok {
+			issue.Line = node.Line
+		}
+		issues = append(issues, issue)
+	}
+
+	return path, issues, nil
+}
+
+// Validate checks content against the published schema: unknown keys, value types,
+// allowed values and ranges. ${VAR} references are expanded with lookup before
+// values are checked; secrets written as literals are reported as warnings.
+func Validate(content []byte, lookup EnvironmentLookup) ([]Issue, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, errors.Wrap(err, "failed to parse config file")
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	var issues []Issue
+	validateNode(document.Content[0], RootSchema(), "", lookup, &issues)
+
+	sort.SliceStable(issues, func(a, b int) bool {
+		return issues[a].Line < issues[b].Line
+	})
+
+	return issues, nil
+}
+
+func validateNode(node *yaml.Node, schema *SchemaNode, key string, lookup EnvironmentLookup, issues *[]Issue) {
+	if node.Tag == "!!null" {
+		return
+	}
+
+	report := func(warning bool, format string, args ...any) {
+		*issues = append(*issues, Issue{
+			Line:    node.Line,
+			Key:     displayKey(key),
+			Message: fmt.Sprintf(format, args...),
+			Warning: warning,
+		})
+	}
+
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			report(false, "expected a mapping")
+			return
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			childKey := joinKey(key, keyNode.Value)
+
+			property, ok := schema.Properties[keyNode.Value]
+			if !ok {
+				if schema.AdditionalProperties == nil || *schema.AdditionalProperties {
+					continue
+				}
+
+				message := "unknown key"
+				if suggestion := Suggest(keyNode.Value, sortedKeys(schema.Properties)); suggestion != "" {
+					message = fmt.Sprintf("unknown key (did you mean %q?)", suggestion)
+				}
+				*issues = append(*issues, Issue{Line: keyNode.Line, Key: childKey, Message: message})
+				continue
+			}
+
+			validateNode(valueNode, property, childKey, lookup, issues)
+		}
+
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			report(false, "expected a list")
+			return
+		}
+		if schema.Items != nil {
+			for index, item := range node.Content {
+				validateNode(item, schema.Items, fmt.Sprintf("%s[%d]", key, index), lookup, issues)
+			}
+		}
+
+	case "string", "integer", "boolean":
+		if node.Kind != yaml.ScalarNode {
+			report(false, "expected a %s", schema.Type)
+			return
+		}
+
+		value := ExpandEnv(node.Value, lookup)
+		if schema.Secret() && value != "" && len(envReferences(node)) == 0 {
+			report(true, "secret stored in plain text; use a ${VAR} reference instead")
+		}
+		if value == "" {
+			return
+		}
+
+		if schema.Type == "integer" {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				report(false, "expected an integer, got %q", value)
+				return
+			}
+			if schema.Minimum != nil && number < *schema.Minimum {
+				report(false, "must be at least %d", *schema.Minimum)
+			}
+			if schema.Maximum != nil && number > *schema.Maximum {
+				report(false, "must be at most %d", *schema.Maximum)
+			}
+		}
+
+		if schema.Type == "boolean" {
+			if _, err := strconv.ParseBool(value); err != nil {
+				report(false, "expected a boolean, got %q", value)
+			}
+		}
+
+		if len(schema.Enum) > 0 && !containsFold(schema.Enum, value) {
+			report(false, "must be one of %s, got %q", strings.Join(schema.Enum, ", "), value)
+		}
+	}
+}
+
+// leafNodes maps the dotted key of every non-mapping value in document to its node.
+func leafNodes(document *yaml.Node) map[string]*yaml.Node {
+	leaves := map[string]*yaml.Node{}
+	if len(document.Content) > 0 {
+		collectLeafNodes(document.Content[0], "", leaves)
+	}
+
+	return leaves
+}
+
+func collectLeafNodes(node *yaml.Node, key string, leaves map[string]*yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		if key != "" {
+			leaves[key] = node
+		}
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		collectLeafNodes(node.Content[i+1], joinKey(key, node.Content[i].Value), leaves)
+	}
+}
+
+// envReferences returns the ${VAR} names referenced by a scalar or sequence node.
+func envReferences(node *yaml.Node) []string {
+	var names []string
+	values := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		values = node.Content
+	}
+
+	for _, value := range values {
+		for _, match := range envReference.FindAllStringSubmatch(value.Value, -1) {
+			names = append(names, match[1])
+		}
+	}
+
+	return names
+}
+
+func joinKey(parent, child string) string {
+	if parent == "" {
+		return child
+	}
+
+	return parent + "." + child
+}
+
+func displayKey(key string) string {
+	if key == "" {
+		return "(root)"
+	}
+
+	return key
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidate(t *testing.T) {
+	lookup := func(key string) string {
+		return map[string]string{"DB_PORT": "6543", "BAD_PORT": "abc"}[key]
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		wantKeys []string
+		wantErr  bool
+	}{
+		{
+			name:    "valid",
+			content: "generate:\n  microservice_dir: internal/ms\n  openapi_servers:\n    - https://api.example.com\ndb:\n  port: ${DB_PORT}\n  password: ${DB_PASSWORD}\n",
+		},
+		{
+			name:     "unknown key",
+			content:  "generate:\n  microservice_dirs: internal/ms\n",
+			wantKeys: []string{"generate.microservice_dirs"},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown section",
+			content:  "generator:\n  models_dir: x\n",
+			wantKeys: []string{"generator"},
+			wantErr:  true,
+		},
+		{
+			name:     "expanded integer",
+			content:  "db:\n  port: ${BAD_PORT}\n",
+			wantKeys: []string{"db.port"},
+			wantErr:  true,
+		},
+		{
+			name:     "out of range",
+			content:  "db:\n  port: 70000\n",
+			wantKeys: []string{"db.port"},
+			wantErr:  true,
+		},
+		{
+			name:     "enum",
+			content:  "db:\n  sslmode: sometimes\n",
+			wantKeys: []string{"db.sslmode"},
+			wantErr:  true,
+		},
+		{
+			name:     "list expected",
+			content:  "generate:\n  openapi_servers: https://api.example.com\n",
+			wantKeys: []string{"generate.openapi_servers"},
+			wantErr:  true,
+		},
+		{
+			name:     "plain text secret is a warning",
+			content:  "db:\n  password: hunter2\n",
+			wantKeys: []string{"db.password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := Validate([]byte(tt.content), lookup)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			var keys []string
+			for _, issue := range issues {
+				keys = append(keys, issue.Key)
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("Validate() issue keys = %v, want %v (issues: %v)", keys, tt.wantKeys, issues)
+			}
+			if HasErrors(issues) != tt.wantErr {
+				t.Errorf("HasErrors() = %v, want %v", HasErrors(issues), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_SuggestsCloseKey(t *testing.T) {
+	issues, err := Validate([]byte("generate:\n  microservice_dirs: x\n"), nil)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("Validate() returned %d issues, want 1", len(issues))
+	}
+	if issues[0].Line != 2 {
+		t.Errorf("Line = %d, want 2", issues[0].Line)
+	}
+	if !strings.Contains(issues[0].Message, `"microservice_dir"`) {
+		t.Errorf("Message = %q, want suggestion", issues[0].Message)
+	}
+}
+
+func TestSchemaCoversEveryField(t *testing.T) {
+	for _, field := range Fields() {
+		if field.Schema == nil {
+			t.Errorf("field %s is missing from pixie.schema.json", field.Key)
+			continue
+		}
+		if field.Schema.Description == "" {
+			t.Errorf("field %s has no description in pixie.schema.json", field.Key)
+		}
+	}
+}
+
+func TestTemplateIsValidAndMatchesDefaults(t *testing.T) {
+	content := Template()
+
+	issues, err := Validate(content, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(issues) > 0 {
+		t.Fatalf("Validate(Template()) issues = %v, want none", issues)
+	}
+
+	cfg := Config{}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+
+	defaults := Default()
+	for _, key := range FieldKeys() {
+		got, _ := cfg.Get(key)
+		want, _ := defaults.Get(key)
+		if got != want {
+			t.Errorf("Template() %s = %q, want default %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package config_cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd returns the config parent command with all subcommands
+func ConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect, validate and initialize the project configuration",
+		Long: `Inspect, validate and initialize .pixie.yaml / pixie.yaml.
+
+Available subcommands:
+  init      - Write a commented config file with every setting and its default
+  show      - Print the effective configuration and where each value came from
+  validate  - Check the config file against the published JSON Schema
+  explain   - Describe a setting (type, default, allowed values, env overrides)
+  schema    - Print the JSON Schema, e.g. for editor integration
+
+Value precedence (highest first):
+  1. --set key=value flags
+  2. Process environment variables, then --env file values (db settings only)
+  3. The config file, with ${VAR} references expanded
+  4. Built-in defaults
+
+Examples:
+  # Create .pixie.yaml at the project root
+  pixie config init
+
+  # Show the effective configuration with sources
+  pixie --env .env config show
+
+  # Catch typos such as microservice_dirs
+  pixie config validate
+
+  # Describe a setting
+  pixie config explain db.sslmode
+`,
+	}
+
+	cmd.AddCommand(initCmd())
+	cmd.AddCommand(showCmd())
+	cmd.AddCommand(validateCmd())
+	cmd.AddCommand(explainCmd())
+	cmd.AddCommand(schemaCmd())
+
+	return cmd
+}
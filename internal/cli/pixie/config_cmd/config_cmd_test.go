@@ -0,0 +1,117 @@
+package config_cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+)
+
+func runConfigCmd(t *testing.T, opts config.Options, args ...string) (string, error) {
+	t.Helper()
+
+	config.SetOptions(opts)
+	defer config.SetOptions(config.Options{})
+
+	var out bytes.Buffer
+	cmd := ConfigCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestInitWritesValidConfig(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, ".pixie.yaml")
+
+	if _, err := runConfigCmd(t, config.Options{}, "init", "--output", path); err != nil {
+		t.Fatalf("config init error = %v", err)
+	}
+
+	out, err := runConfigCmd(t, config.Options{ConfigPath: path}, "validate")
+	if err != nil {
+		t.Fatalf("config validate error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "is valid") {
+		t.Errorf("validate output = %q, want success message", out)
+	}
+
+	if _, err := runConfigCmd(t, config.Options{}, "init", "--output", path); err == nil {
+		t.Fatal("config init error = nil, want error for existing file without --force")
+	}
+}
+
+func TestValidateReportsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pixie.yaml")
+	if err := os.WriteFile(path, []byte("generate:\n  microservice_dirs: internal/ms\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	out, err := runConfigCmd(t, config.Options{ConfigPath: path}, "validate")
+	if err == nil {
+		t.Fatal("config validate error = nil, want error for unknown key")
+	}
+	if !strings.Contains(out, `generate.microservice_dirs: unknown key (did you mean "microservice_dir"?)`) {
+		t.Errorf("validate output = %q, want unknown key with suggestion", out)
+	}
+}
+
+func TestValidateReportsRuntimeProblems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pixie.yaml")
+	content := "runtime:\n  environment:\n    environment: production\n  database:\n    connection_max_lifetime: bogus\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	out, err := runConfigCmd(t, config.Options{ConfigPath: path, WorkDir: filepath.Dir(path)}, "validate")
+	if err == nil {
+		t.Fatalf("config validate error = nil, want error for invalid runtime config\n%s", out)
+	}
+	for _, want := range []string{
+		`line 5: error: runtime.database.connection_max_lifetime: must be a duration such as 30m or 1h, got "bogus"`,
+		"error: runtime.security.jwt_secret_key: is required in production",
+		"error: runtime.database.password: is required in production",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("validate output = %q, want it to contain %q", out, want)
+		}
+	}
+}
+
+func TestShowMasksSecretsAndReportsSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pixie.yaml")
+	if err := os.WriteFile(path, []byte("generate:\n  models_dir: custom/models\ndb:\n  password: hunter2\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	out, err := runConfigCmd(t, config.Options{
+		ConfigPath: path,
+		Lookup:     func(string) string { return "" },
+		Overrides:  []string{"generate.cmd_dir=cmd/services"},
+	}, "show")
+	if err != nil {
+		t.Fatalf("config show error = %v", err)
+	}
+
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("show output leaks secret:\n%s", out)
+	}
+
+	for _, want := range []string{
+		"generate.models_dir",
+		"custom/models",
+		"file (pixie.yaml)",
+		"flag (--set)",
+		"********",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("show output missing %q:\n%s", want, out)
+		}
+	}
+}
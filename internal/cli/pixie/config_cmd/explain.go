@@ -0,0 +1,115 @@
+package config_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+)
+
+func explainCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain [key]",
+		Short: "Describe a setting: type, default, allowed values and env overrides",
+		Long: `Describe a setting such as generate.models_dir or db.sslmode.
+
+Without a key, every setting is listed with a one-line description.
+A section name (generate, db) lists the settings of that section.`,
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return config.FieldKeys(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			prefix := ""
+			if len(args) == 1 {
+				if field, ok := config.LookupField(args[0]); ok {
+					return explainField(cmd, field)
+				}
+				if config.RootSchema().Lookup(args[0]) == nil {
+					if suggestion := config.Suggest(args[0], config.FieldKeys()); suggestion != "" {
+						return errors.New("unknown config key %q (did you mean %q?)", args[0], suggestion)
+					}
+					return errors.New("unknown config key %q", args[0])
+				}
+				prefix = args[0] + "."
+			}
+
+			writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for _, field := range config.Fields() {
+				if !strings.HasPrefix(field.Key, prefix) {
+					continue
+				}
+				fmt.Fprintf(writer, "%s\t%s\n", field.Key, field.Schema.Description)
+			}
+
+			return writer.Flush()
+		},
+	}
+}
+
+func explainField(cmd *cobra.Command, field config.Field) error {
+	out := cmd.OutOrStdout()
+	schema := field.Schema
+
+	fmt.Fprintf(out, "%s\n", field.Key)
+	fmt.Fprintf(out, "  %s\n\n", schema.Description)
+
+	valueType := schema.Type
+	if schema.Items != nil {
+		valueType = fmt.Sprintf("%s of %s", schema.Type, schema.Items.Type)
+	}
+	if schema.Secret() {
+		valueType += " (secret, masked in output)"
+	}
+	fmt.Fprintf(out, "  Type:     %s\n", valueType)
+
+	defaultValue, _ := json.Marshal(schema.Default)
+	fmt.Fprintf(out, "  Default:  %s\n", defaultValue)
+
+	if len(schema.Enum) > 0 {
+		fmt.Fprintf(out, "  Allowed:  %s\n", strings.Join(schema.Enum, ", "))
+	}
+	if schema.Minimum != nil && schema.Maximum != nil {
+		fmt.Fprintf(out, "  Range:    %d-%d\n", *schema.Minimum, *schema.Maximum)
+	}
+	if vars := config.EnvVars(field.Key); len(vars) > 0 {
+		fmt.Fprintf(out, "  Env:      %s\n", strings.Join(vars, ", "))
+	}
+
+	cfg, err := config.Load(config.CurrentOptions())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "  Current:  %s [%s]\n", displayValue(cfg.Display(field.Key)), cfg.Source(field.Key))
+
+	return nil
+}
+
+func schemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema for pixie config files",
+		Long: `Print the JSON Schema for pixie config files.
+
+Editors with YAML language server support can validate .pixie.yaml as you
+type when the schema is saved next to it:
+
+  pixie config schema > .pixie.schema.json
+
+and the config file starts with:
+
+  # yaml-language-server: $schema=./.pixie.schema.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := cmd.OutOrStdout().Write(config.Schema())
+			return err
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package config_cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+)
+
+func initCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write a commented config file with every setting and its default",
+		Long: `Write a commented .pixie.yaml listing every generate and db setting with
+its default value, description and environment overrides.
+
+The file is written to the project root (the nearest directory with a go.mod)
+unless --output is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var output, _ = cmd.Flags().GetString("output")
+			var force, _ = cmd.Flags().GetBool("force")
+
+			if output == "" {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return errors.Wrap(err, "failed to get current working directory")
+				}
+
+				root := config.FindProjectRoot(cwd)
+				for _, name := range config.FileNames {
+					existing := filepath.Join(root, name)
+					if _, err := os.Stat(existing); err == nil && !force {
+						return errors.New("config file already exists: %s (use --force to overwrite)", existing)
+					}
+				}
+				output = filepath.Join(root, config.FileNames[0])
+			} else if _, err := os.Stat(output); err == nil && !force {
+				return errors.New("config file already exists: %s (use --force to overwrite)", output)
+			}
+
+			if err := os.WriteFile(output, config.Template(), 0644); err != nil {
+				return errors.Wrap(err, "failed to write config file: %s", output)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("output", "", "Path of the config file to write (default: <project root>/.pixie.yaml)")
+	cmd.Flags().Bool("force", false, "Overwrite an existing config file")
+
+	return cmd
+}
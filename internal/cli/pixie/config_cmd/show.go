@@ -0,0 +1,51 @@
+package config_cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+)
+
+func showCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration and the source of each value",
+		Long: `Print the effective configuration after merging defaults, the config file,
+environment variables and --set flags. Each value is annotated with its
+source (default, file, env or flag). Secrets are masked.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(config.CurrentOptions())
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			configFile := cfg.Path
+			if configFile == "" {
+				configFile = "none (built-in defaults)"
+			}
+			fmt.Fprintf(out, "Config file:  %s\n", configFile)
+			fmt.Fprintf(out, "Project root: %s\n\n", cfg.Root)
+
+			writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, "KEY\tVALUE\tSOURCE")
+			for _, key := range config.FieldKeys() {
+				fmt.Fprintf(writer, "%s\t%s\t%s\n", key, displayValue(cfg.Display(key)), cfg.Source(key))
+			}
+
+			return writer.Flush()
+		},
+	}
+}
+
+func displayValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+
+	return value
+}
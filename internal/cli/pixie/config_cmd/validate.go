@@ -0,0 +1,50 @@
+package config_cmd
+
+import (
+	"fmt"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+)
+
+func validateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config file against the published JSON Schema and runtime rules",
+		Long: `Check the config file against the published JSON Schema and runtime rules.
+
+Reports unknown keys (with suggestions for likely typos), values of the
+wrong type, values outside the allowed set and secrets stored in plain text.
+A config that matches the schema is then loaded, with the env file and
+environment overrides applied, and checked against the runtime rules
+generators enforce: port ranges, pool sizes, durations, and the secrets
+required outside development.
+Warnings do not fail validation; errors do.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, issues, err := config.ValidateFile(config.CurrentOptions())
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if path == "" {
+				fmt.Fprintln(out, "No config file found; built-in defaults apply.")
+				return nil
+			}
+
+			for _, issue := range issues {
+				fmt.Fprintf(out, "%s: %s\n", path, issue)
+			}
+
+			if config.HasErrors(issues) {
+				return errors.New("%s is invalid (%d issue(s))", path, len(issues))
+			}
+
+			fmt.Fprintf(out, "%s is valid\n", path)
+			return nil
+		},
+	}
+}
@@ -16,9 +16,10 @@ func Cmd() *cobra.Command {
 		Long: `Open an interactive SQL shell through Pixie runtime abstractions.
 
 Configuration precedence:
-  1. Command flags
+  1. Command flags, then --set db.<key>=value
   2. Explicit env file values (--env), then process environment variables
   3. Project config in .pixie.yaml or pixie.yaml under the db: section
+     (discovered by walking up to the project root; ${VAR} references are expanded)
   4. Built-in defaults
 
 Runtime paths:
@@ -37,6 +38,7 @@ Examples:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			configPath, _ := cmd.InheritedFlags().GetString("config")
 			envPath, _ := cmd.InheritedFlags().GetString("env")
+			opts.Overrides, _ = cmd.InheritedFlags().GetStringArray("set")
 
 			resolvedConfig, err := ResolveConfig(opts, resolveConfigPath(configPath), envPath, nil)
 			if err != nil {
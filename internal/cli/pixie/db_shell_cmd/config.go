@@ -4,11 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"github.com/pixie-sh/errors-go"
-	"gopkg.in/yaml.v3"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
 )
 
 const (
@@ -26,24 +26,15 @@ type Options struct {
 	User     string
 	Password string
 	SSLMode  string
-}
 
-type DBConfig struct {
-	Driver   string `yaml:"driver"`
-	DSN      string `yaml:"dsn"`
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	Name     string `yaml:"name"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
-	SSLMode  string `yaml:"sslmode"`
+	// Overrides are --set key=value pairs applied on top of the project config.
+	Overrides []string
 }
 
-type runtimeConfigFile struct {
-	DB DBConfig `yaml:"db"`
-}
+// DBConfig is the db: section of the project configuration.
+type DBConfig = config.DBConfig
 
-type EnvironmentLookup func(string) string
+type EnvironmentLookup = config.EnvironmentLookup
 
 type ResolvedConfig struct {
 	Driver   string
@@ -57,13 +48,14 @@ type ResolvedConfig struct {
 }
 
 func defaultConfig() ResolvedConfig {
+	defaults := config.DefaultDBConfig()
 	return ResolvedConfig{
-		Driver:  defaultPostgresDriver,
-		Host:    "localhost",
-		Port:    5432,
-		Name:    "postgres",
-		User:    "postgres",
-		SSLMode: "disable",
+		Driver:  defaults.Driver,
+		Host:    defaults.Host,
+		Port:    defaults.Port,
+		Name:    defaults.Name,
+		User:    defaults.User,
+		SSLMode: defaults.SSLMode,
 	}
 }
 
@@ -74,20 +66,17 @@ func ResolveConfig(opts Options, configPath, envPath string, envLookup Environme
 
 	cfg := defaultConfig()
 
-	fileCfg, err := loadRuntimeConfig(configPath)
-	if err != nil {
-		return ResolvedConfig{}, err
-	}
-	applyDBConfig(&cfg, fileCfg)
-
-	envFileValues, err := loadEnvFile(envPath)
+	projectCfg, err := config.Load(config.Options{
+		ConfigPath: configPath,
+		EnvPath:    envPath,
+		Lookup:     envLookup,
+		Overrides:  opts.Overrides,
+	})
 	if err != nil {
 		return ResolvedConfig{}, err
 	}
-	applyEnvValues(&cfg, func(key string) string {
-		return envFileValues[key]
-	})
-	applyEnvValues(&cfg, envLookup)
+	// projectCfg.DB already carries env file and process environment overrides.
+	applyDBConfig(&cfg, projectCfg.DB)
 	applyOptions(&cfg, opts)
 
 	if err := finalizeConfig(&cfg); err != nil {
@@ -118,65 +107,6 @@ func (c ResolvedConfig) SafeSummary() string {
 	return fmt.Sprintf("%s %s:%d/%s as %s (sslmode=%s)", c.Driver, c.Host, c.Port, c.Name, c.User, c.SSLMode)
 }
 
-func loadRuntimeConfig(configPath string) (DBConfig, error) {
-	paths := []string{}
-	if configPath != "" {
-		paths = append(paths, configPath)
-	} else {
-		paths = append(paths, ".pixie.yaml", "pixie.yaml")
-	}
-
-	for _, path := range paths {
-		content, err := os.ReadFile(path)
-		if err != nil {
-			if os.IsNotExist(err) {
-				continue
-			}
-			return DBConfig{}, errors.Wrap(err, "failed to read config file: %s", path)
-		}
-
-		var cfg runtimeConfigFile
-		if err := yaml.Unmarshal(content, &cfg); err != nil {
-			return DBConfig{}, errors.Wrap(err, "failed to parse config file: %s", path)
-		}
-
-		return cfg.DB, nil
-	}
-
-	return DBConfig{}, nil
-}
-
-func loadEnvFile(envPath string) (map[string]string, error) {
-	if envPath == "" {
-		return map[string]string{}, nil
-	}
-
-	content, err := os.ReadFile(envPath)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to read env file: %s", envPath)
-	}
-
-	values := make(map[string]string)
-	for index, rawLine := range strings.Split(string(content), "\n") {
-		line := strings.TrimSpace(rawLine)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			return nil, errors.New("invalid env file line %d in %s", index+1, envPath)
-		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		value = strings.Trim(value, `"'`)
-		values[key] = value
-	}
-
-	return values, nil
-}
-
 func applyDBConfig(target *ResolvedConfig, source DBConfig) {
 	if source.Driver != "" {
 		target.Driver = normalizeDriver(source.Driver)
@@ -231,77 +161,6 @@ func applyOptions(target *ResolvedConfig, opts Options) {
 	}
 }
 
-func applyEnvValues(target *ResolvedConfig, lookup EnvironmentLookup) {
-	if lookup == nil {
-		return
-	}
-
-	if value := lookup("PIXIE_DB_DRIVER"); value != "" {
-		target.Driver = normalizeDriver(value)
-	} else if value := lookup("DB_DRIVER"); value != "" {
-		target.Driver = normalizeDriver(value)
-	}
-	if value := lookup("PIXIE_DB_DSN"); value != "" {
-		target.DSN = value
-	} else if value := lookup("DB_DSN"); value != "" {
-		target.DSN = value
-	} else if value := lookup("DATABASE_URL"); value != "" {
-		target.DSN = value
-	}
-	if value := lookup("PIXIE_DB_HOST"); value != "" {
-		target.Host = value
-	} else if value := lookup("DB_HOST"); value != "" {
-		target.Host = value
-	} else if value := lookup("PGHOST"); value != "" {
-		target.Host = value
-	}
-	if value := lookup("PIXIE_DB_PORT"); value != "" {
-		if port, err := strconv.Atoi(value); err == nil {
-			target.Port = port
-		}
-	} else if value := lookup("DB_PORT"); value != "" {
-		if port, err := strconv.Atoi(value); err == nil {
-			target.Port = port
-		}
-	} else if value := lookup("PGPORT"); value != "" {
-		if port, err := strconv.Atoi(value); err == nil {
-			target.Port = port
-		}
-	}
-	if value := lookup("PIXIE_DB_NAME"); value != "" {
-		target.Name = value
-	} else if value := lookup("DB_NAME"); value != "" {
-		target.Name = value
-	} else if value := lookup("PGDATABASE"); value != "" {
-		target.Name = value
-	}
-	if value := lookup("PIXIE_DB_USER"); value != "" {
-		target.User = value
-	} else if value := lookup("DB_USERNAME"); value != "" {
-		target.User = value
-	} else if value := lookup("DB_USER"); value != "" {
-		target.User = value
-	} else if value := lookup("PGUSER"); value != "" {
-		target.User = value
-	}
-	if value := lookup("PIXIE_DB_PASSWORD"); value != "" {
-		target.Password = value
-	} else if value := lookup("DB_PASSWORD"); value != "" {
-		target.Password = value
-	} else if value := lookup("PGPASSWORD"); value != "" {
-		target.Password = value
-	}
-	if value := lookup("PIXIE_DB_SSLMODE"); value != "" {
-		target.SSLMode = value
-	} else if value := lookup("DB_SSL_MODE"); value != "" {
-		target.SSLMode = value
-	} else if value := lookup("DB_SSLMODE"); value != "" {
-		target.SSLMode = value
-	} else if value := lookup("PGSSLMODE"); value != "" {
-		target.SSLMode = value
-	}
-}
-
 func finalizeConfig(target *ResolvedConfig) error {
 	target.Driver = normalizeDriver(target.Driver)
 	if target.Driver != defaultPostgresDriver && target.Driver != defaultSQLiteDriver {
@@ -0,0 +1,603 @@
+package doctor_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+)
+
+// Severity ranks a finding. Only errors make doctor exit non-zero.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is one problem reported by a check.
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Report is the result of running every check against a project.
+type Report struct {
+	Root     string    `json:"root"`
+	Checks   []string  `json:"checks"`
+	Findings []Finding `json:"findings"`
+}
+
+// Errors returns the number of error findings.
+func (r Report) Errors() int {
+	return r.count(SeverityError)
+}
+
+// Warnings returns the number of warning findings.
+func (r Report) Warnings() int {
+	return r.count(SeverityWarning)
+}
+
+func (r Report) count(severity Severity) int {
+	total := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			total++
+		}
+	}
+
+	return total
+}
+
+type check struct {
+	name string
+	run  func(p *project) error
+}
+
+var checks = []check{
+	{"entrypoints", checkEntrypoints},
+	{"migrations", checkMigrations},
+	{"di-tokens", checkInjectionTokens},
+	{"ports", checkPorts},
+	{"env", checkEnvExample},
+}
+
+// CheckNames lists the checks in the order they run.
+func CheckNames() []string {
+	names := make([]string, 0, len(checks))
+	for _, c := range checks {
+		names = append(names, c.name)
+	}
+
+	return names
+}
+
+// Run inspects the project described by cfg against its generator conventions.
+func Run(cfg config.Config) (Report, error) {
+	p := &project{cfg: cfg, report: Report{Root: cfg.Root, Checks: CheckNames(), Findings: []Finding{}}}
+
+	for _, c := range checks {
+		p.check = c.name
+		if err := c.run(p); err != nil {
+			return p.report, errors.Wrap(err, "%s check failed", c.name)
+		}
+	}
+
+	return p.report, nil
+}
+
+type project struct {
+	cfg    config.Config
+	check  string
+	report Report
+}
+
+func (p *project) errorf(path, format string, args ...any) {
+	p.add(SeverityError, path, format, args...)
+}
+
+func (p *project) warnf(path, format string, args ...any) {
+	p.add(SeverityWarning, path, format, args...)
+}
+
+func (p *project) add(severity Severity, path, format string, args ...any) {
+	p.report.Findings = append(p.report.Findings, Finding{
+		Check:    p.check,
+		Severity: severity,
+		Path:     p.rel(path),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// path joins elem onto the project root.
+func (p *project) path(elem ...string) string {
+	return filepath.Join(append([]string{p.cfg.Root}, elem...)...)
+}
+
+// rel returns path relative to the project root for display.
+func (p *project) rel(path string) string {
+	if path == "" || p.cfg.Root == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(p.cfg.Root, path); err == nil {
+		return rel
+	}
+
+	return path
+}
+
+// checkEntrypoints verifies every microservice has an entry point and a JSON config.
+func checkEntrypoints(p *project) error {
+	gen := p.cfg.Generate
+	names, err := microservices(p.path(gen.MicroserviceDir), gen.MicroservicePrefix)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		cmdDir := p.path(gen.CmdDir, name)
+		if !hasGoFiles(cmdDir) {
+			p.errorf(cmdDir, "%s has no entry point (expected Go files in %s)", name, p.rel(cmdDir))
+		}
+
+		configPath := p.path(gen.ConfigsDir, name+".json")
+		if _, err := os.Stat(configPath); err != nil {
+			p.errorf(configPath, "%s has no JSON config (expected %s)", name, p.rel(configPath))
+		}
+	}
+
+	return nil
+}
+
+// microservices lists the directories under dir that carry the microservice prefix.
+func microservices(dir, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read microservice directory: %s", dir)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+func hasGoFiles(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	return len(matches) > 0
+}
+
+// checkMigrations verifies every database.Migration declared in a *_migrations
+// package is listed in that package's migrations.go.
+func checkMigrations(p *project) error {
+	dirs, err := p.findDirs(func(name string) bool { return strings.HasSuffix(name, "_migrations") })
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		registryPath := filepath.Join(dir, "migrations.go")
+		declared, registered, err := migrationNames(dir)
+		if err != nil {
+			return err
+		}
+
+		if registered == nil {
+			if len(declared) > 0 {
+				p.errorf(registryPath, "migrations.go is missing; %d migration(s) are not registered", len(declared))
+			}
+			continue
+		}
+
+		for _, name := range sortedKeys(declared) {
+			if !registered[name] {
+				p.errorf(declared[name], "migration %s is not registered in %s", name, p.rel(registryPath))
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrationNames returns the package-level Migration variables declared in dir, keyed by
+// name with their file, and the identifiers referenced from migrations.go. registered is
+// nil when migrations.go does not exist.
+func migrationNames(dir string) (map[string]string, map[string]bool, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list migrations in %s", dir)
+	}
+
+	declared := map[string]string{}
+	var registered map[string]bool
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to parse %s", path)
+		}
+
+		if filepath.Base(path) == "migrations.go" {
+			registered = map[string]bool{}
+			ast.Inspect(file, func(n ast.Node) bool {
+				if ident, ok := n.(*ast.Ident); ok {
+					registered[ident.Name] = true
+				}
+				return true
+			})
+			continue
+		}
+
+		for _, spec := range valueSpecs(file) {
+			if !isMigrationSpec(spec) {
+				continue
+			}
+			for _, name := range spec.Names {
+				declared[name.Name] = path
+			}
+		}
+	}
+
+	return declared, registered, nil
+}
+
+func valueSpecs(file *ast.File) []*ast.ValueSpec {
+	var specs []*ast.ValueSpec
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+				specs = append(specs, valueSpec)
+			}
+		}
+	}
+
+	return specs
+}
+
+// isMigrationSpec reports whether spec declares a Migration, either by type
+// (var X database.Migration) or by composite literal (var X = database.Migration{...}).
+func isMigrationSpec(spec *ast.ValueSpec) bool {
+	if isMigrationType(spec.Type) {
+		return true
+	}
+	for _, value := range spec.Values {
+		if lit, ok := value.(*ast.CompositeLit); ok && isMigrationType(lit.Type) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isMigrationType(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.SelectorExpr:
+		return t.Sel.Name == "Migration"
+	case *ast.Ident:
+		return t.Name == "Migration"
+	}
+
+	return false
+}
+
+// checkInjectionTokens verifies every DI token referenced from a registry.go exists
+// in injection_tokens.go of the di package in the infra directory.
+func checkInjectionTokens(p *project) error {
+	diDir := filepath.Join(p.cfg.Generate.InfraDir, "di")
+	tokensFile := filepath.Join(diDir, "injection_tokens.go")
+	tokensPath := p.path(tokensFile)
+	content, err := os.ReadFile(tokensPath)
+	if os.IsNotExist(err) {
+		p.warnf(tokensPath, "%s not found; DI tokens were not checked", tokensFile)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read %s", tokensPath)
+	}
+
+	tokensFileAST, err := parser.ParseFile(token.NewFileSet(), tokensPath, content, 0)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse %s", tokensPath)
+	}
+	defined := map[string]bool{}
+	for _, spec := range valueSpecs(tokensFileAST) {
+		for _, name := range spec.Names {
+			defined[name.Name] = true
+		}
+	}
+
+	registries, err := p.findFiles("registry.go")
+	if err != nil {
+		return err
+	}
+
+	for _, path := range registries {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse %s", path)
+		}
+
+		alias := importName(file, "/"+filepath.ToSlash(filepath.Clean(diDir)))
+		if alias == "" {
+			continue
+		}
+
+		seen := map[string]bool{}
+		ast.Inspect(file, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == alias && !defined[sel.Sel.Name] && !seen[sel.Sel.Name] {
+				seen[sel.Sel.Name] = true
+				p.errorf(path, "line %d: DI token %s is not defined in %s", fset.Position(sel.Pos()).Line, sel.Sel.Name, tokensFile)
+			}
+			return true
+		})
+	}
+
+	return nil
+}
+
+// importName returns the local name of the import whose path ends with suffix.
+func importName(file *ast.File, suffix string) string {
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || !strings.HasSuffix(importPath, suffix) {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+
+		return filepath.Base(importPath)
+	}
+
+	return ""
+}
+
+// listenKeys are the top-level config keys holding listen addresses.
+var listenKeys = []string{"listen_addr", "listen_metrics_addr"}
+
+// checkPorts flags HTTP and metrics ports bound by more than one listener across the
+// microservice configs. ${env.*} references in addresses are resolved from the process
+// environment, the --env file and the project .env file; addresses left unresolved are
+// not compared.
+func checkPorts(p *project) error {
+	configs, err := p.configFiles()
+	if err != nil {
+		return err
+	}
+	lookup, err := p.envLookup()
+	if err != nil {
+		return err
+	}
+
+	owners := map[string][]string{}
+	for _, path := range configs {
+		var document map[string]any
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read %s", path)
+		}
+		if err := json.Unmarshal(content, &document); err != nil {
+			p.errorf(path, "invalid JSON: %v", err)
+			continue
+		}
+
+		for _, key := range listenKeys {
+			addr, _ := document[key].(string)
+			port := listenPort(resolveEnv(addr, lookup))
+			if port == "" {
+				continue
+			}
+			owners[port] = append(owners[port], fmt.Sprintf("%s (%s)", p.rel(path), key))
+		}
+	}
+
+	for _, port := range sortedKeys(owners) {
+		if users := owners[port]; len(users) > 1 {
+			p.errorf("", "port %s is used by %s", port, strings.Join(users, ", "))
+		}
+	}
+
+	return nil
+}
+
+// listenPort extracts the port of a literal listen address such as ":8080" or
+// "0.0.0.0:8080". It returns an empty string for unresolved ${env.*} references.
+func listenPort(addr string) string {
+	if addr == "" || strings.Contains(addr, "${") {
+		return ""
+	}
+
+	idx := strings.LastIndex(addr, ":")
+	if idx < 0 {
+		return ""
+	}
+	if _, err := strconv.Atoi(addr[idx+1:]); err != nil {
+		return ""
+	}
+
+	return addr[idx+1:]
+}
+
+var envReference = regexp.MustCompile(`\$\{env\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// envValue is a complete ${env.NAME} reference.
+var envValue = regexp.MustCompile(`\$\{env\.([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveEnv replaces the ${env.NAME} references in value that lookup knows.
+func resolveEnv(value string, lookup config.EnvironmentLookup) string {
+	return envValue.ReplaceAllStringFunc(value, func(reference string) string {
+		if resolved := lookup(envValue.FindStringSubmatch(reference)[1]); resolved != "" {
+			return resolved
+		}
+		return reference
+	})
+}
+
+// envLookup looks variables up in the process environment, then the --env file, then
+// the .env file at the project root.
+func (p *project) envLookup() (config.EnvironmentLookup, error) {
+	dotEnv := map[string]string{}
+	if _, err := os.Stat(p.path(".env")); err == nil {
+		if dotEnv, err = config.LoadEnvFile(p.path(".env")); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(name string) string {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		if value := p.cfg.Env[name]; value != "" {
+			return value
+		}
+		return dotEnv[name]
+	}, nil
+}
+
+// checkEnvExample flags ${env.*} variables used by microservice configs that are
+// not documented in .env.example.
+func checkEnvExample(p *project) error {
+	configs, err := p.configFiles()
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+
+	examplePath := p.path(".env.example")
+	if _, err := os.Stat(examplePath); os.IsNotExist(err) {
+		p.warnf(examplePath, ".env.example not found; ${env.*} variables were not checked")
+		return nil
+	}
+	documented, err := config.LoadEnvFile(examplePath)
+	if err != nil {
+		return err
+	}
+
+	missing := map[string][]string{}
+	for _, path := range configs {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read %s", path)
+		}
+		for _, match := range envReference.FindAllStringSubmatch(string(content), -1) {
+			if _, ok := documented[match[1]]; !ok {
+				missing[match[1]] = appendUnique(missing[match[1]], p.rel(path))
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(missing) {
+		p.warnf(examplePath, "%s is referenced by %s but missing from .env.example", name, strings.Join(missing[name], ", "))
+	}
+
+	return nil
+}
+
+func (p *project) configFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(p.path(p.cfg.Generate.ConfigsDir), "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list config files")
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// skippedDirs are never descended into while searching the project.
+var skippedDirs = map[string]bool{".git": true, "vendor": true, "node_modules": true, ".pixie": true}
+
+func (p *project) findDirs(match func(name string) bool) ([]string, error) {
+	var dirs []string
+	err := p.walk(func(path string, entry os.DirEntry) {
+		if entry.IsDir() && match(entry.Name()) {
+			dirs = append(dirs, path)
+		}
+	})
+
+	return dirs, err
+}
+
+func (p *project) findFiles(name string) ([]string, error) {
+	var files []string
+	err := p.walk(func(path string, entry os.DirEntry) {
+		if !entry.IsDir() && entry.Name() == name {
+			files = append(files, path)
+		}
+	})
+
+	return files, err
+}
+
+func (p *project) walk(visit func(path string, entry os.DirEntry)) error {
+	root := p.path()
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && path != root && skippedDirs[entry.Name()] {
+			return filepath.SkipDir
+		}
+
+		visit(path, entry)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to walk project: %s", root)
+	}
+
+	return nil
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+
+	return append(items, item)
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
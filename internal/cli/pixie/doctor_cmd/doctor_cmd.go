@@ -0,0 +1,112 @@
+package doctor_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+)
+
+// DoctorCmd returns the doctor command.
+func DoctorCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check a generated project against its conventions",
+		Long: `Check a generated project against the generate: conventions of its config.
+
+Checks:
+  entrypoints  every ms_* directory under microservice_dir has an entry point
+               under cmd_dir and a JSON config under configs_dir
+  migrations   every migration in a *_migrations package is registered in
+               its migrations.go
+  di-tokens    every DI token referenced from a registry.go is defined in
+               di/injection_tokens.go under infra_dir
+  ports        no HTTP or metrics port is bound by more than one config;
+               ${env.*} addresses are resolved from the environment, the
+               --env file and .env
+  env          every ${env.*} variable used by a config is listed in .env.example
+
+Errors make the command exit non-zero; warnings do not.
+
+Examples:
+  # Print a report for the current project
+  pixie doctor
+
+  # Machine-readable report for CI
+  pixie doctor --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return errors.New("invalid format %q (expected text or json)", format)
+			}
+
+			cfg, err := config.Load(config.CurrentOptions())
+			if err != nil {
+				return errors.Wrap(err, "failed to load pixie config file")
+			}
+
+			report, err := Run(cfg)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if format == "json" {
+				err = writeJSON(out, report)
+			} else {
+				writeText(out, report)
+			}
+			if err != nil {
+				return err
+			}
+
+			if report.Errors() > 0 {
+				cmd.SilenceUsage = true
+				return errors.New("doctor found %d error(s)", report.Errors())
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+func writeJSON(out io.Writer, report Report) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return errors.Wrap(err, "failed to encode report")
+	}
+
+	return nil
+}
+
+func writeText(out io.Writer, report Report) {
+	fmt.Fprintf(out, "Project: %s\n", report.Root)
+	fmt.Fprintf(out, "Checks:  %s\n\n", strings.Join(report.Checks, ", "))
+
+	for _, finding := range report.Findings {
+		location := ""
+		if finding.Path != "" {
+			location = finding.Path + ": "
+		}
+		fmt.Fprintf(out, "%-7s [%s] %s%s\n", finding.Severity, finding.Check, location, finding.Message)
+	}
+
+	if len(report.Findings) == 0 {
+		fmt.Fprintln(out, "No problems found.")
+		return
+	}
+
+	fmt.Fprintf(out, "\n%d error(s), %d warning(s)\n", report.Errors(), report.Warnings())
+}
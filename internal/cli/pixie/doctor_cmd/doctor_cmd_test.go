@@ -0,0 +1,251 @@
+package doctor_cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+)
+
+// writeProject writes files (path -> content) under a new project root.
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	files["go.mod"] = "module example.com/demo\n"
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	return root
+}
+
+const tokensGo = `package di
+
+import pdi "github.com/pixie-sh/di-go"
+
+var (
+	RegistryTokenOrdersDataLayer = pdi.RegisterInjectionToken("orders_data_layer")
+)
+`
+
+const migrationGo = `package orders_migrations
+
+import "github.com/pixie-sh/database-helpers-go/database"
+
+var CreateOrdersTable1 = database.Migration{ID: "1"}
+
+var AddOrderStatus2 = database.Migration{ID: "2"}
+`
+
+func healthyProject() map[string]string {
+	return map[string]string{
+		"internal/ms/ms_orders/microservice.go":                       "package ms_orders\n",
+		"cmd/ms/ms_orders/application.go":                             "package main\n",
+		"misc/configs/ms_orders.json":                                 `{"listen_addr": ":8080", "listen_metrics_addr": ":9090", "dsn": "${env.DB_HOST}"}`,
+		".env.example":                                                "DB_HOST=localhost\n",
+		"infra/di/injection_tokens.go":                                tokensGo,
+		"internal/domain/orders/orders_migrations/1_create_orders.go": migrationGo,
+		"internal/domain/orders/orders_migrations/migrations.go": `package orders_migrations
+
+var Migrations = []*database.Migration{
+	&CreateOrdersTable1,
+	&AddOrderStatus2,
+}
+`,
+		"internal/domain/orders/registry.go": `package orders
+
+import internaldi "example.com/demo/infra/di"
+
+var token = internaldi.RegistryTokenOrdersDataLayer
+`,
+	}
+}
+
+func runDoctor(t *testing.T, root string) Report {
+	t.Helper()
+
+	cfg, err := config.Load(config.Options{WorkDir: root})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	report, err := Run(cfg)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	return report
+}
+
+func TestRun_HealthyProject(t *testing.T) {
+	report := runDoctor(t, writeProject(t, healthyProject()))
+
+	if len(report.Findings) != 0 {
+		t.Errorf("Findings = %+v, want none", report.Findings)
+	}
+}
+
+func TestRun_ReportsProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(files map[string]string)
+		check    string
+		severity Severity
+		message  string
+	}{
+		{
+			name:     "missing entry point",
+			modify:   func(files map[string]string) { delete(files, "cmd/ms/ms_orders/application.go") },
+			check:    "entrypoints",
+			severity: SeverityError,
+			message:  "ms_orders has no entry point",
+		},
+		{
+			name: "missing config",
+			modify: func(files map[string]string) {
+				files["internal/ms/ms_billing/microservice.go"] = "package ms_billing\n"
+				files["cmd/ms/ms_billing/application.go"] = "package main\n"
+			},
+			check:    "entrypoints",
+			severity: SeverityError,
+			message:  "ms_billing has no JSON config",
+		},
+		{
+			name: "unregistered migration",
+			modify: func(files map[string]string) {
+				files["internal/domain/orders/orders_migrations/migrations.go"] = "package orders_migrations\n\nvar Migrations = []*database.Migration{&CreateOrdersTable1}\n"
+			},
+			check:    "migrations",
+			severity: SeverityError,
+			message:  "migration AddOrderStatus2 is not registered",
+		},
+		{
+			name: "undefined DI token",
+			modify: func(files map[string]string) {
+				files["internal/ms/ms_orders/registry.go"] = "package ms_orders\n\nimport internaldi \"example.com/demo/infra/di\"\n\nvar token = internaldi.RegistryTokenOrdersService\n"
+			},
+			check:    "di-tokens",
+			severity: SeverityError,
+			message:  "DI token RegistryTokenOrdersService is not defined",
+		},
+		{
+			name: "duplicate port",
+			modify: func(files map[string]string) {
+				files["misc/configs/ms_billing.json"] = `{"listen_addr": "0.0.0.0:9090"}`
+			},
+			check:    "ports",
+			severity: SeverityError,
+			message:  "port 9090 is used by",
+		},
+		{
+			name: "duplicate port from the env file",
+			modify: func(files map[string]string) {
+				files["misc/configs/ms_billing.json"] = `{"listen_addr": "${env.LISTEN_ADDR}"}`
+				files[".env"] = "LISTEN_ADDR=:8080\n"
+			},
+			check:    "ports",
+			severity: SeverityError,
+			message:  "port 8080 is used by",
+		},
+		{
+			name:     "undocumented env variable",
+			modify:   func(files map[string]string) { files[".env.example"] = "OTHER=1\n" },
+			check:    "env",
+			severity: SeverityWarning,
+			message:  "DB_HOST is referenced by misc/configs/ms_orders.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := healthyProject()
+			tt.modify(files)
+			report := runDoctor(t, writeProject(t, files))
+
+			for _, finding := range report.Findings {
+				if finding.Check == tt.check && finding.Severity == tt.severity && strings.Contains(finding.Message, tt.message) {
+					return
+				}
+			}
+			t.Errorf("Findings = %+v, want %s %s containing %q", report.Findings, tt.severity, tt.check, tt.message)
+		})
+	}
+}
+
+func TestRun_CustomInfraDir(t *testing.T) {
+	files := healthyProject()
+	files[".pixie.yaml"] = "generate:\n  infra_dir: platform\n"
+	files["platform/di/injection_tokens.go"] = files["infra/di/injection_tokens.go"]
+	delete(files, "infra/di/injection_tokens.go")
+	files["internal/domain/orders/registry.go"] = "package orders\n\nimport internaldi \"example.com/demo/platform/di\"\n\nvar token = internaldi.RegistryTokenOrdersDataLayer\n"
+
+	if report := runDoctor(t, writeProject(t, files)); len(report.Findings) != 0 {
+		t.Errorf("Findings = %+v, want none", report.Findings)
+	}
+
+	files["internal/ms/ms_orders/registry.go"] = "package ms_orders\n\nimport internaldi \"example.com/demo/platform/di\"\n\nvar token = internaldi.RegistryTokenOrdersService\n"
+	report := runDoctor(t, writeProject(t, files))
+	for _, finding := range report.Findings {
+		if finding.Check == "di-tokens" && strings.Contains(finding.Message, "RegistryTokenOrdersService is not defined in platform/di/injection_tokens.go") {
+			return
+		}
+	}
+	t.Errorf("Findings = %+v, want the undefined DI token in platform/di/injection_tokens.go", report.Findings)
+}
+
+func TestListenPort(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{":8080", "8080"},
+		{"0.0.0.0:9090", "9090"},
+		{"${env.LISTEN_ADDR}", ""},
+		{"localhost", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := listenPort(tt.addr); got != tt.want {
+			t.Errorf("listenPort(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestDoctorCmdJSONAndExitCode(t *testing.T) {
+	files := healthyProject()
+	delete(files, "misc/configs/ms_orders.json")
+	root := writeProject(t, files)
+
+	config.SetOptions(config.Options{WorkDir: root})
+	defer config.SetOptions(config.Options{})
+
+	var out bytes.Buffer
+	cmd := DoctorCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--format", "json"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("doctor error = nil, want error for missing config")
+	}
+
+	var report Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v\n%s", err, out.String())
+	}
+	if report.Errors() != 1 {
+		t.Errorf("Errors() = %d, want 1: %+v", report.Errors(), report.Findings)
+	}
+}
@@ -0,0 +1,254 @@
+package features
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+)
+
+// Feature is an optional capability of a generated microservice or domain.
+type Feature struct {
+	Name        string
+	Description string
+	Requires    []string // features enabled along with this one
+	Conflicts   []string // features that cannot be enabled with this one
+	Templates   []string // <pack>/<template> files only generated with this feature
+	Config      []string // config.json sections only written with this feature
+}
+
+var registry = []Feature{
+	{
+		Name:        "database",
+		Description: "PostgreSQL integration with GORM: data layer, entities, repositories and migrations",
+		Templates: []string{
+			"scaffold/data_layer.go.tmpl",
+			"scaffold/entities.go.tmpl",
+			"scaffold/repositories.go.tmpl",
+			"scaffold/entity_migration.go.tmpl",
+			"scaffold/domain_migrations.go.tmpl",
+			"scaffold/business_layer_test.go.tmpl",
+			"scaffold/repository_test.go.tmpl",
+			"scaffold/services_test.go.tmpl",
+			"scaffold/http_controllers_test.go.tmpl",
+			"scaffold/testdb.go.tmpl",
+			"golang/data_layer.go.tmpl",
+			"golang/entities.go.tmpl",
+			"golang/repositories.go.tmpl",
+			"golang/entity_migration.go.tmpl",
+			"golang/domain_migrations.go.tmpl",
+		},
+		Config: []string{"<domain>_business_layer"},
+	},
+	{
+		Name:        "metrics",
+		Description: "Prometheus metrics and health checks",
+	},
+	{
+		Name:        "auth",
+		Description: "JWT authentication and authorization gates on the routes",
+		Requires:    []string{"tokens"},
+		Config:      []string{"authorization_gates_bundle", "token_services_bundle"},
+	},
+	{
+		Name:        "tokens",
+		Description: "JWT token management and sessions",
+	},
+	{
+		Name:        "cache",
+		Description: "Redis caching integration",
+	},
+	{
+		Name:        "events",
+		Description: "Event publishing and handling",
+		Requires:    []string{"cache"},
+	},
+	{
+		Name:        "notifications",
+		Description: "Push notification services",
+		Requires:    []string{"events"},
+	},
+	{
+		Name:        "backoffice",
+		Description: "Admin and management endpoints",
+		Requires:    []string{"auth"},
+		Templates:   []string{"scaffold/http_bo_controllers.go.tmpl", "golang/http_bo_controllers.go.tmpl"},
+	},
+	{
+		Name:        "google_oauth",
+		Description: "Sign in with Google",
+		Requires:    []string{"auth"},
+		Config:      []string{"google_auth_business_layer"},
+	},
+	{
+		Name:        "validation",
+		Description: "Request validation bundle",
+	},
+	{
+		Name:        "adapters",
+		Description: "External service adapters",
+	},
+	{
+		Name:        "apis",
+		Description: "External API integration",
+	},
+	{
+		Name:        "e2e",
+		Description: "End-to-end test suite bootstrapping the microservice",
+		Templates:   []string{"golang/e2e_configuration.go.tmpl", "golang/e2e_bootstrap_test.go.tmpl"},
+	},
+}
+
+// All returns every feature, in registry order.
+func All() []Feature {
+	return append([]Feature{}, registry...)
+}
+
+// Names returns the names of every feature, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for _, feature := range registry {
+		names = append(names, feature.Name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Lookup returns the feature called name.
+func Lookup(name string) (Feature, bool) {
+	return lookup(registry, name)
+}
+
+// Parse splits a comma-separated feature list, failing on unknown feature names.
+func Parse(list string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if err := Validate(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// Validate fails when name is not a registered feature, suggesting the closest one.
+func Validate(name string) error {
+	if _, ok := Lookup(name); ok {
+		return nil
+	}
+	if suggestion := config.Suggest(name, Names()); suggestion != "" {
+		return errors.New("unknown feature %q (did you mean %q?)", name, suggestion)
+	}
+
+	return errors.New("unknown feature %q; run pixie generate features to list them", name)
+}
+
+// Resolve enables the features the enabled ones require, transitively, and fails when
+// two enabled features conflict. Features missing from the registry are left as they are.
+func Resolve(enabled map[string]bool) (map[string]bool, error) {
+	return resolve(registry, enabled)
+}
+
+// Includes reports whether the <pack>/<template> file is generated with the enabled
+// features: it is unless a feature contributes it and that feature is disabled.
+func Includes(enabled map[string]bool, template string) bool {
+	for _, feature := range registry {
+		for _, contributed := range feature.Templates {
+			if contributed == template && !enabled[feature.Name] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// IncludesConfig reports whether the config.json section is written with the enabled
+// features: it is unless a feature contributes it and that feature is disabled.
+func IncludesConfig(enabled map[string]bool, section string) bool {
+	for _, feature := range registry {
+		for _, contributed := range feature.Config {
+			if contributed == section && !enabled[feature.Name] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Complete completes the last name of a comma-separated --features value, leaving out
+// the features already listed.
+func Complete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	listed, current := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		listed, current = toComplete[:i+1], toComplete[i+1:]
+	}
+
+	seen := map[string]bool{}
+	for _, name := range strings.Split(listed, ",") {
+		seen[strings.TrimSpace(name)] = true
+	}
+
+	var completions []string
+	for _, feature := range registry {
+		if seen[feature.Name] || !strings.HasPrefix(feature.Name, current) {
+			continue
+		}
+		completions = append(completions, listed+feature.Name+"\t"+feature.Description)
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+func lookup(features []Feature, name string) (Feature, bool) {
+	for _, feature := range features {
+		if feature.Name == name {
+			return feature, true
+		}
+	}
+
+	return Feature{}, false
+}
+
+func resolve(features []Feature, enabled map[string]bool) (map[string]bool, error) {
+	for {
+		added := false
+		for _, feature := range features {
+			if !enabled[feature.Name] {
+				continue
+			}
+			for _, required := range feature.Requires {
+				if !enabled[required] {
+					enabled[required] = true
+					added = true
+				}
+			}
+		}
+		if !added {
+			break
+		}
+	}
+
+	for _, feature := range features {
+		if !enabled[feature.Name] {
+			continue
+		}
+		for _, conflict := range feature.Conflicts {
+			if enabled[conflict] {
+				return nil, errors.New("features %q and %q cannot be enabled together", feature.Name, conflict)
+			}
+		}
+	}
+
+	return enabled, nil
+}
@@ -0,0 +1,147 @@
+package features
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr string
+	}{
+		{name: "empty", list: "", want: nil},
+		{name: "names", list: " auth, cache ,", want: []string{"auth", "cache"}},
+		{name: "misspelled", list: "auth,databse", wantErr: `unknown feature "databse" (did you mean "database"?)`},
+		{name: "unknown", list: "kubernetes", wantErr: `unknown feature "kubernetes"; run pixie generate features to list them`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.list)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	got, err := Resolve(map[string]bool{"notifications": true, "backoffice": true})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	want := map[string]bool{"notifications": true, "events": true, "cache": true, "backoffice": true, "auth": true, "tokens": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resolve() = %v, want %v", got, want)
+	}
+}
+
+func TestResolveConflicts(t *testing.T) {
+	registry := []Feature{
+		{Name: "sqlite", Conflicts: []string{"database"}},
+		{Name: "database"},
+		{Name: "backoffice", Requires: []string{"database"}},
+	}
+
+	if _, err := resolve(registry, map[string]bool{"sqlite": true}); err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+
+	// The conflict comes in through a required feature
+	_, err := resolve(registry, map[string]bool{"sqlite": true, "backoffice": true})
+	if err == nil || !strings.Contains(err.Error(), `features "sqlite" and "database" cannot be enabled together`) {
+		t.Fatalf("resolve() error = %v, want a conflict", err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	for _, feature := range registry {
+		if feature.Description == "" {
+			t.Errorf("feature %s has no description", feature.Name)
+		}
+		for _, related := range append(append([]string{}, feature.Requires...), feature.Conflicts...) {
+			if _, ok := Lookup(related); !ok {
+				t.Errorf("feature %s refers to unknown feature %s", feature.Name, related)
+			}
+		}
+		for _, template := range feature.Templates {
+			if !strings.Contains(template, "/") {
+				t.Errorf("feature %s template %s is not a <pack>/<template> ID", feature.Name, template)
+			}
+		}
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	enabled := map[string]bool{"database": true}
+
+	tests := map[string]bool{
+		"scaffold/entities.go.tmpl":            true,
+		"scaffold/http_bo_controllers.go.tmpl": false,
+		"scaffold/models.go.tmpl":              true,
+	}
+	for template, want := range tests {
+		if got := Includes(enabled, template); got != want {
+			t.Errorf("Includes(%s) = %v, want %v", template, got, want)
+		}
+	}
+}
+
+func TestIncludesConfig(t *testing.T) {
+	enabled := map[string]bool{"auth": true}
+
+	tests := map[string]bool{
+		"authorization_gates_bundle": true,
+		"<domain>_business_layer":    false,
+		"http_server_bundle":         true,
+	}
+	for section, want := range tests {
+		if got := IncludesConfig(enabled, section); got != want {
+			t.Errorf("IncludesConfig(%s) = %v, want %v", section, got, want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		toComplete string
+		want       []string
+	}{
+		{toComplete: "da", want: []string{"database"}},
+		{toComplete: "database,a", want: []string{"database,auth", "database,adapters", "database,apis"}},
+		{toComplete: "auth,database,au", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.toComplete, func(t *testing.T) {
+			got, directive := Complete(&cobra.Command{}, nil, tt.toComplete)
+			var names []string
+			for _, completion := range got {
+				name, _, _ := strings.Cut(completion, "\t")
+				names = append(names, name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Complete(%q) = %v, want %v", tt.toComplete, names, tt.want)
+			}
+			if directive&cobra.ShellCompDirectiveNoSpace == 0 {
+				t.Errorf("Complete(%q) directive = %v, want NoSpace", tt.toComplete, directive)
+			}
+		})
+	}
+}
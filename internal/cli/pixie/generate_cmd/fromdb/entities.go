@@ -0,0 +1,354 @@
+package fromdb
+
+import (
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// integerTypes are the declared types mapped to int, besides the 64-bit ones.
+var integerTypes = map[string]bool{
+	"int": true, "integer": true, "int2": true, "int4": true, "smallint": true,
+	"tinyint": true, "mediumint": true, "serial": true, "smallserial": true,
+}
+
+// Entity is the entity generated for an introspected table.
+type Entity struct {
+	Table        string // orders
+	Name         string // order: the entity and file name
+	Type         string // Order
+	Fields       []Field
+	Associations []Association
+	ForeignKeys  []Field // foreign key fields, each with a ListBy finder
+	PrimaryKey   []Field
+	Constraints  []ForeignKey // foreign key constraints of the table
+}
+
+// Field is a column of an entity.
+type Field struct {
+	Name   string // CustomerID
+	Column string // customer_id
+	Type   string // Go type: *time.Time
+	Tag    string // gorm tag: not null;type:integer;index
+}
+
+// Association is a belongs-to association resolved from a foreign key.
+type Association struct {
+	Name string // Customer
+	Type string // Customer
+	Tag  string // foreignKey:CustomerID
+	JSON string // customer
+}
+
+// Param returns the name of a Go parameter holding the field: customerID.
+func (f Field) Param() string {
+	for i, r := range f.Name {
+		if !unicode.IsUpper(r) {
+			if i > 1 {
+				i--
+			}
+			return param(strings.ToLower(f.Name[:i]) + f.Name[i:])
+		}
+	}
+	return param(strings.ToLower(f.Name))
+}
+
+// param keeps a parameter name from being a Go keyword: typeValue for type.
+func param(name string) string {
+	if token.IsKeyword(name) {
+		return name + "Value"
+	}
+	return name
+}
+
+// ValueType returns the Go type of a value of the field, without the nullable pointer.
+func (f Field) ValueType() string {
+	if strings.HasPrefix(f.Type, "*") {
+		return f.Type[1:]
+	}
+	return f.Type
+}
+
+// Key returns the primary key field the repository retrieves records by, or nil when
+// the primary key is missing or spans several columns.
+func (e Entity) Key() *Field {
+	if len(e.PrimaryKey) != 1 {
+		return nil
+	}
+	return &e.PrimaryKey[0]
+}
+
+// Imports returns the import paths the entity fields need.
+func (e Entity) Imports() []string {
+	return imports(e.Fields)
+}
+
+// RepositoryImports returns the import paths the parameters of the repository finders need.
+func (e Entity) RepositoryImports() []string {
+	fields := e.ForeignKeys
+	if key := e.Key(); key != nil {
+		fields = append([]Field{*key}, fields...)
+	}
+	return imports(fields)
+}
+
+// imports returns the standard library import paths the types of fields need.
+func imports(fields []Field) []string {
+	paths := map[string]bool{}
+	for _, field := range fields {
+		switch field.ValueType() {
+		case "time.Time":
+			paths["time"] = true
+		case "json.RawMessage":
+			paths["encoding/json"] = true
+		}
+	}
+
+	var result []string
+	for importPath := range paths {
+		result = append(result, importPath)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// DefaultOrder returns the columns the entity List query sorts by when the query
+// parameters ask for no order: its primary key columns, or its first column when it
+// has none.
+func (e Entity) DefaultOrder() []string {
+	var columns []string
+	for _, field := range e.PrimaryKey {
+		columns = append(columns, field.Column)
+	}
+	if len(columns) == 0 && len(e.Fields) > 0 {
+		columns = append(columns, e.Fields[0].Column)
+	}
+	return columns
+}
+
+// SortColumns returns the columns the entity List query sorts by: its text, number
+// and time columns.
+func (e Entity) SortColumns() []genshared.SortColumn {
+	var columns []genshared.SortColumn
+	for _, field := range e.Fields {
+		switch field.ValueType() {
+		case "string":
+			columns = append(columns, genshared.SortColumn{Name: field.Column, Type: "text"})
+		case "int", "int64", "float64":
+			columns = append(columns, genshared.SortColumn{Name: field.Column, Type: "number"})
+		case "time.Time":
+			columns = append(columns, genshared.SortColumn{Name: field.Column, Type: "date"})
+		}
+	}
+	return columns
+}
+
+// Entities maps the introspected tables to entities. Foreign keys to tables outside
+// the introspected set keep their column but get no association.
+func Entities(tables []Table) []Entity {
+	types := map[string]string{}
+	for _, table := range tables {
+		types[table.Name] = initshared.ToCamelCase(EntityName(table.Name))
+	}
+
+	// Foreign key columns take the Go type of the key they reference, so that an
+	// integer column referencing an auto-increment key is an int64 as the key is.
+	keyTypes := map[string]string{}
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			column.Nullable = false
+			keyTypes[table.Name+"."+column.Name] = GoType(column)
+		}
+	}
+
+	var entities []Entity
+	for _, table := range tables {
+		references := map[string]string{}
+		for _, fk := range table.ForeignKeys {
+			if goType, ok := keyTypes[fk.ReferenceTable+"."+fk.ReferenceColumn]; ok {
+				references[fk.Column] = goType
+			}
+		}
+
+		entity := Entity{
+			Table:       table.Name,
+			Name:        EntityName(table.Name),
+			Type:        types[table.Name],
+			Constraints: table.ForeignKeys,
+		}
+
+		fields := map[string]Field{}
+		for _, column := range table.Columns {
+			field := Field{
+				Name:   FieldName(column.Name),
+				Column: column.Name,
+				Type:   GoType(column),
+				Tag:    gormTag(table, column),
+			}
+			if goType, ok := references[column.Name]; ok {
+				if strings.HasPrefix(field.Type, "*") {
+					goType = "*" + goType
+				}
+				field.Type = goType
+			}
+			fields[column.Name] = field
+			entity.Fields = append(entity.Fields, field)
+			if column.PrimaryKey {
+				entity.PrimaryKey = append(entity.PrimaryKey, field)
+			}
+		}
+
+		taken := map[string]bool{}
+		for _, field := range entity.Fields {
+			taken[field.Name] = true
+		}
+		for _, fk := range table.ForeignKeys {
+			field, ok := fields[fk.Column]
+			if !ok {
+				continue
+			}
+			entity.ForeignKeys = append(entity.ForeignKeys, field)
+
+			referenceType, ok := types[fk.ReferenceTable]
+			if !ok {
+				continue
+			}
+			association := Association{Name: associationName(fk.Column, referenceType), Type: referenceType}
+			if taken[association.Name] {
+				continue
+			}
+			taken[association.Name] = true
+
+			association.Tag = "foreignKey:" + field.Name
+			if references := FieldName(fk.ReferenceColumn); references != "ID" {
+				association.Tag += ";references:" + references
+			}
+			association.JSON = initshared.Snake(association.Name)
+			entity.Associations = append(entity.Associations, association)
+		}
+
+		entities = append(entities, entity)
+	}
+
+	return entities
+}
+
+// EntityName returns the entity name of a table: order_item for order_items.
+func EntityName(table string) string {
+	return initshared.Snake(initshared.Singular(table))
+}
+
+// FieldName returns the Go field name of a column: CustomerID for customer_id.
+func FieldName(column string) string {
+	name := genshared.GoName(column)
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "Column" + name
+	}
+	return name
+}
+
+// associationName names the association of a foreign key column after the column
+// without its _id suffix, or after the referenced entity.
+func associationName(column, referenceType string) string {
+	snake := initshared.Snake(column)
+	if trimmed := strings.TrimSuffix(snake, "_id"); trimmed != snake && trimmed != "" {
+		return FieldName(trimmed)
+	}
+	return referenceType
+}
+
+// GoType maps the declared type of a column to a Go type. Nullable columns are
+// pointers, except the types that hold nil themselves.
+func GoType(column Column) string {
+	dbType := column.Type
+	if open := strings.IndexByte(dbType, '('); open >= 0 {
+		dbType = dbType[:open]
+	}
+	dbType = strings.TrimSpace(dbType)
+
+	var goType string
+	switch {
+	case dbType == "bool" || dbType == "boolean":
+		goType = "bool"
+	case dbType == "bigint" || dbType == "int8" || dbType == "bigserial" || column.AutoIncrement && dbType == "integer":
+		goType = "int64"
+	case integerTypes[dbType] || strings.HasSuffix(dbType, " int"):
+		goType = "int"
+	case dbType == "numeric" || dbType == "decimal" || dbType == "money" || strings.Contains(dbType, "real") ||
+		strings.Contains(dbType, "double") || strings.Contains(dbType, "float"):
+		goType = "float64"
+	case strings.HasPrefix(dbType, "timestamp") || dbType == "datetime" || dbType == "date":
+		goType = "time.Time"
+	case dbType == "json" || dbType == "jsonb":
+		return "json.RawMessage"
+	case dbType == "blob" || dbType == "bytea":
+		return "[]byte"
+	default:
+		// char, varchar, text, uuid and the types without a closer Go type
+		goType = "string"
+	}
+
+	if column.Nullable {
+		return "*" + goType
+	}
+	return goType
+}
+
+// gormTag returns the gorm tag of a column, declaring its type, constraints and
+// indexes as they are in the database.
+func gormTag(table Table, column Column) string {
+	var settings []string
+	if initshared.Snake(FieldName(column.Name)) != column.Name {
+		settings = append(settings, "column:"+column.Name)
+	}
+	if column.PrimaryKey {
+		settings = append(settings, "primaryKey")
+	}
+	if column.AutoIncrement {
+		settings = append(settings, "autoIncrement")
+	}
+	if column.Type != "" {
+		settings = append(settings, "type:"+column.Type)
+	}
+	if !column.PrimaryKey && !column.Nullable {
+		settings = append(settings, "not null")
+	}
+	if column.Default != "" && !column.AutoIncrement && !strings.ContainsAny(column.Default, ";\"`") {
+		settings = append(settings, "default:"+column.Default)
+	}
+
+	for _, index := range table.Indexes {
+		position := -1
+		for i, name := range index.Columns {
+			if name == column.Name {
+				position = i
+			}
+		}
+		if position < 0 {
+			continue
+		}
+
+		// SQLite names the indexes of UNIQUE constraints itself and reserves those names
+		autoIndex := strings.HasPrefix(index.Name, "sqlite_autoindex_")
+		switch {
+		case autoIndex && len(index.Columns) == 1:
+			settings = append(settings, "unique")
+		case autoIndex:
+			settings = append(settings, "uniqueIndex:uq_"+table.Name+"_"+strings.Join(index.Columns, "_"))
+		case len(index.Columns) == 1 && index.Name == "idx_"+table.Name+"_"+column.Name && index.Unique:
+			settings = append(settings, "uniqueIndex")
+		case len(index.Columns) == 1 && index.Name == "idx_"+table.Name+"_"+column.Name:
+			settings = append(settings, "index")
+		case index.Unique:
+			settings = append(settings, "uniqueIndex:"+index.Name)
+		default:
+			settings = append(settings, "index:"+index.Name)
+		}
+	}
+
+	return strings.Join(settings, ";")
+}
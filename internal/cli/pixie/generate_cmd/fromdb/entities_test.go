@@ -0,0 +1,164 @@
+package fromdb
+
+import (
+	"context"
+	"go/format"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/scaffold"
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+)
+
+func TestEntities(t *testing.T) {
+	tables, err := Introspect(context.Background(), sqliteFixture(), true, nil)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+
+	entities := Entities(tables)
+	if len(entities) != 2 {
+		t.Fatalf("Entities() returned %d entities, want 2", len(entities))
+	}
+
+	customer := entities[0]
+	if customer.Name != "customer" || customer.Type != "Customer" {
+		t.Errorf("customer = %s/%s, want customer/Customer", customer.Name, customer.Type)
+	}
+	wantCustomer := []Field{
+		{Name: "ID", Column: "id", Type: "int64", Tag: "primaryKey;autoIncrement;type:integer"},
+		{Name: "Email", Column: "email", Type: "string", Tag: "type:varchar(255);not null;unique"},
+	}
+	if !reflect.DeepEqual(customer.Fields, wantCustomer) {
+		t.Errorf("customer.Fields =\n%+v\nwant\n%+v", customer.Fields, wantCustomer)
+	}
+
+	order := entities[1]
+	wantOrder := []Field{
+		{Name: "ID", Column: "id", Type: "int64", Tag: "primaryKey;autoIncrement;type:integer"},
+		{Name: "CustomerID", Column: "customer_id", Type: "int64", Tag: "type:integer;not null"},
+		{Name: "Status", Column: "status", Type: "string", Tag: "type:text;not null;default:'pending';index"},
+		{Name: "ShippedAt", Column: "shipped_at", Type: "*time.Time", Tag: "type:datetime"},
+	}
+	if !reflect.DeepEqual(order.Fields, wantOrder) {
+		t.Errorf("order.Fields =\n%+v\nwant\n%+v", order.Fields, wantOrder)
+	}
+
+	wantAssociations := []Association{{Name: "Customer", Type: "Customer", Tag: "foreignKey:CustomerID", JSON: "customer"}}
+	if !reflect.DeepEqual(order.Associations, wantAssociations) {
+		t.Errorf("order.Associations = %+v, want %+v", order.Associations, wantAssociations)
+	}
+	if got := order.Imports(); !reflect.DeepEqual(got, []string{"time"}) {
+		t.Errorf("order.Imports() = %v, want [time]", got)
+	}
+	if key := order.Key(); key == nil || key.Param() != "id" {
+		t.Errorf("order.Key() = %+v, want id", key)
+	}
+	if got := order.DefaultOrder(); !reflect.DeepEqual(got, []string{"id"}) {
+		t.Errorf("order.DefaultOrder() = %v, want [id]", got)
+	}
+}
+
+func TestEntityTemplates(t *testing.T) {
+	tables, err := Introspect(context.Background(), sqliteFixture(), true, nil)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+
+	data := genshared.NewTemplateData()
+	data.DomainName = "shop"
+	data.ModuleName = "example.com/shop"
+	for _, entity := range Entities(tables) {
+		entityData := entityData{TemplateData: data, Entity: entity}
+		entityData.EntityName = entity.Name
+		entityData.EntityNameCamel = entity.Type
+		entityData.RepositoryName = entity.Name
+		entityData.RepositoryNameCamel = entity.Type
+
+		for _, name := range []string{"db_entity.go.tmpl", "db_repository.go.tmpl"} {
+			content, _, err := scaffold.Templates.Render("", name, entityData)
+			if err != nil {
+				t.Fatalf("Render(%s) error = %v", name, err)
+			}
+			formatted, err := format.Source(content)
+			if err != nil {
+				t.Fatalf("%s for %s does not parse: %v\n%s", name, entity.Type, err, content)
+			}
+			if string(formatted) != string(content) {
+				t.Errorf("%s for %s is not gofmt-formatted:\n%s", name, entity.Type, content)
+			}
+		}
+	}
+
+	order := entityData{TemplateData: data, Entity: Entities(tables)[1]}
+	order.EntityName, order.EntityNameCamel, order.RepositoryNameCamel = "order", "Order", "Order"
+	content, _, err := scaffold.Templates.Render("", "db_repository.go.tmpl", order)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"func (r *OrderRepository) List(ctx context.Context, queryParams map[string][]string) (*operators.PaginatedResult[[]*shop_entities.Order], error)",
+		"func (r *OrderRepository) ListByCustomerID(ctx context.Context, customerID int64, queryParams map[string][]string) (*operators.PaginatedResult[[]*shop_entities.Order], error)",
+		`operators.NewOrderByOperator(queryParams, true, []string{"id"}, OrderSortProperties...)`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("repository missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	tests := map[string]string{
+		"id":          "ID",
+		"customer_id": "CustomerID",
+		"avatar_url":  "AvatarURL",
+		"createdAt":   "CreatedAt",
+		"2fa_enabled": "Column2faEnabled",
+	}
+
+	for column, want := range tests {
+		if got := FieldName(column); got != want {
+			t.Errorf("FieldName(%q) = %q, want %q", column, got, want)
+		}
+	}
+}
+
+func TestGoType(t *testing.T) {
+	tests := []struct {
+		column Column
+		want   string
+	}{
+		{Column{Type: "boolean"}, "bool"},
+		{Column{Type: "bigint"}, "int64"},
+		{Column{Type: "smallint", Nullable: true}, "*int"},
+		{Column{Type: "numeric(10,2)"}, "float64"},
+		{Column{Type: "timestamp with time zone"}, "time.Time"},
+		{Column{Type: "jsonb", Nullable: true}, "json.RawMessage"},
+		{Column{Type: "bytea"}, "[]byte"},
+		{Column{Type: "point"}, "string"},
+		{Column{Type: "uuid", Nullable: true}, "*string"},
+	}
+
+	for _, tt := range tests {
+		if got := GoType(tt.column); got != tt.want {
+			t.Errorf("GoType(%+v) = %q, want %q", tt.column, got, tt.want)
+		}
+	}
+}
+
+func TestGormTagIndexes(t *testing.T) {
+	table := Table{
+		Name: "order_lines",
+		Indexes: []Index{
+			{Name: "idx_order_product", Unique: true, Columns: []string{"order_id", "product_id"}},
+			{Name: "idx_order_lines_order_id", Columns: []string{"order_id"}},
+		},
+	}
+
+	got := gormTag(table, Column{Name: "order_id", Type: "integer"})
+	want := "type:integer;not null;uniqueIndex:idx_order_product;index"
+	if got != want {
+		t.Errorf("gormTag() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,270 @@
+package fromdb
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/db_shell_cmd"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/scaffold"
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/wiring"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// FromDBOptions holds all the options for generation from an existing database.
+type FromDBOptions struct {
+	Domain     string
+	Tables     []string
+	ModuleName string
+	Force      bool
+
+	// Connection, resolved like db-shell: flags, then --set, env and project config
+	DB         db_shell_cmd.Options
+	ConfigPath string
+	EnvPath    string
+}
+
+// entityData is the template data of the files generated for one entity.
+type entityData struct {
+	genshared.TemplateData
+	Entity Entity
+}
+
+// baselineData is the template data of the baseline migration.
+type baselineData struct {
+	genshared.TemplateData
+	Entities []Entity
+}
+
+// Imports returns the import paths the snapshots of the baseline migration need.
+func (d baselineData) Imports() []string {
+	var fields []Field
+	for _, entity := range d.Entities {
+		fields = append(fields, entity.Fields...)
+	}
+	return imports(fields)
+}
+
+// FromDBCmd returns the cobra command generating entities from an existing database.
+func FromDBCmd() *cobra.Command {
+	var opts FromDBOptions
+
+	cmd := &cobra.Command{
+		Use:   "from-db",
+		Short: "Generate entities and repositories from the tables of an existing database",
+		Long: `Generate entities and repositories from the tables of an existing database.
+
+This command connects to the database the way db-shell does, introspects the
+columns, types, nullability, primary keys, foreign keys and indexes of the tables,
+and generates into the domain's data layer:
+- An entity per table, with gorm tags declaring the columns as they are and a
+  belongs-to association for each foreign key to another introspected table
+- A repository per entity with Create, GetBy<key>, List, ListBy<foreign key>,
+  Update and Delete
+- A baseline migration declaring a snapshot of every table. It only creates the
+  tables that are missing, so on the introspected database it just records itself
+  as applied; generate migration compares later entity changes with its snapshots
+
+The baseline migration is appended to the domain's Migrations slice. The generated
+files are not recorded in the manifest: from-db is a one-shot onboarding step, and
+the entities are yours to edit afterwards.
+
+Connection precedence:
+  1. Command flags, then --set db.<key>=value
+  2. Explicit env file values (--env), then process environment variables
+  3. Project config in .pixie.yaml or pixie.yaml under the db: section
+
+Examples:
+  # Generate the orders and customers tables into the legacy domain
+  pixie generate from-db --domain legacy --tables orders,customers
+
+  # Generate every table of a SQLite file, offline
+  pixie generate from-db --domain legacy --driver sqlite --dsn file:legacy.db
+
+  # Preview the generated files without writing them
+  pixie generate from-db --domain legacy --tables orders --diff
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var domain, _ = cmd.Flags().GetString("domain")
+			var tables, _ = cmd.Flags().GetStringSlice("tables")
+			var moduleName, _ = cmd.Flags().GetString("module-name")
+			var force, _ = cmd.Flags().GetBool("force")
+
+			opts.Domain = domain
+			opts.Tables = tables
+			opts.ModuleName = moduleName
+			opts.Force = force
+			opts.ConfigPath, _ = cmd.InheritedFlags().GetString("config")
+			opts.EnvPath, _ = cmd.InheritedFlags().GetString("env")
+			opts.DB.Overrides, _ = cmd.InheritedFlags().GetStringArray("set")
+
+			return generateFromDB(cmd.Context(), opts)
+		},
+	}
+
+	// Required flags
+	cmd.Flags().String("domain", "", "Domain to generate the entities into (required)")
+
+	// Optional flags
+	cmd.Flags().StringSlice("tables", nil, "Tables to generate, comma-separated (defaults to every table)")
+	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
+	cmd.Flags().Bool("force", false, "Overwrite existing files")
+
+	// Connection flags
+	cmd.Flags().StringVar(&opts.DB.Driver, "driver", "", "Database driver: postgres or sqlite (defaults to the project config)")
+	cmd.Flags().StringVar(&opts.DB.DSN, "dsn", "", "Raw DSN/connection string (overrides host/user/db-name fields)")
+	cmd.Flags().StringVar(&opts.DB.Host, "host", "", "Database host")
+	cmd.Flags().IntVar(&opts.DB.Port, "port", 0, "Database port")
+	cmd.Flags().StringVar(&opts.DB.Name, "db-name", "", "Database name")
+	cmd.Flags().StringVar(&opts.DB.User, "user", "", "Database user")
+	cmd.Flags().StringVar(&opts.DB.Password, "password", "", "Database password")
+	cmd.Flags().StringVar(&opts.DB.SSLMode, "sslmode", "", "SSL mode")
+
+	// Mark required flags
+	if err := cmd.MarkFlagRequired("domain"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'domain' flag as required: %v", err))
+	}
+
+	return cmd
+}
+
+func generateFromDB(ctx context.Context, opts FromDBOptions) error {
+	if !genshared.IsValidIdentifier(opts.Domain) {
+		return errors.New("domain name must be a valid identifier (e.g., legacy)")
+	}
+
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	moduleName, err := genshared.ResolveModule(opts.ModuleName)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect module name")
+	}
+
+	dbConfig, err := db_shell_cmd.ResolveConfig(opts.DB, opts.ConfigPath, opts.EnvPath, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve the database connection")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	executor, err := db_shell_cmd.OpenExecutor(ctx, dbConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to %s", dbConfig.SafeSummary())
+	}
+	defer executor.Close()
+
+	tables, err := Introspect(ctx, executor, dbConfig.IsSQLite(), opts.Tables)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return errors.New("no tables found in %s", dbConfig.SafeSummary())
+	}
+	entities := Entities(tables)
+
+	data := genshared.NewTemplateData()
+	data.DomainName = opts.Domain
+	data.DomainNameCamel = initshared.ToCamelCase(opts.Domain)
+	data.ModuleName = moduleName
+	data.ApplyLayout(cfg)
+
+	fmt.Printf("Generating from database: %s\n", dbConfig.SafeSummary())
+	fmt.Printf("   Domain: %s\n", opts.Domain)
+	fmt.Printf("   Module: %s\n", data.ModuleName)
+	for _, entity := range entities {
+		fmt.Printf("   Table: %s -> %s (%d columns)\n", entity.Table, entity.Type, len(entity.Fields))
+	}
+	fmt.Println()
+
+	dataLayerDir := cfg.Path(cfg.DomainDir, opts.Domain, opts.Domain+"_data_layer")
+	for _, entity := range entities {
+		entityData := entityData{TemplateData: data, Entity: entity}
+		entityData.EntityName = entity.Name
+		entityData.EntityNameCamel = entity.Type
+		entityData.RepositoryName = entity.Name
+		entityData.RepositoryNameCamel = entity.Type
+
+		entityPath := filepath.Join(dataLayerDir, opts.Domain+"_entities", entity.Name+".go")
+		if err := render(cfg, "db_entity.go.tmpl", entityPath, entityData, opts.Force); err != nil {
+			return errors.Wrap(err, "failed to generate entity %s", entity.Type)
+		}
+
+		repositoryPath := filepath.Join(dataLayerDir, opts.Domain+"_repositories", entity.Name+"_repository.go")
+		if err := render(cfg, "db_repository.go.tmpl", repositoryPath, entityData, opts.Force); err != nil {
+			return errors.Wrap(err, "failed to generate repository %s", entity.Type)
+		}
+	}
+
+	baseline := baselineData{TemplateData: data, Entities: entities}
+	migrationsDir := filepath.Join(dataLayerDir, opts.Domain+"_migrations")
+	migrationPath := filepath.Join(migrationsDir, data.MigrationTimestamp+"_baseline_"+opts.Domain+"_tables.go")
+	if err := render(cfg, "db_baseline_migration.go.tmpl", migrationPath, baseline, opts.Force); err != nil {
+		return errors.Wrap(err, "failed to generate baseline migration")
+	}
+	if err := wireMigration(migrationsDir, fmt.Sprintf("&Baseline%sTables%s", data.DomainNameCamel, data.MigrationTimestamp)); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSuccessfully generated %d entities from the database\n\n", len(entities))
+	printFromDBNextSteps(data, dataLayerDir, migrationPath)
+
+	return nil
+}
+
+// render writes the scaffold template name to path.
+func render(cfg genshared.GeneratorConfig, name, path string, data interface{}, force bool) error {
+	content, _, err := scaffold.Templates.Render(cfg.Root, name, data)
+	if err != nil {
+		return err
+	}
+	if err := initshared.WriteFile(path, content, force); err != nil {
+		return err
+	}
+
+	fmt.Printf("   Generating %s\n", path)
+	return nil
+}
+
+// wireMigration appends migration to the domain's Migrations slice, printing the
+// change as a manual step when migrations.go does not have the expected shape.
+func wireMigration(migrationsDir, migration string) error {
+	path := filepath.Join(migrationsDir, "migrations.go")
+	changed, err := wiring.AppendToSlice(path, "Migrations", migration)
+	if shapeErr, ok := err.(*wiring.ShapeError); ok {
+		fmt.Printf("   WARNING: could not update %s automatically (%s); make this change by hand:\n", path, shapeErr.Reason)
+		fmt.Printf("      Append %s to the Migrations slice\n", migration)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to update %s", path)
+	}
+
+	if changed {
+		fmt.Printf("   Wiring %s\n", path)
+	} else {
+		fmt.Printf("   Unchanged %s (already wired)\n", path)
+	}
+	return nil
+}
+
+func printFromDBNextSteps(data genshared.TemplateData, dataLayerDir, migrationPath string) {
+	fmt.Printf("Next steps:\n\n")
+
+	fmt.Printf("1. Review the generated entities; columns without a closer Go type are strings:\n")
+	fmt.Printf("   %s\n\n", filepath.Join(dataLayerDir, data.DomainName+"_entities"))
+
+	fmt.Printf("2. Run the migrations against the database to record the baseline as applied:\n")
+	fmt.Printf("   %s\n\n", migrationPath)
+
+	fmt.Printf("3. Test the generated code:\n")
+	fmt.Printf("   go build ./%s/...\n\n", strings.TrimPrefix(filepath.ToSlash(dataLayerDir), "./"))
+}
@@ -0,0 +1,383 @@
+package fromdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/db_shell_cmd"
+)
+
+// Table is an introspected database table.
+type Table struct {
+	Name        string
+	Columns     []Column
+	ForeignKeys []ForeignKey
+	Indexes     []Index
+}
+
+// Column is an introspected table column.
+type Column struct {
+	Name          string
+	Type          string // declared type, lowercased: varchar(64), integer, numeric(10,2)
+	Nullable      bool
+	Default       string // default expression; empty when there is none
+	PrimaryKey    bool
+	AutoIncrement bool
+}
+
+// ForeignKey is a single-column foreign key constraint.
+type ForeignKey struct {
+	Column          string
+	ReferenceTable  string
+	ReferenceColumn string
+}
+
+// Index is a secondary index or unique constraint; primary keys are left out.
+type Index struct {
+	Name    string
+	Unique  bool
+	Columns []string
+}
+
+// Querier runs introspection queries. db_shell_cmd.Executor implements it.
+type Querier interface {
+	Execute(ctx context.Context, statement string) (db_shell_cmd.ExecutionResult, error)
+}
+
+// introspector reads the tables of one database dialect.
+type introspector interface {
+	tableNames(ctx context.Context) ([]string, error)
+	table(ctx context.Context, name string) (Table, error)
+}
+
+// Introspect reads the named tables, or every table when names is empty, sorted so
+// referenced tables come before the tables referencing them.
+func Introspect(ctx context.Context, q Querier, sqlite bool, names []string) ([]Table, error) {
+	var in introspector = postgresIntrospector{q: q}
+	if sqlite {
+		in = sqliteIntrospector{q: q}
+	}
+
+	existing, err := in.tableNames(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list tables")
+	}
+	if len(names) == 0 {
+		names = existing
+	}
+
+	known := map[string]bool{}
+	for _, name := range existing {
+		known[name] = true
+	}
+
+	var tables []Table
+	for _, name := range names {
+		if !known[name] {
+			return nil, errors.New("table %s not found; the database has: %s", name, strings.Join(existing, ", "))
+		}
+
+		table, err := in.table(ctx, name)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to introspect table %s", name)
+		}
+		tables = append(tables, table)
+	}
+
+	return sortByDependency(tables), nil
+}
+
+// sortByDependency orders tables so each comes after the tables it references, keeping
+// the given order otherwise. Reference cycles keep the given order.
+func sortByDependency(tables []Table) []Table {
+	byName := map[string]Table{}
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+
+	var sorted []Table
+	visited := map[string]bool{}
+	var visit func(table Table)
+	visit = func(table Table) {
+		if visited[table.Name] {
+			return
+		}
+		visited[table.Name] = true
+		for _, fk := range table.ForeignKeys {
+			if referenced, ok := byName[fk.ReferenceTable]; ok {
+				visit(referenced)
+			}
+		}
+		sorted = append(sorted, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+
+	return sorted
+}
+
+// query runs statement and returns its rows keyed by column name.
+func query(ctx context.Context, q Querier, statement string) ([]map[string]string, error) {
+	result, err := q.Execute(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]string, len(result.Rows))
+	for i, row := range result.Rows {
+		rows[i] = map[string]string{}
+		for j, column := range result.Columns {
+			if j < len(row) && row[j] != "NULL" {
+				rows[i][strings.ToLower(column)] = row[j]
+			}
+		}
+	}
+	return rows, nil
+}
+
+// literal quotes s as an SQL string literal.
+func literal(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+type sqliteIntrospector struct {
+	q Querier
+}
+
+func (i sqliteIntrospector) tableNames(ctx context.Context) ([]string, error) {
+	rows, err := query(ctx, i.q, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(rows))
+	for j, row := range rows {
+		names[j] = row["name"]
+	}
+	return names, nil
+}
+
+func (i sqliteIntrospector) table(ctx context.Context, name string) (Table, error) {
+	table := Table{Name: name}
+
+	columns, err := query(ctx, i.q, fmt.Sprintf("PRAGMA table_info(%s)", literal(name)))
+	if err != nil {
+		return table, err
+	}
+	primaryKeys := 0
+	for _, row := range columns {
+		if row["pk"] != "0" {
+			primaryKeys++
+		}
+	}
+	for _, row := range columns {
+		column := Column{
+			Name:       row["name"],
+			Type:       strings.ToLower(strings.TrimSpace(row["type"])),
+			Nullable:   row["notnull"] == "0",
+			Default:    row["dflt_value"],
+			PrimaryKey: row["pk"] != "0",
+		}
+		// A single INTEGER PRIMARY KEY column is the rowid, assigned on insert
+		if column.PrimaryKey && primaryKeys == 1 && column.Type == "integer" {
+			column.AutoIncrement = true
+		}
+		if column.PrimaryKey {
+			column.Nullable = false
+		}
+		table.Columns = append(table.Columns, column)
+	}
+
+	foreignKeys, err := query(ctx, i.q, fmt.Sprintf("PRAGMA foreign_key_list(%s)", literal(name)))
+	if err != nil {
+		return table, err
+	}
+	multiColumn := map[string]bool{}
+	for _, row := range foreignKeys {
+		if row["seq"] != "0" {
+			multiColumn[row["id"]] = true
+		}
+	}
+	for _, row := range foreignKeys {
+		if multiColumn[row["id"]] {
+			continue
+		}
+		table.ForeignKeys = append(table.ForeignKeys, ForeignKey{
+			Column:          row["from"],
+			ReferenceTable:  row["table"],
+			ReferenceColumn: row["to"],
+		})
+	}
+
+	indexes, err := query(ctx, i.q, fmt.Sprintf("PRAGMA index_list(%s)", literal(name)))
+	if err != nil {
+		return table, err
+	}
+	for _, row := range indexes {
+		if row["origin"] == "pk" {
+			continue
+		}
+
+		info, err := query(ctx, i.q, fmt.Sprintf("PRAGMA index_info(%s)", literal(row["name"])))
+		if err != nil {
+			return table, err
+		}
+		sort.SliceStable(info, func(a, b int) bool { return atoi(info[a]["seqno"]) < atoi(info[b]["seqno"]) })
+
+		index := Index{Name: row["name"], Unique: row["unique"] == "1"}
+		for _, column := range info {
+			index.Columns = append(index.Columns, column["name"])
+		}
+		table.Indexes = append(table.Indexes, index)
+	}
+	sort.SliceStable(table.Indexes, func(a, b int) bool { return table.Indexes[a].Name < table.Indexes[b].Name })
+
+	return table, nil
+}
+
+type postgresIntrospector struct {
+	q Querier
+}
+
+func (i postgresIntrospector) tableNames(ctx context.Context) ([]string, error) {
+	rows, err := query(ctx, i.q, `SELECT table_name FROM information_schema.tables
+WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+ORDER BY table_name`)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(rows))
+	for j, row := range rows {
+		names[j] = row["table_name"]
+	}
+	return names, nil
+}
+
+func (i postgresIntrospector) table(ctx context.Context, name string) (Table, error) {
+	table := Table{Name: name}
+
+	constraints, err := query(ctx, i.q, fmt.Sprintf(`SELECT tc.constraint_name, tc.constraint_type, kcu.column_name,
+  ccu.table_name AS reference_table, ccu.column_name AS reference_column
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+  ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema
+LEFT JOIN information_schema.constraint_column_usage ccu
+  ON ccu.constraint_name = tc.constraint_name AND ccu.table_schema = tc.table_schema
+  AND tc.constraint_type = 'FOREIGN KEY'
+WHERE tc.table_schema = current_schema() AND tc.table_name = %s
+ORDER BY tc.constraint_name, kcu.ordinal_position`, literal(name)))
+	if err != nil {
+		return table, err
+	}
+	primaryKeys := map[string]bool{}
+	foreignKeyColumns := map[string]int{}
+	for _, row := range constraints {
+		switch row["constraint_type"] {
+		case "PRIMARY KEY":
+			primaryKeys[row["column_name"]] = true
+		case "FOREIGN KEY":
+			foreignKeyColumns[row["constraint_name"]]++
+		}
+	}
+	for _, row := range constraints {
+		if row["constraint_type"] == "FOREIGN KEY" && foreignKeyColumns[row["constraint_name"]] == 1 {
+			table.ForeignKeys = append(table.ForeignKeys, ForeignKey{
+				Column:          row["column_name"],
+				ReferenceTable:  row["reference_table"],
+				ReferenceColumn: row["reference_column"],
+			})
+		}
+	}
+
+	columns, err := query(ctx, i.q, fmt.Sprintf(`SELECT column_name, data_type, udt_name, is_nullable, column_default, is_identity,
+  character_maximum_length, numeric_precision, numeric_scale
+FROM information_schema.columns
+WHERE table_schema = current_schema() AND table_name = %s
+ORDER BY ordinal_position`, literal(name)))
+	if err != nil {
+		return table, err
+	}
+	for _, row := range columns {
+		column := Column{
+			Name:       row["column_name"],
+			Type:       postgresType(row),
+			Nullable:   row["is_nullable"] == "YES",
+			Default:    row["column_default"],
+			PrimaryKey: primaryKeys[row["column_name"]],
+		}
+		if row["is_identity"] == "YES" || strings.HasPrefix(column.Default, "nextval(") {
+			column.AutoIncrement = true
+			column.Default = ""
+		}
+		table.Columns = append(table.Columns, column)
+	}
+
+	indexes, err := query(ctx, i.q, fmt.Sprintf(`SELECT i.relname AS index_name, ix.indisunique AS is_unique, a.attname AS column_name
+FROM pg_class t
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_index ix ON ix.indrelid = t.oid
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, position) ON true
+JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE n.nspname = current_schema() AND t.relname = %s AND NOT ix.indisprimary
+ORDER BY i.relname, k.position`, literal(name)))
+	if err != nil {
+		return table, err
+	}
+	positions := map[string]int{}
+	for _, row := range indexes {
+		indexName := row["index_name"]
+		if position, ok := positions[indexName]; ok {
+			table.Indexes[position].Columns = append(table.Indexes[position].Columns, row["column_name"])
+			continue
+		}
+		positions[indexName] = len(table.Indexes)
+		table.Indexes = append(table.Indexes, Index{
+			Name:    indexName,
+			Unique:  row["is_unique"] == "true",
+			Columns: []string{row["column_name"]},
+		})
+	}
+
+	return table, nil
+}
+
+// postgresType returns the declared type of an information_schema.columns row.
+func postgresType(row map[string]string) string {
+	dataType := strings.ToLower(row["data_type"])
+	switch dataType {
+	case "character varying", "character":
+		name := map[string]string{"character varying": "varchar", "character": "char"}[dataType]
+		if length := row["character_maximum_length"]; length != "" {
+			return fmt.Sprintf("%s(%s)", name, length)
+		}
+		return name
+	case "numeric":
+		if precision := row["numeric_precision"]; precision != "" {
+			return fmt.Sprintf("numeric(%s,%s)", precision, defaultString(row["numeric_scale"], "0"))
+		}
+		return dataType
+	case "user-defined", "array":
+		return row["udt_name"]
+	}
+	return dataType
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,124 @@
+package fromdb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/db_shell_cmd"
+)
+
+// fakeQuerier answers the statements starting with a key of results.
+type fakeQuerier map[string]db_shell_cmd.ExecutionResult
+
+func (q fakeQuerier) Execute(_ context.Context, statement string) (db_shell_cmd.ExecutionResult, error) {
+	for prefix, result := range q {
+		if strings.HasPrefix(statement, prefix) {
+			return result, nil
+		}
+	}
+	return db_shell_cmd.ExecutionResult{}, errors.New("unexpected statement: %s", statement)
+}
+
+func rows(columns []string, values ...[]string) db_shell_cmd.ExecutionResult {
+	return db_shell_cmd.ExecutionResult{Columns: columns, Rows: values, IsQuery: true}
+}
+
+var (
+	tableInfoColumns = []string{"cid", "name", "type", "notnull", "dflt_value", "pk"}
+	fkListColumns    = []string{"id", "seq", "table", "from", "to", "on_update", "on_delete", "match"}
+	indexListColumns = []string{"seq", "name", "unique", "origin", "partial"}
+	indexInfoColumns = []string{"seqno", "cid", "name"}
+)
+
+func sqliteFixture() fakeQuerier {
+	return fakeQuerier{
+		"SELECT name FROM sqlite_master": rows([]string{"name"}, []string{"customers"}, []string{"orders"}),
+
+		"PRAGMA table_info('customers')": rows(tableInfoColumns,
+			[]string{"0", "id", "INTEGER", "0", "NULL", "1"},
+			[]string{"1", "email", "VARCHAR(255)", "1", "NULL", "0"},
+		),
+		"PRAGMA foreign_key_list('customers')": rows(fkListColumns),
+		"PRAGMA index_list('customers')": rows(indexListColumns,
+			[]string{"0", "sqlite_autoindex_customers_1", "1", "u", "0"},
+		),
+		"PRAGMA index_info('sqlite_autoindex_customers_1')": rows(indexInfoColumns, []string{"0", "1", "email"}),
+
+		"PRAGMA table_info('orders')": rows(tableInfoColumns,
+			[]string{"0", "id", "INTEGER", "0", "NULL", "1"},
+			[]string{"1", "customer_id", "INTEGER", "1", "NULL", "0"},
+			[]string{"2", "status", "TEXT", "1", "'pending'", "0"},
+			[]string{"3", "shipped_at", "DATETIME", "0", "NULL", "0"},
+		),
+		"PRAGMA foreign_key_list('orders')": rows(fkListColumns,
+			[]string{"0", "0", "customers", "customer_id", "id", "NO ACTION", "NO ACTION", "NONE"},
+		),
+		"PRAGMA index_list('orders')": rows(indexListColumns,
+			[]string{"0", "idx_orders_status", "0", "c", "0"},
+		),
+		"PRAGMA index_info('idx_orders_status')": rows(indexInfoColumns, []string{"0", "2", "status"}),
+	}
+}
+
+func TestIntrospectSQLite(t *testing.T) {
+	tables, err := Introspect(context.Background(), sqliteFixture(), true, []string{"orders", "customers"})
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+
+	// customers comes first: orders references it
+	want := []Table{
+		{
+			Name: "customers",
+			Columns: []Column{
+				{Name: "id", Type: "integer", PrimaryKey: true, AutoIncrement: true},
+				{Name: "email", Type: "varchar(255)"},
+			},
+			Indexes: []Index{{Name: "sqlite_autoindex_customers_1", Unique: true, Columns: []string{"email"}}},
+		},
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", Type: "integer", PrimaryKey: true, AutoIncrement: true},
+				{Name: "customer_id", Type: "integer"},
+				{Name: "status", Type: "text", Default: "'pending'"},
+				{Name: "shipped_at", Type: "datetime", Nullable: true},
+			},
+			ForeignKeys: []ForeignKey{{Column: "customer_id", ReferenceTable: "customers", ReferenceColumn: "id"}},
+			Indexes:     []Index{{Name: "idx_orders_status", Columns: []string{"status"}}},
+		},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("Introspect() =\n%+v\nwant\n%+v", tables, want)
+	}
+}
+
+func TestIntrospectUnknownTable(t *testing.T) {
+	_, err := Introspect(context.Background(), sqliteFixture(), true, []string{"invoices"})
+	if err == nil || !strings.Contains(err.Error(), "table invoices not found") {
+		t.Fatalf("Introspect() error = %v, want table invoices not found", err)
+	}
+}
+
+func TestPostgresType(t *testing.T) {
+	tests := []struct {
+		row  map[string]string
+		want string
+	}{
+		{map[string]string{"data_type": "character varying", "character_maximum_length": "64"}, "varchar(64)"},
+		{map[string]string{"data_type": "character varying"}, "varchar"},
+		{map[string]string{"data_type": "numeric", "numeric_precision": "10", "numeric_scale": "2"}, "numeric(10,2)"},
+		{map[string]string{"data_type": "USER-DEFINED", "udt_name": "order_status"}, "order_status"},
+		{map[string]string{"data_type": "timestamp with time zone"}, "timestamp with time zone"},
+	}
+
+	for _, tt := range tests {
+		if got := postgresType(tt.row); got != tt.want {
+			t.Errorf("postgresType(%v) = %q, want %q", tt.row, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package fromopenapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/openapi"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/scaffold"
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/wiring"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// FromOpenAPIOptions holds all the options for generation from an OpenAPI document.
+type FromOpenAPIOptions struct {
+	Spec         string
+	Microservice string
+	Domain       string
+	ModuleName   string
+	Force        bool
+}
+
+// templateData is the template data of the files generated from a spec.
+type templateData struct {
+	genshared.TemplateData
+	Spec         string // the spec path, as given
+	Name         string // API: names the setup<Name>Routes function
+	Models       []*Model
+	ModelImports [][]string // standard library, then third-party import paths
+	Groups       []Group
+	Operations   []Operation
+}
+
+// HasRequestBodies reports whether any operation reads a request body.
+func (d templateData) HasRequestBodies() bool {
+	for _, operation := range d.Operations {
+		if operation.Request != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ControllersUseModels reports whether the handlers declare variables of the models.
+func (d templateData) ControllersUseModels() bool {
+	for _, operation := range d.Operations {
+		if strings.Contains(operation.Request, d.DomainName+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// BusinessLayerUses reports whether the business layer method signatures refer to
+// the package qualifier, e.g. "uid.".
+func (d templateData) BusinessLayerUses(qualifier string) bool {
+	for _, operation := range d.Operations {
+		if strings.Contains(operation.Params(), qualifier) || strings.Contains(operation.Response, qualifier) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromOpenAPICmd returns the cobra command generating a microservice API from an
+// OpenAPI document.
+func FromOpenAPICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "from-openapi",
+		Short: "Generate models, controllers and business layer stubs from an OpenAPI document",
+		Long: `Generate models, controllers and business layer stubs from an OpenAPI document.
+
+This command reads an OpenAPI 3.x document, in YAML or JSON, and generates for
+every operation, into an existing microservice and the domain it serves:
+- Request and response structs in the domain models, with json and validate tags
+  declaring the required properties, lengths, bounds, enums and formats
+- A handler reading the path and query parameters and the request body, and the
+  routes registering it under the gates its security requires, set up in SetupHTTP
+- A business layer method stub returning a not implemented error
+
+Object schemas become structs named after their component, or after the operation
+or property declaring them inline; enums are validated with oneof. Handlers and
+business layer methods are named after the operationId without the <method>_handle
+prefix openapi-spec writes, so generate openapi-spec documents the generated routes
+with the same paths, parameters, bodies, responses and security. Only the
+operationIds change: openapi-spec derives them from the handler names.
+
+The domain is the one whose business layer the microservice controllers hold, or the
+one named by --domain. The generated files are recorded in the manifest: re-running
+the command after changing the spec merges the changes into the edited files.
+
+Examples:
+  # Generate the API of api.yaml into the orders microservice
+  pixie generate from-openapi --spec api.yaml --ms orders
+
+  # Preview the generated files without writing them
+  pixie generate from-openapi --spec api.yaml --ms orders --diff
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var spec, _ = cmd.Flags().GetString("spec")
+			var microservice, _ = cmd.Flags().GetString("ms")
+			var domain, _ = cmd.Flags().GetString("domain")
+			var moduleName, _ = cmd.Flags().GetString("module-name")
+			var force, _ = cmd.Flags().GetBool("force")
+
+			opts := FromOpenAPIOptions{
+				Spec:         spec,
+				Microservice: microservice,
+				Domain:       domain,
+				ModuleName:   moduleName,
+				Force:        force,
+			}
+
+			return generateFromOpenAPI(opts)
+		},
+	}
+
+	// Required flags
+	cmd.Flags().String("spec", "", "OpenAPI 3.x document, YAML or JSON (required)")
+	cmd.Flags().String("ms", "", "Existing microservice serving the operations (required)")
+
+	// Optional flags
+	cmd.Flags().String("domain", "", "Domain of the models and business layer (default: the one the microservice controllers use)")
+	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+
+	// Mark required flags
+	if err := cmd.MarkFlagRequired("spec"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'spec' flag as required: %v", err))
+	}
+	if err := cmd.MarkFlagRequired("ms"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'ms' flag as required: %v", err))
+	}
+
+	return cmd
+}
+
+func generateFromOpenAPI(opts FromOpenAPIOptions) error {
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	moduleName, err := genshared.ResolveModule(opts.ModuleName)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect module name")
+	}
+
+	specPath := opts.Spec
+	if _, err := os.Stat(specPath); os.IsNotExist(err) && !filepath.IsAbs(specPath) && cfg.Root != "" {
+		specPath = filepath.Join(cfg.Root, specPath)
+	}
+	spec, err := openapi.LoadSpec(specPath)
+	if err != nil {
+		return err
+	}
+	if len(spec.Paths) == 0 {
+		return errors.New("%s declares no paths", opts.Spec)
+	}
+
+	controllers, err := findControllers(cfg, opts.Microservice, opts.Domain)
+	if err != nil {
+		return err
+	}
+
+	data := templateData{TemplateData: genshared.NewTemplateData(), Spec: filepath.Base(opts.Spec)}
+	data.DomainName = controllers.domain
+	data.DomainNameCamel = initshared.ToCamelCase(controllers.domain)
+	data.ServiceName = strings.TrimPrefix(controllers.microservice, cfg.MicroservicePrefix)
+	data.ModuleName = moduleName
+	data.ApplyLayout(cfg)
+	data.MicroservicePackage = controllers.pkg
+	data.ControllerBusinessLayer = controllers.businessLayer
+	data.ControllerGates = controllers.gates
+
+	base := initshared.Snake(strings.TrimSuffix(filepath.Base(opts.Spec), filepath.Ext(opts.Spec)))
+	data.Name = genshared.GoName(base)
+	if data.Name == "" {
+		return errors.New("cannot name the routes of %s; rename the spec file", opts.Spec)
+	}
+
+	models := NewModels(spec)
+	operations, warnings, err := Operations(spec, models, data.DomainName, controllers.gates)
+	if err != nil {
+		return err
+	}
+	data.Operations = operations
+	data.Groups = Groups(operations)
+	data.Models = models.Models()
+	data.ModelImports = importGroups(models.Imports())
+
+	businessLayerPath := cfg.Path(cfg.DomainDir, data.DomainName, data.BusinessLayerPackage, base+"_business_layer.go")
+	controllersPath := filepath.Join(filepath.Dir(controllers.path), base+"_http_controllers.go")
+	if err := checkDeclared(businessLayerPath, data.DomainNameCamel+"BusinessLayer", operations, func(o Operation) string { return o.Name }); err != nil {
+		return err
+	}
+	if err := checkDeclared(controllersPath, "httpControllers", operations, func(o Operation) string { return o.Handler }); err != nil {
+		return err
+	}
+
+	fmt.Printf("Generating from OpenAPI document: %s\n", opts.Spec)
+	fmt.Printf("   Microservice: %s\n", controllers.microservice)
+	fmt.Printf("   Domain: %s\n", data.DomainName)
+	fmt.Printf("   Module: %s\n", data.ModuleName)
+	fmt.Printf("   Operations: %d\n", len(operations))
+	fmt.Printf("   Models: %d\n\n", len(data.Models))
+
+	inputs := map[string]string{
+		"spec":   opts.Spec,
+		"ms":     opts.Microservice,
+		"domain": opts.Domain,
+	}
+	gen, err := initshared.NewGeneration(cfg.Root, "generate from-openapi", inputs, opts.Force)
+	if err != nil {
+		return err
+	}
+
+	if len(data.Models) > 0 {
+		modelsPath := cfg.Path(cfg.ModelsDir, data.DomainName, base+"_models.go")
+		if _, err := gen.WriteTemplate(scaffold.Templates, "openapi_models.go.tmpl", modelsPath, data); err != nil {
+			return errors.Wrap(err, "failed to generate models file")
+		}
+	}
+	if _, err := gen.WriteTemplate(scaffold.Templates, "openapi_business_layer.go.tmpl", businessLayerPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate business layer file")
+	}
+	if _, err := gen.WriteTemplate(scaffold.Templates, "openapi_http_controllers.go.tmpl", controllersPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate HTTP controllers file")
+	}
+	if err := wireRoutes(controllers.path, fmt.Sprintf("c.setup%sRoutes()", data.Name)); err != nil {
+		return err
+	}
+	if err := gen.Finish(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully generated %d operations from %s\n\n", len(operations), opts.Spec)
+	for _, warning := range warnings {
+		fmt.Printf("   WARNING: %s\n", warning)
+	}
+	if len(warnings) > 0 {
+		fmt.Println()
+	}
+	printFromOpenAPINextSteps(data, businessLayerPath)
+
+	return nil
+}
+
+// importGroups splits import paths into the standard library paths and the others,
+// dropping empty groups.
+func importGroups(paths []string) [][]string {
+	var std, others []string
+	for _, path := range paths {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+
+	var groups [][]string
+	for _, group := range [][]string{std, others} {
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// controllersFile describes the httpControllers of the microservice serving the spec.
+type controllersFile struct {
+	path          string
+	pkg           string
+	microservice  string // ms_orders
+	domain        string // orders
+	businessLayer string // field holding the domain business layer
+	gates         string // field holding the authorization gates; empty when there are none
+}
+
+// findControllers reads the httpControllers of microservice and picks the business
+// layer field of domain, or the only business layer field when domain is empty.
+func findControllers(cfg genshared.GeneratorConfig, microservice, domain string) (*controllersFile, error) {
+	if !strings.HasPrefix(microservice, cfg.MicroservicePrefix) {
+		microservice = cfg.MicroservicePrefix + microservice
+	}
+	path := cfg.Path(cfg.MicroserviceDir, microservice, "http_controllers.go")
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the controllers of microservice %s", microservice)
+	}
+
+	controllers := &controllersFile{path: path, pkg: file.Name.Name, microservice: microservice}
+	businessLayers := map[string]string{} // domain -> field
+	for name, fieldType := range structFields(file, "httpControllers") {
+		pkg, typeName, ok := strings.Cut(strings.TrimPrefix(fieldType, "*"), ".")
+		switch {
+		case fieldType == "*bundles.AuthorizationGatesBundle":
+			controllers.gates = name
+		case ok && strings.HasSuffix(pkg, cfg.BusinessLayerSuffix) && strings.HasSuffix(typeName, "BusinessLayer"):
+			businessLayers[strings.TrimSuffix(pkg, cfg.BusinessLayerSuffix)] = name
+		}
+	}
+
+	if domain != "" {
+		field, ok := businessLayers[domain]
+		if !ok {
+			return nil, errors.New("the httpControllers in %s have no %s business layer field", path, domain)
+		}
+		controllers.domain, controllers.businessLayer = domain, field
+		return controllers, nil
+	}
+
+	var domains []string
+	for name := range businessLayers {
+		domains = append(domains, name)
+	}
+	sort.Strings(domains)
+	switch len(domains) {
+	case 0:
+		return nil, errors.New("the httpControllers in %s have no business layer field", path)
+	case 1:
+		controllers.domain, controllers.businessLayer = domains[0], businessLayers[domains[0]]
+		return controllers, nil
+	}
+	return nil, errors.New("the httpControllers in %s hold the business layers of %s; choose one with --domain", path, strings.Join(domains, ", "))
+}
+
+// structFields returns the fields of the struct typeName in file, mapped to their types.
+func structFields(file *ast.File, typeName string) map[string]string {
+	fields := map[string]string{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok || typeSpec.Name.Name != typeName {
+				continue
+			}
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range structType.Fields.List {
+				for _, name := range field.Names {
+					fields[name.Name] = typeString(field.Type)
+				}
+			}
+		}
+	}
+	return fields
+}
+
+func typeString(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + typeString(x.X)
+	case *ast.SelectorExpr:
+		return typeString(x.X) + "." + x.Sel.Name
+	case *ast.Ident:
+		return x.Name
+	}
+	return ""
+}
+
+// checkDeclared fails when another file of the package of path already declares a
+// method of receiver the operations generate, named by name.
+func checkDeclared(path, receiver string, operations []Operation, name func(Operation) string) error {
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.go"))
+	if err != nil {
+		return err
+	}
+
+	generated := map[string]bool{}
+	for _, operation := range operations {
+		generated[name(operation)] = true
+	}
+
+	for _, file := range files {
+		if file == path || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			continue
+		}
+		for _, decl := range parsed.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 || !generated[fn.Name.Name] {
+				continue
+			}
+			if strings.TrimPrefix(typeString(fn.Recv.List[0].Type), "*") == receiver {
+				return errors.New("%s already declares %s.%s; rename the operation with an operationId", file, receiver, fn.Name.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// wireRoutes registers the routes of the spec in SetupHTTP, printing the change as a
+// manual step when the controllers do not have the expected shape.
+func wireRoutes(controllersPath, stmt string) error {
+	changed, err := wiring.AddStatements(controllersPath, "httpControllers.SetupHTTP", nil, []string{stmt})
+	if shapeErr, ok := err.(*wiring.ShapeError); ok {
+		fmt.Printf("   WARNING: could not update %s automatically (%s); make this change by hand:\n", controllersPath, shapeErr.Reason)
+		fmt.Printf("      Add to SetupHTTP():\n")
+		fmt.Printf("      %s\n", stmt)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to update %s", controllersPath)
+	}
+
+	if changed {
+		fmt.Printf("   Wiring %s\n", controllersPath)
+	} else {
+		fmt.Printf("   Unchanged %s (already wired)\n", controllersPath)
+	}
+	return nil
+}
+
+func printFromOpenAPINextSteps(data templateData, businessLayerPath string) {
+	fmt.Printf("Next steps:\n\n")
+
+	fmt.Printf("1. Implement the business layer methods, which return a not implemented error:\n")
+	fmt.Printf("   %s\n\n", businessLayerPath)
+
+	fmt.Printf("2. Check the routes against the spec:\n")
+	fmt.Printf("   pixie generate openapi-spec --ms %s\n\n", data.ServiceName)
+}
@@ -0,0 +1,383 @@
+package fromopenapi
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pixie-sh/errors-go"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/openapi"
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+)
+
+const (
+	schemaRefPrefix = "#/components/schemas/"
+	uidImport       = "github.com/pixie-sh/core-go/pkg/uid"
+)
+
+// Model is the Go struct generated for an object schema.
+type Model struct {
+	Name   string   // CreateOrderRequest
+	Doc    []string // the schema description
+	Fields []ModelField
+}
+
+// ModelField is a property of an object schema.
+type ModelField struct {
+	Name string   // CustomerID
+	Type string   // Go type: *time.Time
+	Tag  string   // json:"customer_id" validate:"required"
+	Doc  []string // the property description
+}
+
+// Models maps the schemas of a spec to the Go types of a models package. Object
+// schemas become structs, named after their component or, when declared inline,
+// after where they are declared; every other schema maps to a Go type expression.
+type Models struct {
+	spec      *openapi.OpenAPISpec
+	models    []*Model
+	named     map[string]string // object component schema -> Go struct
+	generated map[string]bool   // object components generated
+	resolving map[string]bool   // non-object components being mapped, to stop cycles
+	taken     map[string]bool   // struct names in use
+	imports   map[string]bool
+}
+
+// NewModels returns the models of the schemas of spec, empty until TypeOf maps them.
+// The object components reserve their names first, so that inline schemas never take
+// them.
+func NewModels(spec *openapi.OpenAPISpec) *Models {
+	m := &Models{
+		spec:      spec,
+		named:     map[string]string{},
+		generated: map[string]bool{},
+		resolving: map[string]bool{},
+		taken:     map[string]bool{},
+		imports:   map[string]bool{},
+	}
+
+	var components []string
+	for name, schema := range spec.Components.Schemas {
+		if len(schema.Properties) > 0 {
+			components = append(components, name)
+		}
+	}
+	sort.Strings(components)
+	for _, name := range components {
+		m.named[name] = m.structName(name)
+	}
+
+	return m
+}
+
+// Models returns the structs generated so far, by name.
+func (m *Models) Models() []*Model {
+	models := append([]*Model{}, m.models...)
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models
+}
+
+// Imports returns the import paths the fields of the structs need.
+func (m *Models) Imports() []string {
+	var result []string
+	for importPath := range m.imports {
+		result = append(result, importPath)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// IsModel reports whether name is a struct of the models package.
+func (m *Models) IsModel(name string) bool {
+	for _, model := range m.models {
+		if model.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeOf returns the Go type of schema. The object schemas it declares inline are
+// generated as structs named after name: OrderShippingAddress for the shipping_address
+// property of Order.
+func (m *Models) TypeOf(schema *openapi.SchemaSpec, name string) (string, error) {
+	if schema == nil {
+		return "interface{}", nil
+	}
+	if schema.Ref != "" {
+		return m.component(schema.Ref)
+	}
+
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			m.imports["time"] = true
+			return "time.Time", nil
+		case "uuid":
+			m.imports[uidImport] = true
+			return "uid.UID", nil
+		}
+		return "string", nil
+	case "integer":
+		switch schema.Format {
+		case "int32", "int64", "uint64":
+			return schema.Format, nil
+		}
+		return "int", nil
+	case "number":
+		if schema.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		item, err := m.TypeOf(schema.Items, name+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	}
+
+	if len(schema.Properties) > 0 {
+		structName := m.structName(name)
+		return structName, m.object(structName, schema)
+	}
+	if values, err := additionalProperties(schema); err != nil {
+		return "", errors.Wrap(err, "schema %s", name)
+	} else if values != nil {
+		value, err := m.TypeOf(values, name+"Value")
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + value, nil
+	}
+	if schema.Type == "object" {
+		return "map[string]interface{}", nil
+	}
+
+	// No type: any value, or a composition (allOf, oneOf) the models do not map
+	return "interface{}", nil
+}
+
+// component returns the Go type of the component schema ref points to.
+func (m *Models) component(ref string) (string, error) {
+	name, schema, err := m.resolve(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if len(schema.Properties) == 0 {
+		// Enums, aliases, arrays and maps are written out where they are used
+		if m.resolving[name] {
+			return "interface{}", nil
+		}
+		m.resolving[name] = true
+		defer delete(m.resolving, name)
+		return m.TypeOf(schema, genshared.GoName(name))
+	}
+
+	structName := m.named[name]
+	if m.generated[name] {
+		return structName, nil
+	}
+	m.generated[name] = true
+	return structName, m.object(structName, schema)
+}
+
+// resolve returns the name and schema of the component ref points to.
+func (m *Models) resolve(ref string) (string, *openapi.SchemaSpec, error) {
+	name, ok := strings.CutPrefix(ref, schemaRefPrefix)
+	if !ok {
+		return "", nil, errors.New("unsupported schema reference %s; only %s... references are supported", ref, schemaRefPrefix)
+	}
+	schema, ok := m.spec.Components.Schemas[name]
+	if !ok {
+		return "", nil, errors.New("schema reference %s has no component", ref)
+	}
+	return name, &schema, nil
+}
+
+// object generates the struct named name for an object schema. Required properties are
+// values validated as required; the others are pointers, or nil slices and maps,
+// omitted from the JSON when empty.
+func (m *Models) object(name string, schema *openapi.SchemaSpec) error {
+	model := &Model{Name: name, Doc: lines(schema.Description)}
+	m.models = append(m.models, model)
+
+	required := map[string]bool{}
+	for _, property := range schema.Required {
+		required[property] = true
+	}
+
+	var properties []string
+	for property := range schema.Properties {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	for _, property := range properties {
+		propertySchema := schema.Properties[property]
+		goType, err := m.TypeOf(&propertySchema, name+genshared.GoName(property))
+		if err != nil {
+			return errors.Wrap(err, "property %s of %s", property, name)
+		}
+
+		rules, err := m.rules(&propertySchema)
+		if err != nil {
+			return err
+		}
+
+		jsonTag := property
+		if required[property] {
+			rules = append([]string{"required"}, rules...)
+		} else {
+			if nilable(goType) {
+				goType = "*" + goType
+			}
+			jsonTag += ",omitempty"
+			if len(rules) > 0 {
+				rules = append([]string{"omitempty"}, rules...)
+			}
+		}
+
+		tag := fmt.Sprintf("json:%q", jsonTag)
+		if len(rules) > 0 {
+			tag += fmt.Sprintf(" validate:%q", strings.Join(rules, ","))
+		}
+		model.Fields = append(model.Fields, ModelField{
+			Name: fieldName(property),
+			Type: goType,
+			Tag:  tag,
+			Doc:  lines(propertySchema.Description),
+		})
+	}
+
+	return nil
+}
+
+// rules returns the validate rules of the constraints of schema, or of the enum or
+// alias component it references.
+func (m *Models) rules(schema *openapi.SchemaSpec) ([]string, error) {
+	if schema.Ref != "" {
+		_, target, err := m.resolve(schema.Ref)
+		if err != nil || len(target.Properties) > 0 {
+			return nil, err
+		}
+		schema = target
+	}
+
+	var rules []string
+	switch schema.Type {
+	case "string":
+		if schema.MinLength != nil {
+			rules = append(rules, fmt.Sprintf("min=%d", *schema.MinLength))
+		}
+		if schema.MaxLength != nil {
+			rules = append(rules, fmt.Sprintf("max=%d", *schema.MaxLength))
+		}
+		switch schema.Format {
+		case "email":
+			rules = append(rules, "email")
+		case "uri", "url":
+			rules = append(rules, "url")
+		}
+	case "integer", "number":
+		if schema.Minimum != nil {
+			rules = append(rules, "gte="+strconv.FormatFloat(*schema.Minimum, 'f', -1, 64))
+		}
+		if schema.Maximum != nil {
+			rules = append(rules, "lte="+strconv.FormatFloat(*schema.Maximum, 'f', -1, 64))
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		values := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			values[i] = fmt.Sprint(value)
+			if values[i] == "" || strings.ContainsAny(values[i], " ,|\"'`") {
+				// oneof cannot express the value; the field accepts any
+				return rules, nil
+			}
+		}
+		rules = append(rules, "oneof="+strings.Join(values, " "))
+	}
+
+	return rules, nil
+}
+
+// structName returns an unused struct name for a schema: the schema name when it is
+// an exported Go identifier already, its Go name otherwise.
+func (m *Models) structName(schemaName string) string {
+	name := schemaName
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		name = genshared.GoName(schemaName)
+		if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+			name = "Schema" + name
+		}
+	}
+
+	unique := name
+	for i := 2; m.taken[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	m.taken[unique] = true
+	return unique
+}
+
+// additionalProperties returns the schema of the values of a map schema, nil when
+// the schema declares none.
+func additionalProperties(schema *openapi.SchemaSpec) (*openapi.SchemaSpec, error) {
+	switch values := schema.AdditionalProperties.(type) {
+	case nil, bool:
+		if values == true {
+			return &openapi.SchemaSpec{}, nil
+		}
+		return nil, nil
+	case openapi.SchemaSpec:
+		return &values, nil
+	case *openapi.SchemaSpec:
+		return values, nil
+	default:
+		// Decoded documents hold the schema as a generic map
+		content, err := yaml.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+		var result openapi.SchemaSpec
+		if err := yaml.Unmarshal(content, &result); err != nil {
+			return nil, errors.Wrap(err, "invalid additionalProperties")
+		}
+		return &result, nil
+	}
+}
+
+// nilable reports whether an optional field of goType needs a pointer to tell a
+// missing value from the zero value.
+func nilable(goType string) bool {
+	return !strings.HasPrefix(goType, "[]") && !strings.HasPrefix(goType, "map[") && goType != "interface{}"
+}
+
+// fieldName returns the Go field name of a property: CustomerID for customer_id.
+func fieldName(property string) string {
+	name := genshared.GoName(property)
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "Field" + name
+	}
+	return name
+}
+
+// lines splits a description into comment lines.
+func lines(description string) []string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil
+	}
+	return strings.Split(description, "\n")
+}
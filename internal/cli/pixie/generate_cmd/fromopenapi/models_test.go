@@ -0,0 +1,182 @@
+package fromopenapi
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/openapi"
+)
+
+func TestModelsTypeOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema openapi.SchemaSpec
+		want   string
+	}{
+		{name: "string", schema: openapi.SchemaSpec{Type: "string"}, want: "string"},
+		{name: "date-time", schema: openapi.SchemaSpec{Type: "string", Format: "date-time"}, want: "time.Time"},
+		{name: "uuid", schema: openapi.SchemaSpec{Type: "string", Format: "uuid"}, want: "uid.UID"},
+		{name: "integer", schema: openapi.SchemaSpec{Type: "integer"}, want: "int"},
+		{name: "int64", schema: openapi.SchemaSpec{Type: "integer", Format: "int64"}, want: "int64"},
+		{name: "float", schema: openapi.SchemaSpec{Type: "number", Format: "float"}, want: "float32"},
+		{name: "array", schema: openapi.SchemaSpec{Type: "array", Items: &openapi.SchemaSpec{Type: "boolean"}}, want: "[]bool"},
+		{name: "map", schema: openapi.SchemaSpec{Type: "object", AdditionalProperties: map[string]interface{}{"type": "number"}}, want: "map[string]float64"},
+		{name: "free-form object", schema: openapi.SchemaSpec{Type: "object"}, want: "map[string]interface{}"},
+		{name: "enum component", schema: openapi.SchemaSpec{Ref: "#/components/schemas/Status"}, want: "string"},
+		{name: "object component", schema: openapi.SchemaSpec{Ref: "#/components/schemas/Order"}, want: "Order"},
+		{name: "composition", schema: openapi.SchemaSpec{}, want: "interface{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			models := NewModels(loadTestSpec(t))
+			got, err := models.TypeOf(&tt.schema, "Value")
+			if err != nil {
+				t.Fatalf("TypeOf() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TypeOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelsObject(t *testing.T) {
+	models := NewModels(loadTestSpec(t))
+	if _, err := models.TypeOf(&openapi.SchemaSpec{Ref: "#/components/schemas/Order"}, "Order"); err != nil {
+		t.Fatalf("TypeOf() error = %v", err)
+	}
+
+	got := models.Models()
+	if len(got) != 1 || got[0].Name != "Order" {
+		t.Fatalf("Models() = %+v, want Order", got)
+	}
+	want := []ModelField{
+		{Name: "CreatedAt", Type: "*time.Time", Tag: `json:"created_at,omitempty"`},
+		{Name: "ID", Type: "int64", Tag: `json:"id" validate:"required"`},
+		{Name: "Status", Type: "string", Tag: `json:"status" validate:"required,oneof=pending shipped"`},
+		{Name: "Tags", Type: "[]string", Tag: `json:"tags,omitempty"`},
+	}
+	if !reflect.DeepEqual(got[0].Fields, want) {
+		t.Errorf("Order.Fields =\n%+v\nwant\n%+v", got[0].Fields, want)
+	}
+	if imports := models.Imports(); !reflect.DeepEqual(imports, []string{"time"}) {
+		t.Errorf("Imports() = %v, want [time]", imports)
+	}
+}
+
+func TestModelsInlineNames(t *testing.T) {
+	models := NewModels(loadTestSpec(t))
+	inline := openapi.SchemaSpec{Type: "object", Properties: map[string]openapi.SchemaSpec{"id": {Type: "string"}}}
+
+	// An inline schema never takes the name of an object component
+	got, err := models.TypeOf(&inline, "Order")
+	if err != nil {
+		t.Fatalf("TypeOf() error = %v", err)
+	}
+	if got != "Order2" {
+		t.Errorf("TypeOf() = %q, want Order2", got)
+	}
+}
+
+const ordersSpec = `
+openapi: 3.0.3
+info:
+  title: Orders
+  version: 1.0.0
+security:
+  - bearerAuth: []
+paths:
+  /orders:
+    get:
+      operationId: get_handleListOrders
+      parameters:
+        - name: page
+          in: query
+          schema:
+            type: integer
+        - name: X-Trace
+          in: header
+          schema:
+            type: string
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  data:
+                    type: array
+                    items:
+                      $ref: '#/components/schemas/Order'
+    post:
+      summary: Create an order
+      security:
+        - bearerAuth: [orders:write]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [customer_id]
+              properties:
+                customer_id:
+                  type: string
+                  format: uuid
+                note:
+                  type: string
+                  maxLength: 200
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+  /orders/{id}:
+    delete:
+      security: []
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "204":
+          description: Deleted
+components:
+  schemas:
+    Status:
+      type: string
+      enum: [pending, shipped]
+    Order:
+      type: object
+      required: [id, status]
+      properties:
+        id:
+          type: integer
+          format: int64
+        status:
+          $ref: '#/components/schemas/Status'
+        created_at:
+          type: string
+          format: date-time
+        tags:
+          type: array
+          items:
+            type: string
+`
+
+func loadTestSpec(t *testing.T) *openapi.OpenAPISpec {
+	t.Helper()
+	var spec openapi.OpenAPISpec
+	if err := yaml.Unmarshal([]byte(ordersSpec), &spec); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	return &spec
+}
@@ -0,0 +1,502 @@
+package fromopenapi
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pixie-sh/errors-go"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/openapi"
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+const (
+	parameterRefPrefix   = "#/components/parameters/"
+	requestBodyRefPrefix = "#/components/requestBodies/"
+	responseRefPrefix    = "#/components/responses/"
+)
+
+// pathParamPattern matches the parameters of a spec path: {id}.
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// reservedVars are the names the generated handlers declare themselves.
+var reservedVars = map[string]bool{"c": true, "ctx": true, "err": true, "req": true, "response": true}
+
+// Operation is an operation of the spec: a route of the microservice, its handler and
+// the business layer method behind it.
+type Operation struct {
+	Method      string   // Get: the router method
+	Verb        string   // GET
+	Path        string   // /orders/{id}: the spec path
+	Route       string   // /:id: the route under its group
+	Handler     string   // handleGetOrder
+	Name        string   // GetOrder: the business layer method
+	Doc         []string // the summary and description lines
+	Gates       []string // middleware of the route
+	PathParams  []Param
+	QueryParams []Param
+	Request     string // qualified Go type of the request body; empty without one
+	Response    string // qualified Go type of the success response; empty without content
+	Status      int    // status code of the success response
+}
+
+// Param is a path or query parameter of an operation.
+type Param struct {
+	Name     string // order_id: the spec name
+	Var      string // orderID
+	Type     string // uid.UID
+	Getter   string // http.ParamsUID(ctx, "order_id")
+	Fallible bool   // the getter returns an error too
+}
+
+// Group is the router group of the operations sharing the first segment of their path.
+type Group struct {
+	Var        string // ordersGroup
+	Prefix     string // /orders
+	Operations []Operation
+}
+
+// Args returns the arguments the handler passes to the business layer method.
+func (o Operation) Args() string {
+	args := []string{"ctx.Context()"}
+	for _, param := range append(append([]Param{}, o.PathParams...), o.QueryParams...) {
+		args = append(args, param.Var)
+	}
+	if o.Request != "" {
+		args = append(args, "req")
+	}
+	return strings.Join(args, ", ")
+}
+
+// Params returns the parameters of the business layer method.
+func (o Operation) Params() string {
+	params := []string{"ctx context.Context"}
+	for _, param := range append(append([]Param{}, o.PathParams...), o.QueryParams...) {
+		params = append(params, param.Var+" "+param.Type)
+	}
+	if o.Request != "" {
+		params = append(params, "req "+o.Request)
+	}
+	return strings.Join(params, ", ")
+}
+
+// operations maps the operations of a spec to routes.
+type operations struct {
+	spec     *openapi.OpenAPISpec
+	models   *Models
+	pkg      string // package of the models
+	gates    string // httpControllers field holding the authorization gates
+	warnings []string
+}
+
+// Operations maps the operations of spec to the routes of a microservice, sorted by
+// path and method. The Go types of their parameters and bodies are declared by models
+// and qualified with pkg, the package of the models. gates is the httpControllers field
+// holding the authorization gates; secured operations fail without one. The returned
+// warnings list what the routes leave out of the spec.
+func Operations(spec *openapi.OpenAPISpec, models *Models, pkg, gates string) ([]Operation, []string, error) {
+	o := &operations{spec: spec, models: models, pkg: pkg, gates: gates}
+
+	var paths []string
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var result []Operation
+	handlers := map[string]string{}
+	for _, path := range paths {
+		item := spec.Paths[path]
+		for _, method := range []struct {
+			name string
+			op   *openapi.OperationSpec
+		}{
+			{"Get", item.Get}, {"Post", item.Post}, {"Put", item.Put}, {"Patch", item.Patch},
+			{"Delete", item.Delete}, {"Head", item.Head}, {"Options", item.Options},
+		} {
+			if method.op == nil {
+				continue
+			}
+
+			operation, err := o.operation(path, method.name, item.Parameters, method.op)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "%s %s", strings.ToUpper(method.name), path)
+			}
+			if other, ok := handlers[operation.Handler]; ok {
+				return nil, nil, errors.New("%s %s and %s both map to %s; give them distinct operationIds", operation.Verb, path, other, operation.Handler)
+			}
+			handlers[operation.Handler] = operation.Verb + " " + path
+			result = append(result, operation)
+		}
+	}
+
+	return result, o.warnings, nil
+}
+
+// Groups groups operations by the first segment of their path.
+func Groups(operations []Operation) []Group {
+	var groups []Group
+	index := map[string]int{}
+	for _, operation := range operations {
+		prefix := groupPrefix(operation.Path)
+		i, ok := index[prefix]
+		if !ok {
+			name := "root"
+			if prefix != "" {
+				name = initshared.Camel(prefix)
+			}
+			i = len(groups)
+			index[prefix] = i
+			groups = append(groups, Group{Var: name + "Group", Prefix: prefix})
+		}
+		groups[i].Operations = append(groups[i].Operations, operation)
+	}
+	return groups
+}
+
+// groupPrefix returns the first segment of path when it is not a parameter: /orders
+// for /orders/{id}.
+func groupPrefix(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if segment == "" || strings.Contains(segment, "{") {
+		return ""
+	}
+	return "/" + segment
+}
+
+func (o *operations) operation(path, method string, shared []openapi.ParameterRef, op *openapi.OperationSpec) (Operation, error) {
+	operation := Operation{
+		Method: method,
+		Verb:   strings.ToUpper(method),
+		Path:   path,
+		Route:  pathParamPattern.ReplaceAllString(strings.TrimPrefix(path, groupPrefix(path)), ":$1"),
+	}
+	operation.Name = operationName(operation.Verb, path, op.OperationID)
+	operation.Handler = "handle" + operation.Name
+
+	operation.Doc = append(lines(op.Summary), lines(op.Description)...)
+
+	gates, err := o.gatesOf(op)
+	if err != nil {
+		return operation, err
+	}
+	operation.Gates = gates
+
+	if err := o.params(&operation, shared, op.Parameters); err != nil {
+		return operation, err
+	}
+
+	if op.RequestBody != nil {
+		body := op.RequestBody
+		if body.Ref != "" {
+			name, ok := strings.CutPrefix(body.Ref, requestBodyRefPrefix)
+			resolved, found := o.spec.Components.RequestBodies[name]
+			if !ok || !found {
+				return operation, errors.New("request body reference %s has no component", body.Ref)
+			}
+			body = &resolved
+		}
+		if schema := contentSchema(body.Content); schema != nil {
+			if operation.Request, err = o.typeOf(schema, operation.Name+"Request"); err != nil {
+				return operation, errors.Wrap(err, "request body")
+			}
+		}
+	}
+
+	if err := o.response(&operation, op.Responses); err != nil {
+		return operation, err
+	}
+
+	return operation, nil
+}
+
+// operationName returns the name of the business layer method of an operation: its
+// operationId without the <method>_handle prefix openapi-spec generates, or else a
+// name derived from the method and path.
+func operationName(verb, path, operationID string) string {
+	id := strings.TrimPrefix(operationID, strings.ToLower(verb)+"_")
+	if rest, ok := strings.CutPrefix(id, "handle"); ok && rest != "" && unicode.IsUpper([]rune(rest)[0]) {
+		id = rest
+	}
+	if name := genshared.GoName(id); name != "" && unicode.IsLetter([]rune(name)[0]) {
+		return name
+	}
+
+	words := []string{strings.ToLower(verb)}
+	for _, segment := range strings.Split(path, "/") {
+		if param, ok := strings.CutPrefix(segment, "{"); ok {
+			words = append(words, "by", strings.TrimSuffix(param, "}"))
+		} else if segment != "" {
+			words = append(words, segment)
+		}
+	}
+	return genshared.GoName(strings.Join(words, "_"))
+}
+
+// gatesOf returns the middleware gating an operation: authentication when its
+// security requirements, or else those of the spec, require a scheme, and the
+// requirement of every scope they list.
+func (o *operations) gatesOf(op *openapi.OperationSpec) ([]string, error) {
+	security := o.spec.Security
+	if op.Security != nil {
+		security = op.Security
+	}
+	if len(security) == 0 {
+		return nil, nil
+	}
+
+	var scopes []string
+	seen := map[string]bool{}
+	for _, requirement := range security {
+		if len(requirement) == 0 {
+			// An empty requirement makes authentication optional
+			return nil, nil
+		}
+
+		var schemes []string
+		for scheme := range requirement {
+			schemes = append(schemes, scheme)
+		}
+		sort.Strings(schemes)
+		for _, scheme := range schemes {
+			for _, scope := range requirement[scheme] {
+				if !seen[scope] {
+					seen[scope] = true
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+	}
+
+	if o.gates == "" {
+		return nil, errors.New("the operation requires authentication, but the httpControllers have no authorization gates")
+	}
+
+	gates := []string{fmt.Sprintf("c.%s.IsAuthenticated.Authenticated()", o.gates)}
+	if len(scopes) > 0 {
+		quoted := make([]string, len(scopes))
+		for i, scope := range scopes {
+			quoted[i] = strconv.Quote(scope)
+		}
+		gates = append(gates, fmt.Sprintf("c.%s.IsAuthenticated.AllFeaturesOf(%s)", o.gates, strings.Join(quoted, ", ")))
+	}
+	return gates, nil
+}
+
+// params sets the path and query parameters of an operation: those of its path item,
+// overridden by its own. Path parameters keep the order of the path.
+func (o *operations) params(operation *Operation, shared, own []openapi.ParameterRef) error {
+	declared := map[string]openapi.ParameterRef{}
+	var order []string
+	for _, ref := range append(append([]openapi.ParameterRef{}, shared...), own...) {
+		param := ref
+		if ref.Ref != "" {
+			name, ok := strings.CutPrefix(ref.Ref, parameterRefPrefix)
+			resolved, found := o.spec.Components.Parameters[name]
+			if !ok || !found {
+				return errors.New("parameter reference %s has no component", ref.Ref)
+			}
+			param = resolved
+		}
+
+		key := param.In + " " + param.Name
+		if _, ok := declared[key]; !ok {
+			order = append(order, key)
+		}
+		declared[key] = param
+	}
+
+	vars := map[string]bool{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(operation.Path, -1) {
+		param, ok := declared["path "+match[1]]
+		if !ok {
+			param = openapi.ParameterRef{Name: match[1], In: "path"}
+		}
+		p, err := o.param(param, vars)
+		if err != nil {
+			return err
+		}
+		operation.PathParams = append(operation.PathParams, p)
+	}
+
+	for _, key := range order {
+		param := declared[key]
+		switch param.In {
+		case "path":
+			// Declared by the path
+		case "query":
+			p, err := o.param(param, vars)
+			if err != nil {
+				return err
+			}
+			operation.QueryParams = append(operation.QueryParams, p)
+		default:
+			o.warnings = append(o.warnings, fmt.Sprintf("%s %s: the %s parameter %s is not read by the handler", operation.Verb, operation.Path, param.In, param.Name))
+		}
+	}
+
+	return nil
+}
+
+// param returns the Go variable of a parameter and the getter reading it from the
+// request. Parameter values are scalars: uuid, integer, number, boolean or string.
+func (o *operations) param(param openapi.ParameterRef, vars map[string]bool) (Param, error) {
+	schema := param.Schema
+	if schema != nil && schema.Ref != "" {
+		_, target, err := o.models.resolve(schema.Ref)
+		if err != nil {
+			return Param{}, errors.Wrap(err, "parameter %s", param.Name)
+		}
+		schema = target
+	}
+	if schema == nil {
+		schema = &openapi.SchemaSpec{Type: "string"}
+	}
+
+	p := Param{Name: param.Name, Var: paramVar(param.Name, vars)}
+	if param.In == "path" {
+		switch {
+		case schema.Type == "string" && schema.Format == "uuid":
+			p.Type, p.Getter, p.Fallible = "uid.UID", fmt.Sprintf("http.ParamsUID(ctx, %q)", param.Name), true
+		case schema.Type == "integer" && schema.Format == "uint64":
+			p.Type, p.Getter, p.Fallible = "uint64", fmt.Sprintf("http.ParamsUint64(ctx, %q)", param.Name), true
+		case schema.Type == "integer":
+			p.Type, p.Getter, p.Fallible = "int", fmt.Sprintf("http.ParamsInt(ctx, %q)", param.Name), true
+		default:
+			p.Type, p.Getter = "string", fmt.Sprintf("ctx.Params(%q)", param.Name)
+		}
+		return p, nil
+	}
+
+	switch schema.Type {
+	case "integer":
+		p.Type, p.Getter = "int", fmt.Sprintf("ctx.QueryInt(%q)", param.Name)
+	case "number":
+		p.Type, p.Getter = "float64", fmt.Sprintf("ctx.QueryFloat(%q)", param.Name)
+	case "boolean":
+		p.Type, p.Getter = "bool", fmt.Sprintf("ctx.QueryBool(%q)", param.Name)
+	default:
+		p.Type, p.Getter = "string", fmt.Sprintf("ctx.Query(%q)", param.Name)
+	}
+	return p, nil
+}
+
+// response sets the success response of an operation: its first 2xx response. A
+// response without content, or with status 204, has no Go type. The data property
+// openapi-spec wraps success responses in is unwrapped.
+func (o *operations) response(operation *Operation, responses map[string]openapi.ResponseRef) error {
+	var codes []string
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	operation.Status = 200
+	if len(codes) == 0 {
+		return nil
+	}
+	status, err := strconv.Atoi(codes[0])
+	if err != nil {
+		return errors.New("invalid response status %s", codes[0])
+	}
+	operation.Status = status
+
+	response := responses[codes[0]]
+	if response.Ref != "" {
+		name, ok := strings.CutPrefix(response.Ref, responseRefPrefix)
+		resolved, found := o.spec.Components.Responses[name]
+		if !ok || !found {
+			return errors.New("response reference %s has no component", response.Ref)
+		}
+		response = resolved
+	}
+
+	schema := contentSchema(response.Content)
+	if schema == nil || status == 204 {
+		return nil
+	}
+	if data, ok := schema.Properties["data"]; ok && schema.Ref == "" && len(schema.Properties) == 1 {
+		schema = &data
+	}
+
+	operation.Response, err = o.typeOf(schema, operation.Name+"Response")
+	if err != nil {
+		return errors.Wrap(err, "response %d", status)
+	}
+	return nil
+}
+
+// typeOf returns the Go type of schema, with its models qualified by the package.
+func (o *operations) typeOf(schema *openapi.SchemaSpec, name string) (string, error) {
+	goType, err := o.models.TypeOf(schema, name)
+	if err != nil {
+		return "", err
+	}
+
+	var prefix string
+	base := goType
+	for {
+		switch {
+		case strings.HasPrefix(base, "[]"):
+			prefix, base = prefix+"[]", base[2:]
+		case strings.HasPrefix(base, "map[string]"):
+			prefix, base = prefix+"map[string]", base[len("map[string]"):]
+		case strings.HasPrefix(base, "*"):
+			prefix, base = prefix+"*", base[1:]
+		default:
+			if o.models.IsModel(base) {
+				base = o.pkg + "." + base
+			}
+			return prefix + base, nil
+		}
+	}
+}
+
+// contentSchema returns the schema of the JSON content, or else of the first media type.
+func contentSchema(content map[string]openapi.MediaTypeSpec) *openapi.SchemaSpec {
+	if media, ok := content["application/json"]; ok {
+		return media.Schema
+	}
+
+	var types []string
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	for _, mediaType := range types {
+		if strings.Contains(mediaType, "json") {
+			return content[mediaType].Schema
+		}
+	}
+	if len(types) > 0 {
+		return content[types[0]].Schema
+	}
+	return nil
+}
+
+// paramVar returns an unused Go variable name for a parameter: orderID for order_id.
+func paramVar(name string, vars map[string]bool) string {
+	goName := genshared.GoName(name)
+	if goName == "" || !unicode.IsLetter([]rune(goName)[0]) {
+		name = "param_" + name
+	}
+	variable := genshared.GoVarName(name)
+
+	if token.IsKeyword(variable) || reservedVars[variable] {
+		variable += "Param"
+	}
+	unique := variable
+	for i := 2; vars[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", variable, i)
+	}
+	vars[unique] = true
+	return unique
+}
@@ -0,0 +1,82 @@
+package fromopenapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOperations(t *testing.T) {
+	spec := loadTestSpec(t)
+	operations, warnings, err := Operations(spec, NewModels(spec), "orders", "gates")
+	if err != nil {
+		t.Fatalf("Operations() error = %v", err)
+	}
+	if len(operations) != 3 {
+		t.Fatalf("Operations() returned %d operations, want 3", len(operations))
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "X-Trace") {
+		t.Errorf("warnings = %v, want the X-Trace header", warnings)
+	}
+
+	list, create, remove := operations[0], operations[1], operations[2]
+
+	if list.Name != "ListOrders" || list.Route != "" || list.Response != "[]orders.Order" || list.Status != 200 {
+		t.Errorf("list = %+v, want ListOrders returning []orders.Order", list)
+	}
+	wantQuery := []Param{{Name: "page", Var: "page", Type: "int", Getter: `ctx.QueryInt("page")`}}
+	if !reflect.DeepEqual(list.QueryParams, wantQuery) {
+		t.Errorf("list.QueryParams = %+v, want %+v", list.QueryParams, wantQuery)
+	}
+	if want := []string{"c.gates.IsAuthenticated.Authenticated()"}; !reflect.DeepEqual(list.Gates, want) {
+		t.Errorf("list.Gates = %v, want %v", list.Gates, want)
+	}
+
+	if create.Name != "PostOrders" || create.Request != "orders.PostOrdersRequest" || create.Status != 201 {
+		t.Errorf("create = %+v, want PostOrders reading orders.PostOrdersRequest", create)
+	}
+	wantGates := []string{"c.gates.IsAuthenticated.Authenticated()", `c.gates.IsAuthenticated.AllFeaturesOf("orders:write")`}
+	if !reflect.DeepEqual(create.Gates, wantGates) {
+		t.Errorf("create.Gates = %v, want %v", create.Gates, wantGates)
+	}
+
+	if remove.Route != "/:id" || remove.Response != "" || remove.Status != 204 || len(remove.Gates) != 0 {
+		t.Errorf("remove = %+v, want an ungated /:id answering 204", remove)
+	}
+	wantPath := []Param{{Name: "id", Var: "id", Type: "int", Getter: `http.ParamsInt(ctx, "id")`, Fallible: true}}
+	if !reflect.DeepEqual(remove.PathParams, wantPath) {
+		t.Errorf("remove.PathParams = %+v, want %+v", remove.PathParams, wantPath)
+	}
+	if got := remove.Params(); got != "ctx context.Context, id int" {
+		t.Errorf("remove.Params() = %q", got)
+	}
+
+	groups := Groups(operations)
+	if len(groups) != 1 || groups[0].Var != "ordersGroup" || groups[0].Prefix != "/orders" {
+		t.Errorf("Groups() = %+v, want ordersGroup", groups)
+	}
+}
+
+func TestOperationsRequireGates(t *testing.T) {
+	spec := loadTestSpec(t)
+	if _, _, err := Operations(spec, NewModels(spec), "orders", ""); err == nil {
+		t.Fatal("Operations() error = nil, want an error for gated operations without gates")
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		verb, path, operationID, want string
+	}{
+		{"GET", "/orders", "get_handleListOrders", "ListOrders"},
+		{"POST", "/orders", "createOrder", "CreateOrder"},
+		{"GET", "/orders/{id}", "", "GetOrdersByID"},
+		{"DELETE", "/orders/{order_id}/items", "", "DeleteOrdersByOrderIDItems"},
+	}
+
+	for _, tt := range tests {
+		if got := operationName(tt.verb, tt.path, tt.operationID); got != tt.want {
+			t.Errorf("operationName(%q, %q, %q) = %q, want %q", tt.verb, tt.path, tt.operationID, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package generate_cmd
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/fromdb"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/fromopenapi"
 	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/openapi"
 	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/scaffold"
 )
@@ -18,10 +20,20 @@ Available subcommands:
 
 Scaffold commands:
   microservice  - Generate a new microservice with full structure
+  add-feature   - Enable features on an existing microservice
   domain        - Generate a new domain within a microservice
   entity        - Generate a new entity with migration and model
   service       - Generate a new service in a domain
   repository    - Generate a new repository in a domain
+  resource      - Generate a CRUD resource from entity to HTTP routes
+  endpoint      - Add a route, handler and business layer stub to a microservice
+  event         - Generate a domain event, its publisher and an optional consumer
+  permission    - Add a permission to the typed permission catalogue
+  gate          - Generate a custom authorization gate
+  migration     - Generate a migration for the changes made to an entity
+  from-db       - Generate entities and repositories from an existing database
+  from-openapi  - Generate models, controllers and business layer stubs from an OpenAPI document
+  features      - List the features accepted by --features
 
 OpenAPI commands:
   openapi-spec       - Generate OpenAPI 3.0 specification from controllers
@@ -29,8 +41,9 @@ OpenAPI commands:
 
 Configuration:
   Directory conventions and naming patterns can be customized via
-  .pixie.yaml or pixie.yaml in the project root. Run with defaults
-  if no config file is present.
+  .pixie.yaml or pixie.yaml in the project root. The file is discovered
+  by walking up from the working directory, so commands can be run from
+  any subdirectory. Run with defaults if no config file is present.
 
 Examples:
   # Generate a new microservice
@@ -49,10 +62,20 @@ Examples:
 
 	// Scaffold subcommands
 	cmd.AddCommand(scaffold.MicroserviceCmd())
+	cmd.AddCommand(scaffold.AddFeatureCmd())
 	cmd.AddCommand(scaffold.DomainCmd())
 	cmd.AddCommand(scaffold.EntityCmd())
 	cmd.AddCommand(scaffold.ServiceCmd())
 	cmd.AddCommand(scaffold.RepositoryCmd())
+	cmd.AddCommand(scaffold.ResourceCmd())
+	cmd.AddCommand(scaffold.EndpointCmd())
+	cmd.AddCommand(scaffold.EventCmd())
+	cmd.AddCommand(scaffold.PermissionCmd())
+	cmd.AddCommand(scaffold.GateCmd())
+	cmd.AddCommand(scaffold.MigrationCmd())
+	cmd.AddCommand(fromdb.FromDBCmd())
+	cmd.AddCommand(fromopenapi.FromOpenAPICmd())
+	cmd.AddCommand(scaffold.FeaturesCmd())
 
 	// OpenAPI subcommands
 	cmd.AddCommand(openapi.OpenAPISpecCmd())
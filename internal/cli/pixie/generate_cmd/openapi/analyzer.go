@@ -26,6 +26,10 @@ type EndpointSpec struct {
 	Security        []SecurityRequirement
 	ControllerFile  string
 	HandlerFunction *ast.FuncDecl
+
+	// responseType is the Go type of the success response, used to find the List query
+	// behind generic query parameters
+	responseType string
 }
 
 // ParameterSpec represents a path, query, or header parameter
@@ -59,7 +63,7 @@ type SecurityRequirement struct {
 }
 
 // analyzeControllerFile analyzes a controller file and extracts endpoint specifications
-func analyzeControllerFile(filePath, msName, projectRoot string, verbose bool, blRegistry *BusinessLayerRegistry) ([]*EndpointSpec, error) {
+func analyzeControllerFile(filePath, msName, projectRoot string, verbose bool, blRegistry *BusinessLayerRegistry, lqRegistry *ListQueryRegistry, constRegistry *ConstantRegistry) ([]*EndpointSpec, error) {
 	// First, extract basic endpoint info using existing logic
 	basicEndpoints, err := parseControllerFile(filePath, msName, verbose)
 	if err != nil {
@@ -76,6 +80,8 @@ func analyzeControllerFile(filePath, msName, projectRoot string, verbose bool, b
 		functions:             make(map[string]*ast.FuncDecl),
 		imports:               make(map[string]string),
 		businessLayerRegistry: blRegistry,
+		listQueryRegistry:     lqRegistry,
+		constantRegistry:      constRegistry,
 		controllerFields:      make(map[string]string),
 	}
 
@@ -112,18 +118,14 @@ type analyzerContext struct {
 	functions             map[string]*ast.FuncDecl
 	imports               map[string]string
 	businessLayerRegistry *BusinessLayerRegistry
+	listQueryRegistry     *ListQueryRegistry
+	constantRegistry      *ConstantRegistry
 	// controllerFields maps "controllerType.fieldName" to field type
 	// (e.g., "dealsBOController.businessLayer" -> "*deals_business_layer.DealBusinessLayer")
 	// This prevents collisions when multiple controllers have fields with the same name
 	controllerFields map[string]string
 }
 
-// knownPermissionConstants maps constant references to their actual string values.
-// Add new constants here as they are used in middleware.
-var knownPermissionConstants = map[string]string{
-	"session_manager_models.SuperadminRoleFeature": "superadmin",
-}
-
 // parseDirectoryFiles parses all Go files in a directory to collect function declarations
 func (ac *analyzerContext) parseDirectoryFiles(dirPath string) error {
 	// Read all files in the directory
@@ -134,7 +136,7 @@ func (ac *analyzerContext) parseDirectoryFiles(dirPath string) error {
 
 	// Parse each Go file
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
 			continue
 		}
 
@@ -168,9 +170,10 @@ func (ac *analyzerContext) parseDirectoryFiles(dirPath string) error {
 					}
 				}
 			case *ast.TypeSpec:
-				// Look for controller struct types
+				// Look for controller struct types (e.g., dealsBOController, httpControllers)
 				if structType, ok := x.Type.(*ast.StructType); ok {
-					if strings.HasSuffix(strings.ToLower(x.Name.Name), "controller") {
+					name := strings.ToLower(x.Name.Name)
+					if strings.HasSuffix(name, "controller") || strings.HasSuffix(name, "controllers") {
 						ac.extractControllerFields(x.Name.Name, structType)
 					}
 				}
@@ -317,6 +320,9 @@ func (ac *analyzerContext) enhanceEndpoint(basic EndpointInfo) *EndpointSpec {
 		ac.analyzeFunctionBody(handlerFunc.Body, spec, receiverType)
 	}
 
+	// Replace generic query parameters with those of the List query behind the response
+	ac.applyListQuery(spec)
+
 	// Extract path parameters from path (only if not already found in function body)
 	// This ensures we don't miss parameters that aren't explicitly accessed via ctx.Params()
 	existingParams := make(map[string]bool)
@@ -532,6 +538,20 @@ func (ac *analyzerContext) analyzeCallExpr(call *ast.CallExpr, spec *EndpointSpe
 			}
 		}
 
+	case "Query", "QueryInt", "QueryBool", "QueryFloat":
+		// Extract query parameter: ctx.Query("param_name"), ctx.QueryInt("param_name"), etc.
+		if len(call.Args) > 0 {
+			if paramName := extractStringLiteral(call.Args[0]); paramName != "" {
+				spec.Parameters = append(spec.Parameters, ParameterSpec{
+					Name:        paramName,
+					In:          "query",
+					Required:    false,
+					Description: fmt.Sprintf("Query parameter: %s", paramName),
+					Schema:      ac.inferParamSchema(methodName),
+				})
+			}
+		}
+
 	case "DeserializeFromFn":
 		// Extract request body: serializer.DeserializeFromFn(ctx.BodyParser, &req)
 		if len(call.Args) >= 2 {
@@ -570,7 +590,10 @@ func (ac *analyzerContext) analyzeCallExpr(call *ast.CallExpr, spec *EndpointSpe
 			}
 			// else: http.Response(ctx, data) - dataArgIndex is already 1
 
-			if !hasData {
+			if !hasData && statusCode == "204" {
+				// A 204 response has no body
+				spec.Responses[statusCode] = ResponseSpec{Description: "No content"}
+			} else if !hasData {
 				// http.Response(ctx, statusCode) returns {"data": "Ok"}
 				// The wrapper will add the "data" field, so we just specify the inner type as string
 				spec.Responses[statusCode] = ResponseSpec{
@@ -589,6 +612,12 @@ func (ac *analyzerContext) analyzeCallExpr(call *ast.CallExpr, spec *EndpointSpe
 					isErrorResponse = true
 				}
 
+				if isErrorResponse && len(call.Args) == 2 {
+					// http.Response(ctx, err) answers with the status of the error, which
+					// the default error response documents
+					return
+				}
+
 				if isErrorResponse {
 					// Error response - use the status code from the call
 					spec.Responses[statusCode] = ResponseSpec{
@@ -603,11 +632,38 @@ func (ac *analyzerContext) analyzeCallExpr(call *ast.CallExpr, spec *EndpointSpe
 					}
 				} else {
 					respType := ac.inferTypeFromExprWithVars(dataArg, varTypes, receiverType)
+					spec.responseType = respType
 					// Clean the type name to match the schema name in components
 					cleanedTypeName := ac.cleanTypeName(respType)
 
 					// Only add response schema if we found a valid type (not just a variable name without type info)
-					if cleanedTypeName != "" && cleanedTypeName != "object" {
+					if itemType, ok := paginatedItemType(respType); ok {
+						// Page of a type, e.g. operators.PaginatedResult[[]orders.Order]
+						spec.Responses[statusCode] = ResponseSpec{
+							Description: "Successful response",
+							ContentType: "application/json",
+							Schema: SchemaSpec{
+								Type:        "object",
+								Description: "Page of results",
+								Properties: map[string]SchemaSpec{
+									"data": {
+										Type:  "array",
+										Items: &SchemaSpec{Ref: fmt.Sprintf("#/components/schemas/%s", ac.cleanTypeName(itemType))},
+									},
+								},
+							},
+						}
+					} else if itemType, ok := strings.CutPrefix(respType, "[]"); ok && !strings.Contains(itemType, "[") {
+						// Slice of a type, e.g. []orders.Order
+						spec.Responses[statusCode] = ResponseSpec{
+							Description: "Successful response",
+							ContentType: "application/json",
+							Schema: SchemaSpec{
+								Type:  "array",
+								Items: &SchemaSpec{Ref: fmt.Sprintf("#/components/schemas/%s", ac.cleanTypeName(itemType))},
+							},
+						}
+					} else if cleanedTypeName != "" && cleanedTypeName != "object" {
 						spec.Responses[statusCode] = ResponseSpec{
 							Description: "Successful response",
 							ContentType: "application/json",
@@ -661,6 +717,31 @@ func (ac *analyzerContext) analyzeCallExpr(call *ast.CallExpr, spec *EndpointSpe
 	}
 }
 
+// applyListQuery replaces the generic query parameter added for ParseQueryParameters
+// with the filter, search, sort and pagination parameters of the repository List
+// query whose results the endpoint responds with
+func (ac *analyzerContext) applyListQuery(spec *EndpointSpec) {
+	if ac.listQueryRegistry == nil {
+		return
+	}
+
+	for i, param := range spec.Parameters {
+		if param.In != "query" || param.Name != "query" {
+			continue
+		}
+
+		query, ok := ac.listQueryRegistry.Lookup(spec.responseType)
+		if !ok {
+			return
+		}
+
+		params := append([]ParameterSpec{}, spec.Parameters[:i]...)
+		params = append(params, query.Parameters()...)
+		spec.Parameters = append(params, spec.Parameters[i+1:]...)
+		return
+	}
+}
+
 // extractPathParameters extracts parameter names from path
 func (ac *analyzerContext) extractPathParameters(path string) []ParameterSpec {
 	var params []ParameterSpec
@@ -792,7 +873,8 @@ func (ac *analyzerContext) extractPermissionsFromMiddleware(mw string) []string
 }
 
 // cleanPermissionArg cleans a permission argument by removing quotes and whitespace,
-// and resolves known constant references to their actual values.
+// and resolves constant references, such as gates.PermissionOrdersCancel, to their values
+// as declared by the project.
 func (ac *analyzerContext) cleanPermissionArg(arg string) string {
 	// Trim whitespace
 	arg = strings.TrimSpace(arg)
@@ -801,13 +883,22 @@ func (ac *analyzerContext) cleanPermissionArg(arg string) string {
 	if len(arg) >= 2 {
 		if (arg[0] == '"' && arg[len(arg)-1] == '"') ||
 			(arg[0] == '\'' && arg[len(arg)-1] == '\'') {
-			arg = arg[1 : len(arg)-1]
+			return arg[1 : len(arg)-1]
 		}
 	}
 
-	// Resolve known constant references to their actual values
-	if resolved, ok := knownPermissionConstants[arg]; ok {
-		return resolved
+	// Resolve constant references: pkg.Name through the imports of the controllers,
+	// Name in the controllers' own package
+	if ac.constantRegistry != nil {
+		if pkgName, name, qualified := strings.Cut(arg, "."); qualified {
+			if importPath, exists := ac.imports[pkgName]; exists {
+				if resolved, ok := ac.constantRegistry.Resolve(importPath, name); ok {
+					return resolved
+				}
+			}
+		} else if resolved, ok := ac.constantRegistry.ResolveIn(filepath.Dir(ac.filePath), arg); ok {
+			return resolved
+		}
 	}
 
 	return arg
@@ -820,8 +911,12 @@ func (ac *analyzerContext) inferParamSchema(methodName string) SchemaSpec {
 		return SchemaSpec{Type: "string", Format: "uuid"}
 	case "ParamsUint64":
 		return SchemaSpec{Type: "integer", Format: "uint64"}
-	case "ParamsInt":
+	case "ParamsInt", "QueryInt":
 		return SchemaSpec{Type: "integer", Format: "int64"}
+	case "QueryBool":
+		return SchemaSpec{Type: "boolean"}
+	case "QueryFloat":
+		return SchemaSpec{Type: "number", Format: "double"}
 	case "Params":
 		return SchemaSpec{Type: "string"}
 	default:
@@ -1046,6 +1141,16 @@ func extractComments(commentGroup *ast.CommentGroup) []string {
 	return comments
 }
 
+// paginatedItemType returns the item type of a paginated result, e.g. orders.Order
+// for operators.PaginatedResult[[]orders.Order] or *operators.PaginatedResult[[]*orders.Order]
+func paginatedItemType(typeName string) (string, bool) {
+	inner, ok := strings.CutPrefix(strings.TrimPrefix(typeName, "*"), "operators.PaginatedResult[")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimLeft(strings.TrimSuffix(inner, "]"), "[]*"), true
+}
+
 // cleanTypeName cleans a type name for use in schema references
 // This matches the behavior of TypeResolver.cleanTypeName to ensure references match schema names
 func (ac *analyzerContext) cleanTypeName(typeName string) string {
@@ -0,0 +1,285 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const fixtureControllers = `package ms_orders
+
+import (
+	"github.com/pixie-sh/core-go/pkg/comm/http"
+	"example.com/shop/bundles"
+	"example.com/shop/internal/domain/orders/orders_business_layer"
+)
+
+type httpControllers struct {
+	server        http.Server
+	gates         *bundles.AuthorizationGatesBundle
+	businessLayer *orders_business_layer.OrdersBusinessLayer
+}
+
+func (c httpControllers) SetupHTTP() error {
+	invoiceGroup := c.server.Group("/orders")
+
+	invoiceGroup.Get("/invoices", c.gates.IsAuthenticated.Authenticated(), c.handleListInvoices)
+	invoiceGroup.Get("/invoices/:id", c.gates.IsAuthenticated.Authenticated(), c.handleGetInvoice)
+	return nil
+}
+
+func (c httpControllers) handleListInvoices(ctx http.ServerCtx) error {
+	queryParams := http.ParseQueryParameters(ctx)
+	response, err := c.businessLayer.ListInvoices(ctx.Context(), queryParams)
+	return http.Response(ctx, response, err)
+}
+
+func (c httpControllers) handleGetInvoice(ctx http.ServerCtx) error {
+	id, err := http.ParamsUID(ctx, "id")
+	if err != nil {
+		return http.Response(ctx, err)
+	}
+
+	response, err := c.businessLayer.GetInvoice(ctx.Context(), id)
+	return http.Response(ctx, response, err)
+}
+`
+
+// fixtureControllersTest registers the routes without their gates, as the generated
+// HTTP handler tests do
+const fixtureControllersTest = `package ms_orders
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newInvoiceTestApp(t *testing.T) *fiber.App {
+	c := httpControllers{}
+	app := fiber.New()
+	app.Get("/orders/invoices", c.handleListInvoices)
+	app.Get("/orders/invoices/:id", c.handleGetInvoice)
+	return app
+}
+`
+
+const fixtureBoControllers = `package ms_orders
+
+import (
+	"github.com/pixie-sh/core-go/pkg/comm/http"
+	"example.com/shop/bundles"
+	"example.com/shop/internal/domain/orders/orders_business_layer"
+)
+
+type httpBoControllers struct {
+	server        http.Server
+	gates         *bundles.AuthorizationGatesBundle
+	businessLayer *orders_business_layer.OrdersBusinessLayer
+}
+
+func (c httpBoControllers) SetupHTTP() error {
+	bo := c.server.Group("/backoffice", c.gates.IsAuthenticated.Authenticated())
+
+	bo.Get("/orders", c.handleListOrders)
+	bo.Get("/receipts", c.handleListReceipts)
+	return nil
+}
+
+func (c httpBoControllers) handleListOrders(ctx http.ServerCtx) error {
+	queryParams := http.ParseQueryParameters(ctx)
+	response, err := c.businessLayer.ListOrders(ctx.Context(), queryParams)
+	return http.Response(ctx, response, err)
+}
+
+func (c httpBoControllers) handleListReceipts(ctx http.ServerCtx) error {
+	queryParams := http.ParseQueryParameters(ctx)
+	response, err := c.businessLayer.ListReceipts(ctx.Context(), queryParams)
+	return http.Response(ctx, response, err)
+}
+`
+
+const fixtureBusinessLayer = `package orders_business_layer
+
+import (
+	"context"
+
+	"github.com/pixie-sh/core-go/pkg/models/operators"
+	"github.com/pixie-sh/core-go/pkg/uid"
+	"example.com/shop/pkg/models/orders"
+)
+
+type OrdersBusinessLayer struct{}
+
+func (l OrdersBusinessLayer) ListInvoices(ctx context.Context, queryParams map[string][]string) (operators.PaginatedResult[[]orders.InvoiceResponse], error) {
+	return operators.PaginatedResult[[]orders.InvoiceResponse]{}, nil
+}
+
+func (l OrdersBusinessLayer) GetInvoice(ctx context.Context, id uid.UID) (orders.InvoiceResponse, error) {
+	return orders.InvoiceResponse{}, nil
+}
+
+func (l OrdersBusinessLayer) ListOrders(ctx context.Context, queryParams map[string][]string) (operators.PaginatedResult[[]orders.OrderResponse], error) {
+	return operators.PaginatedResult[[]orders.OrderResponse]{}, nil
+}
+
+func (l OrdersBusinessLayer) ListReceipts(ctx context.Context, queryParams map[string][]string) (orders.ReceiptListResponse, error) {
+	return orders.ReceiptListResponse{}, nil
+}
+`
+
+const fixtureModels = `package orders
+
+type InvoiceResponse struct {
+	Total float64 ` + "`json:\"total\"`" + `
+}
+
+type OrderResponse struct {
+	Status string ` + "`json:\"status\"`" + `
+}
+
+type ReceiptResponse struct {
+	Number string ` + "`json:\"number\"`" + `
+}
+
+type ReceiptListResponse struct {
+	Receipts   []ReceiptResponse ` + "`json:\"receipts\"`" + `
+	NextCursor string            ` + "`json:\"next_cursor,omitempty\"`" + `
+}
+`
+
+// writeProject writes files, by path relative to the project root, to a temporary
+// project and runs the test from its root.
+func writeProject(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	root := t.TempDir()
+	files["go.mod"] = "module example.com/shop\n\ngo 1.24\n"
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() {
+		if err := os.Chdir(origDir); err != nil {
+			t.Logf("Failed to restore directory: %v", err)
+		}
+	})
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+}
+
+func TestExtractEndpoints_SkipsTestFiles(t *testing.T) {
+	writeProject(t, map[string]string{
+		"internal/ms/ms_orders/http_controllers.go":      fixtureControllers,
+		"internal/ms/ms_orders/http_controllers_test.go": fixtureControllersTest,
+	})
+
+	endpoints, err := extractEndpoints(nil, false)
+	if err != nil {
+		t.Fatalf("extractEndpoints() error = %v", err)
+	}
+
+	seen := map[string]int{}
+	for _, endpoint := range endpoints {
+		seen[endpoint.Method+" "+endpoint.Path]++
+	}
+	for _, route := range []string{"Get /orders/invoices", "Get /orders/invoices/:id"} {
+		if seen[route] != 1 {
+			t.Errorf("extractEndpoints() lists %s %d times, want 1", route, seen[route])
+		}
+	}
+	if len(endpoints) != 2 {
+		t.Errorf("extractEndpoints() = %d endpoints, want 2", len(endpoints))
+	}
+}
+
+func TestGenerateOpenAPISpec_SkipsTestFiles(t *testing.T) {
+	writeProject(t, map[string]string{
+		"internal/ms/ms_orders/http_controllers.go":      fixtureControllers,
+		"internal/ms/ms_orders/http_controllers_test.go": fixtureControllersTest,
+	})
+
+	spec, err := generateOpenAPISpec(nil, "API", "1.0.0", "", false)
+	if err != nil {
+		t.Fatalf("generateOpenAPISpec() error = %v", err)
+	}
+
+	for _, path := range []string{"/orders/invoices", "/orders/invoices/{id}"} {
+		item, ok := spec.Paths[path]
+		if !ok || item.Get == nil {
+			t.Errorf("generateOpenAPISpec() has no GET %s", path)
+			continue
+		}
+		if len(item.Get.Security) == 0 {
+			t.Errorf("GET %s security = %v, want the bearerAuth requirement of its gate", path, item.Get.Security)
+		}
+	}
+}
+
+func TestGenerateOpenAPISpec_ListResponses(t *testing.T) {
+	writeProject(t, map[string]string{
+		"internal/ms/ms_orders/http_controllers.go":                             fixtureControllers,
+		"internal/ms/ms_orders/http_bo_controllers.go":                          fixtureBoControllers,
+		"internal/domain/orders/orders_business_layer/orders_business_layer.go": fixtureBusinessLayer,
+		"pkg/models/orders/orders.go":                                           fixtureModels,
+	})
+
+	spec, err := generateOpenAPISpec(nil, "API", "1.0.0", "", false)
+	if err != nil {
+		t.Fatalf("generateOpenAPISpec() error = %v", err)
+	}
+
+	// response returns the schema of the data of the 200 response of GET path
+	response := func(path string) *SchemaSpec {
+		item, ok := spec.Paths[path]
+		if !ok || item.Get == nil {
+			t.Fatalf("generateOpenAPISpec() has no GET %s", path)
+		}
+		content, ok := item.Get.Responses["200"].Content["application/json"]
+		if !ok || content.Schema == nil {
+			t.Fatalf("GET %s has no 200 application/json response", path)
+		}
+		data, ok := content.Schema.Properties["data"]
+		if !ok {
+			t.Fatalf("GET %s 200 response = %+v, want a data property", path, content.Schema)
+		}
+		return &data
+	}
+
+	pages := map[string]string{
+		"/orders/invoices":   "#/components/schemas/InvoiceResponse",
+		"/backoffice/orders": "#/components/schemas/OrderResponse",
+	}
+	for path, itemRef := range pages {
+		page := response(path)
+		items, ok := page.Properties["data"]
+		if page.Type != "object" || !ok || items.Type != "array" || items.Items == nil || items.Items.Ref != itemRef {
+			t.Errorf("GET %s 200 data = %+v, want a page whose data is an array of %s", path, page, itemRef)
+		}
+	}
+
+	if got, want := response("/orders/invoices/{id}").Ref, "#/components/schemas/InvoiceResponse"; got != want {
+		t.Errorf("GET /orders/invoices/{id} 200 data $ref = %q, want %q", got, want)
+	}
+	if got, want := response("/backoffice/receipts").Ref, "#/components/schemas/ReceiptListResponse"; got != want {
+		t.Errorf("GET /backoffice/receipts 200 data $ref = %q, want %q", got, want)
+	}
+
+	receipts := spec.Components.Schemas["ReceiptListResponse"].Properties["receipts"]
+	if receipts.Type != "array" || receipts.Items == nil {
+		t.Errorf("ReceiptListResponse receipts = %+v, want an array", receipts)
+	}
+	for _, name := range []string{"InvoiceResponse", "OrderResponse", "ReceiptResponse"} {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("generateOpenAPISpec() components lack schema %s", name)
+		}
+	}
+}
@@ -108,20 +108,8 @@ func (r *BusinessLayerRegistry) parseBusinessLayerFile(fset *token.FileSet, file
 
 	packageName := node.Name.Name
 
-	// First pass: find struct types that end with "BusinessLayer"
-	businessLayerTypes := make(map[string]bool)
-	ast.Inspect(node, func(n ast.Node) bool {
-		if typeSpec, ok := n.(*ast.TypeSpec); ok {
-			if _, isStruct := typeSpec.Type.(*ast.StructType); isStruct {
-				if strings.HasSuffix(typeSpec.Name.Name, "BusinessLayer") {
-					businessLayerTypes[typeSpec.Name.Name] = true
-				}
-			}
-		}
-		return true
-	})
-
-	// Second pass: find methods on business layer types
+	// Find methods on business layer types; the methods of a business layer may be
+	// spread over several files, such as the per-entity files of generate resource
 	for _, decl := range node.Decls {
 		funcDecl, ok := decl.(*ast.FuncDecl)
 		if !ok || funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
@@ -130,7 +118,7 @@ func (r *BusinessLayerRegistry) parseBusinessLayerFile(fset *token.FileSet, file
 
 		// Get receiver type name
 		receiverType := extractReceiverTypeName(funcDecl.Recv.List[0].Type)
-		if receiverType == "" || !businessLayerTypes[receiverType] {
+		if !strings.HasSuffix(receiverType, "BusinessLayer") {
 			continue
 		}
 
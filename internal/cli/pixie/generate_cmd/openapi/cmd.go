@@ -10,6 +10,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
 )
 
 // OpenAPISpecCmd returns the cobra command for generating OpenAPI specifications
@@ -70,7 +71,7 @@ func OpenAPISpecCmd() *cobra.Command {
 			}
 
 			if outputFile != "" {
-				err = os.WriteFile(outputFile, output, 0644)
+				err = initshared.WriteFile(outputFile, output, true)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Error writing to file: %v\n", err)
 					os.Exit(1)
@@ -100,18 +101,21 @@ func OpenAPISpecCmd() *cobra.Command {
 
 // generateOpenAPISpec generates the OpenAPI specification
 func generateOpenAPISpec(msFilter []string, title, version, description string, verbose bool) (*OpenAPISpec, error) {
-	// Get the project root directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current working directory: %w", err)
-	}
-
 	// Load configuration for directory conventions
 	cfg, err := genshared.LoadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Directories are resolved from the project root so the command works from any subdirectory
+	cwd := cfg.Root
+	if cwd == "" {
+		cwd, err = os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current working directory: %w", err)
+		}
+	}
+
 	msDir := filepath.Join(cwd, cfg.MicroserviceDir)
 	if verbose {
 		fmt.Printf("Scanning microservices directory: %s\n", msDir)
@@ -154,6 +158,16 @@ func generateOpenAPISpec(msFilter []string, title, version, description string,
 		// Continue anyway - responses will fall back to generic object types
 	}
 
+	// Scan repositories for the query parameters of their List queries
+	lqRegistry := NewListQueryRegistry(verbose)
+	if err := lqRegistry.ScanRepositories(filepath.Join(cwd, cfg.DomainDir)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to scan repositories: %v\n", err)
+		// Continue anyway - list endpoints will fall back to a generic query parameter
+	}
+
+	// Permission constants are resolved from the packages declaring them
+	constRegistry := NewConstantRegistry(cwd, verbose)
+
 	// Process each microservice
 	for _, msPath := range msDirectories {
 		msName := filepath.Base(msPath)
@@ -189,7 +203,7 @@ func generateOpenAPISpec(msFilter []string, title, version, description string,
 				fmt.Printf("  Analyzing file: %s\n", filePath)
 			}
 
-			endpoints, err := analyzeControllerFile(filePath, msName, cwd, verbose, blRegistry)
+			endpoints, err := analyzeControllerFile(filePath, msName, cwd, verbose, blRegistry, lqRegistry, constRegistry)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to analyze %s: %v\n", filePath, err)
 				continue
@@ -0,0 +1,141 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConstantRegistry resolves the string constants declared by the packages of the project,
+// such as the permissions of the gates catalogue. Packages are parsed the first time one
+// of their constants is looked up
+type ConstantRegistry struct {
+	projectRoot string
+	module      string
+	// packages maps a package directory to its string constants
+	packages map[string]map[string]string
+	verbose  bool
+}
+
+// NewConstantRegistry creates a new constant registry for the project at projectRoot
+func NewConstantRegistry(projectRoot string, verbose bool) *ConstantRegistry {
+	r := &ConstantRegistry{
+		projectRoot: projectRoot,
+		packages:    make(map[string]map[string]string),
+		verbose:     verbose,
+	}
+
+	content, err := os.ReadFile(filepath.Join(projectRoot, "go.mod"))
+	if err != nil {
+		return r
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			r.module = strings.TrimSpace(strings.TrimPrefix(line, "module "))
+			break
+		}
+	}
+
+	return r
+}
+
+// Resolve returns the value of the string constant name declared by the project package
+// importPath (e.g., "example.com/shop/infra/gates", "PermissionOrdersCancel")
+func (r *ConstantRegistry) Resolve(importPath, name string) (string, bool) {
+	if r.module == "" || (importPath != r.module && !strings.HasPrefix(importPath, r.module+"/")) {
+		return "", false
+	}
+
+	rel := strings.TrimPrefix(strings.TrimPrefix(importPath, r.module), "/")
+	return r.ResolveIn(filepath.Join(r.projectRoot, filepath.FromSlash(rel)), name)
+}
+
+// ResolveIn returns the value of the string constant name declared by the package in dir
+func (r *ConstantRegistry) ResolveIn(dir, name string) (string, bool) {
+	constants, ok := r.packages[dir]
+	if !ok {
+		constants = r.scanPackage(dir)
+		r.packages[dir] = constants
+	}
+
+	value, ok := constants[name]
+	return value, ok
+}
+
+// scanPackage collects the string constants of the Go files in dir. Constants defined
+// by other constants of the package are resolved too
+func (r *ConstantRegistry) scanPackage(dir string) map[string]string {
+	constants := make(map[string]string)
+	references := make(map[string]string)
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return constants
+	}
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		node, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			if r.verbose {
+				fmt.Printf("    Warning: failed to parse %s: %v\n", path, err)
+			}
+			continue
+		}
+
+		for _, decl := range node.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for i, ident := range valueSpec.Names {
+					if i >= len(valueSpec.Values) {
+						continue
+					}
+					switch value := valueSpec.Values[i].(type) {
+					case *ast.BasicLit:
+						if value.Kind != token.STRING {
+							continue
+						}
+						if unquoted, err := strconv.Unquote(value.Value); err == nil {
+							constants[ident.Name] = unquoted
+						}
+					case *ast.Ident:
+						references[ident.Name] = value.Name
+					}
+				}
+			}
+		}
+	}
+
+	for name, target := range references {
+		for depth := 0; depth < len(references); depth++ {
+			if next, ok := references[target]; ok {
+				target = next
+				continue
+			}
+			break
+		}
+		if value, ok := constants[target]; ok {
+			constants[name] = value
+		}
+	}
+
+	if r.verbose && len(constants) > 0 {
+		fmt.Printf("    Resolved %d string constants in %s\n", len(constants), dir)
+	}
+
+	return constants
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/spf13/cobra"
 
 	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
 )
 
 // EndpointInfo represents the structure of an extracted HTTP endpoint
@@ -79,7 +80,7 @@ func ExtractEndpointsCmd() *cobra.Command {
 			}
 
 			if outputFile != "" {
-				err = os.WriteFile(outputFile, output, 0644)
+				err = initshared.WriteFile(outputFile, output, true)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Error writing to file: %v\n", err)
 					os.Exit(1)
@@ -105,18 +106,21 @@ func ExtractEndpointsCmd() *cobra.Command {
 func extractEndpoints(msFilter []string, verbose bool) ([]EndpointInfo, error) {
 	var endpoints []EndpointInfo
 
-	// Get the project root directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get current working directory")
-	}
-
 	// Load configuration for directory conventions
 	cfg, err := genshared.LoadConfig()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to load config")
 	}
 
+	// Directories are resolved from the project root so the command works from any subdirectory
+	cwd := cfg.Root
+	if cwd == "" {
+		cwd, err = os.Getwd()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get current working directory")
+		}
+	}
+
 	msDir := filepath.Join(cwd, cfg.MicroserviceDir)
 	if verbose {
 		fmt.Printf("Scanning microservices directory: %s\n", msDir)
@@ -188,7 +192,7 @@ func findControllerFiles(msPath string) ([]string, error) {
 			return err
 		}
 
-		if !info.IsDir() && strings.HasSuffix(path, ".go") {
+		if !info.IsDir() && strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
 			// Check if the file looks like a controller file
 			basename := filepath.Base(path)
 			if strings.Contains(basename, "controller") ||
@@ -341,11 +345,8 @@ func (ctx *parseContext) parseHTTPMethodCall(call *ast.CallExpr) *EndpointInfo {
 		return nil
 	}
 
-	// Extract the path (first argument)
+	// Extract the path (first argument); an empty path is the group path itself
 	path := extractStringLiteral(call.Args[0])
-	if path == "" {
-		path = "/"
-	}
 
 	// Determine the group path and middleware by checking the selector's receiver
 	groupPath := ""
@@ -0,0 +1,226 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ListQuery describes the query parameters of a generated repository List query
+type ListQuery struct {
+	Filters    []string // columns filtered by equality, one parameter each
+	Search     bool     // whether the search parameter matches text columns
+	Sorts      []string // columns the sort parameter accepts
+	Pagination string   // offset or cursor
+}
+
+// ListQueryRegistry stores the List queries declared by generated repositories,
+// keyed by domain and entity (e.g., "catalog.Product")
+type ListQueryRegistry struct {
+	queries map[string]*ListQuery
+	verbose bool
+}
+
+// NewListQueryRegistry creates a new list query registry
+func NewListQueryRegistry(verbose bool) *ListQueryRegistry {
+	return &ListQueryRegistry{
+		queries: make(map[string]*ListQuery),
+		verbose: verbose,
+	}
+}
+
+// ScanRepositories scans the repository directories under domainDir for the
+// <Entity>FilterColumns, <Entity>SearchProperties, <Entity>SearchColumns,
+// <Entity>SortProperties and <Entity>Pagination declarations of List queries
+func (r *ListQueryRegistry) ScanRepositories(domainDir string) error {
+	err := filepath.Walk(domainDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Skip directories we can't access
+		}
+
+		if !info.IsDir() && strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") &&
+			strings.HasSuffix(filepath.Base(filepath.Dir(path)), "_repositories") {
+			if parseErr := r.parseRepositoryFile(path); parseErr != nil && r.verbose {
+				fmt.Printf("    Warning: failed to parse %s: %v\n", path, parseErr)
+			}
+		}
+		return nil
+	})
+
+	if r.verbose {
+		fmt.Printf("  Total list queries registered: %d\n", len(r.queries))
+	}
+
+	return err
+}
+
+// parseRepositoryFile registers the List query declarations of a repository file
+func (r *ListQueryRegistry) parseRepositoryFile(filePath string) error {
+	node, err := parser.ParseFile(token.NewFileSet(), filePath, nil, 0)
+	if err != nil {
+		return err
+	}
+
+	domain := strings.TrimSuffix(node.Name.Name, "_repositories")
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || (genDecl.Tok != token.VAR && genDecl.Tok != token.CONST) {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok || len(valueSpec.Names) != 1 || len(valueSpec.Values) != 1 {
+				continue
+			}
+			r.register(domain, valueSpec.Names[0].Name, valueSpec.Values[0])
+		}
+	}
+
+	return nil
+}
+
+// register records the declaration name = value if it is part of a List query
+func (r *ListQueryRegistry) register(domain, name string, value ast.Expr) {
+	for _, suffix := range []string{"FilterColumns", "SearchProperties", "SearchColumns", "SortProperties", "Pagination"} {
+		entity, ok := strings.CutSuffix(name, suffix)
+		if !ok || entity == "" {
+			continue
+		}
+
+		key := domain + "." + entity
+		query := r.queries[key]
+		if query == nil {
+			query = &ListQuery{}
+			r.queries[key] = query
+		}
+
+		switch suffix {
+		case "FilterColumns":
+			query.Filters = listColumns(value)
+		case "SearchProperties", "SearchColumns":
+			query.Search = len(listColumns(value)) > 0
+		case "SortProperties":
+			query.Sorts = listColumns(value)
+		case "Pagination":
+			query.Pagination = extractStringLiteral(value)
+		}
+		return
+	}
+}
+
+// listColumns returns the columns of a []string literal or of a
+// []pipelineModels.SearchableProperty literal (their Field values)
+func listColumns(expr ast.Expr) []string {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	var columns []string
+	for _, elt := range lit.Elts {
+		switch e := elt.(type) {
+		case *ast.BasicLit:
+			if column, err := strconv.Unquote(e.Value); err == nil {
+				columns = append(columns, column)
+			}
+		case *ast.CompositeLit:
+			for _, field := range e.Elts {
+				if kv, ok := field.(*ast.KeyValueExpr); ok {
+					if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Field" {
+						columns = append(columns, extractStringLiteral(kv.Value))
+					}
+				}
+			}
+		}
+	}
+	return columns
+}
+
+// Lookup returns the List query behind a response type, such as
+// "operators.PaginatedResult[[]catalog.ProductResponse]" or "catalog.ProductListResponse"
+func (r *ListQueryRegistry) Lookup(responseType string) (ListQuery, bool) {
+	typeName, ok := paginatedItemType(responseType)
+	if !ok {
+		typeName = strings.TrimLeft(responseType, "[]*")
+	}
+
+	pkg, name, ok := strings.Cut(typeName, ".")
+	if !ok {
+		return ListQuery{}, false
+	}
+	entity, ok := strings.CutSuffix(name, "ListResponse")
+	if !ok {
+		entity = strings.TrimSuffix(name, "Response")
+	}
+
+	query, ok := r.queries[pkg+"."+entity]
+	if !ok || query.Pagination == "" {
+		return ListQuery{}, false
+	}
+	return *query, true
+}
+
+// Parameters returns the query parameters of the List query
+func (q ListQuery) Parameters() []ParameterSpec {
+	var params []ParameterSpec
+	for _, column := range q.Filters {
+		params = append(params, ParameterSpec{
+			Name:        column,
+			In:          "query",
+			Description: fmt.Sprintf("Filter by %s; matches any of the comma-separated values", column),
+			Schema:      SchemaSpec{Type: "string"},
+		})
+	}
+
+	if q.Search {
+		params = append(params, ParameterSpec{
+			Name:        "search",
+			In:          "query",
+			Description: "Text matched against the searchable columns",
+			Schema:      SchemaSpec{Type: "string"},
+		})
+	}
+
+	if len(q.Sorts) > 0 {
+		var values []interface{}
+		for _, column := range q.Sorts {
+			values = append(values, column, "-"+column)
+		}
+		params = append(params, ParameterSpec{
+			Name:        "sort",
+			In:          "query",
+			Description: "Column to sort by; a leading - sorts descending",
+			Schema:      SchemaSpec{Type: "string", Enum: values},
+		})
+	}
+
+	if q.Pagination == "cursor" {
+		params = append(params, ParameterSpec{
+			Name:        "cursor",
+			In:          "query",
+			Description: "Cursor of the page, the next_cursor of the previous page",
+			Schema:      SchemaSpec{Type: "string"},
+		})
+	} else {
+		params = append(params, ParameterSpec{
+			Name:        "page",
+			In:          "query",
+			Description: "Page number",
+			Schema:      SchemaSpec{Type: "integer"},
+		})
+	}
+	params = append(params, ParameterSpec{
+		Name:        "page_size",
+		In:          "query",
+		Description: "Number of records per page",
+		Schema:      SchemaSpec{Type: "integer"},
+	})
+
+	return params
+}
@@ -0,0 +1,32 @@
+package openapi
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadSpec reads an OpenAPI 3.x document in YAML or JSON format
+func LoadSpec(path string) (*OpenAPISpec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read %s", path)
+	}
+
+	// JSON is a subset of YAML, so one decoder reads both formats
+	var spec OpenAPISpec
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return nil, errors.Wrap(err, "failed to parse %s", path)
+	}
+
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		if spec.OpenAPI == "" {
+			return nil, errors.New("%s is not an OpenAPI document: it has no openapi version", path)
+		}
+		return nil, errors.New("%s is OpenAPI %s; only OpenAPI 3.x documents are supported", path, spec.OpenAPI)
+	}
+
+	return &spec, nil
+}
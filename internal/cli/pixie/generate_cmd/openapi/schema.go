@@ -247,6 +247,7 @@ func (r *TypeResolver) parseField(field *ast.Field, schema *SchemaSpec) {
 	var jsonName string
 	var omitempty bool
 	var required bool
+	var validateTag string
 
 	if field.Tag != nil {
 		tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
@@ -265,7 +266,7 @@ func (r *TypeResolver) parseField(field *ast.Field, schema *SchemaSpec) {
 		}
 
 		// Parse validate tag to determine if required
-		if validateTag := tag.Get("validate"); validateTag != "" {
+		if validateTag = tag.Get("validate"); validateTag != "" {
 			if strings.Contains(validateTag, "required") {
 				required = true
 			}
@@ -279,6 +280,7 @@ func (r *TypeResolver) parseField(field *ast.Field, schema *SchemaSpec) {
 
 	// Get field type
 	fieldSchema := r.parseFieldType(field.Type)
+	applyValidateRules(&fieldSchema, validateTag)
 
 	// Add description from field comments
 	if field.Doc != nil {
@@ -297,6 +299,60 @@ func (r *TypeResolver) parseField(field *ast.Field, schema *SchemaSpec) {
 	}
 }
 
+// applyValidateRules documents the constraints of a validate tag on the field schema:
+// lengths, bounds, allowed values and string formats
+func applyValidateRules(schema *SchemaSpec, validateTag string) {
+	if schema.Ref != "" {
+		return
+	}
+
+	for _, rule := range strings.Split(validateTag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			switch {
+			case schema.Type == "string" && name == "min":
+				schema.MinLength = &n
+			case schema.Type == "string":
+				schema.MaxLength = &n
+			case (schema.Type == "integer" || schema.Type == "number") && name == "min":
+				bound := float64(n)
+				schema.Minimum = &bound
+			case schema.Type == "integer" || schema.Type == "number":
+				bound := float64(n)
+				schema.Maximum = &bound
+			}
+		case "gte", "lte":
+			bound, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			if name == "gte" {
+				schema.Minimum = &bound
+			} else {
+				schema.Maximum = &bound
+			}
+		case "oneof":
+			schema.Enum = nil
+			for _, value := range strings.Fields(param) {
+				if n, err := strconv.Atoi(value); err == nil && schema.Type == "integer" {
+					schema.Enum = append(schema.Enum, n)
+				} else {
+					schema.Enum = append(schema.Enum, value)
+				}
+			}
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		}
+	}
+}
+
 // parseFieldType parses a field type and returns a schema
 func (r *TypeResolver) parseFieldType(fieldType ast.Expr) SchemaSpec {
 	switch t := fieldType.(type) {
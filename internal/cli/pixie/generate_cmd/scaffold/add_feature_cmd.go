@@ -0,0 +1,277 @@
+package scaffold
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/features"
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/msconfig"
+)
+
+// AddFeatureOptions holds the options for enabling features on a microservice.
+type AddFeatureOptions struct {
+	Microservice string
+	Features     string
+	Force        bool
+}
+
+// AddFeatureCmd returns the cobra command enabling features on an existing microservice.
+func AddFeatureCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-feature",
+		Short: "Enable features on an existing microservice",
+		Long: `Enable features on a microservice generated by pixie generate microservice.
+
+The features are resolved with the ones they require, and only what they contribute
+is generated: files such as the data layer or the backoffice controllers are created,
+files the features change (registries, controllers) are merged with your edits, and
+the sections they need are added to the microservice's JSON config without touching
+the values already there. The manifest records the new feature list, so upgrade
+replays the microservice with it.
+
+Examples:
+  # Add caching and events to the orders microservice
+  pixie generate add-feature --ms orders --feature cache,events
+
+  # Turn on the backoffice endpoints
+  pixie generate add-feature --ms orders --feature backoffice
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var microservice, _ = cmd.Flags().GetString("ms")
+			var feature, _ = cmd.Flags().GetString("feature")
+			var force, _ = cmd.Flags().GetBool("force")
+
+			return addFeature(AddFeatureOptions{
+				Microservice: microservice,
+				Features:     feature,
+				Force:        force,
+			})
+		},
+	}
+
+	cmd.Flags().String("ms", "", "Name of the microservice, as given to generate microservice (required)")
+	cmd.Flags().String("feature", "", "Comma-separated list of features to enable (required)")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+
+	if err := cmd.MarkFlagRequired("ms"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'ms' flag as required: %v", err))
+	}
+	if err := cmd.MarkFlagRequired("feature"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'feature' flag as required: %v", err))
+	}
+
+	// Complete the feature names of --feature
+	if err := cmd.RegisterFlagCompletionFunc("feature", features.Complete); err != nil {
+		panic(fmt.Sprintf("Failed to register 'feature' flag completion: %v", err))
+	}
+
+	return cmd
+}
+
+func addFeature(opts AddFeatureOptions) error {
+	requested, err := features.Parse(opts.Features)
+	if err != nil {
+		return err
+	}
+	if len(requested) == 0 {
+		return errors.New("no feature to add")
+	}
+
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	previous, err := generatedMicroservice(cfg, opts.Microservice)
+	if err != nil {
+		return err
+	}
+	previous.Force = opts.Force
+
+	current := previous
+	current.Features = strings.Join(uniqueAppend(splitFeatures(previous.Features), requested), ",")
+
+	_, before, err := microserviceData(previous)
+	if err != nil {
+		return errors.Wrap(err, "failed to rebuild microservice %s", previous.Name)
+	}
+	cfg, after, err := microserviceData(current)
+	if err != nil {
+		return err
+	}
+
+	var added []string
+	for _, name := range genshared.EnabledFeatures(after.Features) {
+		if !before.Features[name] {
+			added = append(added, name)
+		}
+	}
+	if len(added) == 0 {
+		fmt.Printf("Microservice %s already has %s\n", previous.Name, strings.Join(requested, ", "))
+		return nil
+	}
+
+	fmt.Printf("Adding features to microservice: %s\n", after.ServiceNameCamel)
+	fmt.Printf("   Adding: %s\n", strings.Join(added, ", "))
+	fmt.Printf("   Features: %s\n\n", strings.Join(genshared.EnabledFeatures(after.Features), ", "))
+
+	gen, err := initshared.NewGeneration(cfg.Root, "generate microservice", microserviceInputs(current), opts.Force)
+	if err != nil {
+		return err
+	}
+	before = reuseMigrationTimestamp(gen, before, cfg)
+	after = reuseMigrationTimestamp(gen, after, cfg)
+
+	for _, mapping := range microserviceTemplates(after, current, cfg) {
+		id := Templates.Name + "/" + mapping.templateFile
+		if !features.Includes(after.Features, id) {
+			continue
+		}
+		if err := addFeatureFile(gen, cfg, mapping, before, after, features.Includes(before.Features, id)); err != nil {
+			return errors.Wrap(err, "failed to generate %s", mapping.outputPath)
+		}
+	}
+	if err := addFeatureConfig(gen, microserviceConfigPath(after, cfg), before, after); err != nil {
+		return errors.Wrap(err, "failed to generate the config")
+	}
+	if err := wireDomainTokens(after, cfg); err != nil {
+		return err
+	}
+	if err := gen.Finish(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSuccessfully added %s to microservice: %s\n\n", strings.Join(added, ", "), after.ServiceNameCamel)
+	printMicroserviceNextSteps(after, cfg)
+
+	return nil
+}
+
+// addFeatureFile brings the file of mapping from what the microservice generated with
+// the features of before to what it generates with the features of after.
+func addFeatureFile(gen *initshared.Generation, cfg genshared.GeneratorConfig, mapping microserviceTemplate, before, after genshared.TemplateData, existed bool) error {
+	if !existed {
+		_, err := gen.WriteTemplate(Templates, mapping.templateFile, mapping.outputPath, after)
+		return err
+	}
+
+	previous, _, err := Templates.Render(cfg.Root, mapping.templateFile, before)
+	if err != nil {
+		return err
+	}
+	content, resolved, err := Templates.Render(cfg.Root, mapping.templateFile, after)
+	if err != nil {
+		return err
+	}
+
+	if string(previous) == string(content) {
+		gen.Keep(mapping.outputPath)
+		return nil
+	}
+
+	_, err = gen.Write(mapping.outputPath, resolved.ID(), content)
+	return err
+}
+
+// addFeatureConfig adds the sections the new features need to the JSON config, which
+// keeps its values.
+func addFeatureConfig(gen *initshared.Generation, path string, before, after genshared.TemplateData) error {
+	previous, err := msconfig.Marshal(msconfig.Build(microserviceConfig(before)))
+	if err != nil {
+		return err
+	}
+	content, err := msconfig.Marshal(msconfig.Build(microserviceConfig(after)))
+	if err != nil {
+		return err
+	}
+
+	if string(previous) == string(content) {
+		gen.Keep(path)
+		return nil
+	}
+
+	return msconfig.Write(gen, path, microserviceConfig(after))
+}
+
+// generatedMicroservice returns the options the microservice called name was last
+// generated with, as recorded in the manifest.
+func generatedMicroservice(cfg genshared.GeneratorConfig, name string) (MicroserviceOptions, error) {
+	manifest, err := initshared.LoadManifest(cfg.Root)
+	if err != nil {
+		return MicroserviceOptions{}, err
+	}
+
+	name = strings.TrimPrefix(name, cfg.MicroservicePrefix)
+	var known []string
+	keys := make([]string, 0, len(manifest.Files))
+	for key := range manifest.Files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		entry := manifest.Files[key]
+		if entry.Generator != "generate microservice" {
+			continue
+		}
+		if entry.Inputs["name"] != name {
+			known = append(known, entry.Inputs["name"])
+			continue
+		}
+
+		port, err := strconv.Atoi(entry.Inputs["port"])
+		if err != nil {
+			return MicroserviceOptions{}, errors.Wrap(err, "invalid port recorded for microservice %s", name)
+		}
+		metricsPort, err := strconv.Atoi(entry.Inputs["metrics-port"])
+		if err != nil {
+			return MicroserviceOptions{}, errors.Wrap(err, "invalid metrics port recorded for microservice %s", name)
+		}
+
+		return MicroserviceOptions{
+			Name:        name,
+			Domain:      entry.Inputs["domain"],
+			Features:    entry.Inputs["features"],
+			Template:    entry.Inputs["template"],
+			Output:      entry.Inputs["output"],
+			Port:        port,
+			MetricsPort: metricsPort,
+			NoTests:     entry.Inputs["no-tests"] == "true",
+		}, nil
+	}
+
+	if len(known) == 0 {
+		return MicroserviceOptions{}, errors.New("microservice %s was not generated by pixie generate microservice", name)
+	}
+	sort.Strings(known)
+	known = slices.Compact(known)
+	return MicroserviceOptions{}, errors.New("microservice %s was not generated by pixie generate microservice (known: %s)", name, strings.Join(known, ", "))
+}
+
+func splitFeatures(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// uniqueAppend appends the values list does not hold yet.
+func uniqueAppend(list, values []string) []string {
+	for _, value := range values {
+		if !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,96 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newProject changes into an empty Go module for the duration of the test and returns its root.
+func newProject(t *testing.T) string {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/shop\n\ngo 1.24\n"), 0644); err != nil {
+		t.Fatalf("failed to write go.mod: %v", err)
+	}
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(origDir) })
+
+	return root
+}
+
+// generatedGoFiles returns the content of the Go files under root by path, leaving out
+// the base copies kept by the manifest.
+func generatedGoFiles(t *testing.T, root string) map[string][]byte {
+	t.Helper()
+
+	files := map[string][]byte{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".pixie" {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		files[path] = content
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to walk the project: %v", err)
+	}
+
+	return files
+}
+
+func TestAddFeature_BackofficeParses(t *testing.T) {
+	root := newProject(t)
+
+	err := generateMicroservice(MicroserviceOptions{
+		Name:        "orders",
+		Domain:      "sales",
+		Features:    "database,auth",
+		Template:    "standard",
+		Port:        8080,
+		MetricsPort: 9090,
+	})
+	if err != nil {
+		t.Fatalf("generateMicroservice() error = %v", err)
+	}
+	if err := addFeature(AddFeatureOptions{Microservice: "orders", Features: "backoffice"}); err != nil {
+		t.Fatalf("addFeature() error = %v", err)
+	}
+
+	msDir := filepath.Join(root, "internal", "ms", "ms_orders")
+	content, err := os.ReadFile(filepath.Join(msDir, "microservice.go"))
+	if err != nil {
+		t.Fatalf("failed to read microservice.go: %v", err)
+	}
+	if !strings.Contains(string(content), "httpBoControllers{") {
+		t.Errorf("microservice.go does not construct the backoffice controllers:\n%s", content)
+	}
+
+	for path, content := range generatedGoFiles(t, root) {
+		if _, err := parser.ParseFile(token.NewFileSet(), path, content, parser.AllErrors); err != nil {
+			t.Errorf("generated file does not parse: %v", err)
+		}
+	}
+	if matches, _ := filepath.Glob(filepath.Join(msDir, "*.pixie-new")); len(matches) > 0 {
+		t.Errorf("add-feature wrote sidecars %v, want the files merged", matches)
+	}
+}
@@ -2,9 +2,9 @@ package scaffold
 
 import (
 	"fmt"
-	"path/filepath"
 
 	"github.com/pixie-sh/errors-go"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/features"
 	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
 	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
 	"github.com/spf13/cobra"
@@ -16,6 +16,7 @@ type DomainOptions struct {
 	Features   string
 	ModuleName string
 	Force      bool
+	NoTests    bool
 }
 
 // DomainCmd returns the cobra command for domain generation.
@@ -26,13 +27,17 @@ func DomainCmd() *cobra.Command {
 		Long: `Generate a new domain with business layer, data layer, services, entities, repositories, and migrations.
 
 This command creates a complete domain implementation without microservice-specific files
-like HTTP controllers, command applications, or configurations.
+like HTTP controllers, command applications, or configurations. Unless --no-tests is set,
+the business layer, repository and service come with tests run against an in-memory
+sqlite database.
 
 Available features:
   - database: PostgreSQL integration with GORM (includes entities, repositories, migrations)
   - auth: JWT authentication fields in entities
   - apis: External API integration
 
+Run pixie generate features to list every feature with its dependencies.
+
 Examples:
   # Generate a basic domain
   pixie generate domain --domain users
@@ -42,18 +47,23 @@ Examples:
 
   # Force overwrite existing files
   pixie generate domain --domain existing --force
+
+  # Generate without the tests
+  pixie generate domain --domain users --no-tests
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var domain, _ = cmd.Flags().GetString("domain")
 			var features, _ = cmd.Flags().GetString("features")
 			var moduleName, _ = cmd.Flags().GetString("module-name")
 			var force, _ = cmd.Flags().GetBool("force")
+			var noTests, _ = cmd.Flags().GetBool("no-tests")
 
 			opts := DomainOptions{
 				Domain:     domain,
 				Features:   features,
 				ModuleName: moduleName,
 				Force:      force,
+				NoTests:    noTests,
 			}
 
 			return generateDomain(opts)
@@ -66,13 +76,19 @@ Examples:
 	// Optional flags
 	cmd.Flags().String("features", "database", "Comma-separated list of features")
 	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
-	cmd.Flags().Bool("force", false, "Force overwrite existing files")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+	cmd.Flags().Bool("no-tests", false, noTestsUsage)
 
 	// Mark required flags
 	if err := cmd.MarkFlagRequired("domain"); err != nil {
 		panic(fmt.Sprintf("Failed to mark 'domain' flag as required: %v", err))
 	}
 
+	// Complete the feature names of --features
+	if err := cmd.RegisterFlagCompletionFunc("features", features.Complete); err != nil {
+		panic(fmt.Sprintf("Failed to register 'features' flag completion: %v", err))
+	}
+
 	return cmd
 }
 
@@ -91,8 +107,14 @@ func generateDomain(opts DomainOptions) error {
 		return errors.Wrap(err, "failed to detect module name")
 	}
 
-	features := genshared.ParseFeatures(opts.Features, "minimal")
-	features = genshared.ResolveFeatureDependencies(features)
+	enabled, err := genshared.ParseFeatures(opts.Features, "minimal")
+	if err != nil {
+		return err
+	}
+	enabled, err = genshared.ResolveFeatureDependencies(enabled)
+	if err != nil {
+		return err
+	}
 
 	data := genshared.NewTemplateData()
 	data.DomainName = opts.Domain
@@ -104,10 +126,11 @@ func generateDomain(opts DomainOptions) error {
 	data.RepositoryNameCamel = initshared.ToCamelCase(opts.Domain)
 	data.EntityName = opts.Domain
 	data.EntityNameCamel = initshared.ToCamelCase(opts.Domain)
-	data.Features = features
+	data.Features = enabled
+	data.ApplyLayout(cfg)
 
 	fmt.Printf("Generating domain: %s\n", data.DomainNameCamel)
-	fmt.Printf("   Features: %s\n", genshared.FeaturesListString(features))
+	fmt.Printf("   Features: %s\n", genshared.FeaturesListString(enabled))
 	fmt.Printf("   Module: %s\n\n", data.ModuleName)
 
 	if err := generateDomainFiles(data, opts, cfg); err != nil {
@@ -121,98 +144,110 @@ func generateDomain(opts DomainOptions) error {
 }
 
 func generateDomainFiles(data genshared.TemplateData, opts DomainOptions, cfg genshared.GeneratorConfig) error {
+	gen, err := initshared.NewGeneration(cfg.Root, "generate domain", map[string]string{
+		"domain":   opts.Domain,
+		"features": opts.Features,
+		"no-tests": noTestsInput(opts.NoTests),
+	}, opts.Force)
+	if err != nil {
+		return err
+	}
+
+	migrationsDir := cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations")
+	if timestamp, ok := gen.PreviousPrefix(migrationsDir, "_create_"+data.DomainName+"_table.go"); ok {
+		data.MigrationTimestamp = timestamp
+	}
+
+	businessLayerPath := cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+cfg.BusinessLayerSuffix, data.DomainName+cfg.BusinessLayerSuffix+".go")
+	repositoryPath := cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_repositories", data.DomainName+"_repository.go")
+	servicePath := cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_services", data.DomainName+"_service.go")
+
 	templateMappings := []struct {
 		templateFile string
 		outputPath   string
-		condition    func() bool
 	}{
 		{
-			templateFile: "templates/business_layer.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+cfg.BusinessLayerSuffix, data.DomainName+cfg.BusinessLayerSuffix+".go"),
+			templateFile: "business_layer.go.tmpl",
+			outputPath:   businessLayerPath,
 		},
 		{
-			templateFile: "templates/data_layer.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_data_layer.go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "data_layer.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_data_layer.go"),
 		},
 		{
-			templateFile: "templates/services.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_services", data.DomainName+"_service.go"),
+			templateFile: "services.go.tmpl",
+			outputPath:   servicePath,
 		},
 		{
-			templateFile: "templates/domain_registry.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, "registry.go"),
+			templateFile: "domain_registry.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, "registry.go"),
 		},
 		{
-			templateFile: "templates/entities.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_entities", data.DomainName+".go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "entities.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_entities", data.DomainName+".go"),
 		},
 		{
-			templateFile: "templates/repositories.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_repositories", data.DomainName+"_repository.go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "repositories.go.tmpl",
+			outputPath:   repositoryPath,
 		},
 		{
-			templateFile: "templates/entity_migration.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations", data.MigrationTimestamp+"_create_"+data.DomainName+"_table.go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "entity_migration.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations", data.MigrationTimestamp+"_create_"+data.DomainName+"_table.go"),
 		},
 		{
-			templateFile: "templates/domain_migrations.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations", "migrations.go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "domain_migrations.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations", "migrations.go"),
 		},
 		{
-			templateFile: "templates/models.go.tmpl",
-			outputPath:   filepath.Join(cfg.ModelsDir, data.DomainName, data.DomainName+"_models.go"),
+			templateFile: "models.go.tmpl",
+			outputPath:   cfg.Path(cfg.ModelsDir, data.DomainName, data.DomainName+"_models.go"),
 		},
 	}
+	if !opts.NoTests {
+		templateMappings = append(templateMappings, []struct {
+			templateFile string
+			outputPath   string
+		}{
+			{templateFile: "business_layer_test.go.tmpl", outputPath: testPath(businessLayerPath)},
+			{templateFile: "repository_test.go.tmpl", outputPath: testPath(repositoryPath)},
+			{templateFile: "services_test.go.tmpl", outputPath: testPath(servicePath)},
+			{templateFile: "testdb.go.tmpl", outputPath: testDBPath(cfg)},
+		}...)
+	}
 
 	for _, mapping := range templateMappings {
-		if mapping.condition != nil && !mapping.condition() {
+		if !features.Includes(data.Features, Templates.Name+"/"+mapping.templateFile) {
 			fmt.Printf("   Skipping %s (feature not enabled)\n", mapping.templateFile)
 			continue
 		}
 
-		if !opts.Force && initshared.FileExists(mapping.outputPath) {
-			fmt.Printf("   WARNING: Skipping %s (file exists, use --force to overwrite)\n", mapping.outputPath)
-			continue
-		}
-
-		fmt.Printf("   Generating %s\n", mapping.outputPath)
-
-		content, err := initshared.RenderTemplate(Templates, mapping.templateFile, data)
-		if err != nil {
-			return errors.Wrap(err, "failed to render template %s", mapping.templateFile)
-		}
-
-		if err := initshared.WriteFile(mapping.outputPath, content, opts.Force); err != nil {
-			return errors.Wrap(err, "failed to write %s", mapping.outputPath)
+		if _, err := gen.WriteTemplate(Templates, mapping.templateFile, mapping.outputPath, data); err != nil {
+			return errors.Wrap(err, "failed to generate %s", mapping.outputPath)
 		}
 	}
+	if err := wireDomainTokens(data, cfg); err != nil {
+		return err
+	}
+	if !opts.NoTests && data.Features["database"] {
+		printTestDependencies(cfg)
+	}
 
-	return nil
+	return gen.Finish()
 }
 
 func printDomainNextSteps(data genshared.TemplateData, cfg genshared.GeneratorConfig) {
 	fmt.Printf("Next steps:\n\n")
 
-	fmt.Printf("1. Update DI tokens in infra/di/injection_tokens.go:\n")
-	fmt.Printf("   RegistryToken%sDataLayer     = pdi.RegisterInjectionToken(\"%s_data_layer\")\n", data.DomainNameCamel, data.DomainName)
-	fmt.Printf("   RegistryToken%sBusinessLayer = pdi.RegisterInjectionToken(\"%s_business_layer\")\n", data.DomainNameCamel, data.DomainName)
-	fmt.Printf("   RegistryToken%sService       = pdi.RegisterInjectionToken(\"%s_service\")\n\n", data.DomainNameCamel, data.DomainName)
-
-	fmt.Printf("2. Update dependencies:\n")
+	fmt.Printf("1. Update dependencies:\n")
 	fmt.Printf("   go mod tidy\n\n")
 
 	if data.Features["database"] {
-		fmt.Printf("3. Run database migrations (if database enabled):\n")
+		fmt.Printf("2. Run database migrations (if database enabled):\n")
 		fmt.Printf("   # Register migrations in your migration system\n")
 		fmt.Printf("   # Add %s_migrations.Migrations to your migration registry\n\n", data.DomainName)
 	}
 
-	fmt.Printf("4. Test the generated domain:\n")
+	fmt.Printf("3. Test the generated domain:\n")
 	fmt.Printf("   go build ./%s/%s/...\n", cfg.DomainDir, data.DomainName)
 	fmt.Printf("   go test ./%s/%s/...\n\n", cfg.DomainDir, data.DomainName)
 
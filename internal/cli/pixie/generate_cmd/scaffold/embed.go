@@ -1,6 +1,13 @@
 package scaffold
 
-import "embed"
+import (
+	"embed"
+
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
 
 //go:embed templates/*.tmpl
-var Templates embed.FS
+var templateFS embed.FS
+
+// Templates holds the templates of the generate scaffold commands.
+var Templates = initshared.NewTemplatePack("scaffold", templateFS, "templates")
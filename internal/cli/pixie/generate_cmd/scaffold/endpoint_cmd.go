@@ -0,0 +1,567 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/wiring"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// EndpointOptions holds the options for adding an endpoint to a microservice.
+type EndpointOptions struct {
+	Microservice string
+	Method       string
+	Path         string
+	Request      string
+	Response     string
+	Auth         bool
+	Permissions  string
+	Backoffice   bool
+	Name         string
+	Domain       string
+	ModuleName   string
+}
+
+// endpointMethods maps the HTTP methods an endpoint can serve to their router method.
+var endpointMethods = map[string]string{
+	"GET":    "Get",
+	"POST":   "Post",
+	"PUT":    "Put",
+	"PATCH":  "Patch",
+	"DELETE": "Delete",
+}
+
+// EndpointCmd returns the cobra command adding an endpoint to an existing microservice.
+func EndpointCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "endpoint",
+		Short: "Add an endpoint to an existing microservice",
+		Long: `Add an endpoint to a microservice generated by pixie generate microservice.
+
+This command edits the existing code in place:
+- Registers the route in SetupHTTP of the microservice controllers, under the
+  route group whose prefix the path starts with
+- Appends the handler to the controllers file: it reads the path parameters, the
+  request body and, with --auth, the authenticated user
+- Appends a stub of the business layer method the handler calls
+- Declares the request and response models the domain does not have yet
+
+Declarations that already exist are left as they are, so running the command again
+changes nothing. Path parameters named id or ending in _id are read as UIDs, the
+others as strings. --permission implies --auth.
+
+Examples:
+  # Cancel an order, for users holding the orders.cancel permission
+  pixie generate endpoint --ms orders --method POST --path /orders/:id/cancel \
+    --request CancelOrderRequest --response OrderDetails --auth --permission orders.cancel
+
+  # Backoffice report, in the backoffice controllers
+  pixie generate endpoint --ms orders --bo --method GET --path /backoffice/orders/report \
+    --response OrdersReport --permission orders.report
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var microservice, _ = cmd.Flags().GetString("ms")
+			var method, _ = cmd.Flags().GetString("method")
+			var path, _ = cmd.Flags().GetString("path")
+			var request, _ = cmd.Flags().GetString("request")
+			var response, _ = cmd.Flags().GetString("response")
+			var auth, _ = cmd.Flags().GetBool("auth")
+			var permission, _ = cmd.Flags().GetString("permission")
+			var backoffice, _ = cmd.Flags().GetBool("bo")
+			var name, _ = cmd.Flags().GetString("name")
+			var domain, _ = cmd.Flags().GetString("domain")
+			var moduleName, _ = cmd.Flags().GetString("module-name")
+
+			return generateEndpoint(EndpointOptions{
+				Microservice: microservice,
+				Method:       method,
+				Path:         path,
+				Request:      request,
+				Response:     response,
+				Auth:         auth,
+				Permissions:  permission,
+				Backoffice:   backoffice,
+				Name:         name,
+				Domain:       domain,
+				ModuleName:   moduleName,
+			})
+		},
+	}
+
+	// Required flags
+	cmd.Flags().String("ms", "", "Name of the microservice serving the endpoint (required)")
+	cmd.Flags().String("method", "", "HTTP method: GET, POST, PUT, PATCH or DELETE (required)")
+	cmd.Flags().String("path", "", "Full route path, with :name path parameters (required)")
+
+	// Optional flags
+	cmd.Flags().String("request", "", "Model type of the request body")
+	cmd.Flags().String("response", "", "Model type of the response body (default: 204 No Content)")
+	cmd.Flags().Bool("auth", false, "Require an authenticated user")
+	cmd.Flags().String("permission", "", "Comma-separated permissions the user must hold all of (implies --auth)")
+	cmd.Flags().Bool("bo", false, "Add the endpoint to the backoffice controllers")
+	cmd.Flags().String("name", "", "Name of the business layer method (default: derived from --request or the path)")
+	cmd.Flags().String("domain", "", "Domain whose business layer serves the endpoint, when the controllers hold several")
+	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
+
+	// Mark required flags
+	for _, flag := range []string{"ms", "method", "path"} {
+		if err := cmd.MarkFlagRequired(flag); err != nil {
+			panic(fmt.Sprintf("Failed to mark '%s' flag as required: %v", flag, err))
+		}
+	}
+
+	return cmd
+}
+
+// endpoint is the endpoint being added and the code serving it.
+type endpoint struct {
+	Verb        string // POST
+	Path        string // /orders/:id/cancel
+	Method      string // CancelOrder: the business layer method
+	Request     string
+	Response    string
+	Auth        bool
+	Permissions []string
+	Params      []endpointParam
+}
+
+// endpointParam is a path parameter of an endpoint.
+type endpointParam struct {
+	Name string // order_id
+	Var  string // orderID
+	UID  bool
+}
+
+func generateEndpoint(opts EndpointOptions) error {
+	ep, err := newEndpoint(opts)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+	moduleName, err := genshared.ResolveModule(opts.ModuleName)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect module name")
+	}
+
+	microservice := strings.TrimPrefix(opts.Microservice, cfg.MicroservicePrefix)
+	controllersPath := cfg.Path(cfg.MicroserviceDir, cfg.MicroservicePrefix+microservice, "http_controllers.go")
+	receiver := "httpControllers"
+	if opts.Backoffice {
+		controllersPath = cfg.Path(cfg.MicroserviceDir, cfg.MicroservicePrefix+microservice, "http_bo_controllers.go")
+		receiver = "httpBoControllers"
+	}
+
+	src, err := initshared.ReadFile(controllersPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the controllers of microservice %s", microservice)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), controllersPath, src, 0)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse %s", controllersPath)
+	}
+
+	controllers, err := endpointControllers(cfg, file, receiver, opts.Domain)
+	if err != nil {
+		return errors.Wrap(err, "invalid controllers in %s", controllersPath)
+	}
+	if ep.Auth && controllers.gates == "" {
+		return errors.New("the endpoint requires authentication, but the %s in %s have no authorization gates", receiver, controllersPath)
+	}
+
+	data := genshared.NewTemplateData()
+	data.ModuleName = moduleName
+	data.ServiceName = microservice
+	data.DomainName = strings.TrimSuffix(controllers.businessLayerPkg, cfg.BusinessLayerSuffix)
+	data.DomainNameCamel = controllers.businessLayerType
+	data.ApplyLayout(cfg)
+
+	fmt.Printf("Adding endpoint to microservice: %s\n", microservice)
+	fmt.Printf("   Route: %s %s\n", ep.Verb, ep.Path)
+	fmt.Printf("   Business layer: %s.%sBusinessLayer.%s\n", controllers.businessLayerPkg, data.DomainNameCamel, ep.Method)
+	if ep.Auth {
+		fmt.Printf("   Auth: required")
+		if len(ep.Permissions) > 0 {
+			fmt.Printf(" (%s)", strings.Join(ep.Permissions, ", "))
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+
+	if models := ep.models(); models != "" {
+		modelsPath := cfg.Path(cfg.ModelsDir, data.DomainName, data.DomainName+"_models.go")
+		if err := wireDecls(modelsPath, nil, models); err != nil {
+			return err
+		}
+	}
+
+	businessLayerPath := cfg.Path(cfg.DomainDir, data.DomainName, controllers.businessLayerPkg, controllers.businessLayerPkg+".go")
+	if err := wireDecls(businessLayerPath, map[string]string{
+		"context":                             "context",
+		"github.com/pixie-sh/core-go/pkg/uid": "uid",
+		"github.com/pixie-sh/errors-go":       "errors",
+		data.ModelsImport:                     data.DomainName,
+	}, ep.businessLayerMethod(data)); err != nil {
+		return err
+	}
+
+	if err := wireDecls(controllersPath, map[string]string{
+		"github.com/pixie-sh/core-go/pkg/comm/http":         "http",
+		"github.com/pixie-sh/core-go/pkg/models/serializer": "serializer",
+		data.HTTPContextImport:                              "httpcontext",
+		data.ModelsImport:                                   data.DomainName,
+	}, ep.handler(receiver, controllers.businessLayer, data)); err != nil {
+		return err
+	}
+
+	catalogued, err := permissionConstants(permissionsPath(cfg))
+	if err != nil {
+		return err
+	}
+	stmt := ep.route(file, receiver, controllers.gates, catalogued)
+	var routeImports map[string]string
+	if strings.Contains(stmt, "gates.Permission") {
+		routeImports = map[string]string{data.GatesImport: "gates"}
+	}
+	if err := wire(controllersPath, func() (bool, error) {
+		return wiring.AddStatements(controllersPath, receiver+".SetupHTTP", routeImports, []string{stmt})
+	}, []string{"Add to SetupHTTP():", stmt}); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSuccessfully added endpoint: %s %s\n\n", ep.Verb, ep.Path)
+	printEndpointNextSteps(ep, data, businessLayerPath)
+
+	return nil
+}
+
+// newEndpoint validates opts and returns the endpoint they describe.
+func newEndpoint(opts EndpointOptions) (endpoint, error) {
+	ep := endpoint{
+		Verb:     strings.ToUpper(opts.Method),
+		Path:     "/" + strings.Trim(opts.Path, "/"),
+		Request:  opts.Request,
+		Response: opts.Response,
+		Auth:     opts.Auth,
+	}
+	if _, ok := endpointMethods[ep.Verb]; !ok {
+		return endpoint{}, errors.New("unsupported method %s; use GET, POST, PUT, PATCH or DELETE", opts.Method)
+	}
+
+	for _, model := range []string{ep.Request, ep.Response} {
+		if model != "" && (!token.IsIdentifier(model) || !token.IsExported(model)) {
+			return endpoint{}, errors.New("invalid model type %q: must be an exported Go identifier such as OrderDetails", model)
+		}
+	}
+
+	for _, permission := range strings.Split(opts.Permissions, ",") {
+		if permission = strings.TrimSpace(permission); permission != "" {
+			ep.Permissions = append(ep.Permissions, permission)
+		}
+	}
+	if len(ep.Permissions) > 0 {
+		ep.Auth = true
+	}
+
+	vars := map[string]bool{}
+	var words []string
+	for _, segment := range strings.Split(strings.Trim(ep.Path, "/"), "/") {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			words = append(words, segment)
+			continue
+		}
+		name = strings.TrimSuffix(name, "?")
+		if name == "" {
+			return endpoint{}, errors.New("invalid path %s: empty parameter name", opts.Path)
+		}
+
+		param := endpointParam{Name: name, Var: genshared.GoVarName(name), UID: name == "id" || strings.HasSuffix(name, "_id")}
+		if vars[param.Var] {
+			return endpoint{}, errors.New("invalid path %s: parameter %s appears twice", opts.Path, name)
+		}
+		vars[param.Var] = true
+		ep.Params = append(ep.Params, param)
+	}
+
+	switch {
+	case opts.Name != "":
+		ep.Method = genshared.GoName(opts.Name)
+	case strings.HasSuffix(ep.Request, "Request") && ep.Request != "Request":
+		ep.Method = strings.TrimSuffix(ep.Request, "Request")
+	default:
+		ep.Method = genshared.GoName(strings.ToLower(ep.Verb) + "_" + strings.Join(words, "_"))
+	}
+	if !token.IsIdentifier(ep.Method) {
+		return endpoint{}, errors.New("cannot derive a method name from %s; pass --name", ep.Path)
+	}
+
+	return ep, nil
+}
+
+// endpointController describes the controllers an endpoint is added to.
+type endpointController struct {
+	businessLayer     string // field holding the business layer
+	businessLayerPkg  string // orders_business_layer
+	businessLayerType string // Orders, of OrdersBusinessLayer
+	gates             string // field holding the authorization gates; empty when there are none
+}
+
+// endpointControllers finds the business layer and gates fields of the receiver
+// struct in file. domain picks the business layer when there are several.
+func endpointControllers(cfg genshared.GeneratorConfig, file *ast.File, receiver, domain string) (*endpointController, error) {
+	controllers := &endpointController{}
+	var candidates []string
+	fields := structFields(file, receiver)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fieldType := fields[name]
+		if fieldType == "*bundles.AuthorizationGatesBundle" {
+			controllers.gates = name
+			continue
+		}
+
+		pkg, typeName, ok := strings.Cut(strings.TrimPrefix(fieldType, "*"), ".")
+		if !ok || !strings.HasSuffix(typeName, "BusinessLayer") {
+			continue
+		}
+		candidates = append(candidates, pkg)
+		if domain != "" && pkg != domain+cfg.BusinessLayerSuffix {
+			continue
+		}
+		if controllers.businessLayer != "" {
+			return nil, errors.New("%s holds several business layers (%s); pick one with --domain", receiver, strings.Join(candidates, ", "))
+		}
+		controllers.businessLayer = name
+		controllers.businessLayerPkg = pkg
+		controllers.businessLayerType = strings.TrimSuffix(typeName, "BusinessLayer")
+	}
+
+	if controllers.businessLayer == "" {
+		if domain != "" {
+			return nil, errors.New("%s has no business layer of domain %s", receiver, domain)
+		}
+		return nil, errors.New("%s has no business layer field", receiver)
+	}
+
+	return controllers, nil
+}
+
+// route returns the statement registering the endpoint in SetupHTTP: on the route
+// group with the longest prefix of its path, or else on the server. Permissions of the
+// catalogue, given as constant names by value, are referred to by their constant.
+func (ep endpoint) route(file *ast.File, receiver, gates string, catalogued map[string]string) string {
+	groups, hasPermission := setupRoutes(file, receiver)
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	router, prefix := "c.server", ""
+	for _, name := range names {
+		if len(groups[name]) > len(prefix) && strings.HasPrefix(ep.Path, groups[name]+"/") {
+			router, prefix = name, groups[name]
+		}
+	}
+
+	args := []string{strconv.Quote(strings.TrimPrefix(ep.Path, prefix))}
+	if ep.Auth {
+		args = append(args, fmt.Sprintf("c.%s.IsAuthenticated.Authenticated()", gates))
+	}
+	if len(ep.Permissions) > 0 {
+		quoted := make([]string, len(ep.Permissions))
+		for i, permission := range ep.Permissions {
+			if constant, ok := catalogued[permission]; ok {
+				quoted[i] = "gates." + constant
+			} else {
+				quoted[i] = strconv.Quote(permission)
+			}
+		}
+		if hasPermission == "" {
+			hasPermission = fmt.Sprintf("c.%s.IsAuthenticated.AllFeaturesOf", gates)
+		}
+		args = append(args, fmt.Sprintf("%s(%s)", hasPermission, strings.Join(quoted, ", ")))
+	}
+	args = append(args, "c.handle"+ep.Method)
+
+	return fmt.Sprintf("%s.%s(%s)", router, endpointMethods[ep.Verb], strings.Join(args, ", "))
+}
+
+// setupRoutes returns the route groups SetupHTTP of receiver declares, such as
+// ordersGroup := c.server.Group("/orders"), by variable, with their full prefix. It
+// also returns the variable aliasing the permission gate, as in
+// hasPermission := c.gates.IsAuthenticated.AllFeaturesOf, if any.
+func setupRoutes(file *ast.File, receiver string) (map[string]string, string) {
+	groups := map[string]string{}
+	var hasPermission string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "SetupHTTP" || fn.Body == nil || fn.Recv == nil || len(fn.Recv.List) == 0 ||
+			strings.TrimPrefix(typeString(fn.Recv.List[0].Type), "*") != receiver {
+			continue
+		}
+
+		for _, stmt := range fn.Body.List {
+			assign, ok := stmt.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+				continue
+			}
+			variable, ok := assign.Lhs[0].(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if alias, ok := assign.Rhs[0].(*ast.SelectorExpr); ok && alias.Sel.Name == "AllFeaturesOf" {
+				hasPermission = variable.Name
+				continue
+			}
+			call, isCall := assign.Rhs[0].(*ast.CallExpr)
+			if !isCall || len(call.Args) == 0 {
+				continue
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || selector.Sel.Name != "Group" {
+				continue
+			}
+			literal, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || literal.Kind != token.STRING {
+				continue
+			}
+			prefix, err := strconv.Unquote(literal.Value)
+			if err != nil {
+				continue
+			}
+
+			// Nested groups extend the prefix of the group they are created from
+			if parent, ok := selector.X.(*ast.Ident); ok {
+				parentPrefix, known := groups[parent.Name]
+				if !known {
+					continue
+				}
+				prefix = parentPrefix + prefix
+			}
+			groups[variable.Name] = strings.TrimSuffix(prefix, "/")
+		}
+	}
+
+	return groups, hasPermission
+}
+
+// handler returns the source of the handler serving the endpoint.
+func (ep endpoint) handler(receiver, businessLayer string, data genshared.TemplateData) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// handle%s handles %s %s\n", ep.Method, ep.Verb, ep.Path)
+	fmt.Fprintf(&b, "func (c %s) handle%s(ctx http.ServerCtx) error {\n", receiver, ep.Method)
+
+	args := []string{"ctx.Context()"}
+	for _, param := range ep.Params {
+		if param.UID {
+			fmt.Fprintf(&b, "\t%s, err := http.ParamsUID(ctx, %q)\n\tif err != nil {\n\t\treturn http.Response(ctx, err)\n\t}\n\n", param.Var, param.Name)
+		} else {
+			fmt.Fprintf(&b, "\t%s := ctx.Params(%q)\n\n", param.Var, param.Name)
+		}
+		args = append(args, param.Var)
+	}
+	if ep.Request != "" {
+		fmt.Fprintf(&b, "\tvar req %s.%s\n\tif err := serializer.DeserializeFromFn(ctx.BodyParser, &req); err != nil {\n\t\treturn http.Response(ctx, err)\n\t}\n\n", data.DomainName, ep.Request)
+		args = append(args, "req")
+	}
+	if ep.Auth {
+		b.WriteString("\tjwt := httpcontext.GetCtxJWT(ctx)\n\n")
+		args = append(args, "jwt.User.ID")
+	}
+
+	call := fmt.Sprintf("c.%s.%s(%s)", businessLayer, ep.Method, strings.Join(args, ", "))
+	if ep.Response != "" {
+		fmt.Fprintf(&b, "\tresponse, err := %s\n\treturn http.Response(ctx, response, err)\n", call)
+	} else {
+		fmt.Fprintf(&b, "\tif err := %s; err != nil {\n\t\treturn http.Response(ctx, err)\n\t}\n\treturn http.Response(ctx, 204)\n", call)
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// businessLayerMethod returns the source of the business layer method stub.
+func (ep endpoint) businessLayerMethod(data genshared.TemplateData) string {
+	params := []string{"ctx context.Context"}
+	for _, param := range ep.Params {
+		paramType := "string"
+		if param.UID {
+			paramType = "uid.UID"
+		}
+		params = append(params, param.Var+" "+paramType)
+	}
+	if ep.Request != "" {
+		params = append(params, fmt.Sprintf("req %s.%s", data.DomainName, ep.Request))
+	}
+	if ep.Auth {
+		params = append(params, "userID uid.UID")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s serves %s %s\n", ep.Method, ep.Verb, ep.Path)
+	fmt.Fprintf(&b, "func (l %sBusinessLayer) %s(%s) ", data.DomainNameCamel, ep.Method, strings.Join(params, ", "))
+	if ep.Response != "" {
+		fmt.Fprintf(&b, "(%s.%s, error) {\n\tvar response %s.%s\n", data.DomainName, ep.Response, data.DomainName, ep.Response)
+		fmt.Fprintf(&b, "\treturn response, errors.New(%q).WithErrorCode(errors.ServerErrorErrorCode)\n}\n", ep.Method+" is not implemented")
+	} else {
+		fmt.Fprintf(&b, "error {\n\treturn errors.New(%q).WithErrorCode(errors.ServerErrorErrorCode)\n}\n", ep.Method+" is not implemented")
+	}
+
+	return b.String()
+}
+
+// models returns the source of the request and response models of the endpoint.
+func (ep endpoint) models() string {
+	var decls []string
+	if ep.Request != "" {
+		decls = append(decls, fmt.Sprintf("// %s represents the request payload of %s %s\ntype %s struct{}\n", ep.Request, ep.Verb, ep.Path, ep.Request))
+	}
+	if ep.Response != "" && ep.Response != ep.Request {
+		decls = append(decls, fmt.Sprintf("// %s represents the response payload of %s %s\ntype %s struct{}\n", ep.Response, ep.Verb, ep.Path, ep.Response))
+	}
+
+	return strings.Join(decls, "\n")
+}
+
+// wireDecls appends the declarations of source path is missing.
+func wireDecls(path string, imports map[string]string, source string) error {
+	return wire(path, func() (bool, error) {
+		return wiring.AddDecls(path, imports, source)
+	}, append([]string{"Add:"}, strings.Split(strings.TrimSuffix(source, "\n"), "\n")...))
+}
+
+func printEndpointNextSteps(ep endpoint, data genshared.TemplateData, businessLayerPath string) {
+	fmt.Printf("Next steps:\n\n")
+
+	step := 1
+	fmt.Printf("%d. Implement %s in %s\n\n", step, ep.Method, businessLayerPath)
+	step++
+
+	if ep.Request != "" || ep.Response != "" {
+		fmt.Printf("%d. Add the fields of the %s models\n\n", step, data.DomainName)
+		step++
+	}
+
+	fmt.Printf("%d. Regenerate the OpenAPI spec to document the endpoint:\n", step)
+	fmt.Printf("   pixie generate openapi-spec\n\n")
+}
@@ -2,9 +2,12 @@ package scaffold
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/pixie-sh/errors-go"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/features"
 	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
 	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
 	"github.com/spf13/cobra"
@@ -16,6 +19,11 @@ type EntityOptions struct {
 	EntityName string
 	Features   string
 	ModuleName string
+	Fields     string
+	FieldsFile string
+	Relations  map[string]string // relation flag values keyed by kind
+	Pagination string
+	NoTests    bool
 	Force      bool
 }
 
@@ -29,6 +37,31 @@ func EntityCmd() *cobra.Command {
 This command creates:
 - A new entity file in the domain's entities directory
 - A corresponding migration file in the domain's migrations directory
+- With --fields, --fields-file or relations, the entity's request/response models
+  and its repository, with a finder for every unique or indexed field and preload
+  helpers for every association, and the repository tests against an in-memory
+  sqlite database unless --no-tests is set
+
+Fields are comma-separated name:type[:modifier...] entries. Types are string, text,
+int, int64, float, decimal, bool, time, uid, json and enum(a|b); a trailing ? makes
+the field optional (nullable). Modifiers are required, unique, index, size=N and
+default=V. A fields file is YAML with a top-level "fields" list of
+{name, type, required, optional, unique, index, size, default, values}.
+
+Relations take comma-separated entity names:
+  --belongs-to user         adds a UserID foreign key column, index, constraint and
+                            User association; use domain.entity for an entity of
+                            another domain (column and constraint only)
+  --has-many line_item      adds a LineItems association; line_item declares
+                            --belongs-to with this entity
+  --many-to-many tag        adds a Tags association and the join table
+The entity's migration is registered before the migrations of entities referencing it
+and after the ones it references.
+
+The repository's List query filters on enum, boolean, integer, uid, unique or indexed
+string and foreign key columns (?status=draft,live), matches ?search against the
+string columns and pages with ?page and ?page_size, sorted by ?sort. With
+--pagination cursor it pages newest first with ?cursor and ?page_size instead.
 
 Available features:
   - auth: Adds UserID field for user-scoped entities
@@ -40,6 +73,18 @@ Examples:
   # Generate a user-scoped comment entity in the posts domain
   pixie generate entity --domain posts --name comment --features auth
 
+  # Generate an entity with typed fields
+  pixie generate entity --domain catalog --name product \
+    --fields "title:string:required:size=200,price:decimal,published_at:time?,status:enum(draft|live)"
+
+  # Generate an order line belonging to an order, and the order owning its lines
+  pixie generate entity --domain orders --name line_item --belongs-to order \
+    --fields "quantity:int:required"
+  pixie generate entity --domain orders --name order --has-many line_item
+
+  # Read the fields from a YAML file
+  pixie generate entity --domain catalog --name product --fields-file product.fields.yaml
+
   # Force overwrite existing entity and migration
   pixie generate entity --domain catalog --name product --force
 `,
@@ -48,6 +93,14 @@ Examples:
 			var entityName, _ = cmd.Flags().GetString("name")
 			var features, _ = cmd.Flags().GetString("features")
 			var moduleName, _ = cmd.Flags().GetString("module-name")
+			var fields, _ = cmd.Flags().GetString("fields")
+			var fieldsFile, _ = cmd.Flags().GetString("fields-file")
+			var relations = map[string]string{}
+			for _, kind := range genshared.RelationKinds {
+				relations[kind], _ = cmd.Flags().GetString(kind)
+			}
+			var pagination, _ = cmd.Flags().GetString("pagination")
+			var noTests, _ = cmd.Flags().GetBool("no-tests")
 			var force, _ = cmd.Flags().GetBool("force")
 
 			opts := EntityOptions{
@@ -55,6 +108,11 @@ Examples:
 				EntityName: entityName,
 				Features:   features,
 				ModuleName: moduleName,
+				Fields:     fields,
+				FieldsFile: fieldsFile,
+				Relations:  relations,
+				Pagination: pagination,
+				NoTests:    noTests,
 				Force:      force,
 			}
 
@@ -69,7 +127,14 @@ Examples:
 	// Optional flags
 	cmd.Flags().String("features", "", "Comma-separated list of features (auth)")
 	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
-	cmd.Flags().Bool("force", false, "Force overwrite existing files")
+	cmd.Flags().String("fields", "", "Comma-separated field specs, e.g. title:string:required,published_at:time?")
+	cmd.Flags().String("fields-file", "", "YAML file listing the entity fields")
+	cmd.Flags().String(genshared.BelongsTo, "", "Comma-separated entities this entity belongs to (domain.entity for other domains)")
+	cmd.Flags().String(genshared.HasMany, "", "Comma-separated entities this entity has many of")
+	cmd.Flags().String(genshared.ManyToMany, "", "Comma-separated entities joined to this entity")
+	cmd.Flags().String("pagination", genshared.PaginationOffset, "Pagination of the repository List query (offset, cursor)")
+	cmd.Flags().Bool("no-tests", false, noTestsUsage)
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
 
 	// Mark required flags
 	if err := cmd.MarkFlagRequired("domain"); err != nil {
@@ -79,79 +144,261 @@ Examples:
 		panic(fmt.Sprintf("Failed to mark 'name' flag as required: %v", err))
 	}
 
+	// Complete the feature names of --features
+	if err := cmd.RegisterFlagCompletionFunc("features", features.Complete); err != nil {
+		panic(fmt.Sprintf("Failed to register 'features' flag completion: %v", err))
+	}
+
 	return cmd
 }
 
 func generateEntity(opts EntityOptions) error {
-	if !genshared.IsValidIdentifier(opts.Domain) {
-		return errors.New("domain name must be a valid identifier (e.g., catalog)")
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
 	}
-	if !genshared.IsValidIdentifier(opts.EntityName) {
-		return errors.New("entity name must be a valid identifier (e.g., product)")
+
+	data, err := entityData(opts, cfg)
+	if err != nil {
+		return err
 	}
 
-	cfg, err := genshared.LoadConfig()
+	fmt.Printf("Generating entity: %s\n", data.EntityNameCamel)
+	printEntitySummary(data)
+
+	// Read the other entities of the domain before this run records its own inputs
+	recorded, err := genshared.RecordedEntities(cfg.Root, opts.Domain)
 	if err != nil {
-		return errors.Wrap(err, "failed to load config")
+		return err
 	}
 
-	moduleName, err := genshared.ResolveModule(opts.ModuleName)
+	gen, err := initshared.NewGeneration(cfg.Root, "generate entity", entityInputs(opts), opts.Force)
 	if err != nil {
-		return errors.Wrap(err, "failed to detect module name")
+		return err
+	}
+
+	// With fields or relations, also generate the models and repository matching the entity
+	if err := writeEntity(gen, &data, cfg, recorded, len(data.Fields) > 0 || len(data.Relations) > 0, !opts.NoTests); err != nil {
+		return err
 	}
+	if err := gen.Finish(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully generated entity: %s\n\n", data.EntityNameCamel)
+	printEntityNextSteps(data, opts, cfg)
+	printMissingRelations(data, recorded, cfg)
 
-	features := genshared.ParseFeatures(opts.Features, "minimal")
+	return nil
+}
 
+// entityData validates opts and returns the template data of the entity, with its
+// fields and relations applied.
+func entityData(opts EntityOptions, cfg genshared.GeneratorConfig) (genshared.TemplateData, error) {
 	data := genshared.NewTemplateData()
+
+	if !genshared.IsValidIdentifier(opts.Domain) {
+		return data, errors.New("domain name must be a valid identifier (e.g., catalog)")
+	}
+	if !genshared.IsValidIdentifier(opts.EntityName) && !genshared.IsValidSnakeCase(opts.EntityName) {
+		return data, errors.New("entity name must be a valid identifier (e.g., product or line_item)")
+	}
+
+	moduleName, err := genshared.ResolveModule(opts.ModuleName)
+	if err != nil {
+		return data, errors.Wrap(err, "failed to detect module name")
+	}
+
+	fields, err := genshared.ResolveFields(opts.Fields, opts.FieldsFile, cfg.Root)
+	if err != nil {
+		return data, errors.Wrap(err, "invalid entity fields")
+	}
+
+	relations, err := genshared.RelationsFromInputs(opts.Relations)
+	if err != nil {
+		return data, errors.Wrap(err, "invalid entity relations")
+	}
+
+	pagination, err := genshared.ParsePagination(opts.Pagination)
+	if err != nil {
+		return data, err
+	}
+
 	data.DomainName = opts.Domain
 	data.DomainNameCamel = initshared.ToCamelCase(opts.Domain)
 	data.EntityName = opts.EntityName
 	data.EntityNameCamel = initshared.ToCamelCase(opts.EntityName)
+	data.RepositoryName = opts.EntityName
+	data.RepositoryNameCamel = data.EntityNameCamel
 	data.ModuleName = moduleName
-	data.Features = features
+	data.Features, err = genshared.ParseFeatures(opts.Features, "minimal")
+	if err != nil {
+		return data, err
+	}
+	data.Pagination = pagination
+	data.ApplyLayout(cfg)
+	data.ApplyFields(fields)
+	if err := data.ApplyRelations(relations); err != nil {
+		return data, errors.Wrap(err, "invalid entity relations")
+	}
 
-	fmt.Printf("Generating entity: %s\n", data.EntityNameCamel)
-	fmt.Printf("   Domain: %s\n", opts.Domain)
-	fmt.Printf("   Features: %s\n", genshared.FeaturesListString(features))
+	return data, nil
+}
+
+// entityInputs returns the generator inputs recorded for opts, keyed by flag name.
+func entityInputs(opts EntityOptions) map[string]string {
+	inputs := map[string]string{
+		"domain":      opts.Domain,
+		"name":        opts.EntityName,
+		"features":    opts.Features,
+		"fields":      opts.Fields,
+		"fields-file": opts.FieldsFile,
+		"pagination":  opts.Pagination,
+		"no-tests":    noTestsInput(opts.NoTests),
+	}
+	for _, kind := range genshared.RelationKinds {
+		inputs[kind] = opts.Relations[kind]
+	}
+
+	return inputs
+}
+
+func printEntitySummary(data genshared.TemplateData) {
+	fmt.Printf("   Domain: %s\n", data.DomainName)
+	fmt.Printf("   Features: %s\n", genshared.FeaturesListString(data.Features))
+	if len(data.Fields) > 0 {
+		fmt.Printf("   Fields: %d\n", len(data.Fields))
+	}
+	for _, relation := range data.Relations {
+		fmt.Printf("   Relation: %s %s\n", relation.Kind, relation.Entity)
+	}
 	fmt.Printf("   Module: %s\n\n", data.ModuleName)
+}
+
+// writeEntity writes the entity and its migration, registering the migration after the
+// ones it depends on, and with withModels its request/response models and repository,
+// tested with withTests. It sets the migration timestamp of data to the one of an
+// earlier run.
+func writeEntity(gen *initshared.Generation, data *genshared.TemplateData, cfg genshared.GeneratorConfig, recorded map[string]map[string]string, withModels, withTests bool) error {
+	domain, entity := data.DomainName, data.EntityName
+	dataLayerDir := cfg.Path(cfg.DomainDir, domain, domain+"_data_layer")
+
+	entityPath := filepath.Join(dataLayerDir, domain+"_entities", entity+".go")
+	if _, err := gen.WriteTemplate(Templates, "entities.go.tmpl", entityPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate entity file")
+	}
+
+	// Generate migration file, keeping the timestamp of an earlier run
+	migrationsDir := filepath.Join(dataLayerDir, domain+"_migrations")
+	if timestamp, ok := gen.PreviousPrefix(migrationsDir, "_create_"+entity+"_table.go"); ok {
+		data.MigrationTimestamp = timestamp
+	}
+	migrationPath := filepath.Join(migrationsDir, data.MigrationTimestamp+"_create_"+entity+"_table.go")
+
+	if _, err := gen.WriteTemplate(Templates, "entity_migration.go.tmpl", migrationPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate migration file")
+	}
+	dependents, err := migrationDependents(recorded, domain, entity)
+	if err != nil {
+		return err
+	}
+	if err := wireEntityMigration(*data, migrationsDir, dependents); err != nil {
+		return err
+	}
+
+	if !withModels {
+		return nil
+	}
+
+	modelsPath := cfg.Path(cfg.ModelsDir, domain, entity+"_models.go")
+	if _, err := gen.WriteTemplate(Templates, "models.go.tmpl", modelsPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate models file")
+	}
+
+	repositoryPath := filepath.Join(dataLayerDir, domain+"_repositories", entity+"_repository.go")
+	if _, err := gen.WriteTemplate(Templates, "repositories.go.tmpl", repositoryPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate repository file")
+	}
+
+	if !withTests {
+		return nil
+	}
+
+	repositoryTestPath := filepath.Join(dataLayerDir, domain+"_repositories", entity+"_repository_test.go")
+	if _, err := gen.WriteTemplate(Templates, "repository_test.go.tmpl", repositoryTestPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate repository test file")
+	}
 
-	// Generate entity file
-	entityPath := filepath.Join(cfg.DomainDir, opts.Domain, opts.Domain+"_data_layer", opts.Domain+"_entities", opts.EntityName+".go")
+	return writeTestDB(gen, cfg)
+}
 
-	if !opts.Force && initshared.FileExists(entityPath) {
-		fmt.Printf("   WARNING: Entity file already exists: %s (use --force to overwrite)\n", entityPath)
-	} else {
-		fmt.Printf("   Generating %s\n", entityPath)
-		content, err := initshared.RenderTemplate(Templates, "templates/entities.go.tmpl", data)
+// migrationDependents returns the recorded entities of domain whose migrations reference
+// the table of entity, which must therefore be created first.
+func migrationDependents(recorded map[string]map[string]string, domain, entity string) ([]string, error) {
+	var dependents []string
+	for name, inputs := range recorded {
+		relations, err := genshared.RelationsFromInputs(inputs)
 		if err != nil {
-			return errors.Wrap(err, "failed to render entity template")
+			return nil, errors.Wrap(err, "invalid relations recorded for entity %s", name)
 		}
-		if err := initshared.WriteFile(entityPath, content, opts.Force); err != nil {
-			return errors.Wrap(err, "failed to write entity file")
+
+		for _, relation := range relations {
+			if relation.Kind != genshared.HasMany && name != entity && relation.Entity == entity &&
+				(relation.Domain == "" || relation.Domain == domain) {
+				dependents = append(dependents, name)
+				break
+			}
 		}
 	}
+	sort.Strings(dependents)
 
-	// Generate migration file
-	migrationPath := filepath.Join(cfg.DomainDir, opts.Domain, opts.Domain+"_data_layer", opts.Domain+"_migrations",
-		data.MigrationTimestamp+"_create_"+opts.EntityName+"_table.go")
+	return dependents, nil
+}
 
-	if !opts.Force && initshared.FileExists(migrationPath) {
-		fmt.Printf("   WARNING: Migration file already exists: %s (use --force to overwrite)\n", migrationPath)
-	} else {
-		fmt.Printf("   Generating %s\n", migrationPath)
-		content, err := initshared.RenderTemplate(Templates, "templates/entity_migration.go.tmpl", data)
-		if err != nil {
-			return errors.Wrap(err, "failed to render migration template")
+// printMissingRelations lists the entities the relations need that do not exist yet.
+func printMissingRelations(data genshared.TemplateData, recorded map[string]map[string]string, cfg genshared.GeneratorConfig) {
+	var missing []string
+	for _, relation := range data.Associations() {
+		if relation.Self() {
+			continue
 		}
-		if err := initshared.WriteFile(migrationPath, content, opts.Force); err != nil {
-			return errors.Wrap(err, "failed to write migration file")
+
+		if relation.Kind == genshared.HasMany {
+			if !recordedBelongsTo(recorded[relation.Entity], data.EntityName) {
+				missing = append(missing, fmt.Sprintf("pixie generate entity --domain %s --name %s --belongs-to %s",
+					data.DomainName, relation.Entity, data.EntityName))
+			}
+			continue
+		}
+
+		entityPath := cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_entities", relation.Entity+".go")
+		if _, err := os.Stat(entityPath); os.IsNotExist(err) {
+			missing = append(missing, fmt.Sprintf("pixie generate entity --domain %s --name %s", data.DomainName, relation.Entity))
 		}
 	}
 
-	fmt.Printf("Successfully generated entity: %s\n\n", data.EntityNameCamel)
-	printEntityNextSteps(data, opts, cfg)
+	if len(missing) == 0 {
+		return
+	}
+	fmt.Printf("The relations reference entities that are not generated yet:\n")
+	for _, command := range missing {
+		fmt.Printf("   %s\n", command)
+	}
+	fmt.Println()
+}
 
-	return nil
+func recordedBelongsTo(inputs map[string]string, entity string) bool {
+	relations, err := genshared.ParseRelations(genshared.BelongsTo, inputs[genshared.BelongsTo])
+	if err != nil {
+		return false
+	}
+
+	for _, relation := range relations {
+		if relation.Domain == "" && relation.Entity == entity {
+			return true
+		}
+	}
+	return false
 }
 
 func printEntityNextSteps(data genshared.TemplateData, opts EntityOptions, cfg genshared.GeneratorConfig) {
@@ -165,17 +412,22 @@ func printEntityNextSteps(data genshared.TemplateData, opts EntityOptions, cfg g
 	fmt.Printf("   ./%s/%s/%s_data_layer/%s_migrations/%s_create_%s_table.go\n\n",
 		cfg.DomainDir, opts.Domain, opts.Domain, opts.Domain, data.MigrationTimestamp, opts.EntityName)
 
-	fmt.Printf("3. Update the domain migrations file to include your new migration:\n")
-	fmt.Printf("   ./%s/%s/%s_data_layer/%s_migrations/migrations.go\n\n",
-		cfg.DomainDir, opts.Domain, opts.Domain, opts.Domain)
-
-	fmt.Printf("4. Consider creating a repository for this entity:\n")
-	fmt.Printf("   pixie generate repository --domain %s --name %s --entity %s\n\n",
-		opts.Domain, opts.EntityName, opts.EntityName)
+	step := 3
+	if len(data.Fields) == 0 {
+		fmt.Printf("3. Consider creating a repository for this entity:\n")
+		fmt.Printf("   pixie generate repository --domain %s --name %s --entity %s\n\n",
+			opts.Domain, opts.EntityName, opts.EntityName)
+		step++
+	}
 
-	fmt.Printf("5. Test the entity and migration:\n")
+	fmt.Printf("%d. Test the entity and migration:\n", step)
 	fmt.Printf("   go build ./%s/%s/%s_data_layer/%s_entities/\n",
 		cfg.DomainDir, opts.Domain, opts.Domain, opts.Domain)
-	fmt.Printf("   go build ./%s/%s/%s_data_layer/%s_migrations/\n\n",
+	fmt.Printf("   go build ./%s/%s/%s_data_layer/%s_migrations/\n",
 		cfg.DomainDir, opts.Domain, opts.Domain, opts.Domain)
+	if (len(data.Fields) > 0 || len(data.Relations) > 0) && !opts.NoTests {
+		fmt.Printf("   go test ./%s/%s/%s_data_layer/%s_repositories/\n",
+			cfg.DomainDir, opts.Domain, opts.Domain, opts.Domain)
+	}
+	fmt.Println()
 }
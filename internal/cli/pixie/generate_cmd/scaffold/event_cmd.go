@@ -0,0 +1,303 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/wiring"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// EventOptions holds the options for domain event generation.
+type EventOptions struct {
+	Domain       string
+	EventName    string
+	Description  string
+	Fields       string
+	FieldsFile   string
+	Microservice string
+	ModuleName   string
+	Force        bool
+}
+
+// EventCmd returns the cobra command for domain event generation.
+func EventCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "event",
+		Short: "Generate a domain event, its publisher and an optional consumer",
+		Long: `Generate a domain event of an existing domain.
+
+This command creates:
+- The typed event payload and its New<Event>Event constructor in the event package
+- The registration of the event in the domain's message pack, passed on by
+  message_packs.Register
+- A Publish<Event> helper on the domain business layer, emitting the event
+- With --ms, a handler stub in that microservice, dispatched to by HandleEvent for
+  incoming messages of the event type
+
+Fields take the same specs as generate entity --fields; unique, index and default
+do not apply to events. A description=D modifier sets the field_description of a field.
+
+Examples:
+  # An order_placed event published by the orders domain
+  pixie generate event --domain orders --name order_placed \
+    --fields "order_id:uid:required,total:decimal:required,placed_at:time"
+
+  # Consumed by the notifications microservice
+  pixie generate event --domain orders --name order_placed --fields "order_id:uid" --ms notifications
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var domain, _ = cmd.Flags().GetString("domain")
+			var name, _ = cmd.Flags().GetString("name")
+			var description, _ = cmd.Flags().GetString("description")
+			var fields, _ = cmd.Flags().GetString("fields")
+			var fieldsFile, _ = cmd.Flags().GetString("fields-file")
+			var microservice, _ = cmd.Flags().GetString("ms")
+			var moduleName, _ = cmd.Flags().GetString("module-name")
+			var force, _ = cmd.Flags().GetBool("force")
+
+			return generateEvent(EventOptions{
+				Domain:       domain,
+				EventName:    name,
+				Description:  description,
+				Fields:       fields,
+				FieldsFile:   fieldsFile,
+				Microservice: microservice,
+				ModuleName:   moduleName,
+				Force:        force,
+			})
+		},
+	}
+
+	// Required flags
+	cmd.Flags().String("domain", "", "Existing domain publishing the event (required)")
+	cmd.Flags().String("name", "", "Event name in snake_case, such as order_placed (required)")
+
+	// Optional flags
+	cmd.Flags().String("description", "", "Description of the event, listed with the registered events (default: derived from the name)")
+	cmd.Flags().String("fields", "", "Comma-separated payload field specs, e.g. order_id:uid:required,total:decimal")
+	cmd.Flags().String("fields-file", "", "YAML file listing the payload fields")
+	cmd.Flags().String("ms", "", "Microservice consuming the event: generates its handler stub")
+	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+
+	// Mark required flags
+	if err := cmd.MarkFlagRequired("domain"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'domain' flag as required: %v", err))
+	}
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'name' flag as required: %v", err))
+	}
+
+	return cmd
+}
+
+func generateEvent(opts EventOptions) error {
+	name := strings.TrimSuffix(opts.EventName, "_event")
+	if !genshared.IsValidSnakeCase(name) {
+		return errors.New("event name %q must be in snake_case, such as order_placed", opts.EventName)
+	}
+	if strings.ContainsAny(opts.Description, "\"`") {
+		return errors.New("the event description cannot contain quotes or backticks")
+	}
+
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+	moduleName, err := genshared.ResolveModule(opts.ModuleName)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect module name")
+	}
+
+	fields, err := genshared.ResolvePayloadFields(opts.Fields, opts.FieldsFile, cfg.Root)
+	if err != nil {
+		return errors.Wrap(err, "invalid event fields")
+	}
+	for _, field := range fields {
+		if field.Name == "id" {
+			return errors.New("field id clashes with the event ID; name it after what it identifies, such as %s_id", name)
+		}
+	}
+
+	data := genshared.NewTemplateData()
+	data.DomainName = opts.Domain
+	data.DomainNameCamel = initshared.ToCamelCase(opts.Domain)
+	data.ModuleName = moduleName
+	data.ServiceName = strings.TrimPrefix(opts.Microservice, cfg.MicroservicePrefix)
+	data.EventName = name
+	data.EventNameCamel = initshared.ToCamelCase(name)
+	data.EventDescription = opts.Description
+	if data.EventDescription == "" {
+		data.EventDescription = fmt.Sprintf("%s %s", initshared.ToCamelCase(opts.Domain), strings.ReplaceAll(name, "_", " "))
+	}
+	data.Fields = fields
+	data.ApplyLayout(cfg)
+
+	fmt.Printf("Generating event: %sEvent\n", data.EventNameCamel)
+	fmt.Printf("   Domain: %s\n", data.DomainName)
+	if len(fields) > 0 {
+		fmt.Printf("   Fields: %d\n", len(fields))
+	}
+	if opts.Microservice != "" {
+		fmt.Printf("   Consumer: %s\n", data.MicroservicePackage)
+	}
+	fmt.Printf("   Module: %s\n\n", data.ModuleName)
+
+	gen, err := initshared.NewGeneration(cfg.Root, "generate event", map[string]string{
+		"domain":      opts.Domain,
+		"name":        opts.EventName,
+		"description": opts.Description,
+		"fields":      opts.Fields,
+		"fields-file": opts.FieldsFile,
+		"ms":          opts.Microservice,
+	}, opts.Force)
+	if err != nil {
+		return err
+	}
+
+	eventPath := cfg.Path(cfg.InfraDir, "event", name+"_event.go")
+	if _, err := gen.WriteTemplate(Templates, "event.go.tmpl", eventPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate event file")
+	}
+
+	packPath := cfg.Path(cfg.InfraDir, "message_packs", opts.Domain+"_message_pack.go")
+	if _, err := gen.WriteTemplate(Templates, "message_pack.go.tmpl", packPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate message pack file")
+	}
+	pack := data.DomainNameCamel + "MessagePack"
+	eventType := "event." + data.EventNameCamel + "EventType"
+	if err := wire(packPath, func() (bool, error) {
+		return wiring.AppendToSlice(packPath, pack, eventType)
+	}, []string{fmt.Sprintf("Append %s to the %s slice", eventType, pack)}); err != nil {
+		return err
+	}
+
+	registerPath := cfg.Path(cfg.InfraDir, "message_packs", "message_packs.go")
+	if err := wireDecls(registerPath, messagePackImports, messagePackRegistration); err != nil {
+		return err
+	}
+	registration := fmt.Sprintf("registerMessagePack(ctx, %s)", pack)
+	if err := wire(registerPath, func() (bool, error) {
+		return wiring.AddStatements(registerPath, "Register", nil, []string{registration})
+	}, []string{"Add to Register:", registration}); err != nil {
+		return err
+	}
+
+	payloadImports := map[string]string{
+		"context":                             "context",
+		"encoding/json":                       "json",
+		"time":                                "time",
+		"github.com/pixie-sh/core-go/pkg/uid": "uid",
+		"github.com/pixie-sh/errors-go":       "errors",
+		data.EventImport:                      "event",
+	}
+
+	businessLayerPath := cfg.Path(cfg.DomainDir, opts.Domain, data.BusinessLayerPackage, data.BusinessLayerPackage+".go")
+	if err := wireDecls(businessLayerPath, payloadImports, eventPublisher(data)); err != nil {
+		return err
+	}
+
+	var handlersPath string
+	if opts.Microservice != "" {
+		handlersPath = cfg.Path(cfg.MicroserviceDir, data.MicroservicePackage, "event_handlers.go")
+		if _, err := gen.WriteTemplate(Templates, "event_handlers.go.tmpl", handlersPath, data); err != nil {
+			return errors.Wrap(err, "failed to generate event handlers file")
+		}
+		if err := wireDecls(handlersPath, payloadImports, eventHandler(data)); err != nil {
+			return err
+		}
+
+		subscription := fmt.Sprintf("eventSubscription{eventType: event.%sEventType, handle: handleWith(handle%sEvent)}", data.EventNameCamel, data.EventNameCamel)
+		if err := wire(handlersPath, func() (bool, error) {
+			return wiring.AppendToSlice(handlersPath, "eventSubscriptions", subscription)
+		}, []string{"Append to the eventSubscriptions slice:", subscription}); err != nil {
+			return err
+		}
+	}
+
+	if err := gen.Finish(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSuccessfully generated event: %sEvent\n\n", data.EventNameCamel)
+	printEventNextSteps(data, businessLayerPath, handlersPath)
+
+	return nil
+}
+
+// messagePackImports are the imports of messagePackRegistration.
+var messagePackImports = map[string]string{
+	"context": "context",
+	"github.com/pixie-sh/core-go/infra/message_factory": "message_factory",
+	"github.com/pixie-sh/core-go/pkg/context":           "pixiecontext",
+	"github.com/pixie-sh/core-go/pkg/types":             "types",
+}
+
+// messagePackRegistration is added to the message packs to check, as Register passes
+// them on, that the message factory knows every payload type they list.
+const messagePackRegistration = `// registerMessagePack checks that the message factory knows the payload types of pack,
+// registered by their types.PayloadTypeOf declarations, and logs those it does not.
+func registerMessagePack(ctx context.Context, pack []types.PayloadType) {
+	registered := message_factory.Singleton.GetRegisteredEvents()
+	for _, payloadType := range pack {
+		if _, ok := registered[payloadType]; !ok {
+			pixiecontext.GetCtxLogger(ctx).
+				With("payloadType", payloadType).
+				Log("event %s is not registered with the message factory", payloadType)
+		}
+	}
+}
+`
+
+// eventPublisher returns the source of the business layer method emitting the event.
+func eventPublisher(data genshared.TemplateData) string {
+	params := []string{"ctx context.Context", "id string"}
+	args := []string{"id"}
+	for _, field := range data.Fields {
+		params = append(params, field.VarName()+" "+field.PlainGoType())
+		args = append(args, field.VarName())
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Publish%s emits the %s event of the %s domain; id identifies the event.\n", data.EventNameCamel, data.EventNameCamel, data.DomainName)
+	fmt.Fprintf(&b, "func (l %sBusinessLayer) Publish%s(%s) error {\n", data.DomainNameCamel, data.EventNameCamel, strings.Join(params, ", "))
+	fmt.Fprintf(&b, "\tev := event.New%sEvent(%s)\n", data.EventNameCamel, strings.Join(args, ", "))
+	b.WriteString("\treturn ev.Emit(ctx)\n}\n")
+
+	return b.String()
+}
+
+// eventHandler returns the source of the stub handling the event in a microservice.
+func eventHandler(data genshared.TemplateData) string {
+	handler := "handle" + data.EventNameCamel + "Event"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s handles the %s events of the %s domain\n", handler, data.EventNameCamel, data.DomainName)
+	fmt.Fprintf(&b, "func %s(ctx context.Context, payload event.%sEvent) error {\n", handler, data.EventNameCamel)
+	fmt.Fprintf(&b, "\treturn errors.New(%q).WithErrorCode(errors.ServerErrorErrorCode)\n}\n", handler+" is not implemented")
+
+	return b.String()
+}
+
+func printEventNextSteps(data genshared.TemplateData, businessLayerPath, handlersPath string) {
+	fmt.Printf("Next steps:\n\n")
+
+	step := 1
+	fmt.Printf("%d. Publish the event from the %s business layer (%s):\n", step, data.DomainName, businessLayerPath)
+	args := []string{"ctx", "id"}
+	for _, field := range data.Fields {
+		args = append(args, field.VarName())
+	}
+	fmt.Printf("   err := l.Publish%s(%s)\n\n", data.EventNameCamel, strings.Join(args, ", "))
+	step++
+
+	if handlersPath != "" {
+		fmt.Printf("%d. Implement handle%sEvent in %s, and pass the\n", step, data.EventNameCamel, handlersPath)
+		fmt.Printf("   messages the microservice consumes to HandleEvent\n\n")
+	}
+}
@@ -0,0 +1,41 @@
+package scaffold
+
+import (
+	"go/format"
+	"testing"
+)
+
+func TestGenerateEvent_GofmtClean(t *testing.T) {
+	root := newProject(t)
+
+	err := generateMicroservice(MicroserviceOptions{
+		Name:        "orders",
+		Domain:      "sales",
+		Features:    "database,events",
+		Template:    "standard",
+		Port:        8080,
+		MetricsPort: 9090,
+	})
+	if err != nil {
+		t.Fatalf("generateMicroservice() error = %v", err)
+	}
+	for _, opts := range []EventOptions{
+		{Domain: "sales", EventName: "order_placed", Fields: "order_id:uid:required,total:decimal,note:string?", Microservice: "orders"},
+		{Domain: "sales", EventName: "order_shipped_to_customer", Fields: "order_id:uid:required,shipped_at:time", Microservice: "orders"},
+	} {
+		if err := generateEvent(opts); err != nil {
+			t.Fatalf("generateEvent(%s) error = %v", opts.EventName, err)
+		}
+	}
+
+	for path, content := range generatedGoFiles(t, root) {
+		formatted, err := format.Source(content)
+		if err != nil {
+			t.Errorf("%s does not parse: %v", path, err)
+			continue
+		}
+		if string(formatted) != string(content) {
+			t.Errorf("%s is not gofmt-formatted:\n%s", path, content)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/features"
+)
+
+// FeaturesCmd returns the cobra command listing the features of the registry.
+func FeaturesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "features [feature]",
+		Short: "List the features accepted by --features",
+		Long: `List the features accepted by the --features flag of the generators, with
+the features each one requires or conflicts with.
+
+Given a feature name, show its details: the templates it contributes and the
+config.json sections it adds.
+
+Examples:
+  # List every feature
+  pixie generate features
+
+  # Show what the auth feature generates
+  pixie generate features auth
+`,
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return features.Names(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if len(args) == 1 {
+				if err := features.Validate(args[0]); err != nil {
+					return err
+				}
+				feature, _ := features.Lookup(args[0])
+				explainFeature(cmd, feature)
+				return nil
+			}
+
+			writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, "FEATURE\tREQUIRES\tCONFLICTS\tDESCRIPTION")
+			for _, feature := range features.All() {
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", feature.Name, listOrDash(feature.Requires), listOrDash(feature.Conflicts), feature.Description)
+			}
+
+			return writer.Flush()
+		},
+	}
+}
+
+func explainFeature(cmd *cobra.Command, feature features.Feature) {
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintf(out, "%s\n", feature.Name)
+	fmt.Fprintf(out, "  %s\n\n", feature.Description)
+	fmt.Fprintf(out, "  Requires:   %s\n", listOrDash(feature.Requires))
+	fmt.Fprintf(out, "  Conflicts:  %s\n", listOrDash(feature.Conflicts))
+	fmt.Fprintf(out, "  Templates:  %s\n", listOrDash(feature.Templates))
+	fmt.Fprintf(out, "  Config:     %s\n", listOrDash(feature.Config))
+}
+
+func listOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,159 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/wiring"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// builtinGates are the names pixie init uses in the gates package and the
+// AuthorizationGatesBundle: its gates, their files and the bundle fields.
+var builtinGates = map[string]bool{
+	"is_authenticated":    true,
+	"has_permissions":     true,
+	"authorization_token": true,
+	"has_api_key":         true,
+	"permissions":         true,
+}
+
+// GateOptions holds the options for authorization gate generation.
+type GateOptions struct {
+	GateName    string
+	Description string
+	Force       bool
+}
+
+// GateCmd returns the cobra command for authorization gate generation.
+func GateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gate",
+		Short: "Generate a custom authorization gate",
+		Long: `Generate a custom authorization gate: a middleware routes can be gated on.
+
+This command creates:
+- The gate in the gates package, with its constructor and a Check middleware stub
+- Its field in AuthorizationGatesBundle, constructed by NewAuthorizationGatesBundle
+
+Routes then use it as c.gates.<Gate>.Check().
+
+Examples:
+  pixie generate gate --name has_active_subscription --description "checks that the user has an active subscription"
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name, _ = cmd.Flags().GetString("name")
+			var description, _ = cmd.Flags().GetString("description")
+			var force, _ = cmd.Flags().GetBool("force")
+
+			return generateGate(GateOptions{
+				GateName:    name,
+				Description: description,
+				Force:       force,
+			})
+		},
+	}
+
+	// Required flags
+	cmd.Flags().String("name", "", "Gate name in snake_case, such as has_active_subscription (required)")
+
+	// Optional flags
+	cmd.Flags().String("description", "", "What the gate checks, completing \"<Gate> gate ...\" in its doc comment")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+
+	// Mark required flags
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'name' flag as required: %v", err))
+	}
+
+	return cmd
+}
+
+func generateGate(opts GateOptions) error {
+	if !genshared.IsValidSnakeCase(opts.GateName) {
+		return errors.New("gate name %q must be in snake_case, such as has_active_subscription", opts.GateName)
+	}
+	if builtinGates[opts.GateName] {
+		return errors.New("gate %s is generated by pixie init; choose another name", opts.GateName)
+	}
+
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	data := genshared.NewTemplateData()
+	data.GateName = opts.GateName
+	data.GateNameCamel = initshared.ToCamelCase(opts.GateName)
+	data.GateDescription = strings.TrimSpace(opts.Description)
+	if data.GateDescription == "" {
+		data.GateDescription = fmt.Sprintf("for %s checks", strings.ReplaceAll(opts.GateName, "_", " "))
+	}
+
+	fmt.Printf("Generating gate: %s\n\n", data.GateNameCamel)
+
+	gen, err := initshared.NewGeneration(cfg.Root, "generate gate", map[string]string{
+		"name":        opts.GateName,
+		"description": opts.Description,
+	}, opts.Force)
+	if err != nil {
+		return err
+	}
+
+	gatePath := cfg.Path(cfg.InfraDir, "gates", opts.GateName+".go")
+	if _, err := gen.WriteTemplate(Templates, "gate.go.tmpl", gatePath, data); err != nil {
+		return errors.Wrap(err, "failed to generate gate file")
+	}
+
+	if err := wireGate(data, cfg.Path(cfg.BundlesDir, "authorization_gates_bundle.go")); err != nil {
+		return err
+	}
+
+	if err := gen.Finish(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSuccessfully generated gate: %s\n\n", data.GateNameCamel)
+	fmt.Printf("Next steps:\n\n")
+	fmt.Printf("1. Implement %s.Check in %s\n\n", data.GateNameCamel, gatePath)
+	fmt.Printf("2. Gate routes on it, after IsAuthenticated.Authenticated() when it needs the user:\n")
+	fmt.Printf("   c.gates.%s.Check()\n\n", data.GateNameCamel)
+
+	return nil
+}
+
+// wireGate adds the gate to the AuthorizationGatesBundle: its field, its construction
+// in NewAuthorizationGatesBundle and its assignment in the returned bundle.
+func wireGate(data genshared.TemplateData, bundlePath string) error {
+	gate := data.GateNameCamel
+	variable := initshared.Camel(data.GateName)
+
+	field := fmt.Sprintf("%s gates.%s", gate, gate)
+	if err := wire(bundlePath, func() (bool, error) {
+		return wiring.AddField(bundlePath, "AuthorizationGatesBundle", field)
+	}, []string{"Add to the AuthorizationGatesBundle struct:", field}); err != nil {
+		return err
+	}
+
+	stmts := []string{
+		fmt.Sprintf("%s, err := gates.New%s(ctx)", variable, gate),
+		fmt.Sprintf("if err != nil {\n\treturn AuthorizationGatesBundle{}, errors.Wrap(err, %q)\n}", "failed to create the "+gate+" gate"),
+	}
+	imports := map[string]string{
+		"github.com/pixie-sh/errors-go": "errors",
+	}
+	if err := wire(bundlePath, func() (bool, error) {
+		return wiring.AddStatements(bundlePath, "NewAuthorizationGatesBundle", imports, stmts)
+	}, append([]string{"Add to NewAuthorizationGatesBundle():"}, stmts...)); err != nil {
+		return err
+	}
+
+	element := fmt.Sprintf("%s: %s", gate, variable)
+	return wire(bundlePath, func() (bool, error) {
+		return wiring.AddToReturnedLiteral(bundlePath, "NewAuthorizationGatesBundle", "AuthorizationGatesBundle", element)
+	}, []string{"Set in the AuthorizationGatesBundle returned by NewAuthorizationGatesBundle:", element})
+}
@@ -3,11 +3,14 @@ package scaffold
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/pixie-sh/errors-go"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/features"
 	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
 	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/msconfig"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +25,7 @@ type MicroserviceOptions struct {
 	MetricsPort int
 	ModuleName  string
 	Force       bool
+	NoTests     bool
 }
 
 // MicroserviceCmd returns the cobra command for microservice generation.
@@ -33,6 +37,8 @@ func MicroserviceCmd() *cobra.Command {
 
 This command creates a complete microservice implementation following the established
 architecture patterns, including entry point, domain layers, and configuration.
+Unless --no-tests is set, the business layer, repository, service and HTTP controllers
+come with tests run against an in-memory sqlite database.
 
 Available features:
   - database: PostgreSQL integration with GORM
@@ -47,6 +53,8 @@ Available features:
   - adapters: External service adapters
   - apis: External API integration
 
+Run pixie generate features to list every feature with its dependencies.
+
 Examples:
   # Generate a basic microservice
   pixie generate microservice --name user_management --domain users
@@ -59,6 +67,9 @@ Examples:
 
   # Force overwrite existing files
   pixie generate microservice --name existing_service --domain existing --force
+
+  # Generate without the tests
+  pixie generate microservice --name order_service --domain orders --no-tests
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var name, _ = cmd.Flags().GetString("name")
@@ -70,6 +81,7 @@ Examples:
 			var metricsPort, _ = cmd.Flags().GetInt("metrics-port")
 			var moduleName, _ = cmd.Flags().GetString("module-name")
 			var force, _ = cmd.Flags().GetBool("force")
+			var noTests, _ = cmd.Flags().GetBool("no-tests")
 
 			opts := MicroserviceOptions{
 				Name:        name,
@@ -81,6 +93,7 @@ Examples:
 				MetricsPort: metricsPort,
 				ModuleName:  moduleName,
 				Force:       force,
+				NoTests:     noTests,
 			}
 
 			return generateMicroservice(opts)
@@ -98,7 +111,8 @@ Examples:
 	cmd.Flags().Int("port", 8080, "HTTP server port")
 	cmd.Flags().Int("metrics-port", 9090, "Metrics server port")
 	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
-	cmd.Flags().Bool("force", false, "Force overwrite existing files")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+	cmd.Flags().Bool("no-tests", false, noTestsUsage)
 
 	// Mark required flags
 	if err := cmd.MarkFlagRequired("name"); err != nil {
@@ -108,6 +122,11 @@ Examples:
 		panic(fmt.Sprintf("Failed to mark 'domain' flag as required: %v", err))
 	}
 
+	// Complete the feature names of --features
+	if err := cmd.RegisterFlagCompletionFunc("features", features.Complete); err != nil {
+		panic(fmt.Sprintf("Failed to register 'features' flag completion: %v", err))
+	}
+
 	return cmd
 }
 
@@ -117,27 +136,65 @@ func generateMicroservice(opts MicroserviceOptions) error {
 		return errors.Wrap(err, "validation failed")
 	}
 
+	cfg, data, err := microserviceData(opts)
+	if err != nil {
+		return err
+	}
+
+	// Print generation summary
+	fmt.Printf("Generating microservice: %s\n", data.ServiceNameCamel)
+	fmt.Printf("   Domain: %s\n", data.DomainName)
+	fmt.Printf("   Features: %s\n", strings.Join(genshared.EnabledFeatures(data.Features), ", "))
+	fmt.Printf("   Module: %s\n", data.ModuleName)
+	fmt.Printf("   Port: %d (metrics: %d)\n\n", data.Port, data.MetricsPort)
+
+	// Generate files
+	if err := generateMicroserviceFiles(data, opts, cfg); err != nil {
+		return errors.Wrap(err, "failed to generate files")
+	}
+
+	fmt.Printf("Successfully generated microservice: %s\n\n", data.ServiceNameCamel)
+	printMicroserviceNextSteps(data, cfg)
+
+	return nil
+}
+
+// microserviceData loads the generator config and builds the template data of the
+// microservice described by opts.
+func microserviceData(opts MicroserviceOptions) (genshared.GeneratorConfig, genshared.TemplateData, error) {
 	// Load config
 	cfg, err := genshared.LoadConfig()
 	if err != nil {
-		return errors.Wrap(err, "failed to load config")
+		return cfg, genshared.TemplateData{}, errors.Wrap(err, "failed to load config")
+	}
+
+	runtimeCfg, err := genshared.LoadRuntimeConfig()
+	if err != nil {
+		return cfg, genshared.TemplateData{}, errors.Wrap(err, "failed to load runtime config")
 	}
 
 	// Auto-detect module name if not provided
 	moduleName, err := genshared.ResolveModule(opts.ModuleName)
 	if err != nil {
-		return errors.Wrap(err, "failed to detect module name")
+		return cfg, genshared.TemplateData{}, errors.Wrap(err, "failed to detect module name")
 	}
 	if cfg.ModuleName == "" {
 		cfg.ModuleName = moduleName
 	}
 
 	// Parse features
-	features := genshared.ParseFeatures(opts.Features, opts.Template)
-	features = genshared.ResolveFeatureDependencies(features)
+	enabled, err := genshared.ParseFeatures(opts.Features, opts.Template)
+	if err != nil {
+		return cfg, genshared.TemplateData{}, err
+	}
+	enabled, err = genshared.ResolveFeatureDependencies(enabled)
+	if err != nil {
+		return cfg, genshared.TemplateData{}, err
+	}
 
 	// Create template data
 	data := genshared.NewTemplateData()
+	data.ApplyRuntime(runtimeCfg)
 	data.ServiceName = opts.Name
 	data.ServiceNameCamel = initshared.ToCamelCase(opts.Name)
 	data.DomainName = opts.Domain
@@ -147,26 +204,49 @@ func generateMicroservice(opts MicroserviceOptions) error {
 	data.RepositoryNameCamel = initshared.ToCamelCase(opts.Domain)
 	data.EntityName = opts.Domain
 	data.EntityNameCamel = initshared.ToCamelCase(opts.Domain)
-	data.Features = features
+	data.Features = enabled
 	data.Port = opts.Port
 	data.MetricsPort = opts.MetricsPort
+	data.ApplyLayout(cfg)
+	if rel, ok := projectRelative(cfg.Root, opts.Output); ok {
+		data.MicroserviceImport = genshared.ImportPath(moduleName, rel)
+	}
 
-	// Print generation summary
-	fmt.Printf("Generating microservice: %s\n", data.ServiceNameCamel)
-	fmt.Printf("   Domain: %s\n", data.DomainName)
-	fmt.Printf("   Features: %s\n", strings.Join(genshared.EnabledFeatures(features), ", "))
-	fmt.Printf("   Module: %s\n", data.ModuleName)
-	fmt.Printf("   Port: %d (metrics: %d)\n\n", data.Port, data.MetricsPort)
+	return cfg, data, nil
+}
 
-	// Generate files
-	if err := generateMicroserviceFiles(data, opts, cfg); err != nil {
-		return errors.Wrap(err, "failed to generate files")
+// microserviceInputs returns the flag values recorded in the manifest for the files of
+// the microservice, so that upgrade can replay the generator.
+func microserviceInputs(opts MicroserviceOptions) map[string]string {
+	return map[string]string{
+		"name":         opts.Name,
+		"domain":       opts.Domain,
+		"features":     opts.Features,
+		"template":     opts.Template,
+		"port":         strconv.Itoa(opts.Port),
+		"metrics-port": strconv.Itoa(opts.MetricsPort),
+		"output":       opts.Output,
+		"no-tests":     noTestsInput(opts.NoTests),
 	}
+}
 
-	fmt.Printf("Successfully generated microservice: %s\n\n", data.ServiceNameCamel)
-	printMicroserviceNextSteps(data, cfg)
+// projectRelative returns the --output directory relative to the project root, or false
+// when there is none or it lies outside the root.
+func projectRelative(root, output string) (string, bool) {
+	if output == "" || root == "" {
+		return "", false
+	}
 
-	return nil
+	abs, err := filepath.Abs(output)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return rel, true
 }
 
 func validateMicroserviceOptions(opts MicroserviceOptions) error {
@@ -202,7 +282,64 @@ func validateMicroserviceOptions(opts MicroserviceOptions) error {
 }
 
 func generateMicroserviceFiles(data genshared.TemplateData, opts MicroserviceOptions, cfg genshared.GeneratorConfig) error {
-	msDir := filepath.Join(cfg.MicroserviceDir, cfg.MicroservicePrefix+data.ServiceName)
+	gen, err := initshared.NewGeneration(cfg.Root, "generate microservice", microserviceInputs(opts), opts.Force)
+	if err != nil {
+		return err
+	}
+	data = reuseMigrationTimestamp(gen, data, cfg)
+
+	for _, mapping := range microserviceTemplates(data, opts, cfg) {
+		if !features.Includes(data.Features, Templates.Name+"/"+mapping.templateFile) {
+			fmt.Printf("   Skipping %s (feature not enabled)\n", mapping.templateFile)
+			continue
+		}
+
+		if _, err := gen.WriteTemplate(Templates, mapping.templateFile, mapping.outputPath, data); err != nil {
+			return errors.Wrap(err, "failed to generate %s", mapping.outputPath)
+		}
+	}
+	if err := msconfig.Write(gen, microserviceConfigPath(data, cfg), microserviceConfig(data)); err != nil {
+		return errors.Wrap(err, "failed to generate the config")
+	}
+	if err := wireDomainTokens(data, cfg); err != nil {
+		return err
+	}
+	if !opts.NoTests && data.Features["database"] {
+		printTestDependencies(cfg)
+	}
+
+	return gen.Finish()
+}
+
+// reuseMigrationTimestamp keeps the name of the entity migration the project already has.
+func reuseMigrationTimestamp(gen *initshared.Generation, data genshared.TemplateData, cfg genshared.GeneratorConfig) genshared.TemplateData {
+	migrationsDir := cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations")
+	if timestamp, ok := gen.PreviousPrefix(migrationsDir, "_create_"+data.DomainName+"_table.go"); ok {
+		data.MigrationTimestamp = timestamp
+	}
+
+	return data
+}
+
+// microserviceConfigPath returns the JSON config of the microservice.
+func microserviceConfigPath(data genshared.TemplateData, cfg genshared.GeneratorConfig) string {
+	return cfg.Path(cfg.ConfigsDir, cfg.MicroservicePrefix+data.ServiceName+".json")
+}
+
+// microserviceConfig returns the options of the microservice's JSON config.
+func microserviceConfig(data genshared.TemplateData) msconfig.Options {
+	return msconfig.Options{Domain: data.DomainName, Features: data.Features}
+}
+
+// microserviceTemplate maps a template of the microservice to the file it generates.
+type microserviceTemplate struct {
+	templateFile string
+	outputPath   string
+}
+
+// microserviceTemplates returns every template of the microservice, whatever its features.
+func microserviceTemplates(data genshared.TemplateData, opts MicroserviceOptions, cfg genshared.GeneratorConfig) []microserviceTemplate {
+	msDir := cfg.Path(cfg.MicroserviceDir, cfg.MicroservicePrefix+data.ServiceName)
 
 	getOutputPath := func(fileName string) string {
 		if opts.Output != "" {
@@ -211,144 +348,123 @@ func generateMicroserviceFiles(data genshared.TemplateData, opts MicroserviceOpt
 		return filepath.Join(msDir, fileName)
 	}
 
-	templateMappings := []struct {
-		templateFile string
-		outputPath   string
-		condition    func() bool
-	}{
+	businessLayerPath := cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+cfg.BusinessLayerSuffix, data.DomainName+cfg.BusinessLayerSuffix+".go")
+	repositoryPath := cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_repositories", data.DomainName+"_repository.go")
+	servicePath := cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_services", data.DomainName+"_service.go")
+
+	templates := []microserviceTemplate{
 		{
-			templateFile: "templates/cmd_application.go.tmpl",
-			outputPath:   filepath.Join(cfg.CmdDir, cfg.MicroservicePrefix+data.ServiceName, "application.go"),
+			templateFile: "cmd_application.go.tmpl",
+			outputPath:   cfg.Path(cfg.CmdDir, cfg.MicroservicePrefix+data.ServiceName, "application.go"),
 		},
 		{
-			templateFile: "templates/microservice.go.tmpl",
+			templateFile: "microservice.go.tmpl",
 			outputPath:   getOutputPath("microservice.go"),
 		},
 		{
-			templateFile: "templates/ms_registry.go.tmpl",
+			templateFile: "ms_registry.go.tmpl",
 			outputPath:   getOutputPath("registry.go"),
 		},
 		{
-			templateFile: "templates/http_controllers.go.tmpl",
+			templateFile: "http_controllers.go.tmpl",
 			outputPath:   getOutputPath("http_controllers.go"),
 		},
 		{
-			templateFile: "templates/http_bo_controllers.go.tmpl",
+			templateFile: "http_bo_controllers.go.tmpl",
 			outputPath:   getOutputPath("http_bo_controllers.go"),
-			condition:    func() bool { return data.Features["backoffice"] },
-		},
-		{
-			templateFile: "templates/business_layer.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+cfg.BusinessLayerSuffix, data.DomainName+cfg.BusinessLayerSuffix+".go"),
 		},
 		{
-			templateFile: "templates/data_layer.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_data_layer.go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "business_layer.go.tmpl",
+			outputPath:   businessLayerPath,
 		},
 		{
-			templateFile: "templates/services.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_services", data.DomainName+"_service.go"),
+			templateFile: "data_layer.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_data_layer.go"),
 		},
 		{
-			templateFile: "templates/domain_registry.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, "registry.go"),
+			templateFile: "services.go.tmpl",
+			outputPath:   servicePath,
 		},
 		{
-			templateFile: "templates/entities.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_entities", data.DomainName+".go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "domain_registry.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, "registry.go"),
 		},
 		{
-			templateFile: "templates/repositories.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_repositories", data.DomainName+"_repository.go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "entities.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_entities", data.DomainName+".go"),
 		},
 		{
-			templateFile: "templates/entity_migration.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations", data.MigrationTimestamp+"_create_"+data.DomainName+"_table.go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "repositories.go.tmpl",
+			outputPath:   repositoryPath,
 		},
 		{
-			templateFile: "templates/domain_migrations.go.tmpl",
-			outputPath:   filepath.Join(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations", "migrations.go"),
-			condition:    func() bool { return data.Features["database"] },
+			templateFile: "entity_migration.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations", data.MigrationTimestamp+"_create_"+data.DomainName+"_table.go"),
 		},
 		{
-			templateFile: "templates/models.go.tmpl",
-			outputPath:   filepath.Join(cfg.ModelsDir, data.DomainName, data.DomainName+"_models.go"),
+			templateFile: "domain_migrations.go.tmpl",
+			outputPath:   cfg.Path(cfg.DomainDir, data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations", "migrations.go"),
 		},
 		{
-			templateFile: "templates/config.json.tmpl",
-			outputPath:   filepath.Join(cfg.ConfigsDir, cfg.MicroservicePrefix+data.ServiceName+".json"),
+			templateFile: "models.go.tmpl",
+			outputPath:   cfg.Path(cfg.ModelsDir, data.DomainName, data.DomainName+"_models.go"),
 		},
 	}
-
-	for _, mapping := range templateMappings {
-		if mapping.condition != nil && !mapping.condition() {
-			fmt.Printf("   Skipping %s (feature not enabled)\n", mapping.templateFile)
-			continue
-		}
-
-		if !opts.Force && initshared.FileExists(mapping.outputPath) {
-			fmt.Printf("   WARNING: Skipping %s (file exists, use --force to overwrite)\n", mapping.outputPath)
-			continue
-		}
-
-		fmt.Printf("   Generating %s\n", mapping.outputPath)
-
-		content, err := initshared.RenderTemplate(Templates, mapping.templateFile, data)
-		if err != nil {
-			return errors.Wrap(err, "failed to render template %s", mapping.templateFile)
-		}
-
-		if err := initshared.WriteFile(mapping.outputPath, content, opts.Force); err != nil {
-			return errors.Wrap(err, "failed to write %s", mapping.outputPath)
-		}
+	if opts.NoTests {
+		return templates
 	}
 
-	return nil
+	return append(templates,
+		microserviceTemplate{templateFile: "business_layer_test.go.tmpl", outputPath: testPath(businessLayerPath)},
+		microserviceTemplate{templateFile: "repository_test.go.tmpl", outputPath: testPath(repositoryPath)},
+		microserviceTemplate{templateFile: "services_test.go.tmpl", outputPath: testPath(servicePath)},
+		microserviceTemplate{templateFile: "http_controllers_test.go.tmpl", outputPath: getOutputPath("http_controllers_test.go")},
+		microserviceTemplate{templateFile: "testdb.go.tmpl", outputPath: testDBPath(cfg)},
+	)
 }
 
 func printMicroserviceNextSteps(data genshared.TemplateData, cfg genshared.GeneratorConfig) {
 	fmt.Printf("Next steps:\n\n")
 
-	fmt.Printf("1. Update DI tokens in infra/di/injection_tokens.go:\n")
-	fmt.Printf("   RegistryToken%sDataLayer     = pdi.RegisterInjectionToken(\"%s_data_layer\")\n", data.DomainNameCamel, data.DomainName)
-	fmt.Printf("   RegistryToken%sBusinessLayer = pdi.RegisterInjectionToken(\"%s_business_layer\")\n", data.DomainNameCamel, data.DomainName)
-	fmt.Printf("   RegistryToken%sService       = pdi.RegisterInjectionToken(\"%s_service\")\n\n", data.DomainNameCamel, data.DomainName)
-
-	fmt.Printf("2. Update dependencies:\n")
+	fmt.Printf("1. Update dependencies:\n")
 	fmt.Printf("   go mod tidy\n\n")
 
 	if data.Features["database"] {
-		fmt.Printf("3. Run database migrations (if enabled):\n")
+		fmt.Printf("2. Run database migrations (if enabled):\n")
 		fmt.Printf("   export DATABASE_HOST=%s\n", data.DatabaseHost)
 		fmt.Printf("   export DATABASE_PORT=%d\n", data.DatabasePort)
 		fmt.Printf("   export DATABASE_NAME=%s\n", data.DatabaseName)
 		fmt.Printf("   export DATABASE_USERNAME=%s\n", data.DatabaseUsername)
-		fmt.Printf("   export DATABASE_PASSWORD=%s\n\n", data.DatabasePassword)
+		fmt.Printf("   export DATABASE_PASSWORD=%s\n\n", secretHint(data.DatabasePassword, "runtime.database.password"))
 	}
 
 	if data.Features["auth"] {
-		fmt.Printf("4. Set security environment variables:\n")
-		fmt.Printf("   export JWT_SECRET_KEY=your-secure-jwt-secret\n")
-		fmt.Printf("   export ADMIN_API_KEY=your-admin-api-key\n\n")
+		fmt.Printf("3. Set security environment variables:\n")
+		fmt.Printf("   export JWT_SECRET_KEY=%s\n", secretHint(data.JWTSecretKey, "runtime.security.jwt_secret_key"))
+		fmt.Printf("   export ADMIN_API_KEY=%s\n\n", secretHint(data.AdminAPIKey, "runtime.security.admin_api_key"))
 	}
 
 	if data.Features["cache"] {
-		fmt.Printf("5. Configure Redis (if cache enabled):\n")
+		fmt.Printf("4. Configure Redis (if cache enabled):\n")
 		fmt.Printf("   export REDIS_HOST=%s\n", data.RedisHost)
 		fmt.Printf("   export REDIS_PORT=%d\n\n", data.RedisPort)
 	}
 
 	msPrefix := cfg.MicroservicePrefix
-	fmt.Printf("6. Test the generated service:\n")
+	fmt.Printf("5. Test the generated service:\n")
 	fmt.Printf("   go build ./%s/%s%s/\n", cfg.CmdDir, msPrefix, data.ServiceName)
 	fmt.Printf("   go test ./%s/%s%s/...\n\n", cfg.MicroserviceDir, msPrefix, data.ServiceName)
 
-	fmt.Printf("7. Start the service:\n")
+	fmt.Printf("6. Start the service:\n")
 	fmt.Printf("   cd %s/%s%s && go run application.go --config=../../../%s/%s%s.json\n\n",
 		cfg.CmdDir, msPrefix, data.ServiceName,
 		cfg.ConfigsDir, msPrefix, data.ServiceName)
 }
+
+// secretHint describes a secret for the next-steps output without printing its value.
+func secretHint(value, key string) string {
+	if value == "" {
+		return fmt.Sprintf("<required, not set in %s>", key)
+	}
+	return fmt.Sprintf("<configured in %s>", key)
+}
@@ -0,0 +1,40 @@
+package scaffold
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateMicroservice_Tests(t *testing.T) {
+	root := newProject(t)
+
+	err := generateMicroservice(MicroserviceOptions{
+		Name:        "orders",
+		Domain:      "sales",
+		Features:    "database,auth",
+		Template:    "standard",
+		Port:        8080,
+		MetricsPort: 9090,
+	})
+	if err != nil {
+		t.Fatalf("generateMicroservice() error = %v", err)
+	}
+
+	for _, path := range []string{
+		"internal/domain/sales/sales_business_layer/sales_business_layer_test.go",
+		"internal/domain/sales/sales_data_layer/sales_repositories/sales_repository_test.go",
+		"internal/domain/sales/sales_services/sales_service_test.go",
+		"internal/ms/ms_orders/http_controllers_test.go",
+	} {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+		if err != nil {
+			t.Errorf("failed to read %s: %v", path, err)
+			continue
+		}
+		if formatted, err := format.Source(content); err != nil || string(formatted) != string(content) {
+			t.Errorf("%s is not gofmt-formatted Go (%v):\n%s", path, err, content)
+		}
+	}
+}
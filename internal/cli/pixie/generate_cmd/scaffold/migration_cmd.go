@@ -0,0 +1,217 @@
+package scaffold
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/wiring"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+// MigrationOptions holds all the options for migration generation.
+type MigrationOptions struct {
+	Domain     string
+	EntityName string
+}
+
+// migrationData is the template data of an alter table migration.
+type migrationData struct {
+	genshared.TemplateData
+	Previous genshared.Schema // snapshot of the latest migration of the table
+	Current  genshared.Schema // snapshot of the entity, declared by the new migration
+	Diff     genshared.SchemaDiff
+}
+
+// MigrationCmd returns the cobra command for schema change migration generation.
+func MigrationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migration",
+		Short: "Generate a migration for the changes made to an entity struct",
+		Long: `Generate a migration for the changes made to an entity struct.
+
+Every generated migration declares a snapshot of the entity struct it migrates to.
+This command compares the entity struct with the snapshot of the latest migration
+of its table and writes a migration with:
+- DropIndex/DropColumn steps for the removed indexes and columns
+- AddColumn/AlterColumn steps for the added columns and the columns whose type or
+  gorm settings changed
+- CreateIndex steps for the added indexes
+- A Rollback undoing those steps against the previous snapshot
+- A new snapshot of the entity, for the next migration to compare with
+
+The migration is appended to the domain's Migrations slice. Columns are matched by
+name, so a renamed field is dropped and added; edit the migration to use
+RenameColumn instead. Migrations are not recorded in the manifest: once applied,
+pixie upgrade leaves them alone.
+
+Examples:
+  # Add a migration for the changes to the Invoice entity
+  pixie generate migration --domain orders --entity invoice
+
+  # Preview the migration without writing it
+  pixie generate migration --domain orders --entity invoice --diff
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var domain, _ = cmd.Flags().GetString("domain")
+			var entityName, _ = cmd.Flags().GetString("entity")
+
+			opts := MigrationOptions{
+				Domain:     domain,
+				EntityName: entityName,
+			}
+
+			return generateMigration(opts)
+		},
+	}
+
+	// Required flags
+	cmd.Flags().String("domain", "", "Existing domain name (required)")
+	cmd.Flags().String("entity", "", "Entity whose changes to migrate (required)")
+
+	// Mark required flags
+	if err := cmd.MarkFlagRequired("domain"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'domain' flag as required: %v", err))
+	}
+	if err := cmd.MarkFlagRequired("entity"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'entity' flag as required: %v", err))
+	}
+
+	return cmd
+}
+
+func generateMigration(opts MigrationOptions) error {
+	if !genshared.IsValidIdentifier(opts.Domain) {
+		return errors.New("domain name must be a valid identifier (e.g., orders)")
+	}
+	if !genshared.IsValidIdentifier(opts.EntityName) && !genshared.IsValidSnakeCase(opts.EntityName) {
+		return errors.New("entity name must be a valid identifier (e.g., invoice or line_item)")
+	}
+
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	data := migrationData{TemplateData: genshared.NewTemplateData()}
+	data.DomainName = opts.Domain
+	data.DomainNameCamel = initshared.ToCamelCase(opts.Domain)
+	data.EntityName = opts.EntityName
+	data.EntityNameCamel = initshared.ToCamelCase(opts.EntityName)
+	data.ApplyLayout(cfg)
+
+	dataLayerDir := cfg.Path(cfg.DomainDir, opts.Domain, opts.Domain+"_data_layer")
+	entityPath := filepath.Join(dataLayerDir, opts.Domain+"_entities", opts.EntityName+".go")
+	migrationsDir := filepath.Join(dataLayerDir, opts.Domain+"_migrations")
+
+	current, err := genshared.ReadEntitySchema(entityPath, data.EntityNameCamel)
+	if err != nil {
+		return errors.Wrap(err, "failed to read entity %s", data.EntityNameCamel)
+	}
+	if current.Table == "" {
+		current.Table = opts.Domain + "_" + opts.EntityName
+	}
+
+	previous, ok, err := genshared.LatestSnapshot(migrationsDir, current.Table)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("no migration in %s declares a snapshot of table %s; generate the entity first with:\n  pixie generate entity --domain %s --name %s",
+			migrationsDir, current.Table, opts.Domain, opts.EntityName)
+	}
+
+	// The new snapshot keeps the embedded types of the previous one, such as the
+	// soft delete columns entities leave to their migrations
+	current.Type = opts.EntityName + data.MigrationTimestamp
+	current.Embedded = previous.Embedded
+	for _, embedded := range previous.Embedded {
+		if pkg, _, ok := strings.Cut(strings.TrimPrefix(embedded, "*"), "."); ok {
+			current.Imports[pkg] = previous.Imports[pkg]
+		}
+	}
+
+	data.Previous, data.Current = previous, current
+	data.Diff = genshared.DiffSchemas(previous, current)
+
+	fmt.Printf("Generating migration: %s\n", data.EntityNameCamel)
+	fmt.Printf("   Domain: %s\n", opts.Domain)
+	fmt.Printf("   Table: %s\n", current.Table)
+	fmt.Printf("   Compared with: %s (%s)\n\n", previous.Type, filepath.Base(previous.File))
+
+	if data.Diff.Empty() {
+		fmt.Printf("No schema changes: %s matches the snapshot of its latest migration\n", data.EntityNameCamel)
+		return nil
+	}
+	printSchemaDiff(data.Diff)
+
+	content, _, err := Templates.Render(cfg.Root, "entity_alter_migration.go.tmpl", data)
+	if err != nil {
+		return err
+	}
+
+	migrationPath := filepath.Join(migrationsDir, data.MigrationTimestamp+"_alter_"+opts.EntityName+"_table.go")
+	if err := initshared.WriteFile(migrationPath, content, false); err != nil {
+		return errors.Wrap(err, "failed to generate migration file")
+	}
+	fmt.Printf("   Generating %s\n", migrationPath)
+
+	migration := fmt.Sprintf("&Alter%sTable%s", data.EntityNameCamel, data.MigrationTimestamp)
+	path := filepath.Join(migrationsDir, "migrations.go")
+	err = wire(path, func() (bool, error) {
+		return wiring.AppendToSlice(path, "Migrations", migration)
+	}, []string{fmt.Sprintf("Append %s to the Migrations slice", migration)})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSuccessfully generated migration: Alter%sTable%s\n\n", data.EntityNameCamel, data.MigrationTimestamp)
+	printMigrationNextSteps(migrationPath, data.Diff)
+
+	return nil
+}
+
+func printSchemaDiff(diff genshared.SchemaDiff) {
+	for _, column := range diff.Added {
+		fmt.Printf("   + column %s %s\n", column.Name, column.Type)
+	}
+	for _, column := range diff.Removed {
+		fmt.Printf("   - column %s %s\n", column.Name, column.Type)
+	}
+	for _, change := range diff.Altered {
+		fmt.Printf("   ~ column %s %s -> %s %s\n", change.To.Name, change.From.Type, change.To.Type, change.To.Definition())
+	}
+	for _, index := range diff.DroppedIndexes {
+		fmt.Printf("   - %s\n", indexSummary(index))
+	}
+	for _, index := range diff.CreatedIndexes {
+		fmt.Printf("   + %s\n", indexSummary(index))
+	}
+	fmt.Println()
+}
+
+func indexSummary(index genshared.Index) string {
+	kind := "index"
+	if index.Unique {
+		kind = "unique index"
+	}
+	return fmt.Sprintf("%s %s (%s)", kind, index.Name, strings.Join(index.Columns, ", "))
+}
+
+func printMigrationNextSteps(migrationPath string, diff genshared.SchemaDiff) {
+	fmt.Printf("Next steps:\n\n")
+
+	fmt.Printf("1. Review the generated migration:\n")
+	fmt.Printf("   %s\n", migrationPath)
+	if len(diff.Added) > 0 && len(diff.Removed) > 0 {
+		fmt.Printf("   Columns were both added and removed; if a field was renamed, replace the\n")
+		fmt.Printf("   DropColumn and AddColumn steps with migrator.RenameColumn to keep its data.\n")
+	}
+	fmt.Println()
+
+	fmt.Printf("2. Test the migration:\n")
+	fmt.Printf("   go build ./%s\n\n", filepath.ToSlash(filepath.Dir(migrationPath)))
+}
@@ -0,0 +1,205 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/wiring"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// permissionName matches permission names: dot-separated snake_case segments.
+var permissionName = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// PermissionOptions holds the options for permission generation.
+type PermissionOptions struct {
+	Name        string
+	Description string
+	Force       bool
+}
+
+// PermissionCmd returns the cobra command adding a permission to the catalogue.
+func PermissionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "permission",
+		Short: "Add a permission to the typed permission catalogue",
+		Long: `Add a permission to the typed permission catalogue of the project.
+
+The catalogue lives next to the authorization gates and declares:
+- A Permission<Name> constant holding the feature string the gates check
+- An entry with its description in the Permissions slice
+
+Routes then gate on the constant instead of a free-form string, such as
+c.gates.IsAuthenticated.AllFeaturesOf(gates.PermissionOrdersCancel), and
+generate endpoint --permission uses the constant of a catalogued permission.
+
+Examples:
+  pixie generate permission --name orders.cancel --description "Cancel any order"
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name, _ = cmd.Flags().GetString("name")
+			var description, _ = cmd.Flags().GetString("description")
+			var force, _ = cmd.Flags().GetBool("force")
+
+			return generatePermission(PermissionOptions{
+				Name:        name,
+				Description: description,
+				Force:       force,
+			})
+		},
+	}
+
+	// Required flags
+	cmd.Flags().String("name", "", "Permission name, dot-separated snake_case such as orders.cancel (required)")
+
+	// Optional flags
+	cmd.Flags().String("description", "", "Description of the permission (default: derived from the name)")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+
+	// Mark required flags
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'name' flag as required: %v", err))
+	}
+
+	return cmd
+}
+
+func generatePermission(opts PermissionOptions) error {
+	if !permissionName.MatchString(opts.Name) {
+		return errors.New("permission name %q must be dot-separated snake_case, such as orders.cancel", opts.Name)
+	}
+
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	constant := permissionConstant(opts.Name)
+	description := opts.Description
+	if description == "" {
+		words := strings.ReplaceAll(strings.ReplaceAll(opts.Name, ".", " "), "_", " ")
+		description = strings.ToUpper(words[:1]) + words[1:]
+	}
+
+	fmt.Printf("Adding permission: %s\n", opts.Name)
+	fmt.Printf("   Constant: gates.%s\n\n", constant)
+
+	gen, err := initshared.NewGeneration(cfg.Root, "generate permission", map[string]string{
+		"name":        opts.Name,
+		"description": opts.Description,
+	}, opts.Force)
+	if err != nil {
+		return err
+	}
+
+	path := permissionsPath(cfg)
+	if _, err := gen.WriteTemplate(Templates, "permissions.go.tmpl", path, genshared.NewTemplateData()); err != nil {
+		return errors.Wrap(err, "failed to generate permission catalogue")
+	}
+
+	catalogued, err := permissionConstants(path)
+	if err != nil {
+		return err
+	}
+	for value, existing := range catalogued {
+		if existing == constant && value != opts.Name {
+			return errors.New("%s already declares permission %q", constant, value)
+		}
+	}
+
+	if _, ok := catalogued[opts.Name]; ok {
+		fmt.Printf("   Unchanged %s (already catalogued)\n", path)
+	} else {
+		decl := fmt.Sprintf("// %s is the %s permission: %s\nconst %s = %q\n", constant, opts.Name, description, constant, opts.Name)
+		if err := wireDecls(path, nil, decl); err != nil {
+			return err
+		}
+
+		entry := fmt.Sprintf("Permission{Name: %s, Description: %s}", constant, strconv.Quote(description))
+		if err := wire(path, func() (bool, error) {
+			return wiring.AppendToSlice(path, "Permissions", entry)
+		}, []string{"Append to the Permissions slice:", entry}); err != nil {
+			return err
+		}
+	}
+
+	if err := gen.Finish(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSuccessfully added permission: %s\n\n", opts.Name)
+	fmt.Printf("Next steps:\n\n")
+	fmt.Printf("1. Gate routes on the permission:\n")
+	fmt.Printf("   c.gates.IsAuthenticated.AllFeaturesOf(gates.%s)\n\n", constant)
+	fmt.Printf("2. Grant %q to the users or roles holding it\n\n", opts.Name)
+
+	return nil
+}
+
+// permissionsPath returns the file holding the project's permission catalogue.
+func permissionsPath(cfg genshared.GeneratorConfig) string {
+	return cfg.Path(cfg.InfraDir, "gates", "permissions.go")
+}
+
+// permissionConstant returns the name of the catalogue constant of permission:
+// PermissionOrdersCancel for orders.cancel.
+func permissionConstant(permission string) string {
+	var b strings.Builder
+	b.WriteString("Permission")
+	for _, segment := range strings.Split(permission, ".") {
+		b.WriteString(genshared.GoName(segment))
+	}
+	return b.String()
+}
+
+// permissionConstants returns the string constants of the permission catalogue at
+// path, constant names by value. A project without a catalogue has none.
+func permissionConstants(path string) (map[string]string, error) {
+	constants := map[string]string{}
+
+	src, err := initshared.ReadFile(path)
+	if os.IsNotExist(err) {
+		return constants, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read %s", path)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), path, src, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse %s", path)
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, ident := range value.Names {
+				if i >= len(value.Values) {
+					continue
+				}
+				lit, ok := value.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				if unquoted, err := strconv.Unquote(lit.Value); err == nil {
+					constants[unquoted] = ident.Name
+				}
+			}
+		}
+	}
+
+	return constants, nil
+}
@@ -2,7 +2,6 @@ package scaffold
 
 import (
 	"fmt"
-	"path/filepath"
 
 	"github.com/pixie-sh/errors-go"
 	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
@@ -17,6 +16,7 @@ type RepositoryOptions struct {
 	EntityName     string
 	ModuleName     string
 	Force          bool
+	NoTests        bool
 }
 
 // RepositoryCmd returns the cobra command for repository generation.
@@ -28,6 +28,8 @@ func RepositoryCmd() *cobra.Command {
 
 This command creates a new repository file alongside the existing domain repository,
 allowing you to add specialized repositories to handle specific data access patterns.
+Unless --no-tests is set, it also creates the tests of the repository, run against an
+in-memory sqlite database.
 
 Examples:
   # Generate an email repository in the users domain (uses User entity)
@@ -38,6 +40,9 @@ Examples:
 
   # Force overwrite existing repository
   pixie generate repository --domain users --name email --force
+
+  # Generate a repository without its tests
+  pixie generate repository --domain users --name email --no-tests
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var domain, _ = cmd.Flags().GetString("domain")
@@ -45,6 +50,7 @@ Examples:
 			var entityName, _ = cmd.Flags().GetString("entity")
 			var moduleName, _ = cmd.Flags().GetString("module-name")
 			var force, _ = cmd.Flags().GetBool("force")
+			var noTests, _ = cmd.Flags().GetBool("no-tests")
 
 			// Default entity name to domain name if not provided
 			if entityName == "" {
@@ -57,6 +63,7 @@ Examples:
 				EntityName:     entityName,
 				ModuleName:     moduleName,
 				Force:          force,
+				NoTests:        noTests,
 			}
 
 			return generateRepository(opts)
@@ -70,7 +77,8 @@ Examples:
 	// Optional flags
 	cmd.Flags().String("entity", "", "Entity name to reference (defaults to domain name)")
 	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
-	cmd.Flags().Bool("force", false, "Force overwrite existing files")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+	cmd.Flags().Bool("no-tests", false, noTestsUsage)
 
 	// Mark required flags
 	if err := cmd.MarkFlagRequired("domain"); err != nil {
@@ -110,27 +118,56 @@ func generateRepository(opts RepositoryOptions) error {
 	data.EntityName = opts.EntityName
 	data.EntityNameCamel = initshared.ToCamelCase(opts.EntityName)
 	data.Features = map[string]bool{"database": true}
+	data.ApplyLayout(cfg)
+
+	// Look up the entity by the fields and relations it was generated with
+	fields, relations, err := genshared.RecordedEntity(cfg.Root, opts.Domain, opts.EntityName)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the fields of entity %s", opts.EntityName)
+	}
+	data.ApplyFields(fields)
+	if err := data.ApplyRelations(relations); err != nil {
+		return errors.Wrap(err, "invalid relations recorded for entity %s", opts.EntityName)
+	}
+
+	recorded, err := genshared.RecordedEntities(cfg.Root, opts.Domain)
+	if err != nil {
+		return err
+	}
+	if data.Pagination, err = genshared.ParsePagination(recorded[opts.EntityName]["pagination"]); err != nil {
+		return errors.Wrap(err, "invalid pagination recorded for entity %s", opts.EntityName)
+	}
 
 	fmt.Printf("Generating repository: %s\n", data.RepositoryNameCamel)
 	fmt.Printf("   Domain: %s\n", opts.Domain)
 	fmt.Printf("   Entity: %s\n", data.EntityNameCamel)
 	fmt.Printf("   Module: %s\n\n", data.ModuleName)
 
-	outputPath := filepath.Join(cfg.DomainDir, opts.Domain, opts.Domain+"_data_layer", opts.Domain+"_repositories", opts.RepositoryName+"_repository.go")
-
-	if !opts.Force && initshared.FileExists(outputPath) {
-		return errors.New("repository file already exists: %s (use --force to overwrite)", outputPath)
-	}
-
-	fmt.Printf("   Generating %s\n", outputPath)
+	outputPath := cfg.Path(cfg.DomainDir, opts.Domain, opts.Domain+"_data_layer", opts.Domain+"_repositories", opts.RepositoryName+"_repository.go")
 
-	content, err := initshared.RenderTemplate(Templates, "templates/repositories.go.tmpl", data)
+	gen, err := initshared.NewGeneration(cfg.Root, "generate repository", map[string]string{
+		"domain":   opts.Domain,
+		"name":     opts.RepositoryName,
+		"entity":   opts.EntityName,
+		"no-tests": noTestsInput(opts.NoTests),
+	}, opts.Force)
 	if err != nil {
-		return errors.Wrap(err, "failed to render repository template")
+		return err
 	}
 
-	if err := initshared.WriteFile(outputPath, content, opts.Force); err != nil {
-		return errors.Wrap(err, "failed to write repository file")
+	if _, err := gen.WriteTemplate(Templates, "repositories.go.tmpl", outputPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate repository file")
+	}
+	if !opts.NoTests {
+		if _, err := gen.WriteTemplate(Templates, "repository_test.go.tmpl", testPath(outputPath), data); err != nil {
+			return errors.Wrap(err, "failed to generate repository test file")
+		}
+		if err := writeTestDB(gen, cfg); err != nil {
+			return err
+		}
+	}
+	if err := gen.Finish(); err != nil {
+		return err
 	}
 
 	fmt.Printf("Successfully generated repository: %s\n\n", data.RepositoryNameCamel)
@@ -156,6 +193,9 @@ func printRepositoryNextSteps(data genshared.TemplateData, opts RepositoryOption
 	fmt.Printf("4. Test the repository:\n")
 	fmt.Printf("   go build ./%s/%s/%s_data_layer/%s_repositories/\n",
 		cfg.DomainDir, opts.Domain, opts.Domain, opts.Domain)
-	fmt.Printf("   go test ./%s/%s/%s_data_layer/%s_repositories/\n\n",
-		cfg.DomainDir, opts.Domain, opts.Domain, opts.Domain)
+	if !opts.NoTests {
+		fmt.Printf("   go test ./%s/%s/%s_data_layer/%s_repositories/\n",
+			cfg.DomainDir, opts.Domain, opts.Domain, opts.Domain)
+	}
+	fmt.Println()
 }
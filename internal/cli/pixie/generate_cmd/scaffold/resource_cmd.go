@@ -0,0 +1,402 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/features"
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+	"github.com/spf13/cobra"
+)
+
+// ResourceOptions holds all the options for resource generation.
+type ResourceOptions struct {
+	EntityOptions
+	Microservice string
+}
+
+// ResourceCmd returns the cobra command for CRUD resource generation.
+func ResourceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resource",
+		Short: "Generate a CRUD resource across every layer of an existing domain",
+		Long: `Generate a CRUD resource across every layer of an existing domain.
+
+This command creates, for one entity:
+- The entity, its migration, request/response models and repository, as
+  generate entity does with --fields
+- Create/Get/List/Update/Delete methods on the domain service and business layer
+- HTTP controllers with the CRUD routes under /<domain>/<entities>, registered in
+  SetupHTTP of the microservice serving the domain
+- Unless --no-tests is set, tests of every layer: the repository and service against
+  an in-memory sqlite database, the business layer on a fake data layer and the
+  routes through the HTTP handlers
+
+The microservice is the one whose httpControllers hold the domain business layer,
+or the one named by --ms. With the auth feature, the routes require an
+authenticated user and every record is scoped to the user who created it.
+
+Fields, relations and --pagination take the same flags as generate entity; the list
+route passes its query parameters to the repository List query.
+
+Examples:
+  # Generate a product resource in the catalog domain
+  pixie generate resource --domain catalog --name product \
+    --fields "title:string:required:size=200,price:decimal,status:enum(draft|live)"
+
+  # User-scoped resource in the orders microservice
+  pixie generate resource --domain orders --name invoice --features auth \
+    --fields "total:decimal:required" --ms orders
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var domain, _ = cmd.Flags().GetString("domain")
+			var name, _ = cmd.Flags().GetString("name")
+			var features, _ = cmd.Flags().GetString("features")
+			var moduleName, _ = cmd.Flags().GetString("module-name")
+			var fields, _ = cmd.Flags().GetString("fields")
+			var fieldsFile, _ = cmd.Flags().GetString("fields-file")
+			var pagination, _ = cmd.Flags().GetString("pagination")
+			var microservice, _ = cmd.Flags().GetString("ms")
+			var noTests, _ = cmd.Flags().GetBool("no-tests")
+			var force, _ = cmd.Flags().GetBool("force")
+			var relations = map[string]string{}
+			for _, kind := range genshared.RelationKinds {
+				relations[kind], _ = cmd.Flags().GetString(kind)
+			}
+
+			opts := ResourceOptions{
+				EntityOptions: EntityOptions{
+					Domain:     domain,
+					EntityName: name,
+					Features:   features,
+					ModuleName: moduleName,
+					Fields:     fields,
+					FieldsFile: fieldsFile,
+					Relations:  relations,
+					Pagination: pagination,
+					NoTests:    noTests,
+					Force:      force,
+				},
+				Microservice: microservice,
+			}
+
+			return generateResource(opts)
+		},
+	}
+
+	// Required flags
+	cmd.Flags().String("domain", "", "Existing domain name (required)")
+	cmd.Flags().String("name", "", "Resource entity name (required)")
+
+	// Optional flags
+	cmd.Flags().String("features", "", "Comma-separated list of features (auth)")
+	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
+	cmd.Flags().String("fields", "", "Comma-separated field specs, e.g. title:string:required,published_at:time?")
+	cmd.Flags().String("fields-file", "", "YAML file listing the entity fields")
+	cmd.Flags().String(genshared.BelongsTo, "", "Comma-separated entities this entity belongs to (domain.entity for other domains)")
+	cmd.Flags().String(genshared.HasMany, "", "Comma-separated entities this entity has many of")
+	cmd.Flags().String(genshared.ManyToMany, "", "Comma-separated entities joined to this entity")
+	cmd.Flags().String("pagination", genshared.PaginationOffset, "Pagination of the List route (offset, cursor)")
+	cmd.Flags().String("ms", "", "Microservice serving the routes (default: the one whose controllers use the domain)")
+	cmd.Flags().Bool("no-tests", false, noTestsUsage)
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+
+	// Mark required flags
+	if err := cmd.MarkFlagRequired("domain"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'domain' flag as required: %v", err))
+	}
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(fmt.Sprintf("Failed to mark 'name' flag as required: %v", err))
+	}
+
+	// Complete the feature names of --features
+	if err := cmd.RegisterFlagCompletionFunc("features", features.Complete); err != nil {
+		panic(fmt.Sprintf("Failed to register 'features' flag completion: %v", err))
+	}
+
+	return cmd
+}
+
+func generateResource(opts ResourceOptions) error {
+	if opts.EntityName == opts.Domain {
+		return errors.New("resource name must differ from the domain name; the domain scaffold already covers %s", opts.Domain)
+	}
+
+	cfg, err := genshared.LoadConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	data, err := entityData(opts.EntityOptions, cfg)
+	if err != nil {
+		return err
+	}
+	data.ResourcePath = strings.ReplaceAll(initshared.Plural(data.EntityName), "_", "-")
+
+	controllers, err := findControllers(cfg, data, opts.Microservice)
+	if err != nil {
+		return err
+	}
+	if controllers != nil {
+		data.MicroservicePackage = controllers.pkg
+		data.ControllerBusinessLayer = controllers.businessLayer
+		data.ControllerGates = controllers.gates
+		if data.Features["auth"] && data.ControllerGates == "" {
+			return errors.New("the auth feature gates the routes, but the controllers in %s have no authorization gates", controllers.path)
+		}
+	}
+
+	businessLayerPath := cfg.Path(cfg.DomainDir, opts.Domain, data.BusinessLayerPackage, opts.Domain+cfg.BusinessLayerSuffix+".go")
+	layer, err := readBusinessLayer(businessLayerPath, data.DomainNameCamel)
+	if err != nil {
+		return err
+	}
+	data.ControllerAdapters = layer.adapters
+
+	fmt.Printf("Generating resource: %s\n", data.EntityNameCamel)
+	printEntitySummary(data)
+
+	// Read the other entities of the domain before this run records its own inputs
+	recorded, err := genshared.RecordedEntities(cfg.Root, opts.Domain)
+	if err != nil {
+		return err
+	}
+
+	inputs := entityInputs(opts.EntityOptions)
+	inputs["ms"] = opts.Microservice
+
+	gen, err := initshared.NewGeneration(cfg.Root, "generate resource", inputs, opts.Force)
+	if err != nil {
+		return err
+	}
+
+	if err := writeEntity(gen, &data, cfg, recorded, true, !opts.NoTests); err != nil {
+		return err
+	}
+
+	servicePath := cfg.Path(cfg.DomainDir, opts.Domain, opts.Domain+"_services", opts.EntityName+"_service.go")
+	if _, err := gen.WriteTemplate(Templates, "resource_service.go.tmpl", servicePath, data); err != nil {
+		return errors.Wrap(err, "failed to generate service file")
+	}
+
+	resourceLayerPath := cfg.Path(cfg.DomainDir, opts.Domain, data.BusinessLayerPackage, opts.EntityName+"_business_layer.go")
+	if _, err := gen.WriteTemplate(Templates, "resource_business_layer.go.tmpl", resourceLayerPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate business layer file")
+	}
+
+	if !opts.NoTests {
+		if _, err := gen.WriteTemplate(Templates, "resource_service_test.go.tmpl", testPath(servicePath), data); err != nil {
+			return errors.Wrap(err, "failed to generate service test file")
+		}
+		if _, err := gen.WriteTemplate(Templates, "resource_business_layer_test.go.tmpl", testPath(resourceLayerPath), data); err != nil {
+			return errors.Wrap(err, "failed to generate business layer test file")
+		}
+	}
+
+	if controllers != nil {
+		controllersPath := filepath.Join(filepath.Dir(controllers.path), opts.EntityName+"_http_controllers.go")
+		if _, err := gen.WriteTemplate(Templates, "resource_http_controllers.go.tmpl", controllersPath, data); err != nil {
+			return errors.Wrap(err, "failed to generate HTTP controllers file")
+		}
+		if !opts.NoTests {
+			if _, err := gen.WriteTemplate(Templates, "resource_http_controllers_test.go.tmpl", testPath(controllersPath), data); err != nil {
+				return errors.Wrap(err, "failed to generate HTTP controllers test file")
+			}
+		}
+		if err := wireRoutes(data, controllers.path); err != nil {
+			return err
+		}
+	}
+	if err := gen.Finish(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully generated resource: %s\n\n", data.EntityNameCamel)
+	printResourceNextSteps(data, controllers, layer, opts, cfg)
+	printMissingRelations(data, recorded, cfg)
+
+	return nil
+}
+
+// controllersFile describes the httpControllers of a microservice.
+type controllersFile struct {
+	path          string
+	pkg           string
+	businessLayer string // field holding the domain business layer
+	gates         string // field holding the authorization gates; empty when there are none
+}
+
+// findControllers returns the HTTP controllers the resource routes go to: those of the
+// named microservice, or else the first whose httpControllers hold the domain business
+// layer. It returns nil when no microservice serves the domain.
+func findControllers(cfg genshared.GeneratorConfig, data genshared.TemplateData, microservice string) (*controllersFile, error) {
+	var candidates []string
+	if microservice != "" {
+		if !strings.HasPrefix(microservice, cfg.MicroservicePrefix) {
+			microservice = cfg.MicroservicePrefix + microservice
+		}
+		candidates = []string{cfg.Path(cfg.MicroserviceDir, microservice, "http_controllers.go")}
+	} else {
+		matches, err := filepath.Glob(cfg.Path(cfg.MicroserviceDir, "*", "http_controllers.go"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		candidates = matches
+	}
+
+	businessLayerType := "*" + data.BusinessLayerPackage + "." + data.DomainNameCamel + "BusinessLayer"
+	for _, path := range candidates {
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			if microservice != "" {
+				return nil, errors.Wrap(err, "failed to read the controllers of microservice %s", microservice)
+			}
+			continue
+		}
+
+		controllers := &controllersFile{path: path, pkg: file.Name.Name}
+		for name, fieldType := range structFields(file, "httpControllers") {
+			switch fieldType {
+			case businessLayerType:
+				controllers.businessLayer = name
+			case "*bundles.AuthorizationGatesBundle":
+				controllers.gates = name
+			}
+		}
+
+		if controllers.businessLayer != "" {
+			return controllers, nil
+		}
+		if microservice != "" {
+			return nil, errors.New("the httpControllers in %s have no %s field", path, businessLayerType)
+		}
+	}
+
+	return nil, nil
+}
+
+// structFields returns the fields of the struct typeName in file, mapped to their types.
+func structFields(file *ast.File, typeName string) map[string]string {
+	fields := map[string]string{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != typeName {
+			return true
+		}
+
+		if structType, ok := spec.Type.(*ast.StructType); ok {
+			for _, field := range structType.Fields.List {
+				for _, name := range field.Names {
+					fields[name.Name] = typeString(field.Type)
+				}
+			}
+		}
+		return false
+	})
+
+	return fields
+}
+
+func typeString(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + typeString(x.X)
+	case *ast.SelectorExpr:
+		return typeString(x.X) + "." + x.Sel.Name
+	case *ast.Ident:
+		return x.Name
+	}
+	return ""
+}
+
+// businessLayerFile describes the domain business layer the resource methods extend.
+type businessLayerFile struct {
+	transactor bool // the data layer is taken as a Transactor, which the tests fake
+	adapters   bool // the constructor takes the domain adapters
+}
+
+// readBusinessLayer reads the domain business layer at path. A domain without one has
+// neither.
+func readBusinessLayer(path, domainCamel string) (businessLayerFile, error) {
+	var layer businessLayerFile
+
+	src, err := initshared.ReadFile(path)
+	if os.IsNotExist(err) {
+		return layer, nil
+	}
+	if err != nil {
+		return layer, errors.Wrap(err, "failed to read %s", path)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), path, src, 0)
+	if err != nil {
+		return layer, errors.Wrap(err, "failed to parse %s", path)
+	}
+
+	layer.transactor = file.Scope.Lookup("Transactor") != nil
+	obj := file.Scope.Lookup("New" + domainCamel + "BusinessLayer")
+	if obj == nil {
+		return layer, nil
+	}
+	if fn, ok := obj.Decl.(*ast.FuncDecl); ok {
+		for _, param := range fn.Type.Params.List {
+			for _, name := range param.Names {
+				layer.adapters = layer.adapters || name.Name == "adapters"
+			}
+		}
+	}
+
+	return layer, nil
+}
+
+func printResourceNextSteps(data genshared.TemplateData, controllers *controllersFile, layer businessLayerFile, opts ResourceOptions, cfg genshared.GeneratorConfig) {
+	fmt.Printf("Next steps:\n\n")
+
+	step := 1
+	if controllers == nil {
+		fmt.Printf("%d. No microservice serves the %s domain; generate one, or pass --ms, and re-run:\n", step, data.DomainName)
+		fmt.Printf("   pixie generate microservice --name %s --domain %s\n\n", data.DomainName, data.DomainName)
+		step++
+	} else {
+		fmt.Printf("%d. The %s routes are served by %s:\n", step, data.EntityName, filepath.Dir(controllers.path))
+		for _, route := range []string{"POST   ", "GET    ", "GET    :id ", "PUT    :id ", "DELETE :id "} {
+			method, param, _ := strings.Cut(route, " ")
+			path := "/" + data.DomainName + "/" + data.ResourcePath
+			if strings.TrimSpace(param) == ":id" {
+				path += "/:id"
+			}
+			fmt.Printf("   %-6s %s\n", method, path)
+		}
+		fmt.Println()
+		step++
+	}
+
+	fmt.Printf("%d. Regenerate the OpenAPI spec to document the new routes:\n", step)
+	fmt.Printf("   pixie generate openapi-spec\n\n")
+	step++
+
+	if opts.NoTests {
+		return
+	}
+	if !layer.transactor {
+		fmt.Printf("%d. The business layer tests fake its data layer through the Transactor interface;\n", step)
+		fmt.Printf("   regenerate the %s business layer to declare it:\n", data.DomainName)
+		fmt.Printf("   pixie upgrade\n\n")
+		step++
+	}
+
+	fmt.Printf("%d. Run the generated tests:\n", step)
+	fmt.Printf("   go test ./%s/%s/...\n", cfg.DomainDir, data.DomainName)
+	if controllers != nil {
+		fmt.Printf("   go test ./%s/\n", cfg.MicroserviceDir+"/"+filepath.Base(filepath.Dir(controllers.path)))
+	}
+	fmt.Println()
+}
@@ -2,7 +2,6 @@ package scaffold
 
 import (
 	"fmt"
-	"path/filepath"
 
 	"github.com/pixie-sh/errors-go"
 	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
@@ -16,6 +15,7 @@ type ServiceOptions struct {
 	ServiceName string
 	ModuleName  string
 	Force       bool
+	NoTests     bool
 }
 
 // ServiceCmd returns the cobra command for service generation.
@@ -27,6 +27,7 @@ func ServiceCmd() *cobra.Command {
 
 This command creates a new service file alongside the existing domain service,
 allowing you to add specialized services to handle specific business logic.
+Unless --no-tests is set, it also creates the tests of the service.
 
 Examples:
   # Generate an email service in the users domain
@@ -35,6 +36,9 @@ Examples:
   # Generate a payment service in the orders domain
   pixie generate service --domain orders --name payment
 
+  # Generate a service without its tests
+  pixie generate service --domain users --name email --no-tests
+
   # Force overwrite existing service
   pixie generate service --domain users --name email --force
 `,
@@ -43,12 +47,14 @@ Examples:
 			var serviceName, _ = cmd.Flags().GetString("name")
 			var moduleName, _ = cmd.Flags().GetString("module-name")
 			var force, _ = cmd.Flags().GetBool("force")
+			var noTests, _ = cmd.Flags().GetBool("no-tests")
 
 			opts := ServiceOptions{
 				Domain:      domain,
 				ServiceName: serviceName,
 				ModuleName:  moduleName,
 				Force:       force,
+				NoTests:     noTests,
 			}
 
 			return generateService(opts)
@@ -61,7 +67,8 @@ Examples:
 
 	// Optional flags
 	cmd.Flags().String("module-name", "", "Go module name (auto-detected if not provided)")
-	cmd.Flags().Bool("force", false, "Force overwrite existing files")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
+	cmd.Flags().Bool("no-tests", false, noTestsUsage)
 
 	// Mark required flags
 	if err := cmd.MarkFlagRequired("domain"); err != nil {
@@ -98,26 +105,36 @@ func generateService(opts ServiceOptions) error {
 	data.ServiceName = opts.ServiceName
 	data.ServiceNameCamel = initshared.ToCamelCase(opts.ServiceName)
 	data.ModuleName = moduleName
+	data.ApplyLayout(cfg)
 
 	fmt.Printf("Generating service: %s\n", data.ServiceNameCamel)
 	fmt.Printf("   Domain: %s\n", opts.Domain)
 	fmt.Printf("   Module: %s\n\n", data.ModuleName)
 
-	outputPath := filepath.Join(cfg.DomainDir, opts.Domain, opts.Domain+"_services", opts.ServiceName+"_service.go")
-
-	if !opts.Force && initshared.FileExists(outputPath) {
-		return errors.New("service file already exists: %s (use --force to overwrite)", outputPath)
-	}
-
-	fmt.Printf("   Generating %s\n", outputPath)
+	outputPath := cfg.Path(cfg.DomainDir, opts.Domain, opts.Domain+"_services", opts.ServiceName+"_service.go")
 
-	content, err := initshared.RenderTemplate(Templates, "templates/services.go.tmpl", data)
+	gen, err := initshared.NewGeneration(cfg.Root, "generate service", map[string]string{
+		"domain":   opts.Domain,
+		"name":     opts.ServiceName,
+		"no-tests": noTestsInput(opts.NoTests),
+	}, opts.Force)
 	if err != nil {
-		return errors.Wrap(err, "failed to render service template")
+		return err
 	}
 
-	if err := initshared.WriteFile(outputPath, content, opts.Force); err != nil {
-		return errors.Wrap(err, "failed to write service file")
+	if _, err := gen.WriteTemplate(Templates, "services.go.tmpl", outputPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate service file")
+	}
+	if !opts.NoTests {
+		if _, err := gen.WriteTemplate(Templates, "services_test.go.tmpl", testPath(outputPath), data); err != nil {
+			return errors.Wrap(err, "failed to generate service tests")
+		}
+	}
+	if err := wireService(data, cfg); err != nil {
+		return err
+	}
+	if err := gen.Finish(); err != nil {
+		return err
 	}
 
 	fmt.Printf("Successfully generated service: %s\n\n", data.ServiceNameCamel)
@@ -133,13 +150,9 @@ func printServiceNextSteps(data genshared.TemplateData, opts ServiceOptions, cfg
 	fmt.Printf("   ./%s/%s/%s_services/%s_service.go\n\n",
 		cfg.DomainDir, opts.Domain, opts.Domain, opts.ServiceName)
 
-	fmt.Printf("2. Update the domain registry if needed:\n")
-	fmt.Printf("   ./%s/%s/registry.go\n", cfg.DomainDir, opts.Domain)
-	fmt.Printf("   Add DI registration for your new service\n\n")
-
-	fmt.Printf("3. Implement the service methods\n\n")
+	fmt.Printf("2. Implement the service methods\n\n")
 
-	fmt.Printf("4. Test the service:\n")
+	fmt.Printf("3. Test the service:\n")
 	fmt.Printf("   go build ./%s/%s/%s_services/\n", cfg.DomainDir, opts.Domain, opts.Domain)
 	fmt.Printf("   go test ./%s/%s/%s_services/\n\n", cfg.DomainDir, opts.Domain, opts.Domain)
 }
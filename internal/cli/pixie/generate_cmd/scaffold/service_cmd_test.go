@@ -0,0 +1,45 @@
+package scaffold
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateService_Tests(t *testing.T) {
+	tests := []struct {
+		name      string
+		noTests   bool
+		wantTests bool
+	}{
+		{name: "writes the service tests", wantTests: true},
+		{name: "no tests", noTests: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newProject(t)
+			err := generateDomain(DomainOptions{Domain: "users", Features: "database", NoTests: true})
+			if err != nil {
+				t.Fatalf("generateDomain() error = %v", err)
+			}
+
+			if err := generateService(ServiceOptions{Domain: "users", ServiceName: "email", NoTests: tt.noTests}); err != nil {
+				t.Fatalf("generateService() error = %v", err)
+			}
+
+			path := filepath.Join(root, "internal", "domain", "users", "users_services", "email_service_test.go")
+			content, err := os.ReadFile(path)
+			if gotTests := err == nil; gotTests != tt.wantTests {
+				t.Fatalf("email_service_test.go exists = %v, want %v", gotTests, tt.wantTests)
+			}
+			if !tt.wantTests {
+				return
+			}
+			if formatted, err := format.Source(content); err != nil || string(formatted) != string(content) {
+				t.Errorf("email_service_test.go is not gofmt-formatted Go (%v):\n%s", err, content)
+			}
+		})
+	}
+}
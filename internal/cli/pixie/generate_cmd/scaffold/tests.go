@@ -0,0 +1,58 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// sqliteDriver is the module of the in-memory database the generated tests run against.
+const sqliteDriver = "github.com/glebarez/sqlite"
+
+// noTestsUsage is the usage of the --no-tests flag of the generators writing tests.
+const noTestsUsage = "Do not generate the unit tests of the generated layers"
+
+// testDBPath returns the file of the testdb package, which opens the databases of the
+// generated tests and fakes the data layers.
+func testDBPath(cfg genshared.GeneratorConfig) string {
+	return cfg.Path(cfg.InfraDir, "testdb", "testdb.go")
+}
+
+// testPath returns the test file of the Go file at path.
+func testPath(path string) string {
+	return strings.TrimSuffix(path, ".go") + "_test.go"
+}
+
+// writeTestDB writes the testdb package the generated tests use.
+func writeTestDB(gen *initshared.Generation, cfg genshared.GeneratorConfig) error {
+	if _, err := gen.WriteTemplate(Templates, "testdb.go.tmpl", testDBPath(cfg), genshared.NewTemplateData()); err != nil {
+		return errors.Wrap(err, "failed to generate the test database helpers")
+	}
+
+	printTestDependencies(cfg)
+	return nil
+}
+
+// printTestDependencies points out the sqlite driver when the go.mod of the project does
+// not require it yet.
+func printTestDependencies(cfg genshared.GeneratorConfig) {
+	content, err := initshared.ReadFile(cfg.Path("go.mod"))
+	if err != nil || strings.Contains(string(content), sqliteDriver+" ") {
+		return
+	}
+
+	fmt.Printf("   The generated tests need the sqlite driver: go get %s\n", sqliteDriver)
+}
+
+// noTestsInput returns the --no-tests value recorded in the manifest, empty unless set
+// so that upgrade replays the generator with the flag only when it was given.
+func noTestsInput(noTests bool) string {
+	if noTests {
+		return "true"
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package scaffold
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/wiring"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// tokensPath returns the file holding the project's DI injection tokens.
+func tokensPath(cfg genshared.GeneratorConfig) string {
+	return cfg.Path(cfg.InfraDir, "di", "injection_tokens.go")
+}
+
+// wireDomainTokens declares the DI tokens the domain templates reference.
+func wireDomainTokens(data genshared.TemplateData, cfg genshared.GeneratorConfig) error {
+	tokens := []wiring.Token{
+		{Name: "RegistryToken" + data.DomainNameCamel + "DataLayer", Key: data.DomainName + "_data_layer"},
+		{Name: "RegistryToken" + data.DomainNameCamel + "BusinessLayer", Key: data.DomainName + "_business_layer"},
+		{Name: "RegistryToken" + data.DomainNameCamel + "Service", Key: data.DomainName + "_service"},
+	}
+	if data.Features["apis"] {
+		tokens = append(tokens, wiring.Token{Name: "RegistryToken" + data.DomainNameCamel + "API", Key: data.DomainName + "_api"})
+	}
+
+	path := tokensPath(cfg)
+	return wire(path, func() (bool, error) {
+		return wiring.AddTokens(path, data.DomainNameCamel+" domain tokens", tokens)
+	}, tokenLines(tokens))
+}
+
+// wireEntityMigration registers the entity migration in the domain's ordered migration
+// list, before the migrations of the dependents: entities whose tables reference it.
+func wireEntityMigration(data genshared.TemplateData, migrationsDir string, dependents []string) error {
+	migration := fmt.Sprintf("&Create%sTable%s", data.EntityNameCamel, data.MigrationTimestamp)
+
+	before := make([]string, len(dependents))
+	for i, dependent := range dependents {
+		before[i] = fmt.Sprintf("&Create%sTable", initshared.ToCamelCase(dependent))
+	}
+
+	manual := fmt.Sprintf("Append %s to the Migrations slice", migration)
+	if len(before) > 0 {
+		manual = fmt.Sprintf("Add %s to the Migrations slice before %s", migration, strings.Join(before, ", "))
+	}
+
+	path := filepath.Join(migrationsDir, "migrations.go")
+	return wire(path, func() (bool, error) {
+		return wiring.InsertIntoSlice(path, "Migrations", migration, before)
+	}, []string{manual})
+}
+
+// wireService declares the DI token of a domain service and registers the service in
+// the domain registry.
+func wireService(data genshared.TemplateData, cfg genshared.GeneratorConfig) error {
+	token := wiring.Token{Name: "RegistryToken" + data.ServiceNameCamel + "Service", Key: data.ServiceName + "_service"}
+
+	path := tokensPath(cfg)
+	err := wire(path, func() (bool, error) {
+		return wiring.AddTokens(path, data.ServiceNameCamel+" service tokens", []wiring.Token{token})
+	}, tokenLines([]wiring.Token{token}))
+	if err != nil {
+		return err
+	}
+
+	services := data.DomainName + "_services"
+	stmts := []string{
+		fmt.Sprintf("errors.Must(di.RegisterConfiguration[*%s.%sServiceConfiguration](%s.Registry%sServiceConfiguration, di.WithToken(internaldi.%s)))",
+			services, data.ServiceNameCamel, services, data.ServiceNameCamel, token.Name),
+		fmt.Sprintf("errors.Must(di.Register[*%s.%sService](%s.Registry%sService, di.WithToken(internaldi.%s)))",
+			services, data.ServiceNameCamel, services, data.ServiceNameCamel, token.Name),
+	}
+	imports := map[string]string{
+		"github.com/pixie-sh/di-go":     "di",
+		"github.com/pixie-sh/errors-go": "errors",
+		data.DIImport:                   "internaldi",
+		data.ServicesImport:             services,
+	}
+
+	path = cfg.Path(cfg.DomainDir, data.DomainName, "registry.go")
+	return wire(path, func() (bool, error) {
+		return wiring.AddStatements(path, "Registry", imports, stmts)
+	}, append([]string{"Add to Registry():"}, stmts...))
+}
+
+// wireRoutes registers the resource routes in SetupHTTP of the microservice controllers.
+func wireRoutes(data genshared.TemplateData, controllersPath string) error {
+	stmt := fmt.Sprintf("c.setup%sRoutes()", data.EntityNameCamel)
+
+	return wire(controllersPath, func() (bool, error) {
+		return wiring.AddStatements(controllersPath, "httpControllers.SetupHTTP", nil, []string{stmt})
+	}, []string{"Add to SetupHTTP():", stmt})
+}
+
+// wire applies edit to path. When path does not have the shape edit expects, the change
+// is printed as a manual step instead.
+func wire(path string, edit func() (bool, error), manual []string) error {
+	changed, err := edit()
+	if shapeErr, ok := err.(*wiring.ShapeError); ok {
+		fmt.Printf("   WARNING: could not update %s automatically (%s); make this change by hand:\n", path, shapeErr.Reason)
+		for _, line := range manual {
+			fmt.Printf("      %s\n", line)
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to update %s", path)
+	}
+
+	if changed {
+		fmt.Printf("   Wiring %s\n", path)
+	} else {
+		fmt.Printf("   Unchanged %s (already wired)\n", path)
+	}
+
+	return nil
+}
+
+func tokenLines(tokens []wiring.Token) []string {
+	lines := make([]string, len(tokens))
+	for i, t := range tokens {
+		lines[i] = fmt.Sprintf("%s = pdi.RegisterInjectionToken(%q)", t.Name, t.Key)
+	}
+	return lines
+}
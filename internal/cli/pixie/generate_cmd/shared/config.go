@@ -2,93 +2,59 @@ package shared
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/pixie-sh/errors-go"
-	"gopkg.in/yaml.v3"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/models"
 )
 
 // GeneratorConfig holds all configurable paths and conventions for code generation.
-// Paths are relative to the project root. Loaded from .pixie.yaml or pixie.yaml if present,
-// otherwise sensible defaults are used.
-type GeneratorConfig struct {
-	// Directory conventions (relative to project root)
-	MicroserviceDir string `yaml:"microservice_dir"` // e.g. "internal/ms"
-	DomainDir       string `yaml:"domain_dir"`       // e.g. "internal/domain"
-	ModelsDir       string `yaml:"models_dir"`       // e.g. "pkg/models"
-	ConfigsDir      string `yaml:"configs_dir"`      // e.g. "misc/configs"
-	CmdDir          string `yaml:"cmd_dir"`          // e.g. "cmd/ms"
-
-	// Naming conventions
-	MicroservicePrefix  string `yaml:"microservice_prefix"`   // e.g. "ms_"
-	BusinessLayerSuffix string `yaml:"business_layer_suffix"` // e.g. "_business_layer"
-
-	// OpenAPI defaults
-	OpenAPITitle   string   `yaml:"openapi_title"`   // default title for OpenAPI spec
-	OpenAPIServers []string `yaml:"openapi_servers"` // default server URLs
-	OAuthAuthorize string   `yaml:"oauth_authorize"` // OAuth authorize URL
-	OAuthToken     string   `yaml:"oauth_token"`     // OAuth token URL
-
-	// Module name (auto-detected from go.mod if empty)
-	ModuleName string `yaml:"module_name"`
-}
+// It is the generate: section of the project configuration.
+type GeneratorConfig = config.GeneratorConfig
 
 // DefaultConfig returns a GeneratorConfig with sensible defaults.
 func DefaultConfig() GeneratorConfig {
-	return GeneratorConfig{
-		MicroserviceDir:     "internal/ms",
-		DomainDir:           "internal/domain",
-		ModelsDir:           "pkg/models",
-		ConfigsDir:          "misc/configs",
-		CmdDir:              "cmd/ms",
-		MicroservicePrefix:  "ms_",
-		BusinessLayerSuffix: "_business_layer",
-		OpenAPITitle:        "API",
-		OpenAPIServers:      []string{},
-		OAuthAuthorize:      "",
-		OAuthToken:          "",
-	}
+	return config.DefaultGeneratorConfig()
 }
 
-// LoadConfig loads configuration from .pixie.yaml or pixie.yaml in the current directory.
-// If no config file is found, returns DefaultConfig with no error.
+// LoadConfig loads the generate: section of the project configuration.
+// The config file is discovered by walking up to the project root, honouring the
+// root --config and --env flags. If no config file is found, returns DefaultConfig with no error.
 func LoadConfig() (GeneratorConfig, error) {
-	cfg := DefaultConfig()
-
-	// Try .pixie.yaml first, then pixie.yaml
-	configPaths := []string{".pixie.yaml", "pixie.yaml"}
-
-	var data []byte
-	var found bool
-	for _, path := range configPaths {
-		content, err := os.ReadFile(path)
-		if err == nil {
-			data = content
-			found = true
-			break
-		}
+	cfg, err := config.Load(config.CurrentOptions())
+	if err != nil {
+		return cfg.Generate, errors.Wrap(err, "failed to load pixie config file")
 	}
 
-	if !found {
-		return cfg, nil
-	}
+	return cfg.Generate, nil
+}
 
-	// Parse YAML into a wrapper struct that has a "generate" key
-	var wrapper struct {
-		Generate GeneratorConfig `yaml:"generate"`
+// LoadRuntimeConfig loads and validates the runtime: section of the project configuration,
+// with DATABASE_*, REDIS_*, JWT_SECRET_KEY and ADMIN_API_KEY environment overrides applied.
+func LoadRuntimeConfig() (models.CLIConfig, error) {
+	cfg, err := config.Load(config.CurrentOptions())
+	if err != nil {
+		return cfg.Runtime, errors.Wrap(err, "failed to load pixie config file")
 	}
-	wrapper.Generate = cfg // preserve defaults
 
-	if err := yaml.Unmarshal(data, &wrapper); err != nil {
-		return cfg, errors.Wrap(err, "failed to parse pixie config file")
+	if err := cfg.Runtime.Validate(); err != nil {
+		return cfg.Runtime, err
 	}
 
-	return wrapper.Generate, nil
+	return cfg.Runtime, nil
 }
 
-// DetectModule reads go.mod from the current directory and returns the module path.
+// DetectModule reads go.mod from the project root and returns the module path.
 func DetectModule() (string, error) {
-	content, err := os.ReadFile("go.mod")
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get current working directory")
+	}
+
+	content, err := os.ReadFile(filepath.Join(config.FindProjectRoot(cwd), "go.mod"))
 	if err != nil {
 		return "", errors.Wrap(err, "could not read go.mod file")
 	}
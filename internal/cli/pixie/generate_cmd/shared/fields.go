@@ -0,0 +1,682 @@
+package shared
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"gopkg.in/yaml.v3"
+
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// FieldTypes maps the field types accepted by --fields to their Go types.
+var FieldTypes = map[string]string{
+	"string":  "string",
+	"text":    "string",
+	"int":     "int",
+	"int64":   "int64",
+	"float":   "float64",
+	"decimal": "float64",
+	"bool":    "bool",
+	"time":    "time.Time",
+	"uid":     "uid.UID",
+	"json":    "json.RawMessage",
+	"enum":    "string",
+}
+
+// reservedFields are columns every entity template declares already.
+var reservedFields = map[string]bool{
+	"id": true, "created_at": true, "updated_at": true, "deleted_at": true, "user_id": true,
+}
+
+// Field is one column of a generated entity.
+type Field struct {
+	Name     string   `yaml:"name"`     // snake_case column and JSON name: published_at
+	Type     string   `yaml:"type"`     // a key of FieldTypes
+	Optional bool     `yaml:"optional"` // nullable column with a pointer Go type
+	Required bool     `yaml:"required"` // validate:"required" on requests
+	Unique   bool     `yaml:"unique"`
+	Index    bool     `yaml:"index"`
+	Size     int      `yaml:"size"`    // maximum length of string and enum columns
+	Default  string   `yaml:"default"` // column default
+	Values   []string `yaml:"values"`  // allowed values of an enum
+
+	// Description documents the field, as the field_description of event payloads.
+	Description string `yaml:"description"`
+
+	// EnumType is the Go type of an enum field, set by TemplateData.ApplyFields.
+	EnumType string `yaml:"-"`
+}
+
+// GoName returns the Go field name, spelling initialisms in upper case: PublishedAt,
+// ExternalID.
+func (f Field) GoName() string {
+	return GoName(f.Name)
+}
+
+// VarName returns the Go variable name of the field: publishedAt, externalID.
+func (f Field) VarName() string {
+	name := GoVarName(f.Name)
+	if token.IsKeyword(name) {
+		return name + "Value"
+	}
+	return name
+}
+
+// FieldDescription returns the description of the field, or its name in words when it
+// has none: Order id.
+func (f Field) FieldDescription() string {
+	if f.Description != "" {
+		return f.Description
+	}
+	words := strings.ReplaceAll(f.Name, "_", " ")
+	return strings.ToUpper(words[:1]) + words[1:]
+}
+
+// IsEnum reports whether the field is an enum.
+func (f Field) IsEnum() bool {
+	return f.Type == "enum"
+}
+
+// GoType returns the type of the field in the entity, which uses the enum type.
+func (f Field) GoType() string {
+	if f.IsEnum() && f.EnumType != "" {
+		return f.pointer(f.EnumType)
+	}
+	return f.PlainGoType()
+}
+
+// PlainGoType returns the type of the field outside the entity package: enums are strings.
+func (f Field) PlainGoType() string {
+	return f.pointer(FieldTypes[f.Type])
+}
+
+func (f Field) pointer(goType string) string {
+	if f.Optional && f.Type != "json" {
+		return "*" + goType
+	}
+	return goType
+}
+
+// ValueGoType returns the non-pointer type of the field in package pkg, qualifying the
+// enum type with the entities package.
+func (f Field) ValueGoType(entitiesPackage string) string {
+	if f.IsEnum() && f.EnumType != "" {
+		return entitiesPackage + "." + f.EnumType
+	}
+	return FieldTypes[f.Type]
+}
+
+// FromModel returns the Go expression converting expr, the field of a request model, to
+// the entity type of the field in package entitiesPackage.
+func (f Field) FromModel(expr, entitiesPackage string) string {
+	if !f.IsEnum() || f.EnumType == "" {
+		return expr
+	}
+	if f.Optional {
+		return "(*" + entitiesPackage + "." + f.EnumType + ")(" + expr + ")"
+	}
+	return entitiesPackage + "." + f.EnumType + "(" + expr + ")"
+}
+
+// ToModel returns the Go expression converting expr, the field of an entity, to the
+// type of the field in response models.
+func (f Field) ToModel(expr string) string {
+	if !f.IsEnum() || f.EnumType == "" {
+		return expr
+	}
+	if f.Optional {
+		return "(*string)(" + expr + ")"
+	}
+	return "string(" + expr + ")"
+}
+
+// GormTag returns the gorm struct tag value of the field.
+func (f Field) GormTag() string {
+	var parts []string
+	if !f.Optional {
+		parts = append(parts, "not null")
+	}
+
+	switch f.Type {
+	case "string", "enum":
+		parts = append(parts, "size:"+strconv.Itoa(f.size()))
+	case "text":
+		parts = append(parts, "type:text")
+	case "decimal":
+		parts = append(parts, "type:numeric(20,4)")
+	case "uid":
+		parts = append(parts, "type:char(26)")
+	case "json":
+		parts = append(parts, "type:jsonb")
+	}
+
+	switch {
+	case f.Unique:
+		parts = append(parts, "uniqueIndex")
+	case f.Index:
+		parts = append(parts, "index")
+	}
+	if f.Default != "" {
+		parts = append(parts, "default:"+f.defaultLiteral())
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// JSONTag returns the json struct tag value of the field.
+func (f Field) JSONTag() string {
+	if f.Optional {
+		return f.Name + ",omitempty"
+	}
+	return f.Name
+}
+
+// ValidateTag returns the validate struct tag value of the field on requests, empty
+// when there is nothing to validate.
+func (f Field) ValidateTag() string {
+	var rules []string
+	switch {
+	case f.Required:
+		rules = append(rules, "required")
+	case f.Optional:
+		rules = append(rules, "omitempty")
+	}
+
+	switch f.Type {
+	case "string":
+		rules = append(rules, "max="+strconv.Itoa(f.size()))
+	case "enum":
+		rules = append(rules, "oneof="+strings.Join(f.Values, " "))
+	}
+
+	if len(rules) == 1 && rules[0] == "omitempty" {
+		return ""
+	}
+	return strings.Join(rules, ",")
+}
+
+// EnumConstant returns the name of the constant holding value of an enum field.
+func (f Field) EnumConstant(value string) string {
+	return f.EnumType + initshared.Pascal(value)
+}
+
+// SampleValue returns a Go expression of a value of the field for generated tests. Values
+// differ for every n where the type allows it, so that unique columns take distinct ones,
+// and are never the zero value.
+// Enums are the constants of entitiesPackage, or their string values when it is empty,
+// as request models take them.
+func (f Field) SampleValue(n int, entitiesPackage string) string {
+	switch f.Type {
+	case "string", "text":
+		value := f.Name + "-" + strconv.Itoa(n)
+		if f.Type == "string" && len(value) > f.size() {
+			value = value[len(value)-f.size():]
+		}
+		return strconv.Quote(value)
+	case "int", "int64":
+		return strconv.Itoa(n)
+	case "float", "decimal":
+		return strconv.Itoa(n) + ".5"
+	case "bool":
+		// false would fail the required validation of requests
+		return "true"
+	case "time":
+		return fmt.Sprintf("time.Date(2024, time.January, %d, 12, 0, 0, 0, time.UTC)", n)
+	case "uid":
+		return "uid.New()"
+	case "json":
+		return fmt.Sprintf("json.RawMessage(`{\"n\":%d}`)", n)
+	case "enum":
+		value := f.Values[n%len(f.Values)]
+		if entitiesPackage == "" || f.EnumType == "" {
+			return strconv.Quote(value)
+		}
+		return entitiesPackage + "." + f.EnumConstant(value)
+	}
+	return ""
+}
+
+func (f Field) size() int {
+	if f.Size > 0 {
+		return f.Size
+	}
+	if f.IsEnum() {
+		longest := 0
+		for _, value := range f.Values {
+			if len(value) > longest {
+				longest = len(value)
+			}
+		}
+		return longest
+	}
+	return 255
+}
+
+func (f Field) defaultLiteral() string {
+	switch f.Type {
+	case "string", "text", "enum", "uid":
+		return "'" + strings.ReplaceAll(f.Default, "'", "''") + "'"
+	}
+	return f.Default
+}
+
+// ParseFields parses a --fields specification: comma-separated name:type[:modifier...]
+// entries. A type ending in ? is optional; enum(a|b) declares an enum. Modifiers are
+// required, unique, index, size=N, default=V and description=D.
+//
+//	title:string:required:size=200,price:decimal,published_at:time?,status:enum(draft|live)
+func ParseFields(spec string) ([]Field, error) {
+	fields, err := parseFields(spec)
+	if err != nil {
+		return nil, err
+	}
+	return fields, validateColumns(fields)
+}
+
+func parseFields(spec string) ([]Field, error) {
+	var fields []Field
+	for _, entry := range splitOutsideParens(spec, ',') {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 {
+			return nil, errors.New("field %q has no type, expected name:type", entry)
+		}
+
+		field := Field{Name: strings.TrimSpace(parts[0])}
+		if err := field.parseType(strings.TrimSpace(parts[1])); err != nil {
+			return nil, errors.Wrap(err, "field %s", field.Name)
+		}
+		for _, modifier := range parts[2:] {
+			if err := field.applyModifier(strings.TrimSpace(modifier)); err != nil {
+				return nil, errors.Wrap(err, "field %s", field.Name)
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, validateFields(fields)
+}
+
+// LoadFieldsFile reads fields from a YAML file with a top-level fields list. Types may
+// use the --fields shorthand, such as time? or enum(a|b).
+func LoadFieldsFile(path string) ([]Field, error) {
+	fields, err := loadFieldsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return fields, validateColumns(fields)
+}
+
+func loadFieldsFile(path string) ([]Field, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read fields file: %s", path)
+	}
+
+	var file struct {
+		Fields []Field `yaml:"fields"`
+	}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, errors.Wrap(err, "failed to parse fields file: %s", path)
+	}
+
+	for i := range file.Fields {
+		field := &file.Fields[i]
+		optional, required, values := field.Optional, field.Required, field.Values
+		if err := field.parseType(field.Type); err != nil {
+			return nil, errors.Wrap(err, "field %s in %s", field.Name, path)
+		}
+		field.Optional = field.Optional || optional
+		field.Required = field.Required || required
+		if len(field.Values) == 0 {
+			field.Values = values
+		}
+	}
+
+	return file.Fields, validateFields(file.Fields)
+}
+
+// ResolveFields returns the fields of --fields or --fields-file; at most one may be set.
+// A relative file is looked up from the working directory, then from root.
+func ResolveFields(spec, file, root string) ([]Field, error) {
+	fields, err := resolveFields(spec, file, root)
+	if err != nil {
+		return nil, err
+	}
+	return fields, validateColumns(fields)
+}
+
+// ResolvePayloadFields is ResolveFields for the fields of a payload, such as an event,
+// rather than of an entity: they may be named like the columns every entity declares,
+// but take no column modifiers.
+func ResolvePayloadFields(spec, file, root string) ([]Field, error) {
+	fields, err := resolveFields(spec, file, root)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		if field.Unique || field.Index || field.Default != "" {
+			return nil, errors.New("field %s: unique, index and default apply to entity columns only", field.Name)
+		}
+	}
+	return fields, nil
+}
+
+func resolveFields(spec, file, root string) ([]Field, error) {
+	switch {
+	case spec != "" && file != "":
+		return nil, errors.New("--fields and --fields-file cannot be used together")
+	case spec != "":
+		return parseFields(spec)
+	case file != "":
+		if _, err := os.Stat(file); os.IsNotExist(err) && !filepath.IsAbs(file) && root != "" {
+			file = filepath.Join(root, file)
+		}
+		return loadFieldsFile(file)
+	}
+
+	return nil, nil
+}
+
+// RecordedEntities returns the generate entity and generate resource inputs recorded in
+// the manifest of the project at root, keyed by entity name, for the entities of domain.
+func RecordedEntities(root, domain string) (map[string]map[string]string, error) {
+	manifest, err := initshared.LoadManifest(root)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := map[string]map[string]string{}
+	for _, entry := range manifest.Files {
+		if (entry.Generator == "generate entity" || entry.Generator == "generate resource") && entry.Inputs["domain"] == domain {
+			entities[entry.Inputs["name"]] = entry.Inputs
+		}
+	}
+
+	return entities, nil
+}
+
+// RecordedEntity returns the fields and relations the entity was last generated with.
+// Both are nil when the entity was not generated with any.
+func RecordedEntity(root, domain, entity string) ([]Field, []Relation, error) {
+	entities, err := RecordedEntities(root, domain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inputs, ok := entities[entity]
+	if !ok {
+		return nil, nil, nil
+	}
+
+	fields, err := ResolveFields(inputs["fields"], inputs["fields-file"], root)
+	if err != nil {
+		return nil, nil, err
+	}
+	relations, err := RelationsFromInputs(inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fields, relations, nil
+}
+
+func (f *Field) parseType(spec string) error {
+	if strings.HasSuffix(spec, "?") {
+		f.Optional = true
+		spec = strings.TrimSuffix(spec, "?")
+	}
+
+	if strings.HasPrefix(spec, "enum(") && strings.HasSuffix(spec, ")") {
+		f.Type = "enum"
+		for _, value := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(spec, "enum("), ")"), "|") {
+			if value = strings.TrimSpace(value); value != "" {
+				f.Values = append(f.Values, value)
+			}
+		}
+		return nil
+	}
+
+	if _, ok := FieldTypes[spec]; !ok {
+		return errors.New("unknown type %q; expected one of %s or enum(a|b)", spec, strings.Join(fieldTypeNames(), ", "))
+	}
+	f.Type = spec
+	return nil
+}
+
+func (f *Field) applyModifier(modifier string) error {
+	name, value, hasValue := strings.Cut(modifier, "=")
+	switch {
+	case name == "required" && !hasValue:
+		f.Required = true
+	case name == "unique" && !hasValue:
+		f.Unique = true
+	case name == "index" && !hasValue:
+		f.Index = true
+	case name == "size" && hasValue:
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return errors.New("size must be a positive number, got %q", value)
+		}
+		f.Size = size
+	case name == "default" && hasValue:
+		f.Default = value
+	case name == "description" && hasValue:
+		f.Description = value
+	default:
+		return errors.New("unknown modifier %q; expected required, unique, index, size=N, default=V or description=D", modifier)
+	}
+
+	return nil
+}
+
+// validateColumns checks that no field is a column every entity declares already.
+func validateColumns(fields []Field) error {
+	for _, field := range fields {
+		if reservedFields[field.Name] {
+			return errors.New("field %s is declared by every entity already", field.Name)
+		}
+	}
+	return nil
+}
+
+func validateFields(fields []Field) error {
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if !IsValidSnakeCase(field.Name) {
+			return errors.New("field name %q must be in snake_case", field.Name)
+		}
+		if seen[field.Name] {
+			return errors.New("field %s is declared twice", field.Name)
+		}
+		seen[field.Name] = true
+
+		if field.IsEnum() {
+			if len(field.Values) == 0 {
+				return errors.New("enum field %s has no values", field.Name)
+			}
+			for _, value := range field.Values {
+				if !IsValidIdentifier(strings.ReplaceAll(value, "_", "")) {
+					return errors.New("enum value %q of field %s must be an identifier", value, field.Name)
+				}
+			}
+			if field.Default != "" && !contains(field.Values, field.Default) {
+				return errors.New("default %q of field %s is not one of its values", field.Default, field.Name)
+			}
+		}
+		if field.Type == "json" && (field.Unique || field.Index) {
+			return errors.New("json field %s cannot be unique or indexed", field.Name)
+		}
+		if field.Size > 0 && field.Type != "string" && !field.IsEnum() {
+			return errors.New("size applies to string and enum fields, not %s (field %s)", field.Type, field.Name)
+		}
+		if strings.ContainsAny(field.Description, "\"`") {
+			return errors.New("the description of field %s cannot contain quotes or backticks", field.Name)
+		}
+	}
+
+	return nil
+}
+
+// ApplyFields sets the fields of the entity and names the enum types after it.
+func (d *TemplateData) ApplyFields(fields []Field) {
+	d.Fields = fields
+	for i := range d.Fields {
+		if d.Fields[i].IsEnum() {
+			d.Fields[i].EnumType = d.EntityNameCamel + d.Fields[i].GoName()
+		}
+	}
+}
+
+// HasFieldType reports whether any field of the entity has the type fieldType.
+func (d TemplateData) HasFieldType(fieldType string) bool {
+	for _, field := range d.Fields {
+		if field.Type == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// FinderFields returns the unique and indexed fields, which repositories look up by.
+func (d TemplateData) FinderFields() []Field {
+	var finders []Field
+	for _, field := range d.Fields {
+		if field.Unique || field.Index {
+			finders = append(finders, field)
+		}
+	}
+	return finders
+}
+
+// HasFinderType reports whether any of FinderFields has the type fieldType.
+func (d TemplateData) HasFinderType(fieldType string) bool {
+	for _, field := range d.FinderFields() {
+		if field.Type == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// SampleAssignments returns the "Name: value" pairs setting sample values, as of
+// Field.SampleValue, on the columns of the entity generated tests must set: the required
+// fields, or the default Name and Description without fields, and the foreign keys of
+// the entities it belongs to. Optional fields are left unset. With an empty
+// entitiesPackage they set the request models rather than the entity.
+func (d TemplateData) SampleAssignments(n int, entitiesPackage string) []string {
+	var assignments []string
+	if len(d.Fields) == 0 {
+		assignments = append(assignments,
+			fmt.Sprintf("Name: %q", "name-"+strconv.Itoa(n)),
+			fmt.Sprintf("Description: %q", "description-"+strconv.Itoa(n)))
+	}
+	for _, field := range d.Fields {
+		if !field.Optional {
+			assignments = append(assignments, field.GoName()+": "+field.SampleValue(n, entitiesPackage))
+		}
+	}
+	for _, relation := range d.RelationsOf(BelongsTo) {
+		if !relation.Self() {
+			assignments = append(assignments, relation.ForeignKey()+": uid.New()")
+		}
+	}
+
+	return assignments
+}
+
+// HasSampleType reports whether SampleAssignments sets a field of the type fieldType.
+func (d TemplateData) HasSampleType(fieldType string) bool {
+	for _, field := range d.Fields {
+		if field.Type == fieldType && !field.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldsSpec formats fields back into a --fields specification.
+func FieldsSpec(fields []Field) string {
+	entries := make([]string, len(fields))
+	for i, f := range fields {
+		fieldType := f.Type
+		if f.IsEnum() {
+			fieldType = "enum(" + strings.Join(f.Values, "|") + ")"
+		}
+		if f.Optional {
+			fieldType += "?"
+		}
+
+		parts := []string{f.Name, fieldType}
+		if f.Required {
+			parts = append(parts, "required")
+		}
+		if f.Unique {
+			parts = append(parts, "unique")
+		}
+		if f.Index {
+			parts = append(parts, "index")
+		}
+		if f.Size > 0 {
+			parts = append(parts, "size="+strconv.Itoa(f.Size))
+		}
+		if f.Default != "" {
+			parts = append(parts, "default="+f.Default)
+		}
+		if f.Description != "" {
+			parts = append(parts, "description="+f.Description)
+		}
+		entries[i] = strings.Join(parts, ":")
+	}
+
+	return strings.Join(entries, ",")
+}
+
+func fieldTypeNames() []string {
+	names := make([]string, 0, len(FieldTypes))
+	for name := range FieldTypes {
+		if name != "enum" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func splitOutsideParens(s string, sep rune) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
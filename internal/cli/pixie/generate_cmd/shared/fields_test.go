@@ -0,0 +1,298 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("title:string:required:size=200, price:decimal,published_at:time?,status:enum(draft|live):default=draft,sku:string:unique")
+	if err != nil {
+		t.Fatalf("ParseFields() error = %v", err)
+	}
+
+	want := []Field{
+		{Name: "title", Type: "string", Required: true, Size: 200},
+		{Name: "price", Type: "decimal"},
+		{Name: "published_at", Type: "time", Optional: true},
+		{Name: "status", Type: "enum", Values: []string{"draft", "live"}, Default: "draft"},
+		{Name: "sku", Type: "string", Unique: true},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("ParseFields() =\n%+v\nwant\n%+v", fields, want)
+	}
+
+	if got, spec := FieldsSpec(fields), "title:string:required:size=200,price:decimal,published_at:time?,status:enum(draft|live):default=draft,sku:string:unique"; got != spec {
+		t.Errorf("FieldsSpec() = %q, want %q", got, spec)
+	}
+}
+
+func TestParseFieldsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"missing type", "title"},
+		{"unknown type", "title:varchar"},
+		{"unknown modifier", "title:string:primary"},
+		{"bad size", "title:string:size=abc"},
+		{"size on int", "count:int:size=10"},
+		{"reserved name", "created_at:time"},
+		{"duplicate", "title:string,title:text"},
+		{"not snake_case", "Title:string"},
+		{"empty enum", "status:enum()"},
+		{"enum default not a value", "status:enum(draft|live):default=gone"},
+		{"indexed json", "payload:json:index"},
+		{"quoted description", `title:string:description=the "title"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseFields(tt.spec); err == nil {
+				t.Errorf("ParseFields(%q) error = nil, want an error", tt.spec)
+			}
+		})
+	}
+}
+
+func TestFieldDescription(t *testing.T) {
+	fields, err := ParseFields("order_id:uid:required:description=Order being placed,total:decimal")
+	if err != nil {
+		t.Fatalf("ParseFields() error = %v", err)
+	}
+
+	if got, want := fields[0].FieldDescription(), "Order being placed"; got != want {
+		t.Errorf("FieldDescription() = %q, want %q", got, want)
+	}
+	if got, want := fields[1].FieldDescription(), "Total"; got != want {
+		t.Errorf("FieldDescription() = %q, want %q", got, want)
+	}
+	if got, want := FieldsSpec(fields), "order_id:uid:required:description=Order being placed,total:decimal"; got != want {
+		t.Errorf("FieldsSpec() = %q, want %q", got, want)
+	}
+}
+
+func TestFieldNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		goName  string
+		varName string
+	}{
+		{"published_at", "PublishedAt", "publishedAt"},
+		{"external_id", "ExternalID", "externalID"},
+		{"image_url", "ImageURL", "imageURL"},
+		{"http_status", "HTTPStatus", "httpStatus"},
+		{"api_key", "APIKey", "apiKey"},
+		{"json_payload", "JSONPayload", "jsonPayload"},
+		{"uuid", "UUID", "uuid"},
+		{"type", "Type", "typeValue"},
+	}
+
+	for _, tt := range tests {
+		field := Field{Name: tt.name}
+		if got := field.GoName(); got != tt.goName {
+			t.Errorf("Field{%q}.GoName() = %q, want %q", tt.name, got, tt.goName)
+		}
+		if got := field.VarName(); got != tt.varName {
+			t.Errorf("Field{%q}.VarName() = %q, want %q", tt.name, got, tt.varName)
+		}
+	}
+}
+
+func TestResolvePayloadFields(t *testing.T) {
+	fields, err := ResolvePayloadFields("order_id:uid:required,user_id:uid,placed_at:time", "", "")
+	if err != nil {
+		t.Fatalf("ResolvePayloadFields() error = %v", err)
+	}
+	if len(fields) != 3 || fields[1].Name != "user_id" {
+		t.Errorf("ResolvePayloadFields() = %+v, want order_id, user_id and placed_at", fields)
+	}
+
+	for _, spec := range []string{"sku:string:unique", "status:enum(new|paid):default=new", "title"} {
+		if _, err := ResolvePayloadFields(spec, "", ""); err == nil {
+			t.Errorf("ResolvePayloadFields(%q) error = nil, want an error", spec)
+		}
+	}
+}
+
+func TestFieldTags(t *testing.T) {
+	tests := []struct {
+		field        Field
+		wantGoType   string
+		wantGorm     string
+		wantJSON     string
+		wantValidate string
+	}{
+		{
+			field:        Field{Name: "title", Type: "string", Required: true, Size: 200},
+			wantGoType:   "string",
+			wantGorm:     "not null;size:200",
+			wantJSON:     "title",
+			wantValidate: "required,max=200",
+		},
+		{
+			field:      Field{Name: "price", Type: "decimal"},
+			wantGoType: "float64",
+			wantGorm:   "not null;type:numeric(20,4)",
+			wantJSON:   "price",
+		},
+		{
+			field:      Field{Name: "published_at", Type: "time", Optional: true},
+			wantGoType: "*time.Time",
+			wantGorm:   "",
+			wantJSON:   "published_at,omitempty",
+		},
+		{
+			field:        Field{Name: "status", Type: "enum", Values: []string{"draft", "live"}, Default: "draft", EnumType: "ProductStatus"},
+			wantGoType:   "ProductStatus",
+			wantGorm:     "not null;size:5;default:'draft'",
+			wantJSON:     "status",
+			wantValidate: "oneof=draft live",
+		},
+		{
+			field:        Field{Name: "sku", Type: "string", Optional: true, Unique: true},
+			wantGoType:   "*string",
+			wantGorm:     "size:255;uniqueIndex",
+			wantJSON:     "sku,omitempty",
+			wantValidate: "omitempty,max=255",
+		},
+		{
+			field:      Field{Name: "payload", Type: "json", Optional: true},
+			wantGoType: "json.RawMessage",
+			wantGorm:   "type:jsonb",
+			wantJSON:   "payload,omitempty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field.Name, func(t *testing.T) {
+			if got := tt.field.GoType(); got != tt.wantGoType {
+				t.Errorf("GoType() = %q, want %q", got, tt.wantGoType)
+			}
+			if got := tt.field.GormTag(); got != tt.wantGorm {
+				t.Errorf("GormTag() = %q, want %q", got, tt.wantGorm)
+			}
+			if got := tt.field.JSONTag(); got != tt.wantJSON {
+				t.Errorf("JSONTag() = %q, want %q", got, tt.wantJSON)
+			}
+			if got := tt.field.ValidateTag(); got != tt.wantValidate {
+				t.Errorf("ValidateTag() = %q, want %q", got, tt.wantValidate)
+			}
+		})
+	}
+}
+
+func TestLoadFieldsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "product.fields.yaml")
+	content := `fields:
+  - name: title
+    type: string
+    required: true
+    size: 200
+  - name: published_at
+    type: time?
+  - name: status
+    type: enum
+    values: [draft, live]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	fields, err := LoadFieldsFile(path)
+	if err != nil {
+		t.Fatalf("LoadFieldsFile() error = %v", err)
+	}
+
+	want := []Field{
+		{Name: "title", Type: "string", Required: true, Size: 200},
+		{Name: "published_at", Type: "time", Optional: true},
+		{Name: "status", Type: "enum", Values: []string{"draft", "live"}},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("LoadFieldsFile() =\n%+v\nwant\n%+v", fields, want)
+	}
+}
+
+func TestApplyFields(t *testing.T) {
+	fields, err := ParseFields("status:enum(draft|in_review),sku:string:unique,published_at:time:index")
+	if err != nil {
+		t.Fatalf("ParseFields() error = %v", err)
+	}
+
+	data := NewTemplateData()
+	data.EntityNameCamel = "Product"
+	data.ApplyFields(fields)
+
+	status := data.Fields[0]
+	if status.EnumType != "ProductStatus" {
+		t.Errorf("EnumType = %q, want ProductStatus", status.EnumType)
+	}
+	if got := status.EnumConstant("in_review"); got != "ProductStatusInReview" {
+		t.Errorf("EnumConstant() = %q, want ProductStatusInReview", got)
+	}
+	if got := status.ValueGoType("catalog_entities"); got != "catalog_entities.ProductStatus" {
+		t.Errorf("ValueGoType() = %q, want catalog_entities.ProductStatus", got)
+	}
+	if got := status.FromModel("req.Status", "catalog_entities"); got != "catalog_entities.ProductStatus(req.Status)" {
+		t.Errorf("FromModel() = %q", got)
+	}
+	status.Optional = true
+	if got := status.ToModel("entity.Status"); got != "(*string)(entity.Status)" {
+		t.Errorf("ToModel() = %q", got)
+	}
+	if got := len(data.FinderFields()); got != 2 {
+		t.Errorf("len(FinderFields()) = %d, want 2", got)
+	}
+	if !data.HasFinderType("time") || data.HasFieldType("json") {
+		t.Errorf("HasFinderType(time) = %v, HasFieldType(json) = %v", data.HasFinderType("time"), data.HasFieldType("json"))
+	}
+}
+
+func TestSampleValues(t *testing.T) {
+	fields, err := ParseFields("code:string:unique:size=4,title:string,price:decimal,active:bool,status:enum(draft|live),published_at:time?")
+	if err != nil {
+		t.Fatalf("ParseFields() error = %v", err)
+	}
+
+	data := NewTemplateData()
+	data.EntityNameCamel = "Product"
+	data.ApplyFields(fields)
+
+	tests := []struct {
+		field    int
+		n        int
+		entities string
+		want     string
+	}{
+		{0, 1, "", `"de-1"`},
+		{1, 2, "", `"title-2"`},
+		{2, 1, "", "1.5"},
+		{3, 2, "", "true"},
+		{4, 1, "", `"live"`},
+		{4, 2, "catalog_entities", "catalog_entities.ProductStatusDraft"},
+	}
+	for _, tt := range tests {
+		if got := data.Fields[tt.field].SampleValue(tt.n, tt.entities); got != tt.want {
+			t.Errorf("%s.SampleValue(%d, %q) = %s, want %s", data.Fields[tt.field].Name, tt.n, tt.entities, got, tt.want)
+		}
+	}
+
+	relations, err := ParseRelations(BelongsTo, "category")
+	if err != nil {
+		t.Fatalf("ParseRelations() error = %v", err)
+	}
+	if err := data.ApplyRelations(relations); err != nil {
+		t.Fatalf("ApplyRelations() error = %v", err)
+	}
+
+	want := []string{`Code: "de-1"`, `Title: "title-1"`, "Price: 1.5", "Active: true", `Status: "live"`, "CategoryID: uid.New()"}
+	if got := data.SampleAssignments(1, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("SampleAssignments() = %q, want %q", got, want)
+	}
+	if data.HasSampleType("time") || !data.HasSampleType("bool") {
+		t.Errorf("HasSampleType(time) = %v, HasSampleType(bool) = %v", data.HasSampleType("time"), data.HasSampleType("bool"))
+	}
+}
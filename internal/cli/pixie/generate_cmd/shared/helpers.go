@@ -2,11 +2,26 @@ package shared
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/features"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/models"
 )
 
-// TemplateData holds the data passed to scaffold templates.
+// initialisms are the words Go names spell in upper case.
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true,
+	"json": true, "sql": true, "url": true, "uuid": true,
+}
+
+// TemplateData holds the data passed to scaffold templates. Templates can derive other
+// casings and plurals with the functions of initshared.TemplateFuncs; the *Camel fields
+// are kept for existing templates and equal {{pascal .X}} for snake_case names.
 type TemplateData struct {
 	// Basic information
 	ServiceName         string // user_management (for microservices) or custom service name
@@ -19,6 +34,54 @@ type TemplateData struct {
 	EntityName          string // For entities: custom name or domain name
 	EntityNameCamel     string // CamelCase version of EntityName
 
+	// Fields of the entity from --fields, set by ApplyFields; empty keeps the default fields
+	Fields []Field
+	// Relations of the entity, set by ApplyRelations
+	Relations []Relation
+	// Pagination of the repository List query: PaginationOffset or PaginationCursor
+	Pagination string
+
+	// HTTP controllers of a resource: the route path under the domain group and the
+	// httpControllers fields holding the domain business layer and the authorization gates
+	ResourcePath            string // products
+	ControllerBusinessLayer string // businessLayer
+	ControllerGates         string // gates; empty when the routes are not gated
+	ControllerAdapters      bool   // the domain business layer is constructed with its adapters
+
+	// Domain event: the event name, its description and the Fields of its payload
+	EventName        string // order_placed
+	EventNameCamel   string // OrderPlaced
+	EventDescription string // event_description tag of the payload
+
+	// Authorization gate: the gate name and what it checks
+	GateName        string // has_active_subscription
+	GateNameCamel   string // HasActiveSubscription
+	GateDescription string // checks that the user has an active subscription
+
+	// Package names and import paths (computed from the generator config by ApplyLayout)
+	BusinessLayerPackage string // users_business_layer
+	MicroservicePackage  string // ms_user_management
+	DomainImport         string // github.com/company/my-project/internal/domain/users
+	BusinessLayerImport  string // <DomainImport>/users_business_layer
+	DataLayerImport      string // <DomainImport>/users_data_layer
+	EntitiesImport       string // <DataLayerImport>/users_entities
+	RepositoriesImport   string // <DataLayerImport>/users_repositories
+	MigrationsImport     string // <DataLayerImport>/users_migrations
+	ServicesImport       string // <DomainImport>/users_services
+	ModelsImport         string // github.com/company/my-project/pkg/models/users
+	NotificationsImport  string // github.com/company/my-project/internal/domain/notifications
+	AdaptersImport       string // github.com/company/my-project/internal/adapters/users_adapters
+	MicroserviceImport   string // github.com/company/my-project/internal/ms/ms_user_management
+	BundlesImport        string // github.com/company/my-project/bundles
+	DIImport             string // github.com/company/my-project/infra/di
+	APIsImport           string // github.com/company/my-project/infra/apis
+	MessagePacksImport   string // github.com/company/my-project/infra/message_packs
+	EventImport          string // github.com/company/my-project/infra/event
+	GatesImport          string // github.com/company/my-project/infra/gates
+	HTTPContextImport    string // github.com/company/my-project/pkg/context/http
+	SessionModelsImport  string // github.com/company/my-project/pkg/models/session_manager_models
+	TestDBImport         string // github.com/company/my-project/infra/testdb
+
 	// Features
 	Features map[string]bool // Feature flags
 
@@ -28,7 +91,7 @@ type TemplateData struct {
 	Timestamp          string // Generation timestamp
 	MigrationTimestamp string // Migration timestamp for DB migrations
 
-	// Database configuration (with defaults)
+	// Database configuration (from the runtime config)
 	DatabaseHost                  string
 	DatabasePort                  int
 	DatabaseName                  string
@@ -39,47 +102,123 @@ type TemplateData struct {
 	DatabaseMaxIdleConnections    int
 	DatabaseConnectionMaxLifetime string
 
-	// Redis configuration (with defaults)
+	// Redis configuration (from the runtime config)
 	RedisHost     string
 	RedisPort     int
 	RedisPassword string
 	RedisDatabase int
 
-	// Security configuration (with defaults)
+	// Security configuration (from the runtime config; empty unless configured)
 	JWTSecretKey string
 	AdminAPIKey  string
 }
 
-// NewTemplateData creates a TemplateData with timestamp defaults populated.
+// NewTemplateData creates a TemplateData with timestamp and runtime defaults populated.
+// Secrets stay empty until ApplyRuntime is called with a configured runtime.
 func NewTemplateData() TemplateData {
-	return TemplateData{
+	data := TemplateData{
 		Features:           make(map[string]bool),
 		Port:               8080,
 		MetricsPort:        9090,
 		Timestamp:          time.Now().Format(time.RFC3339),
 		MigrationTimestamp: fmt.Sprintf("%d", time.Now().Unix()),
+		DatabaseName:       "app_database",
+		Pagination:         PaginationOffset,
+	}
+	data.ApplyRuntime(models.DefaultCLIConfig())
+
+	return data
+}
+
+// ApplyRuntime copies the runtime configuration into the template values.
+// An empty database name keeps the current one.
+func (d *TemplateData) ApplyRuntime(runtimeCfg models.CLIConfig) {
+	d.DatabaseHost = runtimeCfg.Database.Host
+	d.DatabasePort = runtimeCfg.Database.Port
+	if runtimeCfg.Database.Name != "" {
+		d.DatabaseName = runtimeCfg.Database.Name
+	}
+	d.DatabaseUsername = runtimeCfg.Database.Username
+	d.DatabasePassword = runtimeCfg.Database.Password
+	d.DatabaseSSLMode = runtimeCfg.Database.SSLMode
+	d.DatabaseMaxOpenConnections = runtimeCfg.Database.MaxOpenConnections
+	d.DatabaseMaxIdleConnections = runtimeCfg.Database.MaxIdleConnections
+	d.DatabaseConnectionMaxLifetime = runtimeCfg.Database.ConnectionMaxLifetime
+
+	d.RedisHost = runtimeCfg.Redis.Host
+	d.RedisPort = runtimeCfg.Redis.Port
+	d.RedisPassword = runtimeCfg.Redis.Password
+	d.RedisDatabase = runtimeCfg.Redis.Database
+
+	d.JWTSecretKey = runtimeCfg.Security.JWTSecretKey
+	d.AdminAPIKey = runtimeCfg.Security.AdminAPIKey
+}
+
+// ApplyLayout computes the package names and import paths of every layer from the
+// directories in cfg. ModuleName, DomainName and ServiceName must be set first.
+func (d *TemplateData) ApplyLayout(cfg GeneratorConfig) {
+	d.BusinessLayerPackage = d.DomainName + cfg.BusinessLayerSuffix
+	d.MicroservicePackage = cfg.MicroservicePrefix + d.ServiceName
+
+	d.DomainImport = ImportPath(d.ModuleName, cfg.DomainDir, d.DomainName)
+	d.BusinessLayerImport = d.DomainImport + "/" + d.BusinessLayerPackage
+	d.DataLayerImport = d.DomainImport + "/" + d.DomainName + "_data_layer"
+	d.EntitiesImport = d.DataLayerImport + "/" + d.DomainName + "_entities"
+	d.RepositoriesImport = d.DataLayerImport + "/" + d.DomainName + "_repositories"
+	d.MigrationsImport = d.DataLayerImport + "/" + d.DomainName + "_migrations"
+	d.ServicesImport = d.DomainImport + "/" + d.DomainName + "_services"
+	d.ModelsImport = ImportPath(d.ModuleName, cfg.ModelsDir, d.DomainName)
+	d.NotificationsImport = ImportPath(d.ModuleName, cfg.DomainDir, "notifications")
+	d.AdaptersImport = ImportPath(d.ModuleName, cfg.AdaptersDir, d.DomainName+"_adapters")
+	d.MicroserviceImport = ImportPath(d.ModuleName, cfg.MicroserviceDir, d.MicroservicePackage)
+	d.BundlesImport = ImportPath(d.ModuleName, cfg.BundlesDir)
+	d.DIImport = ImportPath(d.ModuleName, cfg.InfraDir, "di")
+	d.APIsImport = ImportPath(d.ModuleName, cfg.InfraDir, "apis")
+	d.MessagePacksImport = ImportPath(d.ModuleName, cfg.InfraDir, "message_packs")
+	d.EventImport = ImportPath(d.ModuleName, cfg.InfraDir, "event")
+	d.GatesImport = ImportPath(d.ModuleName, cfg.InfraDir, "gates")
+	d.HTTPContextImport = ImportPath(d.ModuleName, cfg.ContextDir, "http")
+	d.SessionModelsImport = ImportPath(d.ModuleName, cfg.ModelsDir, "session_manager_models")
+	d.TestDBImport = ImportPath(d.ModuleName, cfg.InfraDir, "testdb")
+}
+
+// ImportPath returns the import path of the package in the project-relative directory
+// dirs joined, within module.
+func ImportPath(module string, dirs ...string) string {
+	joined := path.Clean(filepath.ToSlash(filepath.Join(dirs...)))
+	if joined == "." {
+		return module
+	}
+
+	return module + "/" + strings.TrimPrefix(joined, "/")
+}
+
+// GoName returns the exported Go name of s, spelling initialisms in upper case:
+// CustomerID for customer_id or customerId.
+func GoName(s string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(initshared.Snake(s), "_") {
+		if initialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+		} else {
+			b.WriteString(initshared.Pascal(word))
+		}
+	}
+	return b.String()
+}
 
-		// Database defaults
-		DatabaseHost:                  "localhost",
-		DatabasePort:                  5432,
-		DatabaseName:                  "app_database",
-		DatabaseUsername:              "postgres",
-		DatabasePassword:              "password",
-		DatabaseSSLMode:               "disable",
-		DatabaseMaxOpenConnections:    100,
-		DatabaseMaxIdleConnections:    10,
-		DatabaseConnectionMaxLifetime: "1h",
-
-		// Redis defaults
-		RedisHost:     "localhost",
-		RedisPort:     6379,
-		RedisPassword: "",
-		RedisDatabase: 0,
-
-		// Security defaults
-		JWTSecretKey: "your-secret-key",
-		AdminAPIKey:  "admin-api-key",
+// GoVarName returns the unexported Go name of s, lowering its leading initialism:
+// orderID for order_id, id for id, urlPath for url_path.
+func GoVarName(s string) string {
+	runes := []rune(GoName(s))
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
 	}
+	if upper > 1 && upper < len(runes) {
+		upper--
+	}
+	return strings.ToLower(string(runes[:upper])) + string(runes[upper:])
 }
 
 // IsValidSnakeCase checks whether s is a valid snake_case identifier.
@@ -109,71 +248,47 @@ func IsValidIdentifier(s string) bool {
 }
 
 // ParseFeatures parses a comma-separated feature string and applies template defaults.
-func ParseFeatures(featureStr, templateType string) map[string]bool {
-	features := make(map[string]bool)
+// Unknown feature names are an error.
+func ParseFeatures(featureStr, templateType string) (map[string]bool, error) {
+	enabled := make(map[string]bool)
 
 	// Apply template defaults
 	switch templateType {
 	case "minimal":
-		features["metrics"] = true
+		enabled["metrics"] = true
 	case "standard":
-		features["database"] = true
-		features["metrics"] = true
+		enabled["database"] = true
+		enabled["metrics"] = true
 	case "full":
-		features["database"] = true
-		features["metrics"] = true
-		features["auth"] = true
-		features["cache"] = true
-		features["tokens"] = true
-		features["events"] = true
-		features["notifications"] = true
-		features["backoffice"] = true
-		features["validation"] = true
-		features["adapters"] = true
-		features["apis"] = true
+		enabled["database"] = true
+		enabled["metrics"] = true
+		enabled["auth"] = true
+		enabled["cache"] = true
+		enabled["tokens"] = true
+		enabled["events"] = true
+		enabled["notifications"] = true
+		enabled["backoffice"] = true
+		enabled["validation"] = true
+		enabled["adapters"] = true
+		enabled["apis"] = true
 	}
 
 	// Parse user-specified features
-	if featureStr != "" {
-		for _, feature := range strings.Split(featureStr, ",") {
-			feature = strings.TrimSpace(feature)
-			if feature != "" {
-				features[feature] = true
-			}
-		}
+	names, err := features.Parse(featureStr)
+	if err != nil {
+		return nil, err
 	}
-
-	return features
-}
-
-// ResolveFeatureDependencies ensures all required feature dependencies are satisfied.
-func ResolveFeatureDependencies(features map[string]bool) map[string]bool {
-	dependencies := map[string][]string{
-		"auth":          {"tokens"},
-		"backoffice":    {"auth"},
-		"events":        {"cache"},
-		"notifications": {"events"},
-		"google_oauth":  {"auth"},
+	for _, name := range names {
+		enabled[name] = true
 	}
 
-	for {
-		added := false
-		for feature, deps := range dependencies {
-			if features[feature] {
-				for _, dep := range deps {
-					if !features[dep] {
-						features[dep] = true
-						added = true
-					}
-				}
-			}
-		}
-		if !added {
-			break
-		}
-	}
+	return enabled, nil
+}
 
-	return features
+// ResolveFeatureDependencies enables the features the enabled ones require and checks
+// them for conflicts, as declared in the feature registry.
+func ResolveFeatureDependencies(enabled map[string]bool) (map[string]bool, error) {
+	return features.Resolve(enabled)
 }
 
 // EnabledFeatures returns a sorted list of enabled feature names.
@@ -2,7 +2,10 @@ package shared
 
 import (
 	"sort"
+	"strings"
 	"testing"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/models"
 )
 
 func TestIsValidSnakeCase(t *testing.T) {
@@ -71,6 +74,7 @@ func TestParseFeatures(t *testing.T) {
 		featureStr   string
 		templateType string
 		wantKeys     []string
+		wantErr      string
 	}{
 		{
 			name:         "minimal template",
@@ -91,10 +95,16 @@ func TestParseFeatures(t *testing.T) {
 			wantKeys:     []string{"database", "metrics", "auth", "cache", "tokens", "events", "notifications", "backoffice", "validation", "adapters", "apis"},
 		},
 		{
-			name:         "custom features override",
+			name:         "unknown feature",
 			featureStr:   "custom1, custom2",
 			templateType: "minimal",
-			wantKeys:     []string{"metrics", "custom1", "custom2"},
+			wantErr:      `unknown feature "custom1"`,
+		},
+		{
+			name:         "misspelled feature",
+			featureStr:   "auth,databse",
+			templateType: "",
+			wantErr:      `unknown feature "databse" (did you mean "database"?)`,
 		},
 		{
 			name:         "no template type with features",
@@ -118,7 +128,16 @@ func TestParseFeatures(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := ParseFeatures(tt.featureStr, tt.templateType)
+			got, err := ParseFeatures(tt.featureStr, tt.templateType)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseFeatures() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFeatures() error = %v", err)
+			}
 
 			if tt.wantKeys == nil {
 				if len(got) != 0 {
@@ -176,7 +195,10 @@ func TestResolveFeatureDependencies(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := ResolveFeatureDependencies(tt.input)
+			got, err := ResolveFeatureDependencies(tt.input)
+			if err != nil {
+				t.Fatalf("ResolveFeatureDependencies() error = %v", err)
+			}
 
 			for _, key := range tt.wantKeys {
 				if !got[key] {
@@ -271,3 +293,176 @@ func TestNewTemplateData(t *testing.T) {
 		t.Errorf("RedisPort = %d, want 6379", td.RedisPort)
 	}
 }
+
+func TestNewTemplateData_NoPlaceholderSecrets(t *testing.T) {
+	td := NewTemplateData()
+
+	secrets := map[string]string{
+		"DatabasePassword": td.DatabasePassword,
+		"RedisPassword":    td.RedisPassword,
+		"JWTSecretKey":     td.JWTSecretKey,
+		"AdminAPIKey":      td.AdminAPIKey,
+	}
+	for name, value := range secrets {
+		if value != "" {
+			t.Errorf("%s = %q, want empty", name, value)
+		}
+	}
+}
+
+func TestTemplateDataApplyRuntime(t *testing.T) {
+	runtimeCfg := models.DefaultCLIConfig()
+	runtimeCfg.Database.Host = "db.internal"
+	runtimeCfg.Database.Port = 6543
+	runtimeCfg.Database.Password = "s3cret"
+	runtimeCfg.Redis.Database = 3
+	runtimeCfg.Security.JWTSecretKey = "jwt-key"
+
+	td := NewTemplateData()
+	td.ApplyRuntime(runtimeCfg)
+
+	if td.DatabaseHost != "db.internal" {
+		t.Errorf("DatabaseHost = %q, want db.internal", td.DatabaseHost)
+	}
+	if td.DatabasePort != 6543 {
+		t.Errorf("DatabasePort = %d, want 6543", td.DatabasePort)
+	}
+	if td.DatabaseName != "app_database" {
+		t.Errorf("DatabaseName = %q, want app_database to be kept when the config leaves it empty", td.DatabaseName)
+	}
+	if td.DatabasePassword != "s3cret" {
+		t.Errorf("DatabasePassword = %q, want s3cret", td.DatabasePassword)
+	}
+	if td.RedisDatabase != 3 {
+		t.Errorf("RedisDatabase = %d, want 3", td.RedisDatabase)
+	}
+	if td.JWTSecretKey != "jwt-key" {
+		t.Errorf("JWTSecretKey = %q, want jwt-key", td.JWTSecretKey)
+	}
+}
+
+func TestTemplateDataApplyLayout(t *testing.T) {
+	custom := DefaultConfig()
+	custom.DomainDir = "app/domains/"
+	custom.ModelsDir = "api/models"
+	custom.MicroserviceDir = "services"
+	custom.MicroservicePrefix = "svc_"
+	custom.BusinessLayerSuffix = "_logic"
+	custom.BundlesDir = "platform/bundles"
+	custom.InfraDir = "platform"
+
+	tests := []struct {
+		name string
+		cfg  GeneratorConfig
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			cfg:  DefaultConfig(),
+			want: map[string]string{
+				"BusinessLayerPackage": "users_business_layer",
+				"MicroservicePackage":  "ms_user_management",
+				"BusinessLayerImport":  "example.com/demo/internal/domain/users/users_business_layer",
+				"EntitiesImport":       "example.com/demo/internal/domain/users/users_data_layer/users_entities",
+				"ModelsImport":         "example.com/demo/pkg/models/users",
+				"MicroserviceImport":   "example.com/demo/internal/ms/ms_user_management",
+				"BundlesImport":        "example.com/demo/bundles",
+				"DIImport":             "example.com/demo/infra/di",
+				"HTTPContextImport":    "example.com/demo/pkg/context/http",
+			},
+		},
+		{
+			name: "custom layout",
+			cfg:  custom,
+			want: map[string]string{
+				"BusinessLayerPackage": "users_logic",
+				"MicroservicePackage":  "svc_user_management",
+				"BusinessLayerImport":  "example.com/demo/app/domains/users/users_logic",
+				"EntitiesImport":       "example.com/demo/app/domains/users/users_data_layer/users_entities",
+				"NotificationsImport":  "example.com/demo/app/domains/notifications",
+				"ModelsImport":         "example.com/demo/api/models/users",
+				"MicroserviceImport":   "example.com/demo/services/svc_user_management",
+				"BundlesImport":        "example.com/demo/platform/bundles",
+				"DIImport":             "example.com/demo/platform/di",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := NewTemplateData()
+			td.ModuleName = "example.com/demo"
+			td.DomainName = "users"
+			td.ServiceName = "user_management"
+			td.ApplyLayout(tt.cfg)
+
+			got := map[string]string{
+				"BusinessLayerPackage": td.BusinessLayerPackage,
+				"MicroservicePackage":  td.MicroservicePackage,
+				"BusinessLayerImport":  td.BusinessLayerImport,
+				"EntitiesImport":       td.EntitiesImport,
+				"NotificationsImport":  td.NotificationsImport,
+				"ModelsImport":         td.ModelsImport,
+				"MicroserviceImport":   td.MicroserviceImport,
+				"BundlesImport":        td.BundlesImport,
+				"DIImport":             td.DIImport,
+				"HTTPContextImport":    td.HTTPContextImport,
+			}
+			for field, want := range tt.want {
+				if got[field] != want {
+					t.Errorf("%s = %q, want %q", field, got[field], want)
+				}
+			}
+		})
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	tests := []struct {
+		dirs []string
+		want string
+	}{
+		{[]string{"internal/domain", "users"}, "example.com/demo/internal/domain/users"},
+		{[]string{"./bundles/"}, "example.com/demo/bundles"},
+		{[]string{""}, "example.com/demo"},
+	}
+
+	for _, tt := range tests {
+		if got := ImportPath("example.com/demo", tt.dirs...); got != tt.want {
+			t.Errorf("ImportPath(%q) = %q, want %q", tt.dirs, got, tt.want)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"id":          "ID",
+		"customer_id": "CustomerID",
+		"customerId":  "CustomerID",
+		"avatar_url":  "AvatarURL",
+		"createdAt":   "CreatedAt",
+		"order-items": "OrderItems",
+	}
+
+	for input, want := range tests {
+		if got := GoName(input); got != want {
+			t.Errorf("GoName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestGoVarName(t *testing.T) {
+	tests := map[string]string{
+		"id":         "id",
+		"order_id":   "orderID",
+		"url_path":   "urlPath",
+		"status":     "status",
+		"customerId": "customerID",
+	}
+
+	for input, want := range tests {
+		if got := GoVarName(input); got != want {
+			t.Errorf("GoVarName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package shared
+
+import (
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// Paginations of the List query of generated repositories.
+const (
+	PaginationOffset = "offset" // ?page and ?page_size through the operators pipeline
+	PaginationCursor = "cursor" // ?cursor and ?page_size on created_at, id
+)
+
+// Paginations lists the pagination styles, the default first.
+var Paginations = []string{PaginationOffset, PaginationCursor}
+
+// ParsePagination validates a --pagination value; empty selects the default.
+func ParsePagination(pagination string) (string, error) {
+	if pagination == "" {
+		return PaginationOffset, nil
+	}
+	for _, p := range Paginations {
+		if pagination == p {
+			return pagination, nil
+		}
+	}
+
+	return "", errors.New("unknown pagination %q; expected one of %s", pagination, strings.Join(Paginations, ", "))
+}
+
+// SortColumn is a column the List query sorts by.
+type SortColumn struct {
+	Name string
+	Type string // type of the operators SearchableProperty: text, number or date
+}
+
+// FilterColumns returns the columns the List query filters on, each from the query
+// parameter of the same name: enums, booleans, integers, uids, unique or indexed
+// strings and belongs-to foreign keys.
+func (d TemplateData) FilterColumns() []string {
+	var columns []string
+	for _, field := range d.Fields {
+		switch field.Type {
+		case "enum", "bool", "int", "int64", "uid":
+			columns = append(columns, field.Name)
+		case "string":
+			if field.Unique || field.Index {
+				columns = append(columns, field.Name)
+			}
+		}
+	}
+	for _, relation := range d.RelationsOf(BelongsTo) {
+		columns = append(columns, relation.ForeignKeyColumn())
+	}
+
+	return columns
+}
+
+// SearchColumns returns the text columns the search query parameter matches.
+func (d TemplateData) SearchColumns() []string {
+	if len(d.Fields) == 0 {
+		return []string{"name", "description"}
+	}
+
+	var columns []string
+	for _, field := range d.Fields {
+		if field.Type == "string" || field.Type == "text" {
+			columns = append(columns, field.Name)
+		}
+	}
+	return columns
+}
+
+// SortColumns returns the columns the List query sorts by, ending with the timestamps.
+func (d TemplateData) SortColumns() []SortColumn {
+	var columns []SortColumn
+	if len(d.Fields) == 0 {
+		columns = append(columns, SortColumn{Name: "name", Type: "text"})
+	}
+	for _, field := range d.Fields {
+		switch field.Type {
+		case "string", "enum":
+			columns = append(columns, SortColumn{Name: field.Name, Type: "text"})
+		case "int", "int64", "float", "decimal":
+			columns = append(columns, SortColumn{Name: field.Name, Type: "number"})
+		case "time":
+			columns = append(columns, SortColumn{Name: field.Name, Type: "date"})
+		}
+	}
+
+	return append(columns,
+		SortColumn{Name: "created_at", Type: "date"},
+		SortColumn{Name: "updated_at", Type: "date"})
+}
+
+// CursorPagination reports whether the List query pages with a cursor.
+func (d TemplateData) CursorPagination() bool {
+	return d.Pagination == PaginationCursor
+}
@@ -0,0 +1,73 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", PaginationOffset, false},
+		{"offset", PaginationOffset, false},
+		{"cursor", PaginationCursor, false},
+		{"keyset", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePagination(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePagination(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePagination(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListQueryColumns(t *testing.T) {
+	fields, err := ParseFields("title:string:required,sku:string:unique,body:text,price:decimal,status:enum(draft|live),featured:bool,published_at:time?,payload:json?")
+	if err != nil {
+		t.Fatalf("ParseFields() error = %v", err)
+	}
+	relations, err := ParseRelations(BelongsTo, "category")
+	if err != nil {
+		t.Fatalf("ParseRelations() error = %v", err)
+	}
+
+	data := NewTemplateData()
+	data.DomainName, data.EntityName, data.EntityNameCamel = "catalog", "product", "Product"
+	data.ApplyFields(fields)
+	if err := data.ApplyRelations(relations); err != nil {
+		t.Fatalf("ApplyRelations() error = %v", err)
+	}
+
+	if got, want := data.FilterColumns(), []string{"sku", "status", "featured", "category_id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterColumns() = %v, want %v", got, want)
+	}
+	if got, want := data.SearchColumns(), []string{"title", "sku", "body"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchColumns() = %v, want %v", got, want)
+	}
+
+	want := []SortColumn{
+		{Name: "title", Type: "text"},
+		{Name: "sku", Type: "text"},
+		{Name: "price", Type: "number"},
+		{Name: "status", Type: "text"},
+		{Name: "published_at", Type: "date"},
+		{Name: "created_at", Type: "date"},
+		{Name: "updated_at", Type: "date"},
+	}
+	if got := data.SortColumns(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortColumns() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if data.CursorPagination() {
+		t.Errorf("CursorPagination() = true for the default pagination")
+	}
+}
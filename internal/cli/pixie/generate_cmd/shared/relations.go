@@ -0,0 +1,277 @@
+package shared
+
+import (
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// Relation kinds, named after the generate entity flags that declare them.
+const (
+	BelongsTo  = "belongs-to"
+	HasMany    = "has-many"
+	ManyToMany = "many-to-many"
+)
+
+// RelationKinds lists the relation kinds in the order they are rendered.
+var RelationKinds = []string{BelongsTo, HasMany, ManyToMany}
+
+// Relation is an association between a generated entity, the owner, and another entity.
+// Relations to entities of other domains only add the foreign key column and constraint:
+// gorm associations are kept within a domain's entities package.
+type Relation struct {
+	Kind   string // BelongsTo, HasMany or ManyToMany
+	Domain string // domain of the related entity; empty for the owner's domain
+	Entity string // snake_case related entity: line_item
+
+	// Set by TemplateData.ApplyRelations
+	Owner       string // snake_case owner entity: order
+	OwnerDomain string
+}
+
+// ParseRelations parses the comma-separated entities of a relation flag. An entity of
+// another domain is written domain.entity.
+func ParseRelations(kind, spec string) ([]Relation, error) {
+	var relations []Relation
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		relation := Relation{Kind: kind, Entity: entry}
+		if domain, entity, ok := strings.Cut(entry, "."); ok {
+			if !IsValidIdentifier(domain) {
+				return nil, errors.New("--%s %s: domain must be a valid identifier", kind, entry)
+			}
+			relation.Domain, relation.Entity = domain, entity
+		}
+		if !IsValidSnakeCase(relation.Entity) {
+			return nil, errors.New("--%s %s: entity must be in snake_case", kind, entry)
+		}
+		relations = append(relations, relation)
+	}
+
+	return relations, nil
+}
+
+// RelationsFromInputs parses the relation flags recorded in generator inputs.
+func RelationsFromInputs(inputs map[string]string) ([]Relation, error) {
+	var relations []Relation
+	for _, kind := range RelationKinds {
+		parsed, err := ParseRelations(kind, inputs[kind])
+		if err != nil {
+			return nil, err
+		}
+		relations = append(relations, parsed...)
+	}
+
+	return relations, nil
+}
+
+// External reports whether the related entity lives in another domain.
+func (r Relation) External() bool {
+	return r.Domain != ""
+}
+
+// Self reports whether the relation points back at its owner.
+func (r Relation) Self() bool {
+	return !r.External() && r.Entity == r.Owner
+}
+
+// EntityType returns the Go type of the related entity: LineItem.
+func (r Relation) EntityType() string {
+	return initshared.Pascal(r.Entity)
+}
+
+// FieldName returns the association field on the owner: User, LineItems.
+func (r Relation) FieldName() string {
+	if r.Kind == BelongsTo {
+		return r.EntityType()
+	}
+	return initshared.Plural(r.EntityType())
+}
+
+// JSONName returns the JSON name of the association field: user, line_items.
+func (r Relation) JSONName() string {
+	if r.Kind == BelongsTo {
+		return r.Entity
+	}
+	return initshared.Plural(r.Entity)
+}
+
+// ForeignKey returns the Go name of the foreign key: UserID on the owner for BelongsTo,
+// OrderID on the related entity for HasMany.
+func (r Relation) ForeignKey() string {
+	if r.Kind == HasMany {
+		return r.OwnerKey()
+	}
+	return r.RelatedKey()
+}
+
+// ForeignKeyColumn returns the column of the BelongsTo foreign key: user_id.
+func (r Relation) ForeignKeyColumn() string {
+	return r.Entity + "_id"
+}
+
+// OwnerKey returns the Go name of the column referencing the owner: OrderID.
+func (r Relation) OwnerKey() string {
+	return initshared.Pascal(r.Owner) + "ID"
+}
+
+// RelatedKey returns the Go name of the column referencing the related entity: UserID.
+func (r Relation) RelatedKey() string {
+	return r.EntityType() + "ID"
+}
+
+// Table returns the table of the related entity.
+func (r Relation) Table() string {
+	if r.External() {
+		return r.Domain + "_" + r.Entity
+	}
+	return r.OwnerDomain + "_" + r.Entity
+}
+
+// JoinTable returns the ManyToMany join table: catalog_product_tags.
+func (r Relation) JoinTable() string {
+	return r.OwnerDomain + "_" + r.Owner + "_" + initshared.Plural(r.Entity)
+}
+
+// JoinType returns the name of the migration struct of the ManyToMany join table.
+func (r Relation) JoinType() string {
+	return r.Owner + "_" + r.Entity
+}
+
+// GormTag returns the gorm tag of the association field.
+func (r Relation) GormTag() string {
+	if r.Kind == ManyToMany {
+		return "many2many:" + r.JoinTable()
+	}
+	return "foreignKey:" + r.ForeignKey()
+}
+
+// ForeignKeyConstraint is a foreign key a migration adds after creating its tables.
+type ForeignKeyConstraint struct {
+	Name       string
+	Table      string
+	Column     string
+	References string
+}
+
+// ApplyRelations sets the relations of the entity. EntityName, DomainName and Fields
+// must be set first; relations clashing with them are rejected.
+func (d *TemplateData) ApplyRelations(relations []Relation) error {
+	columns := map[string]string{}
+	for _, field := range d.Fields {
+		columns[field.Name] = "field " + field.Name
+		columns[field.GoName()] = "field " + field.Name
+	}
+	if d.Features["auth"] {
+		columns["user_id"] = "the auth feature's user_id"
+	}
+
+	d.Relations = nil
+	for _, relation := range relations {
+		relation.Owner, relation.OwnerDomain = d.EntityName, d.DomainName
+		if relation.Domain == d.DomainName {
+			relation.Domain = ""
+		}
+
+		flag := "--" + relation.Kind + " " + relation.Entity
+		switch {
+		case relation.External() && relation.Kind != BelongsTo:
+			return errors.New("%s: %s relations must stay within domain %s", flag, relation.Kind, d.DomainName)
+		case relation.Self() && relation.Kind == ManyToMany:
+			return errors.New("%s: an entity cannot be many-to-many with itself", flag)
+		}
+
+		names := []string{relation.FieldName()}
+		if relation.Kind == BelongsTo {
+			names = append(names, relation.ForeignKeyColumn(), relation.ForeignKey())
+		}
+		for _, name := range names {
+			if owner, ok := columns[name]; ok {
+				return errors.New("%s: %s clashes with %s", flag, name, owner)
+			}
+			columns[name] = flag
+		}
+
+		d.Relations = append(d.Relations, relation)
+	}
+
+	return nil
+}
+
+// RelationsOf returns the relations of the given kind.
+func (d TemplateData) RelationsOf(kind string) []Relation {
+	var relations []Relation
+	for _, relation := range d.Relations {
+		if relation.Kind == kind {
+			relations = append(relations, relation)
+		}
+	}
+	return relations
+}
+
+// Associations returns the relations rendered as gorm association fields.
+func (d TemplateData) Associations() []Relation {
+	var relations []Relation
+	for _, relation := range d.Relations {
+		if !relation.External() {
+			relations = append(relations, relation)
+		}
+	}
+	return relations
+}
+
+// AssociationNames returns the association field names joined for a doc comment.
+func (d TemplateData) AssociationNames() string {
+	var names []string
+	for _, relation := range d.Associations() {
+		names = append(names, relation.FieldName())
+	}
+	return strings.Join(names, ", ")
+}
+
+// ForeignKeys returns the constraints the entity migration adds: one per BelongsTo
+// column and two per ManyToMany join table.
+func (d TemplateData) ForeignKeys() []ForeignKeyConstraint {
+	table := d.DomainName + "_" + d.EntityName
+
+	var keys []ForeignKeyConstraint
+	for _, relation := range d.Relations {
+		switch relation.Kind {
+		case BelongsTo:
+			keys = append(keys, foreignKey(table, relation.ForeignKeyColumn(), relation.Table()))
+		case ManyToMany:
+			join := relation.JoinTable()
+			keys = append(keys,
+				foreignKey(join, relation.Owner+"_id", table),
+				foreignKey(join, relation.Entity+"_id", relation.Table()))
+		}
+	}
+	return keys
+}
+
+func foreignKey(table, column, references string) ForeignKeyConstraint {
+	return ForeignKeyConstraint{
+		Name:       "fk_" + table + "_" + column,
+		Table:      table,
+		Column:     column,
+		References: references,
+	}
+}
+
+// MigrationDependencies returns the entities of the owner's domain whose tables the
+// entity migration references, so their migrations must run first.
+func (d TemplateData) MigrationDependencies() []string {
+	var entities []string
+	for _, relation := range d.Relations {
+		if relation.Kind != HasMany && !relation.External() && !relation.Self() {
+			entities = append(entities, relation.Entity)
+		}
+	}
+	return entities
+}
@@ -0,0 +1,129 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRelations(t *testing.T) {
+	relations, err := ParseRelations(BelongsTo, "order, authentication.user")
+	if err != nil {
+		t.Fatalf("ParseRelations() error = %v", err)
+	}
+
+	want := []Relation{
+		{Kind: BelongsTo, Entity: "order"},
+		{Kind: BelongsTo, Domain: "authentication", Entity: "user"},
+	}
+	if !reflect.DeepEqual(relations, want) {
+		t.Errorf("ParseRelations() = %+v, want %+v", relations, want)
+	}
+
+	for _, spec := range []string{"LineItem", "line-item", "bad domain.user"} {
+		if _, err := ParseRelations(HasMany, spec); err == nil {
+			t.Errorf("ParseRelations(%q) error = nil, want an error", spec)
+		}
+	}
+}
+
+func relationData(t *testing.T, inputs map[string]string) TemplateData {
+	t.Helper()
+
+	relations, err := RelationsFromInputs(inputs)
+	if err != nil {
+		t.Fatalf("RelationsFromInputs() error = %v", err)
+	}
+
+	data := NewTemplateData()
+	data.DomainName = "orders"
+	data.EntityName = "order"
+	data.EntityNameCamel = "Order"
+	if err := data.ApplyRelations(relations); err != nil {
+		t.Fatalf("ApplyRelations() error = %v", err)
+	}
+
+	return data
+}
+
+func TestApplyRelations(t *testing.T) {
+	data := relationData(t, map[string]string{
+		BelongsTo:  "customer,authentication.user",
+		HasMany:    "line_item",
+		ManyToMany: "tag",
+	})
+
+	tests := []struct {
+		relation  Relation
+		field     string
+		gormTag   string
+		reference string
+	}{
+		{data.Relations[0], "Customer", "foreignKey:CustomerID", "orders_customer"},
+		{data.Relations[1], "User", "foreignKey:UserID", "authentication_user"},
+		{data.Relations[2], "LineItems", "foreignKey:OrderID", "orders_line_item"},
+		{data.Relations[3], "Tags", "many2many:orders_order_tags", "orders_tag"},
+	}
+	for _, tt := range tests {
+		if got := tt.relation.FieldName(); got != tt.field {
+			t.Errorf("%s FieldName() = %q, want %q", tt.relation.Entity, got, tt.field)
+		}
+		if got := tt.relation.GormTag(); got != tt.gormTag {
+			t.Errorf("%s GormTag() = %q, want %q", tt.relation.Entity, got, tt.gormTag)
+		}
+		if got := tt.relation.Table(); got != tt.reference {
+			t.Errorf("%s Table() = %q, want %q", tt.relation.Entity, got, tt.reference)
+		}
+	}
+
+	if got := len(data.Associations()); got != 3 {
+		t.Errorf("len(Associations()) = %d, want 3 (external relations have no association)", got)
+	}
+
+	wantKeys := []ForeignKeyConstraint{
+		{Name: "fk_orders_order_customer_id", Table: "orders_order", Column: "customer_id", References: "orders_customer"},
+		{Name: "fk_orders_order_user_id", Table: "orders_order", Column: "user_id", References: "authentication_user"},
+		{Name: "fk_orders_order_tags_order_id", Table: "orders_order_tags", Column: "order_id", References: "orders_order"},
+		{Name: "fk_orders_order_tags_tag_id", Table: "orders_order_tags", Column: "tag_id", References: "orders_tag"},
+	}
+	if got := data.ForeignKeys(); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("ForeignKeys() =\n%+v\nwant\n%+v", got, wantKeys)
+	}
+
+	if got, want := data.MigrationDependencies(), []string{"customer", "tag"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MigrationDependencies() = %v, want %v", got, want)
+	}
+}
+
+func TestApplyRelationsErrors(t *testing.T) {
+	fields, err := ParseFields("customer_id:uid")
+	if err != nil {
+		t.Fatalf("ParseFields() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		fields    []Field
+		auth      bool
+		relations []Relation
+	}{
+		{"external has-many", nil, false, []Relation{{Kind: HasMany, Domain: "billing", Entity: "invoice"}}},
+		{"self many-to-many", nil, false, []Relation{{Kind: ManyToMany, Entity: "order"}}},
+		{"clashes with a field", fields, false, []Relation{{Kind: BelongsTo, Entity: "customer"}}},
+		{"clashes with auth", nil, true, []Relation{{Kind: BelongsTo, Entity: "user"}}},
+		{"declared twice", nil, false, []Relation{{Kind: BelongsTo, Entity: "tag"}, {Kind: BelongsTo, Entity: "tag"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := NewTemplateData()
+			data.DomainName = "orders"
+			data.EntityName = "order"
+			data.Features["auth"] = tt.auth
+			data.ApplyFields(tt.fields)
+
+			if err := data.ApplyRelations(tt.relations); err == nil {
+				t.Errorf("ApplyRelations() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,513 @@
+package shared
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// Schema is the table layout of a struct passed to gorm: an entity, or the snapshot of
+// the entity a migration declares.
+type Schema struct {
+	Type     string            // struct type name
+	Table    string            // table name returned by TableName
+	File     string            // file declaring the struct
+	Embedded []string          // embedded types, such as database_models.SoftDeletable
+	Columns  []Column          // columns in declaration order
+	Imports  map[string]string // package name to import path, for the packages the struct uses
+}
+
+// Column is a column of a Schema.
+type Column struct {
+	Field string // Go field name: PublishedAt
+	Name  string // column name: published_at
+	Type  string // Go type: *time.Time
+	Tag   string // gorm struct tag value
+}
+
+// Index is an index declared by the gorm tags of a Schema.
+type Index struct {
+	Name    string
+	Unique  bool
+	Columns []string
+}
+
+// ColumnChange is a column whose type or gorm settings differ between two schemas.
+type ColumnChange struct {
+	From Column
+	To   Column
+}
+
+// SchemaDiff lists the changes turning one schema into another. Columns are matched by
+// name, so a renamed column is a removed and an added one.
+type SchemaDiff struct {
+	Added          []Column
+	Removed        []Column
+	Altered        []ColumnChange
+	CreatedIndexes []Index
+	DroppedIndexes []Index
+}
+
+// Empty reports whether the schemas are the same.
+func (d SchemaDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Altered) == 0 &&
+		len(d.CreatedIndexes) == 0 && len(d.DroppedIndexes) == 0
+}
+
+// ReadEntitySchema reads the schema of the entity struct typeName declared in file.
+// Association fields are left out, and named types of the entities package, such as
+// enums, are replaced by their underlying type so the schema can be copied into a
+// migration.
+func ReadEntitySchema(file, typeName string) (Schema, error) {
+	pkg, err := parseDir(filepath.Dir(file))
+	if err != nil {
+		return Schema{}, err
+	}
+
+	node, ok := pkg.files[filepath.Join(filepath.Dir(file), filepath.Base(file))]
+	if !ok {
+		return Schema{}, errors.New("entity file not found: %s", file)
+	}
+
+	return readSchema(node, file, typeName, pkg)
+}
+
+// LatestSnapshot returns the struct for table declared by the latest migration in dir,
+// ordered by the timestamp prefix of the file names. It reports false when no
+// migration declares one.
+func LatestSnapshot(dir, table string) (Schema, bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Schema{}, false, errors.Wrap(err, "failed to read migrations directory: %s", dir)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			files = append(files, name)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return migrationTimestamp(files[i]) > migrationTimestamp(files[j])
+	})
+
+	for _, name := range files {
+		file := filepath.Join(dir, name)
+		node, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			return Schema{}, false, errors.Wrap(err, "failed to parse %s", file)
+		}
+
+		for typeName, t := range tableNames(node) {
+			if t == table {
+				schema, err := readSchema(node, file, typeName, nil)
+				return schema, err == nil, err
+			}
+		}
+	}
+
+	return Schema{}, false, nil
+}
+
+// migrationTimestamp returns the leading digits of a migration file name.
+func migrationTimestamp(name string) int64 {
+	digits := strings.IndexFunc(name, func(r rune) bool { return r < '0' || r > '9' })
+	if digits < 0 {
+		digits = len(name)
+	}
+	timestamp, _ := strconv.ParseInt(name[:digits], 10, 64)
+	return timestamp
+}
+
+// DiffSchemas returns the changes turning the from schema into the to schema.
+func DiffSchemas(from, to Schema) SchemaDiff {
+	var diff SchemaDiff
+
+	fromColumns := map[string]Column{}
+	for _, column := range from.Columns {
+		fromColumns[column.Name] = column
+	}
+	toColumns := map[string]bool{}
+	for _, column := range to.Columns {
+		toColumns[column.Name] = true
+
+		previous, ok := fromColumns[column.Name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, column)
+		case previous.Type != column.Type || previous.Definition() != column.Definition():
+			diff.Altered = append(diff.Altered, ColumnChange{From: previous, To: column})
+		}
+	}
+	for _, column := range from.Columns {
+		if !toColumns[column.Name] {
+			diff.Removed = append(diff.Removed, column)
+		}
+	}
+
+	fromIndexes := indexesByName(from.Indexes())
+	toIndexes := indexesByName(to.Indexes())
+	for _, index := range from.Indexes() {
+		if other, ok := toIndexes[index.Name]; !ok || !reflect.DeepEqual(index, other) {
+			diff.DroppedIndexes = append(diff.DroppedIndexes, index)
+		}
+	}
+	for _, index := range to.Indexes() {
+		if other, ok := fromIndexes[index.Name]; !ok || !reflect.DeepEqual(index, other) {
+			diff.CreatedIndexes = append(diff.CreatedIndexes, index)
+		}
+	}
+
+	return diff
+}
+
+func indexesByName(indexes []Index) map[string]Index {
+	byName := make(map[string]Index, len(indexes))
+	for _, index := range indexes {
+		byName[index.Name] = index
+	}
+	return byName
+}
+
+// Definition returns the gorm settings of the column without its index settings,
+// sorted, so columns declared in a different order compare equal.
+func (c Column) Definition() string {
+	var settings []string
+	for _, setting := range strings.Split(c.Tag, ";") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" || isIndexSetting(setting) {
+			continue
+		}
+		settings = append(settings, setting)
+	}
+	sort.Strings(settings)
+
+	return strings.Join(settings, ";")
+}
+
+func isIndexSetting(setting string) bool {
+	key, _, _ := strings.Cut(setting, ":")
+	key = strings.ToLower(strings.TrimSpace(key))
+	return key == "index" || key == "uniqueindex"
+}
+
+// Indexes returns the indexes the gorm tags of the schema declare, named as gorm names
+// them when the tag does not: idx_<table>_<column>. Columns sharing an index name form
+// a composite index.
+func (s Schema) Indexes() []Index {
+	var indexes []Index
+	positions := map[string]int{}
+	for _, column := range s.Columns {
+		for _, setting := range strings.Split(column.Tag, ";") {
+			setting = strings.TrimSpace(setting)
+			if !isIndexSetting(setting) {
+				continue
+			}
+
+			key, value, _ := strings.Cut(setting, ":")
+			options := strings.Split(value, ",")
+			name := strings.TrimSpace(options[0])
+			if name == "" {
+				name = "idx_" + s.Table + "_" + column.Name
+			}
+			unique := strings.EqualFold(strings.TrimSpace(key), "uniqueIndex")
+			for _, option := range options[1:] {
+				unique = unique || strings.EqualFold(strings.TrimSpace(option), "unique")
+			}
+
+			if i, ok := positions[name]; ok {
+				indexes[i].Columns = append(indexes[i].Columns, column.Name)
+				indexes[i].Unique = indexes[i].Unique || unique
+				continue
+			}
+			positions[name] = len(indexes)
+			indexes = append(indexes, Index{Name: name, Unique: unique, Columns: []string{column.Name}})
+		}
+	}
+
+	return indexes
+}
+
+// ImportGroups returns the import specs of the packages the schema uses, standard
+// library first, each group sorted.
+func (s Schema) ImportGroups(extra ...string) [][]string {
+	imports := map[string]string{}
+	for name, importPath := range s.Imports {
+		imports[importPath] = name
+	}
+	for _, importPath := range extra {
+		if _, ok := imports[importPath]; !ok {
+			imports[importPath] = path.Base(importPath)
+		}
+	}
+
+	var std, others []string
+	for importPath, name := range imports {
+		spec := strconv.Quote(importPath)
+		if name != path.Base(importPath) {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".") {
+			others = append(others, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Slice(std, func(i, j int) bool { return unquoteSpec(std[i]) < unquoteSpec(std[j]) })
+	sort.Slice(others, func(i, j int) bool { return unquoteSpec(others[i]) < unquoteSpec(others[j]) })
+
+	var groups [][]string
+	for _, group := range [][]string{std, others} {
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// unquoteSpec returns the import path of an import spec.
+func unquoteSpec(spec string) string {
+	if _, quoted, ok := strings.Cut(spec, " "); ok {
+		spec = quoted
+	}
+	importPath, _ := strconv.Unquote(spec)
+	return importPath
+}
+
+// packageDecls holds the parsed files of a package directory and its type declarations.
+type packageDecls struct {
+	files   map[string]*ast.File
+	structs map[string]bool     // struct types
+	named   map[string]ast.Expr // other named types, to their underlying type
+}
+
+func parseDir(dir string) (*packageDecls, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read directory: %s", dir)
+	}
+
+	pkg := &packageDecls{files: map[string]*ast.File{}, structs: map[string]bool{}, named: map[string]ast.Expr{}}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file := filepath.Join(dir, name)
+		node, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse %s", file)
+		}
+		pkg.files[file] = node
+
+		ast.Inspect(node, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			if _, ok := spec.Type.(*ast.StructType); ok {
+				pkg.structs[spec.Name.Name] = true
+			} else if spec.Assign == 0 {
+				pkg.named[spec.Name.Name] = spec.Type
+			}
+			return false
+		})
+	}
+
+	return pkg, nil
+}
+
+// tableNames maps the types of node with a TableName method returning a string literal
+// to that literal.
+func tableNames(node *ast.File) map[string]string {
+	tables := map[string]string{}
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "TableName" || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil {
+			continue
+		}
+
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		ident, ok := recv.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		for _, stmt := range fn.Body.List {
+			ret, ok := stmt.(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 {
+				continue
+			}
+			if lit, ok := ret.Results[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if table, err := strconv.Unquote(lit.Value); err == nil {
+					tables[ident.Name] = table
+				}
+			}
+		}
+	}
+
+	return tables
+}
+
+// readSchema reads the struct typeName of node. With pkg, association fields are left
+// out and the named types of pkg are replaced by their underlying type.
+func readSchema(node *ast.File, file, typeName string, pkg *packageDecls) (Schema, error) {
+	var structType *ast.StructType
+	ast.Inspect(node, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.TypeSpec); ok && spec.Name.Name == typeName {
+			structType, _ = spec.Type.(*ast.StructType)
+			return false
+		}
+		return structType == nil
+	})
+	if structType == nil {
+		return Schema{}, errors.New("no struct %s in %s", typeName, file)
+	}
+
+	schema := Schema{Type: typeName, Table: tableNames(node)[typeName], File: file, Imports: map[string]string{}}
+	imports := fileImports(node)
+	uses := func(expr ast.Expr) {
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok {
+					if importPath, ok := imports[ident.Name]; ok {
+						schema.Imports[ident.Name] = importPath
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	for _, field := range structType.Fields.List {
+		var gormTag string
+		if field.Tag != nil {
+			if tag, err := strconv.Unquote(field.Tag.Value); err == nil {
+				gormTag = reflect.StructTag(tag).Get("gorm")
+			}
+		}
+		if gormTag == "-" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			schema.Embedded = append(schema.Embedded, exprString(field.Type))
+			uses(field.Type)
+			continue
+		}
+
+		fieldType := field.Type
+		if pkg != nil {
+			if isAssociation(fieldType, gormTag, pkg) {
+				continue
+			}
+			fieldType = pkg.underlying(fieldType)
+		}
+		uses(fieldType)
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			schema.Columns = append(schema.Columns, Column{
+				Field: name.Name,
+				Name:  columnName(name.Name, gormTag),
+				Type:  exprString(fieldType),
+				Tag:   gormTag,
+			})
+		}
+	}
+
+	return schema, nil
+}
+
+// isAssociation reports whether a field is a gorm association rather than a column.
+func isAssociation(expr ast.Expr, gormTag string, pkg *packageDecls) bool {
+	lower := strings.ToLower(gormTag)
+	if strings.Contains(lower, "foreignkey:") || strings.Contains(lower, "many2many:") {
+		return true
+	}
+
+	for {
+		switch x := expr.(type) {
+		case *ast.StarExpr:
+			expr = x.X
+		case *ast.ArrayType:
+			expr = x.Elt
+		case *ast.Ident:
+			return pkg.structs[x.Name]
+		default:
+			return false
+		}
+	}
+}
+
+// underlying replaces the named types of the package within expr by their underlying type.
+func (p *packageDecls) underlying(expr ast.Expr) ast.Expr {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: p.underlying(x.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: x.Len, Elt: p.underlying(x.Elt)}
+	case *ast.Ident:
+		if underlying, ok := p.named[x.Name]; ok {
+			return p.underlying(underlying)
+		}
+	}
+	return expr
+}
+
+// columnName returns the column of a field: the gorm column setting, or the snake_case
+// field name as gorm's default naming strategy has it.
+func columnName(field, gormTag string) string {
+	for _, setting := range strings.Split(gormTag, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(setting), ":")
+		if ok && strings.EqualFold(key, "column") {
+			return value
+		}
+	}
+	return initshared.Snake(field)
+}
+
+// fileImports maps the package names node refers to its imports by to their paths.
+func fileImports(node *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, spec := range node.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), expr); err != nil {
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,245 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const schemaEntity = `package orders_entities
+
+import (
+	"time"
+
+	"github.com/pixie-sh/core-go/pkg/uid"
+)
+
+type InvoiceStatus string
+
+type Invoice struct {
+	ID        uid.UID       ` + "`" + `gorm:"primaryKey;type:char(26)" json:"id"` + "`" + `
+	Number    string        ` + "`" + `gorm:"not null;size:64;index" json:"number"` + "`" + `
+	Status    InvoiceStatus ` + "`" + `gorm:"not null;size:16" json:"status"` + "`" + `
+	PaidAt    *time.Time    ` + "`" + `json:"paid_at"` + "`" + `
+	OrderID   uid.UID       ` + "`" + `gorm:"not null;type:char(26);uniqueIndex" json:"order_id"` + "`" + `
+	CreatedAt time.Time     ` + "`" + `gorm:"autoCreateTime" json:"created_at"` + "`" + `
+	Internal  string        ` + "`" + `gorm:"-" json:"-"` + "`" + `
+
+	// Associations
+	Order *Order ` + "`" + `gorm:"foreignKey:OrderID" json:"order,omitempty"` + "`" + `
+	Lines []*Line ` + "`" + `json:"lines,omitempty"` + "`" + `
+}
+
+func (Invoice) TableName() string {
+	return "orders_invoice"
+}
+
+type Order struct {
+	ID uid.UID
+}
+
+type Line struct {
+	ID uid.UID
+}
+`
+
+const schemaCreateMigration = `package orders_migrations
+
+import (
+	"time"
+
+	"github.com/pixie-sh/core-go/pkg/models/database_models"
+	"github.com/pixie-sh/core-go/pkg/uid"
+)
+
+type invoice1700000000 struct {
+	database_models.SoftDeletable
+
+	ID        uid.UID   ` + "`" + `gorm:"primaryKey;type:char(26)"` + "`" + `
+	Number    string    ` + "`" + `gorm:"not null;size:32"` + "`" + `
+	Total     float64   ` + "`" + `gorm:"not null"` + "`" + `
+	OrderID   uid.UID   ` + "`" + `gorm:"type:char(26);not null;index"` + "`" + `
+	CreatedAt time.Time ` + "`" + `gorm:"autoCreateTime"` + "`" + `
+}
+
+func (invoice1700000000) TableName() string {
+	return "orders_invoice"
+}
+`
+
+const schemaAlterMigration = `package orders_migrations
+
+import "github.com/pixie-sh/core-go/pkg/uid"
+
+type invoice1700000100 struct {
+	ID uid.UID ` + "`" + `gorm:"primaryKey;type:char(26)"` + "`" + `
+}
+
+func (invoice1700000100) TableName() string {
+	return "orders_invoice"
+}
+
+type other1700000200 struct {
+	ID uid.UID
+}
+
+func (other1700000200) TableName() string {
+	return "orders_other"
+}
+`
+
+func writeSchemaFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestReadEntitySchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders_entities", "invoice.go")
+	writeSchemaFile(t, path, schemaEntity)
+
+	schema, err := ReadEntitySchema(path, "Invoice")
+	if err != nil {
+		t.Fatalf("ReadEntitySchema() error = %v", err)
+	}
+
+	if schema.Table != "orders_invoice" {
+		t.Errorf("Table = %q, want orders_invoice", schema.Table)
+	}
+	want := []Column{
+		{Field: "ID", Name: "id", Type: "uid.UID", Tag: "primaryKey;type:char(26)"},
+		{Field: "Number", Name: "number", Type: "string", Tag: "not null;size:64;index"},
+		{Field: "Status", Name: "status", Type: "string", Tag: "not null;size:16"},
+		{Field: "PaidAt", Name: "paid_at", Type: "*time.Time"},
+		{Field: "OrderID", Name: "order_id", Type: "uid.UID", Tag: "not null;type:char(26);uniqueIndex"},
+		{Field: "CreatedAt", Name: "created_at", Type: "time.Time", Tag: "autoCreateTime"},
+	}
+	if !reflect.DeepEqual(schema.Columns, want) {
+		t.Errorf("Columns =\n%+v\nwant\n%+v", schema.Columns, want)
+	}
+
+	wantImports := map[string]string{"time": "time", "uid": "github.com/pixie-sh/core-go/pkg/uid"}
+	if !reflect.DeepEqual(schema.Imports, wantImports) {
+		t.Errorf("Imports = %v, want %v", schema.Imports, wantImports)
+	}
+}
+
+func TestLatestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	writeSchemaFile(t, filepath.Join(dir, "1700000000_create_invoice_table.go"), schemaCreateMigration)
+	writeSchemaFile(t, filepath.Join(dir, "1700000100_alter_invoice_table.go"), schemaAlterMigration)
+	writeSchemaFile(t, filepath.Join(dir, "migrations.go"), "package orders_migrations\n")
+
+	schema, ok, err := LatestSnapshot(dir, "orders_invoice")
+	if err != nil || !ok {
+		t.Fatalf("LatestSnapshot() = %v, %v", ok, err)
+	}
+	if schema.Type != "invoice1700000100" {
+		t.Errorf("Type = %q, want invoice1700000100", schema.Type)
+	}
+
+	if _, ok, err := LatestSnapshot(dir, "orders_payment"); err != nil || ok {
+		t.Errorf("LatestSnapshot(orders_payment) = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	dir := t.TempDir()
+	entityPath := filepath.Join(dir, "orders_entities", "invoice.go")
+	migrationPath := filepath.Join(dir, "orders_migrations", "1700000000_create_invoice_table.go")
+	writeSchemaFile(t, entityPath, schemaEntity)
+	writeSchemaFile(t, migrationPath, schemaCreateMigration)
+
+	current, err := ReadEntitySchema(entityPath, "Invoice")
+	if err != nil {
+		t.Fatalf("ReadEntitySchema() error = %v", err)
+	}
+	previous, ok, err := LatestSnapshot(filepath.Dir(migrationPath), "orders_invoice")
+	if err != nil || !ok {
+		t.Fatalf("LatestSnapshot() = %v, %v", ok, err)
+	}
+
+	if embedded := previous.Embedded; !reflect.DeepEqual(embedded, []string{"database_models.SoftDeletable"}) {
+		t.Errorf("Embedded = %v", embedded)
+	}
+
+	diff := DiffSchemas(previous, current)
+
+	names := func(columns []Column) []string {
+		var result []string
+		for _, column := range columns {
+			result = append(result, column.Name)
+		}
+		return result
+	}
+	if got, want := names(diff.Added), []string{"status", "paid_at"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Added = %v, want %v", got, want)
+	}
+	if got, want := names(diff.Removed), []string{"total"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Removed = %v, want %v", got, want)
+	}
+	// order_id only moves from index to uniqueIndex and reorders its settings
+	if len(diff.Altered) != 1 || diff.Altered[0].To.Name != "number" {
+		t.Errorf("Altered = %+v, want number", diff.Altered)
+	}
+
+	wantDropped := []Index{{Name: "idx_orders_invoice_order_id", Columns: []string{"order_id"}}}
+	if !reflect.DeepEqual(diff.DroppedIndexes, wantDropped) {
+		t.Errorf("DroppedIndexes = %+v, want %+v", diff.DroppedIndexes, wantDropped)
+	}
+	wantCreated := []Index{
+		{Name: "idx_orders_invoice_number", Columns: []string{"number"}},
+		{Name: "idx_orders_invoice_order_id", Unique: true, Columns: []string{"order_id"}},
+	}
+	if !reflect.DeepEqual(diff.CreatedIndexes, wantCreated) {
+		t.Errorf("CreatedIndexes = %+v, want %+v", diff.CreatedIndexes, wantCreated)
+	}
+
+	if !DiffSchemas(current, current).Empty() {
+		t.Errorf("DiffSchemas(current, current) is not empty")
+	}
+}
+
+func TestSchemaIndexes(t *testing.T) {
+	schema := Schema{
+		Table: "orders_line",
+		Columns: []Column{
+			{Name: "order_id", Tag: "index:idx_order_product,unique"},
+			{Name: "product_id", Tag: "index:idx_order_product"},
+			{Name: "sku", Tag: "size:32;uniqueIndex"},
+		},
+	}
+
+	want := []Index{
+		{Name: "idx_order_product", Unique: true, Columns: []string{"order_id", "product_id"}},
+		{Name: "idx_orders_line_sku", Unique: true, Columns: []string{"sku"}},
+	}
+	if got := schema.Indexes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Indexes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSchemaImportGroups(t *testing.T) {
+	schema := Schema{Imports: map[string]string{
+		"uid":  "github.com/pixie-sh/core-go/pkg/uid",
+		"time": "time",
+		"dm":   "github.com/pixie-sh/core-go/pkg/models/database_models",
+	}}
+
+	want := [][]string{
+		{`"time"`},
+		{
+			`dm "github.com/pixie-sh/core-go/pkg/models/database_models"`,
+			`"github.com/pixie-sh/core-go/pkg/uid"`,
+			`"gorm.io/gorm"`,
+		},
+	}
+	if got := schema.ImportGroups("gorm.io/gorm", "time"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportGroups() = %v, want %v", got, want)
+	}
+}
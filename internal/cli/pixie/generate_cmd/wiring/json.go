@@ -0,0 +1,265 @@
+package wiring
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// MergeJSON adds to the JSON object src the members of generated it is missing,
+// recursing into the objects both declare. Members of base, the previously generated
+// content, that src no longer has were removed by the user and stay removed; existing
+// values are never changed and keep their layout. base may be nil. A src that is not
+// a JSON object is reported with a *ShapeError.
+func MergeJSON(src, base, generated []byte) ([]byte, error) {
+	current, ok := parseJSONObject(src)
+	if !ok {
+		return nil, &ShapeError{Reason: "not a JSON object"}
+	}
+	want, ok := parseJSONObject(generated)
+	if !ok {
+		return nil, errors.New("generated content is not a JSON object")
+	}
+	previous, _ := parseJSONObject(base)
+
+	m := jsonMerge{src: src, unit: jsonIndentUnit(src, current)}
+	if err := m.merge(current, previous, want, generated); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(m.edits, func(i, j int) bool { return m.edits[i].start < m.edits[j].start })
+	var out bytes.Buffer
+	last := 0
+	for _, edit := range m.edits {
+		out.Write(src[last:edit.start])
+		out.WriteString(edit.text)
+		last = edit.end
+	}
+	out.Write(src[last:])
+
+	if !json.Valid(out.Bytes()) {
+		return nil, errors.New("merge produced invalid JSON")
+	}
+
+	return out.Bytes(), nil
+}
+
+type jsonMerge struct {
+	src   []byte
+	unit  string
+	edits []jsonEdit
+}
+
+// jsonEdit replaces src[start:end] with text.
+type jsonEdit struct {
+	start, end int
+	text       string
+}
+
+func (m *jsonMerge) merge(current, previous, want *jsonObject, generated []byte) error {
+	var added []*jsonMember
+	for i := range want.members {
+		member := &want.members[i]
+		existing := current.member(member.name)
+		if existing == nil {
+			if previous != nil && previous.member(member.name) != nil {
+				continue
+			}
+			added = append(added, member)
+			continue
+		}
+
+		if member.object == nil || existing.object == nil {
+			continue
+		}
+		var before *jsonObject
+		if previous != nil {
+			if p := previous.member(member.name); p != nil {
+				before = p.object
+			}
+		}
+		if err := m.merge(existing.object, before, member.object, generated); err != nil {
+			return err
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	closeIndent := lineIndent(m.src, current.open)
+	indent := closeIndent + m.unit
+	if len(current.members) > 0 {
+		first := current.members[0]
+		if start := bytes.LastIndexByte(m.src[:first.keyStart], '\n') + 1; strings.TrimSpace(string(m.src[start:first.keyStart])) == "" {
+			indent = string(m.src[start:first.keyStart])
+		}
+	}
+
+	var text strings.Builder
+	for i, member := range added {
+		var value bytes.Buffer
+		if err := json.Indent(&value, generated[member.start:member.end], indent, m.unit); err != nil {
+			return errors.Wrap(err, "failed to format %s", member.name)
+		}
+		if i > 0 || len(current.members) > 0 {
+			text.WriteString(",")
+		}
+		text.WriteString("\n" + indent)
+		text.Write(generated[member.keyStart:member.keyEnd])
+		text.WriteString(": ")
+		text.Write(value.Bytes())
+	}
+
+	if len(current.members) == 0 {
+		// Replace the blank interior of an empty object
+		text.WriteString("\n" + closeIndent)
+		m.edits = append(m.edits, jsonEdit{start: current.open + 1, end: current.close, text: text.String()})
+		return nil
+	}
+
+	last := current.members[len(current.members)-1].end
+	m.edits = append(m.edits, jsonEdit{start: last, end: last, text: text.String()})
+	return nil
+}
+
+// jsonObject is a JSON object with the offsets of its braces and members in its source.
+type jsonObject struct {
+	open, close int
+	members     []jsonMember
+}
+
+type jsonMember struct {
+	name             string
+	keyStart, keyEnd int
+	start, end       int         // value
+	object           *jsonObject // value, when it is an object
+}
+
+func (o *jsonObject) member(name string) *jsonMember {
+	for i := range o.members {
+		if o.members[i].name == name {
+			return &o.members[i]
+		}
+	}
+	return nil
+}
+
+// parseJSONObject scans a document holding a single JSON object.
+func parseJSONObject(src []byte) (*jsonObject, bool) {
+	if !json.Valid(src) {
+		return nil, false
+	}
+
+	s := jsonScanner{src: src}
+	s.skipSpace()
+	if s.pos >= len(src) || src[s.pos] != '{' {
+		return nil, false
+	}
+
+	return s.object(), true
+}
+
+// jsonScanner walks JSON already checked with json.Valid.
+type jsonScanner struct {
+	src []byte
+	pos int
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.src) && strings.IndexByte(" \t\r\n", s.src[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+func (s *jsonScanner) object() *jsonObject {
+	obj := &jsonObject{open: s.pos}
+	s.pos++
+	for {
+		s.skipSpace()
+		switch s.src[s.pos] {
+		case '}':
+			obj.close = s.pos
+			s.pos++
+			return obj
+		case ',':
+			s.pos++
+			continue
+		}
+
+		member := jsonMember{keyStart: s.pos}
+		s.str()
+		member.keyEnd = s.pos
+		_ = json.Unmarshal(s.src[member.keyStart:member.keyEnd], &member.name)
+		s.skipSpace()
+		s.pos++ // ':'
+		s.skipSpace()
+		member.start = s.pos
+		if s.src[s.pos] == '{' {
+			member.object = s.object()
+		} else {
+			s.value()
+		}
+		member.end = s.pos
+		obj.members = append(obj.members, member)
+	}
+}
+
+func (s *jsonScanner) value() {
+	switch s.src[s.pos] {
+	case '"':
+		s.str()
+	case '{':
+		s.object()
+	case '[':
+		s.pos++
+		for {
+			s.skipSpace()
+			switch s.src[s.pos] {
+			case ']':
+				s.pos++
+				return
+			case ',':
+				s.pos++
+			default:
+				s.value()
+			}
+		}
+	default:
+		for s.pos < len(s.src) && strings.IndexByte(",}] \t\r\n", s.src[s.pos]) < 0 {
+			s.pos++
+		}
+	}
+}
+
+func (s *jsonScanner) str() {
+	s.pos++
+	for s.src[s.pos] != '"' {
+		if s.src[s.pos] == '\\' {
+			s.pos++
+		}
+		s.pos++
+	}
+	s.pos++
+}
+
+// jsonIndentUnit returns the indentation of the first member of the top-level object,
+// two spaces when it has none.
+func jsonIndentUnit(src []byte, root *jsonObject) string {
+	if len(root.members) > 0 {
+		offset := root.members[0].keyStart
+		start := bytes.LastIndexByte(src[:offset], '\n') + 1
+		if unit := string(src[start:offset]); unit != "" && strings.TrimSpace(unit) == "" {
+			return unit
+		}
+	}
+
+	return "  "
+}
+
+func lineIndent(src []byte, offset int) string {
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+	return leadingSpace(string(src[start:offset]))
+}
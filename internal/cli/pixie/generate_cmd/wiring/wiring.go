@@ -0,0 +1,906 @@
+// Package wiring edits the Go files of a generated project so generators can register
+// what they create (DI tokens, migrations, registry calls) instead of asking the user to.
+//
+// Files are parsed with go/parser to locate the declaration to extend; new code is
+// spliced in at that position, new imports are added to the import group they belong
+// to, and the edited file is gofmt-formatted, keeping its comments. Every edit is
+// idempotent. A file that does not have the expected shape is left alone and reported
+// with a *ShapeError.
+//
+// JSON configs are merged the same way: members a generator adds are spliced in after
+// the existing ones, which keep their values and layout.
+package wiring
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"golang.org/x/tools/go/ast/astutil"
+
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// ShapeError reports a file that does not have the shape an edit expects, such as a
+// missing declaration. Callers fall back to describing the edit as a manual step.
+type ShapeError struct {
+	Path   string
+	Reason string
+}
+
+func (e *ShapeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+// IsShapeError reports whether err is a *ShapeError.
+func IsShapeError(err error) bool {
+	_, ok := err.(*ShapeError)
+	return ok
+}
+
+// Token is a DI injection token: var Name = pdi.RegisterInjectionToken("Key").
+type Token struct {
+	Name string
+	Key  string
+}
+
+// DIModule is the import path of the DI package injection tokens are registered with.
+const DIModule = "github.com/pixie-sh/di-go"
+
+// AddTokens appends the tokens the file at path does not declare yet, in a var block
+// headed by comment. It reports whether the file changed.
+func AddTokens(path, comment string, tokens []Token) (bool, error) {
+	f, err := load(path)
+	if err != nil {
+		return false, err
+	}
+
+	pdi, ok := f.importName(DIModule)
+	if !ok {
+		return false, f.shapeError("does not import %s", DIModule)
+	}
+
+	declared := f.declaredNames()
+	var block strings.Builder
+	for _, t := range tokens {
+		if declared[t.Name] {
+			continue
+		}
+		fmt.Fprintf(&block, "\t%s = %s.RegisterInjectionToken(%s)\n", t.Name, pdi, strconv.Quote(t.Key))
+	}
+	if block.Len() == 0 {
+		return false, nil
+	}
+
+	snippet, err := formatDecls(fmt.Sprintf("// %s\nvar (\n%s)\n", comment, block.String()))
+	if err != nil {
+		return false, err
+	}
+
+	prefix := "\n"
+	if !bytes.HasSuffix(f.src, []byte("\n")) {
+		prefix = "\n\n"
+	}
+	f.insert(len(f.src), prefix+snippet)
+
+	return f.save()
+}
+
+// AppendToSlice appends element to the slice literal assigned to the top-level variable
+// name, unless an equal element is already there. It reports whether the file changed.
+func AppendToSlice(path, name, element string) (bool, error) {
+	return InsertIntoSlice(path, name, element, nil)
+}
+
+// InsertIntoSlice adds element to the slice literal assigned to the top-level variable
+// name, before the first element starting with one of the before prefixes, or at the
+// end. An equal element already in the slice is left where it is. It reports whether
+// the file changed.
+func InsertIntoSlice(path, name, element string, before []string) (bool, error) {
+	f, err := load(path)
+	if err != nil {
+		return false, err
+	}
+
+	lit := f.sliceLiteral(name)
+	if lit == nil {
+		return false, f.shapeError("no slice literal assigned to %s", name)
+	}
+
+	expr, err := parser.ParseExpr(element)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid slice element %q", element)
+	}
+	want := nodeString(token.NewFileSet(), expr)
+	for _, elt := range lit.Elts {
+		if nodeString(f.fset, elt) == want {
+			return false, nil
+		}
+	}
+
+	for _, elt := range lit.Elts {
+		if !hasAnyPrefix(nodeString(f.fset, elt), before) {
+			continue
+		}
+
+		pos := f.offset(elt.Pos())
+		if start, ok := f.lineStart(pos); ok {
+			f.insert(start, string(f.src[start:pos])+want+",\n")
+		} else {
+			f.insert(pos, want+", ")
+		}
+		return f.save()
+	}
+
+	if err := f.appendElement(lit, want, name); err != nil {
+		return false, err
+	}
+
+	return f.save()
+}
+
+// AddField adds field, such as "Name Type", to the struct type typeName, unless the
+// struct has a field of that name already. It reports whether the file changed.
+func AddField(path, typeName, field string) (bool, error) {
+	f, err := load(path)
+	if err != nil {
+		return false, err
+	}
+
+	structType := f.structType(typeName)
+	if structType == nil {
+		return false, f.shapeError("no struct type %s", typeName)
+	}
+
+	parsed, err := parser.ParseFile(token.NewFileSet(), "", "package p\n\ntype t struct {\n"+field+"\n}\n", 0)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid struct field %q", field)
+	}
+	fields := parsed.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.StructType).Fields.List
+	if len(fields) != 1 {
+		return false, errors.New("invalid struct field %q", field)
+	}
+	existing := fieldNames(structType.Fields.List)
+	for name := range fieldNames(fields) {
+		if existing[name] {
+			return false, nil
+		}
+	}
+
+	rbrace := f.offset(structType.Fields.Closing)
+	if f.line(structType.Fields.Opening) == f.line(structType.Fields.Closing) {
+		if len(structType.Fields.List) > 0 {
+			return false, f.shapeError("struct %s is declared on a single line", typeName)
+		}
+		f.insert(rbrace, "\n\t"+strings.TrimSpace(field)+"\n")
+		return f.save()
+	}
+
+	start, ok := f.lineStart(rbrace)
+	if !ok {
+		return false, f.shapeError("closing brace of %s does not start a line", typeName)
+	}
+	f.insert(start, f.indentOf(structType.Fields.List, rbrace)+strings.TrimSpace(field)+"\n")
+
+	return f.save()
+}
+
+// AddToReturnedLiteral adds element, a "Key: value" pair, to the typeName composite
+// literal the final return statement of the function funcName returns, unless the
+// literal sets that key already. funcName names a method as Type.Method. It reports
+// whether the file changed.
+func AddToReturnedLiteral(path, funcName, typeName, element string) (bool, error) {
+	f, err := load(path)
+	if err != nil {
+		return false, err
+	}
+
+	body := f.funcBody(funcName)
+	if body == nil {
+		return false, f.shapeError("no function %s", funcName)
+	}
+
+	var lit *ast.CompositeLit
+	if n := len(body.List); n > 0 {
+		if ret, ok := body.List[n-1].(*ast.ReturnStmt); ok {
+			for _, result := range ret.Results {
+				if unary, ok := result.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+					result = unary.X
+				}
+				if candidate, ok := result.(*ast.CompositeLit); ok && candidate.Type != nil && nodeString(f.fset, candidate.Type) == typeName {
+					lit = candidate
+					break
+				}
+			}
+		}
+	}
+	if lit == nil {
+		return false, f.shapeError("%s does not end returning a %s literal", funcName, typeName)
+	}
+
+	expr, err := parser.ParseExpr(typeName + "{" + element + "}")
+	if err != nil {
+		return false, errors.Wrap(err, "invalid literal element %q", element)
+	}
+	elts := expr.(*ast.CompositeLit).Elts
+	if len(elts) != 1 {
+		return false, errors.New("invalid literal element %q", element)
+	}
+	kv, ok := elts[0].(*ast.KeyValueExpr)
+	if !ok {
+		return false, errors.New("literal element %q is not a Key: value pair", element)
+	}
+	key := nodeString(token.NewFileSet(), kv.Key)
+	for _, elt := range lit.Elts {
+		if existing, ok := elt.(*ast.KeyValueExpr); ok && nodeString(f.fset, existing.Key) == key {
+			return false, nil
+		}
+	}
+
+	if err := f.appendElement(lit, nodeString(token.NewFileSet(), kv), typeName); err != nil {
+		return false, err
+	}
+
+	return f.save()
+}
+
+// AddDecls appends to the file at path the declarations of source the package of the
+// file does not declare yet, matched by name: Type.Method for methods. imports maps the
+// import paths the declarations use to their names; only those the added declarations
+// refer to are imported. It reports whether the file changed.
+func AddDecls(path string, imports map[string]string, source string) (bool, error) {
+	f, err := load(path)
+	if err != nil {
+		return false, err
+	}
+
+	declared, err := f.packageDecls()
+	if err != nil {
+		return false, err
+	}
+
+	fset := token.NewFileSet()
+	src := "package p\n\n" + source
+	parsed, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid declarations")
+	}
+
+	var missing []string
+	for _, decl := range parsed.Decls {
+		names := declNames(decl)
+		if len(names) == 0 || declared[names[0]] {
+			continue
+		}
+		for _, name := range names {
+			declared[name] = true
+		}
+
+		start := decl.Pos()
+		if doc := declDoc(decl); doc != nil {
+			start = doc.Pos()
+		}
+		missing = append(missing, src[fset.Position(start).Offset:fset.Position(decl.End()).Offset])
+	}
+	if len(missing) == 0 {
+		return false, nil
+	}
+
+	decls, err := formatDecls(strings.Join(missing, "\n\n"))
+	if err != nil {
+		return false, err
+	}
+
+	used := map[string]string{}
+	for importPath, name := range imports {
+		if strings.Contains(decls, name+".") {
+			used[importPath] = name
+		}
+	}
+	if err := f.requireImports(used); err != nil {
+		return false, err
+	}
+
+	separator := "\n"
+	if !bytes.HasSuffix(f.src, []byte("\n")) {
+		separator = "\n\n"
+	}
+	f.insert(len(f.src), separator+decls)
+
+	return f.save()
+}
+
+// AddStatements appends the statements the function funcName does not contain yet to its
+// body, before a final return statement. funcName names a method as Type.Method. When
+// the body is a single call taking a function literal, such as once.Do, the statements
+// go into that literal. imports maps the import paths the statements need to
+// the package names they use; missing imports are added. It reports whether the file changed.
+func AddStatements(path, funcName string, imports map[string]string, stmts []string) (bool, error) {
+	f, err := load(path)
+	if err != nil {
+		return false, err
+	}
+
+	body := f.funcBody(funcName)
+	if body == nil {
+		return false, f.shapeError("no function %s", funcName)
+	}
+
+	existing := map[string]bool{}
+	for _, stmt := range body.List {
+		existing[nodeString(f.fset, stmt)] = true
+	}
+
+	var missing []string
+	for _, source := range stmts {
+		stmt, err := parseStmt(source)
+		if err != nil {
+			return false, errors.Wrap(err, "invalid statement %q", source)
+		}
+		if text := nodeString(token.NewFileSet(), stmt); !existing[text] {
+			existing[text] = true
+			missing = append(missing, text)
+		}
+	}
+	if len(missing) == 0 {
+		return false, nil
+	}
+
+	paths := make([]string, 0, len(imports))
+	for importPath := range imports {
+		paths = append(paths, importPath)
+	}
+	sort.Strings(paths)
+	for _, importPath := range paths {
+		if err := f.requireImport(importPath, imports[importPath]); err != nil {
+			return false, err
+		}
+	}
+
+	rbrace := f.offset(body.Rbrace)
+	start, ok := f.lineStart(rbrace)
+	if !ok {
+		return false, f.shapeError("closing brace of %s does not start a line", funcName)
+	}
+	indent := f.indentOf(body.List, rbrace)
+
+	if n := len(body.List); n > 0 {
+		if ret, isReturn := body.List[n-1].(*ast.ReturnStmt); isReturn {
+			pos := f.offset(ret.Pos())
+			if start, ok = f.lineStart(pos); !ok {
+				return false, f.shapeError("return statement of %s does not start a line", funcName)
+			}
+			indent = string(f.src[start:pos])
+		}
+	}
+
+	var text strings.Builder
+	for _, stmt := range missing {
+		for _, line := range strings.Split(stmt, "\n") {
+			text.WriteString(indent + line + "\n")
+		}
+	}
+	f.insert(start, text.String())
+
+	return f.save()
+}
+
+// file is a parsed Go file with pending insertions and imports.
+type file struct {
+	path    string
+	src     []byte
+	fset    *token.FileSet
+	ast     *ast.File
+	inserts []insertion
+	imports map[string]string // import path to name, empty for the default name
+}
+
+type insertion struct {
+	offset int
+	text   string
+}
+
+func load(path string) (*file, error) {
+	src, err := initshared.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, &ShapeError{Path: path, Reason: "file not found"}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read %s", path)
+	}
+
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, &ShapeError{Path: path, Reason: "not valid Go: " + err.Error()}
+	}
+
+	return &file{path: path, src: src, fset: fset, ast: parsed}, nil
+}
+
+func (f *file) shapeError(format string, args ...interface{}) error {
+	return &ShapeError{Path: f.path, Reason: fmt.Sprintf(format, args...)}
+}
+
+func (f *file) insert(offset int, text string) {
+	f.inserts = append(f.inserts, insertion{offset: offset, text: text})
+}
+
+// save applies the insertions and imports and writes the file gofmt-formatted, refusing
+// results that do not parse.
+func (f *file) save() (bool, error) {
+	if len(f.inserts) == 0 && len(f.imports) == 0 {
+		return false, nil
+	}
+
+	sort.SliceStable(f.inserts, func(i, j int) bool { return f.inserts[i].offset < f.inserts[j].offset })
+	var out bytes.Buffer
+	last := 0
+	for _, ins := range f.inserts {
+		out.Write(f.src[last:ins.offset])
+		out.WriteString(ins.text)
+		last = ins.offset
+	}
+	out.Write(f.src[last:])
+
+	fset := token.NewFileSet()
+	edited, err := parser.ParseFile(fset, f.path, out.Bytes(), parser.ParseComments)
+	if err != nil {
+		return false, errors.Wrap(err, "edit of %s produced invalid Go", f.path)
+	}
+	for importPath, name := range f.imports {
+		astutil.AddNamedImport(fset, edited, name, importPath)
+	}
+
+	var formatted bytes.Buffer
+	if err := format.Node(&formatted, fset, edited); err != nil {
+		return false, errors.Wrap(err, "failed to format %s", f.path)
+	}
+	result, err := groupStdImports(formatted.Bytes())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to format %s", f.path)
+	}
+	if err := initshared.WriteFile(f.path, result, true); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// appendElement inserts element after the last element of lit, on a line of its own
+// unless lit is written on one line. name identifies lit in errors.
+func (f *file) appendElement(lit *ast.CompositeLit, element, name string) error {
+	rbrace := f.offset(lit.Rbrace)
+	switch {
+	case len(lit.Elts) == 0 && f.line(lit.Lbrace) == f.line(lit.Rbrace):
+		f.insert(rbrace, "\n\t"+element+",\n")
+	case len(lit.Elts) > 0 && f.line(lit.Elts[len(lit.Elts)-1].End()) == f.line(lit.Rbrace):
+		f.insert(rbrace, ", "+element)
+	default:
+		start, ok := f.lineStart(rbrace)
+		if !ok {
+			return f.shapeError("closing brace of %s does not start a line", name)
+		}
+		f.insert(start, f.indentOf(lit.Elts, rbrace)+element+",\n")
+	}
+
+	return nil
+}
+
+func (f *file) offset(pos token.Pos) int {
+	return f.fset.Position(pos).Offset
+}
+
+func (f *file) line(pos token.Pos) int {
+	return f.fset.Position(pos).Line
+}
+
+// lineStart returns the offset of the start of the line holding offset, provided only
+// whitespace precedes offset on that line.
+func (f *file) lineStart(offset int) (int, bool) {
+	start := bytes.LastIndexByte(f.src[:offset], '\n') + 1
+	return start, strings.TrimSpace(string(f.src[start:offset])) == ""
+}
+
+// indentOf returns the indentation of the last of nodes, or of the closing brace at
+// rbrace plus a tab when there are none.
+func (f *file) indentOf(nodes interface{}, rbrace int) string {
+	var last ast.Node
+	switch list := nodes.(type) {
+	case []ast.Expr:
+		if len(list) > 0 {
+			last = list[len(list)-1]
+		}
+	case []ast.Stmt:
+		if len(list) > 0 {
+			last = list[len(list)-1]
+		}
+	case []*ast.Field:
+		if len(list) > 0 {
+			last = list[len(list)-1]
+		}
+	}
+
+	if last != nil {
+		offset := f.offset(last.Pos())
+		start := bytes.LastIndexByte(f.src[:offset], '\n') + 1
+		return leadingSpace(string(f.src[start:offset]))
+	}
+
+	start := bytes.LastIndexByte(f.src[:rbrace], '\n') + 1
+	return leadingSpace(string(f.src[start:rbrace])) + "\t"
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+// declaredNames returns the names of the top-level variables and constants.
+func (f *file) declaredNames() map[string]bool {
+	names := map[string]bool{}
+	for _, decl := range f.ast.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || (gen.Tok != token.VAR && gen.Tok != token.CONST) {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				names[name.Name] = true
+			}
+		}
+	}
+
+	return names
+}
+
+// packageDecls returns the names declared by the package of the file: those of its
+// other files too, test files aside.
+func (f *file) packageDecls() (map[string]bool, error) {
+	names := map[string]bool{}
+	for _, decl := range f.ast.Decls {
+		for _, name := range declNames(decl) {
+			names[name] = true
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(f.path), "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range paths {
+		if filepath.Clean(other) == filepath.Clean(f.path) || strings.HasSuffix(other, "_test.go") {
+			continue
+		}
+		src, err := initshared.ReadFile(other)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read %s", other)
+		}
+		parsed, err := parser.ParseFile(token.NewFileSet(), other, src, parser.SkipObjectResolution)
+		if err != nil || parsed.Name.Name != f.ast.Name.Name {
+			continue
+		}
+		for _, decl := range parsed.Decls {
+			for _, name := range declNames(decl) {
+				names[name] = true
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// declNames returns the names decl declares: Type.Method for methods.
+func declNames(decl ast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if receiver := receiverType(d); receiver != "" {
+			return []string{receiver + "." + d.Name.Name}
+		}
+		return []string{d.Name.Name}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+func (f *file) sliceLiteral(name string) *ast.CompositeLit {
+	for _, decl := range f.ast.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, ident := range value.Names {
+				if ident.Name != name || i >= len(value.Values) {
+					continue
+				}
+				lit, ok := value.Values[i].(*ast.CompositeLit)
+				if !ok {
+					return nil
+				}
+				if array, ok := lit.Type.(*ast.ArrayType); !ok || array.Len != nil {
+					return nil
+				}
+				return lit
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *file) structType(name string) *ast.StructType {
+	for _, decl := range f.ast.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if typeSpec.Name.Name != name {
+				continue
+			}
+			structType, _ := typeSpec.Type.(*ast.StructType)
+			return structType
+		}
+	}
+
+	return nil
+}
+
+// fieldNames returns the names of fields; embedded fields are named after their type.
+func fieldNames(fields []*ast.Field) map[string]bool {
+	names := map[string]bool{}
+	for _, field := range fields {
+		for _, name := range field.Names {
+			names[name.Name] = true
+		}
+		if len(field.Names) == 0 {
+			typ := field.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			switch t := typ.(type) {
+			case *ast.Ident:
+				names[t.Name] = true
+			case *ast.SelectorExpr:
+				names[t.Sel.Name] = true
+			}
+		}
+	}
+
+	return names
+}
+
+// funcBody returns the block statements are added to in the function name: the body of
+// the function literal when the body is a single call taking one, the body otherwise.
+func (f *file) funcBody(name string) *ast.BlockStmt {
+	receiver, name, isMethod := strings.Cut(name, ".")
+	if !isMethod {
+		receiver, name = "", receiver
+	}
+
+	for _, decl := range f.ast.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != name || fn.Body == nil || receiverType(fn) != receiver {
+			continue
+		}
+
+		if len(fn.Body.List) == 1 {
+			if expr, ok := fn.Body.List[0].(*ast.ExprStmt); ok {
+				if call, ok := expr.X.(*ast.CallExpr); ok && len(call.Args) > 0 {
+					if lit, ok := call.Args[len(call.Args)-1].(*ast.FuncLit); ok {
+						return lit.Body
+					}
+				}
+			}
+		}
+		return fn.Body
+	}
+
+	return nil
+}
+
+// receiverType returns the name of the receiver type of fn, empty for functions.
+func receiverType(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// importName returns the name importPath is imported under.
+func (f *file) importName(importPath string) (string, bool) {
+	for _, imp := range f.ast.Imports {
+		if value, err := strconv.Unquote(imp.Path.Value); err != nil || value != importPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name, true
+		}
+		return defaultName(importPath), true
+	}
+
+	return "", false
+}
+
+// requireImport adds importPath under name unless it is imported already, in which case
+// it must be under name.
+func (f *file) requireImport(importPath, name string) error {
+	return f.requireImports(map[string]string{importPath: name})
+}
+
+// requireImports is requireImport for several imports. save adds them with astutil,
+// in the import group of the paths they share the longest prefix with.
+func (f *file) requireImports(imports map[string]string) error {
+	for importPath, name := range imports {
+		if current, ok := f.importName(importPath); ok {
+			if current != name {
+				return f.shapeError("imports %s as %s, expected %s", importPath, current, name)
+			}
+			continue
+		}
+		if f.imports == nil {
+			f.imports = make(map[string]string)
+		}
+		if name == defaultName(importPath) {
+			name = ""
+		}
+		f.imports[importPath] = name
+	}
+	return nil
+}
+
+// groupStdImports moves the standard library imports of an import group that also has
+// other imports to a group of their own, ahead of them. astutil joins new imports to the
+// group of their closest match, so a module import added to a file importing only the
+// standard library would otherwise end up in its group.
+func groupStdImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(string(src), "\n")
+	changed := false
+	for _, decl := range parsed.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() {
+			continue
+		}
+
+		// groups are runs of import specs on consecutive lines, each one line long
+		var groups [][]*ast.ImportSpec
+		for i, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			if i == 0 || fset.Position(imp.Pos()).Line != fset.Position(gen.Specs[i-1].End()).Line+1 {
+				groups = append(groups, nil)
+			}
+			groups[len(groups)-1] = append(groups[len(groups)-1], imp)
+		}
+
+		for _, group := range groups {
+			var std, other []string
+			for _, imp := range group {
+				line := fset.Position(imp.Pos()).Line - 1
+				if imp.Doc != nil || fset.Position(imp.End()).Line-1 != line {
+					std, other = nil, nil
+					break
+				}
+				if importPath, _ := strconv.Unquote(imp.Path.Value); strings.Contains(strings.Split(importPath, "/")[0], ".") {
+					other = append(other, lines[line])
+				} else {
+					std = append(std, lines[line])
+				}
+			}
+			if len(std) == 0 || len(other) == 0 {
+				continue
+			}
+
+			first := fset.Position(group[0].Pos()).Line - 1
+			lines[first] = strings.Join(std, "") + "\n" + strings.Join(other, "")
+			for line := first + 1; line < first+len(group); line++ {
+				lines[line] = ""
+			}
+			changed = true
+		}
+	}
+	if !changed {
+		return src, nil
+	}
+
+	return format.Source([]byte(strings.Join(lines, "")))
+}
+
+// defaultName guesses the package name of importPath from its last element, dropping
+// the -go and go- affixes Go modules commonly carry.
+func defaultName(importPath string) string {
+	name := path.Base(importPath)
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "go-"), "-go")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+func parseStmt(source string) (ast.Stmt, error) {
+	parsed, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc _() {\n"+source+"\n}\n", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	body := parsed.Decls[0].(*ast.FuncDecl).Body
+	if len(body.List) != 1 {
+		return nil, errors.New("expected exactly one statement")
+	}
+
+	return body.List[0], nil
+}
+
+// formatDecls gofmt-formats top-level declarations.
+func formatDecls(decls string) (string, error) {
+	const header = "package p\n\n"
+	formatted, err := format.Source([]byte(header + decls))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid declarations")
+	}
+
+	return strings.TrimPrefix(string(formatted), header), nil
+}
+
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
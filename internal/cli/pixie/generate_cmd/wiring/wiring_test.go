@@ -0,0 +1,741 @@
+package wiring
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSource(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "file.go")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	return path
+}
+
+func readSource(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+
+	return string(content)
+}
+
+const tokensFile = `package di
+
+import pdi "github.com/pixie-sh/di-go"
+
+// global bundles
+var (
+	RegistryTokenDatabaseORM = pdi.RegisterInjectionToken("database_orm")
+)
+`
+
+func TestAddTokens(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		tokens      []Token
+		want        string
+		wantChanged bool
+		wantShape   bool
+	}{
+		{
+			name:   "appends a block",
+			source: tokensFile,
+			tokens: []Token{
+				{Name: "RegistryTokenOrdersDataLayer", Key: "orders_data_layer"},
+				{Name: "RegistryTokenOrdersBusinessLayer", Key: "orders_business_layer"},
+			},
+			want: tokensFile + `
+// Orders domain tokens
+var (
+	RegistryTokenOrdersDataLayer     = pdi.RegisterInjectionToken("orders_data_layer")
+	RegistryTokenOrdersBusinessLayer = pdi.RegisterInjectionToken("orders_business_layer")
+)
+`,
+			wantChanged: true,
+		},
+		{
+			name:   "skips declared tokens",
+			source: tokensFile,
+			tokens: []Token{
+				{Name: "RegistryTokenDatabaseORM", Key: "database_orm"},
+				{Name: "RegistryTokenOrdersService", Key: "orders_service"},
+			},
+			want: tokensFile + `
+// Orders domain tokens
+var (
+	RegistryTokenOrdersService = pdi.RegisterInjectionToken("orders_service")
+)
+`,
+			wantChanged: true,
+		},
+		{
+			name:   "all tokens declared",
+			source: tokensFile,
+			tokens: []Token{{Name: "RegistryTokenDatabaseORM", Key: "database_orm"}},
+			want:   tokensFile,
+		},
+		{
+			name:      "di package not imported",
+			source:    "package di\n",
+			tokens:    []Token{{Name: "RegistryTokenOrdersService", Key: "orders_service"}},
+			want:      "package di\n",
+			wantShape: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSource(t, tt.source)
+
+			changed, err := AddTokens(path, "Orders domain tokens", tt.tokens)
+			if tt.wantShape {
+				if !IsShapeError(err) {
+					t.Fatalf("AddTokens() error = %v, want a ShapeError", err)
+				}
+			} else if err != nil {
+				t.Fatalf("AddTokens() error = %v", err)
+			}
+
+			if changed != tt.wantChanged {
+				t.Errorf("AddTokens() changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if got := readSource(t, path); got != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendToSlice(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		want      string
+		wantShape bool
+	}{
+		{
+			name:   "multi-line literal",
+			source: "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateOrdersTable1,\n}\n",
+			want:   "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateOrdersTable1,\n\t&CreateItemTable2,\n}\n",
+		},
+		{
+			name:   "single-line literal",
+			source: "package m\n\nvar Migrations = []*database.Migration{&CreateOrdersTable1}\n",
+			want:   "package m\n\nvar Migrations = []*database.Migration{&CreateOrdersTable1, &CreateItemTable2}\n",
+		},
+		{
+			name:   "empty literal",
+			source: "package m\n\nvar Migrations = []*database.Migration{}\n",
+			want:   "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateItemTable2,\n}\n",
+		},
+		{
+			name:   "element already present",
+			source: "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateItemTable2,\n}\n",
+			want:   "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateItemTable2,\n}\n",
+		},
+		{
+			name:      "no such variable",
+			source:    "package m\n\nvar Others = []int{1}\n",
+			want:      "package m\n\nvar Others = []int{1}\n",
+			wantShape: true,
+		},
+		{
+			name:      "not a slice literal",
+			source:    "package m\n\nvar Migrations = load()\n",
+			want:      "package m\n\nvar Migrations = load()\n",
+			wantShape: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSource(t, tt.source)
+
+			_, err := AppendToSlice(path, "Migrations", "&CreateItemTable2")
+			if tt.wantShape {
+				if !IsShapeError(err) {
+					t.Fatalf("AppendToSlice() error = %v, want a ShapeError", err)
+				}
+			} else if err != nil {
+				t.Fatalf("AppendToSlice() error = %v", err)
+			}
+
+			if got := readSource(t, path); got != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertIntoSlice(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		before []string
+		want   string
+	}{
+		{
+			name:   "before a dependent migration",
+			source: "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateOrdersTable1,\n\t&CreateLineItemTable3,\n}\n",
+			before: []string{"&CreateLineItemTable"},
+			want:   "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateOrdersTable1,\n\t&CreateItemTable2,\n\t&CreateLineItemTable3,\n}\n",
+		},
+		{
+			name:   "single-line literal",
+			source: "package m\n\nvar Migrations = []*database.Migration{&CreateOrdersTable1, &CreateLineItemTable3}\n",
+			before: []string{"&CreateLineItemTable"},
+			want:   "package m\n\nvar Migrations = []*database.Migration{&CreateOrdersTable1, &CreateItemTable2, &CreateLineItemTable3}\n",
+		},
+		{
+			name:   "no dependent appends",
+			source: "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateOrdersTable1,\n}\n",
+			before: []string{"&CreateLineItemTable"},
+			want:   "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateOrdersTable1,\n\t&CreateItemTable2,\n}\n",
+		},
+		{
+			name:   "already present stays in place",
+			source: "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateLineItemTable3,\n\t&CreateItemTable2,\n}\n",
+			before: []string{"&CreateLineItemTable"},
+			want:   "package m\n\nvar Migrations = []*database.Migration{\n\t&CreateLineItemTable3,\n\t&CreateItemTable2,\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSource(t, tt.source)
+
+			if _, err := InsertIntoSlice(path, "Migrations", "&CreateItemTable2", tt.before); err != nil {
+				t.Fatalf("InsertIntoSlice() error = %v", err)
+			}
+
+			if got := readSource(t, path); got != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+const registryFile = `package orders
+
+import (
+	"sync"
+
+	"github.com/pixie-sh/di-go"
+)
+
+var once sync.Once
+
+func Registry() {
+	once.Do(func() {
+		//services
+		errors.Must(di.Register[*orders_services.OrdersService](orders_services.RegistryOrdersService))
+	})
+}
+`
+
+func TestAddStatements(t *testing.T) {
+	stmt := "errors.Must(di.Register[*orders_services.EmailService](orders_services.RegistryEmailService))"
+	imports := map[string]string{
+		"github.com/pixie-sh/di-go":     "di",
+		"github.com/pixie-sh/errors-go": "errors",
+	}
+
+	t.Run("adds statements and imports once", func(t *testing.T) {
+		path := writeSource(t, registryFile)
+
+		for i := 0; i < 2; i++ {
+			changed, err := AddStatements(path, "Registry", imports, []string{stmt})
+			if err != nil {
+				t.Fatalf("AddStatements() error = %v", err)
+			}
+			if changed != (i == 0) {
+				t.Errorf("run %d: AddStatements() changed = %v", i, changed)
+			}
+		}
+
+		want := `package orders
+
+import (
+	"sync"
+
+	"github.com/pixie-sh/di-go"
+	"github.com/pixie-sh/errors-go"
+)
+
+var once sync.Once
+
+func Registry() {
+	once.Do(func() {
+		//services
+		errors.Must(di.Register[*orders_services.OrdersService](orders_services.RegistryOrdersService))
+		errors.Must(di.Register[*orders_services.EmailService](orders_services.RegistryEmailService))
+	})
+}
+`
+		if got := readSource(t, path); got != want {
+			t.Errorf("file =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("plain function body", func(t *testing.T) {
+		path := writeSource(t, "package ms\n\nfunc init() {\n\tbundles.Registry()\n}\n")
+
+		if _, err := AddStatements(path, "init", nil, []string{"orders.Registry()"}); err != nil {
+			t.Fatalf("AddStatements() error = %v", err)
+		}
+
+		want := "package ms\n\nfunc init() {\n\tbundles.Registry()\n\torders.Registry()\n}\n"
+		if got := readSource(t, path); got != want {
+			t.Errorf("file =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("method before the final return", func(t *testing.T) {
+		source := "package ms\n\nfunc (c httpControllers) SetupHTTP() error {\n\tc.setupOrderRoutes()\n\n\treturn nil\n}\n"
+		path := writeSource(t, source)
+
+		if _, err := AddStatements(path, "httpControllers.SetupHTTP", nil, []string{"c.setupItemRoutes()"}); err != nil {
+			t.Fatalf("AddStatements() error = %v", err)
+		}
+
+		want := "package ms\n\nfunc (c httpControllers) SetupHTTP() error {\n\tc.setupOrderRoutes()\n\n\tc.setupItemRoutes()\n\treturn nil\n}\n"
+		if got := readSource(t, path); got != want {
+			t.Errorf("file =\n%s\nwant\n%s", got, want)
+		}
+
+		if _, err := AddStatements(path, "SetupHTTP", nil, []string{"c.setupItemRoutes()"}); !IsShapeError(err) {
+			t.Errorf("AddStatements() on a method without its receiver: error = %v, want a ShapeError", err)
+		}
+	})
+
+	t.Run("missing function", func(t *testing.T) {
+		path := writeSource(t, "package ms\n")
+
+		if _, err := AddStatements(path, "Registry", nil, []string{"orders.Registry()"}); !IsShapeError(err) {
+			t.Fatalf("AddStatements() error = %v, want a ShapeError", err)
+		}
+	})
+
+	t.Run("imports join their groups", func(t *testing.T) {
+		source := `package message_packs
+
+import (
+	"context"
+
+	"github.com/pixie-sh/core-go/pkg/types"
+
+	"example.com/shop/infra/event"
+)
+
+var OrdersMessagePack = []types.PayloadType{event.OrderPlacedEventType}
+
+func Register(ctx context.Context) {
+}
+`
+		path := writeSource(t, source)
+
+		imports := map[string]string{
+			"fmt": "fmt",
+			"github.com/pixie-sh/core-go/infra/message_factory": "message_factory",
+			"example.com/shop/pkg/context":                      "pixiecontext",
+		}
+		stmts := []string{"registerMessagePack(ctx, OrdersMessagePack)", "pixiecontext.GetCtxLogger(ctx).Log(fmt.Sprint(message_factory.Singleton))"}
+		if _, err := AddStatements(path, "Register", imports, stmts); err != nil {
+			t.Fatalf("AddStatements() error = %v", err)
+		}
+
+		want := `package message_packs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pixie-sh/core-go/infra/message_factory"
+	"github.com/pixie-sh/core-go/pkg/types"
+
+	"example.com/shop/infra/event"
+	pixiecontext "example.com/shop/pkg/context"
+)
+
+var OrdersMessagePack = []types.PayloadType{event.OrderPlacedEventType}
+
+func Register(ctx context.Context) {
+	registerMessagePack(ctx, OrdersMessagePack)
+	pixiecontext.GetCtxLogger(ctx).Log(fmt.Sprint(message_factory.Singleton))
+}
+`
+		got := readSource(t, path)
+		if got != want {
+			t.Errorf("file =\n%s\nwant\n%s", got, want)
+		}
+		if formatted, err := format.Source([]byte(got)); err != nil || string(formatted) != got {
+			t.Errorf("file is not gofmt-formatted: error = %v", err)
+		}
+	})
+
+	t.Run("module imports of a file importing the standard library", func(t *testing.T) {
+		path := writeSource(t, "package message_packs\n\nimport (\n\t\"context\"\n)\n\nfunc Register(ctx context.Context) {\n}\n")
+
+		imports := map[string]string{"github.com/pixie-sh/core-go/pkg/types": "types", "time": "time"}
+		if _, err := AddStatements(path, "Register", imports, []string{"_ = []types.PayloadType{}", "_ = time.Now()"}); err != nil {
+			t.Fatalf("AddStatements() error = %v", err)
+		}
+
+		want := "package message_packs\n\nimport (\n\t\"context\"\n\t\"time\"\n\n\t\"github.com/pixie-sh/core-go/pkg/types\"\n)\n"
+		if got := readSource(t, path); !strings.HasPrefix(got, want) {
+			t.Errorf("file =\n%s\nwant it to start with\n%s", got, want)
+		}
+	})
+
+	t.Run("conflicting import name", func(t *testing.T) {
+		path := writeSource(t, "package ms\n\nimport pdi \"github.com/pixie-sh/di-go\"\n\nfunc init() {\n}\n")
+
+		_, err := AddStatements(path, "init", map[string]string{"github.com/pixie-sh/di-go": "di"}, []string{"di.Reset()"})
+		if !IsShapeError(err) {
+			t.Fatalf("AddStatements() error = %v, want a ShapeError", err)
+		}
+	})
+}
+
+func TestAddDecls(t *testing.T) {
+	source := "package ms\n\ntype httpControllers struct{}\n\n// handleList handles GET /orders\nfunc (c httpControllers) handleList() error {\n\treturn nil\n}\n"
+	decls := `// handleCancel handles POST /orders/:id/cancel
+func (c httpControllers) handleCancel(id uid.UID) error {
+	return errors.New("not implemented")
+}
+
+// handleList handles GET /orders
+func (c httpControllers) handleList() error { return nil }
+
+// CancelOrderRequest is the body of a cancel request.
+type CancelOrderRequest struct{}
+`
+	imports := map[string]string{
+		"github.com/pixie-sh/errors-go":   "errors",
+		"github.com/pixie-sh/core-go/uid": "uid",
+		"context":                         "context",
+	}
+
+	t.Run("appends missing declarations once", func(t *testing.T) {
+		path := writeSource(t, source)
+
+		for i := 0; i < 2; i++ {
+			changed, err := AddDecls(path, imports, decls)
+			if err != nil {
+				t.Fatalf("AddDecls() error = %v", err)
+			}
+			if changed != (i == 0) {
+				t.Errorf("run %d: AddDecls() changed = %v", i, changed)
+			}
+		}
+
+		want := `package ms
+
+import (
+	"github.com/pixie-sh/core-go/uid"
+	"github.com/pixie-sh/errors-go"
+)
+
+type httpControllers struct{}
+
+// handleList handles GET /orders
+func (c httpControllers) handleList() error {
+	return nil
+}
+
+// handleCancel handles POST /orders/:id/cancel
+func (c httpControllers) handleCancel(id uid.UID) error {
+	return errors.New("not implemented")
+}
+
+// CancelOrderRequest is the body of a cancel request.
+type CancelOrderRequest struct{}
+`
+		if got := readSource(t, path); got != want {
+			t.Errorf("file =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("declared in another file of the package", func(t *testing.T) {
+		path := writeSource(t, "package ms\n")
+		other := filepath.Join(filepath.Dir(path), "models.go")
+		if err := os.WriteFile(other, []byte("package ms\n\ntype CancelOrderRequest struct{}\n"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", other, err)
+		}
+
+		if _, err := AddDecls(path, nil, "type CancelOrderRequest struct{}\n\ntype OrderDetails struct{}\n"); err != nil {
+			t.Fatalf("AddDecls() error = %v", err)
+		}
+
+		want := "package ms\n\ntype OrderDetails struct{}\n"
+		if got := readSource(t, path); got != want {
+			t.Errorf("file =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("invalid declarations", func(t *testing.T) {
+		path := writeSource(t, source)
+
+		if _, err := AddDecls(path, nil, "func broken("); err == nil {
+			t.Fatalf("AddDecls() error = nil, want an error")
+		}
+	})
+}
+
+const bundleFile = `package bundles
+
+// AuthorizationGatesBundle holds authorization gates
+type AuthorizationGatesBundle struct {
+	config AuthorizationGatesBundleConfiguration
+
+	IsAuthenticated gates.IsAuthenticated
+}
+
+// NewAuthorizationGatesBundle creates a new AuthorizationGatesBundle
+func NewAuthorizationGatesBundle(ctx context.Context, config AuthorizationGatesBundleConfiguration) (AuthorizationGatesBundle, error) {
+	return AuthorizationGatesBundle{
+		config:          config,
+		IsAuthenticated: isAuthenticated,
+	}, nil
+}
+`
+
+func TestAddField(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		field   string
+		want    string
+		changed bool
+	}{
+		{
+			name:    "appends the field",
+			source:  bundleFile,
+			field:   "HasActiveSubscription gates.HasActiveSubscription",
+			want:    "\tIsAuthenticated       gates.IsAuthenticated\n\tHasActiveSubscription gates.HasActiveSubscription\n}",
+			changed: true,
+		},
+		{
+			name:   "field already declared",
+			source: bundleFile,
+			field:  "IsAuthenticated gates.IsAuthenticated",
+			want:   "\tIsAuthenticated gates.IsAuthenticated\n}",
+		},
+		{
+			name:    "empty struct",
+			source:  "package p\n\ntype AuthorizationGatesBundle struct{}\n",
+			field:   "HasActiveSubscription gates.HasActiveSubscription",
+			want:    "type AuthorizationGatesBundle struct {\n\tHasActiveSubscription gates.HasActiveSubscription\n}",
+			changed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSource(t, tt.source)
+
+			changed, err := AddField(path, "AuthorizationGatesBundle", tt.field)
+			if err != nil {
+				t.Fatalf("AddField() error = %v", err)
+			}
+			if changed != tt.changed {
+				t.Errorf("AddField() changed = %v, want %v", changed, tt.changed)
+			}
+			if got := readSource(t, path); !strings.Contains(got, tt.want) {
+				t.Errorf("file = %s, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("gofmt-formatted", func(t *testing.T) {
+		path := writeSource(t, bundleFile)
+
+		if _, err := AddField(path, "AuthorizationGatesBundle", "HasActiveSubscription gates.HasActiveSubscription"); err != nil {
+			t.Fatalf("AddField() error = %v", err)
+		}
+		got := readSource(t, path)
+		if formatted, err := format.Source([]byte(got)); err != nil || string(formatted) != got {
+			t.Errorf("file = %s, want it gofmt-formatted (error = %v)", got, err)
+		}
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		path := writeSource(t, bundleFile)
+
+		if _, err := AddField(path, "Missing", "A int"); !IsShapeError(err) {
+			t.Fatalf("AddField() error = %v, want a ShapeError", err)
+		}
+	})
+}
+
+func TestAddToReturnedLiteral(t *testing.T) {
+	t.Run("adds the element once", func(t *testing.T) {
+		path := writeSource(t, bundleFile)
+
+		for i := 0; i < 2; i++ {
+			changed, err := AddToReturnedLiteral(path, "NewAuthorizationGatesBundle", "AuthorizationGatesBundle", "HasActiveSubscription: hasActiveSubscription")
+			if err != nil {
+				t.Fatalf("AddToReturnedLiteral() error = %v", err)
+			}
+			if changed != (i == 0) {
+				t.Errorf("run %d: AddToReturnedLiteral() changed = %v", i, changed)
+			}
+		}
+
+		want := "\t\tIsAuthenticated:       isAuthenticated,\n\t\tHasActiveSubscription: hasActiveSubscription,\n\t}, nil"
+		if got := readSource(t, path); !strings.Contains(got, want) {
+			t.Errorf("file = %s, want it to contain %q", got, want)
+		}
+	})
+
+	t.Run("key already set", func(t *testing.T) {
+		path := writeSource(t, bundleFile)
+
+		changed, err := AddToReturnedLiteral(path, "NewAuthorizationGatesBundle", "AuthorizationGatesBundle", "IsAuthenticated: other")
+		if err != nil {
+			t.Fatalf("AddToReturnedLiteral() error = %v", err)
+		}
+		if changed {
+			t.Errorf("AddToReturnedLiteral() changed = true, want false")
+		}
+	})
+
+	t.Run("no returned literal", func(t *testing.T) {
+		path := writeSource(t, bundleFile)
+
+		if _, err := AddToReturnedLiteral(path, "NewAuthorizationGatesBundle", "Other", "A: a"); !IsShapeError(err) {
+			t.Fatalf("AddToReturnedLiteral() error = %v, want a ShapeError", err)
+		}
+	})
+
+	t.Run("not a key-value element", func(t *testing.T) {
+		path := writeSource(t, bundleFile)
+
+		if _, err := AddToReturnedLiteral(path, "NewAuthorizationGatesBundle", "AuthorizationGatesBundle", "a"); err == nil {
+			t.Fatalf("AddToReturnedLiteral() error = nil, want an error")
+		}
+	})
+}
+
+func TestMissingFileIsShapeError(t *testing.T) {
+	_, err := AddTokens(filepath.Join(t.TempDir(), "missing.go"), "tokens", []Token{{Name: "A", Key: "a"}})
+	if !IsShapeError(err) {
+		t.Fatalf("AddTokens() error = %v, want a ShapeError", err)
+	}
+}
+
+func TestMergeJSON(t *testing.T) {
+	base := `{
+  "http_server_bundle": {
+    "port": 8080
+  }
+}`
+	generated := `{
+  "http_server_bundle": {
+    "port": 8080,
+    "cors": {"enabled": true}
+  },
+  "cache_bundle": {"addr": "localhost:6379", "pools": [1, 2]}
+}`
+
+	tests := []struct {
+		name    string
+		current string
+		want    string
+	}{
+		{
+			name: "adds missing members",
+			current: `{
+    "http_server_bundle": {
+        "port": 9090
+    },
+    "custom": true
+}
+`,
+			want: `{
+    "http_server_bundle": {
+        "port": 9090,
+        "cors": {
+            "enabled": true
+        }
+    },
+    "custom": true,
+    "cache_bundle": {
+        "addr": "localhost:6379",
+        "pools": [
+            1,
+            2
+        ]
+    }
+}
+`,
+		},
+		{
+			name:    "keeps members the user removed",
+			current: "{\n  \"custom\": true\n}\n",
+			want: `{
+  "custom": true,
+  "cache_bundle": {
+    "addr": "localhost:6379",
+    "pools": [
+      1,
+      2
+    ]
+  }
+}
+`,
+		},
+		{
+			name:    "fills an empty object",
+			current: "{ }",
+			want:    "{\n  \"cache_bundle\": {\n    \"addr\": \"localhost:6379\",\n    \"pools\": [\n      1,\n      2\n    ]\n  }\n}",
+		},
+		{
+			name:    "up to date",
+			current: generated,
+			want:    generated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeJSON([]byte(tt.current), []byte(base), []byte(generated))
+			if err != nil {
+				t.Fatalf("MergeJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MergeJSON() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("without base", func(t *testing.T) {
+		got, err := MergeJSON([]byte("{\n  \"custom\": true\n}\n"), nil, []byte(`{"http_server_bundle": {"port": 8080}}`))
+		if err != nil {
+			t.Fatalf("MergeJSON() error = %v", err)
+		}
+		want := "{\n  \"custom\": true,\n  \"http_server_bundle\": {\n    \"port\": 8080\n  }\n}\n"
+		if string(got) != want {
+			t.Errorf("MergeJSON() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := MergeJSON([]byte(`{"a": 1,}`), []byte(base), []byte(generated))
+		if !IsShapeError(err) {
+			t.Fatalf("MergeJSON() error = %v, want a ShapeError", err)
+		}
+	})
+}
@@ -2,9 +2,9 @@ package angular
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/pixie-sh/errors-go"
@@ -24,6 +24,8 @@ type Options struct {
 	SkipPwa   bool   // Skip PWA configuration
 	SkipState bool   // Skip NGXS state management
 	SkipI18n  bool   // Skip internationalization
+
+	generation *shared.Generation // Records generated files in the project manifest; started by Run
 }
 
 // TemplateData holds the data passed to templates
@@ -106,7 +108,7 @@ Examples:
 	cmd.Flags().String("output", ".", "Output directory")
 	cmd.Flags().String("prefix", "app", "Angular component selector prefix (2-5 lowercase letters)")
 	cmd.Flags().String("api-url", "http://localhost:3000", "Default API base URL")
-	cmd.Flags().Bool("force", false, "Force overwrite existing files")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
 	cmd.Flags().Bool("skip-auth", false, "Skip authentication module")
 	cmd.Flags().Bool("skip-pwa", false, "Skip PWA configuration")
 	cmd.Flags().Bool("skip-state", false, "Skip NGXS state management")
@@ -137,6 +139,20 @@ func Run(opts Options) error {
 	fmt.Printf("      State (NGXS): %v\n", !opts.SkipState)
 	fmt.Printf("      i18n: %v\n\n", !opts.SkipI18n)
 
+	generation, err := shared.NewGeneration(opts.Output, "init angular", map[string]string{
+		"name":       opts.Name,
+		"prefix":     opts.Prefix,
+		"api-url":    opts.ApiUrl,
+		"skip-auth":  strconv.FormatBool(opts.SkipAuth),
+		"skip-pwa":   strconv.FormatBool(opts.SkipPwa),
+		"skip-state": strconv.FormatBool(opts.SkipState),
+		"skip-i18n":  strconv.FormatBool(opts.SkipI18n),
+	}, opts.Force)
+	if err != nil {
+		return err
+	}
+	opts.generation = generation
+
 	// Generate GitHub Actions workflows
 	fmt.Println("Generating GitHub Actions workflows...")
 	if err := generateGitHubActions(opts); err != nil {
@@ -149,6 +165,10 @@ func Run(opts Options) error {
 		return errors.Wrap(err, "failed to generate project structure")
 	}
 
+	if err := opts.generation.Finish(); err != nil {
+		return errors.Wrap(err, "failed to save generation manifest")
+	}
+
 	fmt.Printf("\nAngular frontend project initialized successfully!\n\n")
 	printNextSteps(opts)
 
@@ -180,7 +200,7 @@ func validateOptions(opts Options) error {
 func generateGitHubActions(opts Options) error {
 	workflowsDir := filepath.Join(opts.Output, ".github", "workflows")
 
-	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
+	if err := shared.CreateDir(workflowsDir); err != nil {
 		return errors.Wrap(err, "failed to create workflows directory")
 	}
 
@@ -217,14 +237,8 @@ jobs:
 `
 
 	testsPath := filepath.Join(workflowsDir, "tests.yaml")
-	if err := shared.WriteFile(testsPath, []byte(testsContent), opts.Force); err != nil {
-		if !opts.Force {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", testsPath)
-		} else {
-			return errors.Wrap(err, "failed to write tests.yaml")
-		}
-	} else {
-		fmt.Printf("   Generated %s\n", testsPath)
+	if _, err := opts.generation.Write(testsPath, "angular:github_actions/tests.yaml", []byte(testsContent)); err != nil {
+		return errors.Wrap(err, "failed to write tests.yaml")
 	}
 
 	// Angular-specific build workflow
@@ -264,14 +278,8 @@ jobs:
 `
 
 	buildPath := filepath.Join(workflowsDir, "build.yaml")
-	if err := shared.WriteFile(buildPath, []byte(buildContent), opts.Force); err != nil {
-		if !opts.Force {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", buildPath)
-		} else {
-			return errors.Wrap(err, "failed to write build.yaml")
-		}
-	} else {
-		fmt.Printf("   Generated %s\n", buildPath)
+	if _, err := opts.generation.Write(buildPath, "angular:github_actions/build.yaml", []byte(buildContent)); err != nil {
+		return errors.Wrap(err, "failed to write build.yaml")
 	}
 
 	return nil
@@ -307,25 +315,9 @@ func generateProjectStructure(opts Options) error {
 		}
 
 		outputPath := filepath.Join(opts.Output, mapping.outputPath)
-
-		// Check if file exists
-		if !opts.Force && shared.FileExists(outputPath) {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", outputPath)
-			continue
-		}
-
-		// Render template
-		content, err := shared.RenderTemplate(TemplateFS, mapping.templateFile, data)
-		if err != nil {
-			return errors.Wrap(err, "failed to render template %s", mapping.templateFile)
+		if _, err := opts.generation.WriteTemplate(Templates, mapping.templateFile, outputPath, data); err != nil {
+			return errors.Wrap(err, "failed to generate %s", outputPath)
 		}
-
-		// Write file
-		if err := shared.WriteFile(outputPath, content, opts.Force); err != nil {
-			return errors.Wrap(err, "failed to write %s", outputPath)
-		}
-
-		fmt.Printf("   Generated %s\n", outputPath)
 	}
 
 	return nil
@@ -347,129 +339,129 @@ func getTemplateMappings(opts Options) []templateMapping {
 
 	return []templateMapping{
 		// Root configuration files
-		{"templates/angular_json.tmpl", "angular.json", nil},
-		{"templates/package_json.tmpl", "package.json", nil},
-		{"templates/tsconfig_json.tmpl", "tsconfig.json", nil},
-		{"templates/tsconfig_app_json.tmpl", "tsconfig.app.json", nil},
-		{"templates/gitignore.tmpl", ".gitignore", nil},
-		{"templates/editorconfig.tmpl", ".editorconfig", nil},
-		{"templates/eslintrc_json.tmpl", ".eslintrc.json", nil},
-		{"templates/prettierrc_json.tmpl", ".prettierrc.json", nil},
-		{"templates/readme.tmpl", "README.md", nil},
+		{"angular_json.tmpl", "angular.json", nil},
+		{"package_json.tmpl", "package.json", nil},
+		{"tsconfig_json.tmpl", "tsconfig.json", nil},
+		{"tsconfig_app_json.tmpl", "tsconfig.app.json", nil},
+		{"gitignore.tmpl", ".gitignore", nil},
+		{"editorconfig.tmpl", ".editorconfig", nil},
+		{"eslintrc_json.tmpl", ".eslintrc.json", nil},
+		{"prettierrc_json.tmpl", ".prettierrc.json", nil},
+		{"readme.tmpl", "README.md", nil},
 
 		// Entry points
-		{"templates/index_html.tmpl", "src/index.html", nil},
-		{"templates/main_ts.tmpl", "src/main.ts", nil},
-		{"templates/styles_scss.tmpl", "src/styles.scss", nil},
+		{"index_html.tmpl", "src/index.html", nil},
+		{"main_ts.tmpl", "src/main.ts", nil},
+		{"styles_scss.tmpl", "src/styles.scss", nil},
 
 		// App shell
-		{"templates/app_component_ts.tmpl", "src/app/app.component.ts", nil},
-		{"templates/app_component_html.tmpl", "src/app/app.component.html", nil},
-		{"templates/app_component_scss.tmpl", "src/app/app.component.scss", nil},
-		{"templates/app_config_ts.tmpl", "src/app/app.config.ts", nil},
-		{"templates/app_routes_ts.tmpl", "src/app/app.routes.ts", nil},
+		{"app_component_ts.tmpl", "src/app/app.component.ts", nil},
+		{"app_component_html.tmpl", "src/app/app.component.html", nil},
+		{"app_component_scss.tmpl", "src/app/app.component.scss", nil},
+		{"app_config_ts.tmpl", "src/app/app.config.ts", nil},
+		{"app_routes_ts.tmpl", "src/app/app.routes.ts", nil},
 
 		// Environments
-		{"templates/environment_ts.tmpl", "src/environments/environment.ts", nil},
-		{"templates/environment_local_ts.tmpl", "src/environments/environment.local.ts", nil},
-		{"templates/environment_development_ts.tmpl", "src/environments/environment.development.ts", nil},
-		{"templates/environment_staging_ts.tmpl", "src/environments/environment.staging.ts", nil},
+		{"environment_ts.tmpl", "src/environments/environment.ts", nil},
+		{"environment_local_ts.tmpl", "src/environments/environment.local.ts", nil},
+		{"environment_development_ts.tmpl", "src/environments/environment.development.ts", nil},
+		{"environment_staging_ts.tmpl", "src/environments/environment.staging.ts", nil},
 
 		// Core - API services
-		{"templates/api_service_ts.tmpl", "src/app/_core/api/api.service.ts", nil},
-		{"templates/auth_api_service_ts.tmpl", "src/app/_core/api/auth-api.service.ts", func() bool { return hasAuth }},
-		{"templates/user_api_service_ts.tmpl", "src/app/_core/api/user-api.service.ts", func() bool { return hasAuth }},
+		{"api_service_ts.tmpl", "src/app/_core/api/api.service.ts", nil},
+		{"auth_api_service_ts.tmpl", "src/app/_core/api/auth-api.service.ts", func() bool { return hasAuth }},
+		{"user_api_service_ts.tmpl", "src/app/_core/api/user-api.service.ts", func() bool { return hasAuth }},
 
 		// Core - Auth services
-		{"templates/token_service_ts.tmpl", "src/app/_core/services/auth/token.service.ts", func() bool { return hasAuth }},
-		{"templates/can_activate_ts.tmpl", "src/app/_core/services/auth/canActivate.function.ts", func() bool { return hasAuth }},
+		{"token_service_ts.tmpl", "src/app/_core/services/auth/token.service.ts", func() bool { return hasAuth }},
+		{"can_activate_ts.tmpl", "src/app/_core/services/auth/canActivate.function.ts", func() bool { return hasAuth }},
 
 		// Core - Interceptors
-		{"templates/auth_interceptor_ts.tmpl", "src/app/_core/interceptors/auth.interceptor.ts", func() bool { return hasAuth }},
-		{"templates/error_interceptor_ts.tmpl", "src/app/_core/interceptors/error.interceptor.ts", nil},
+		{"auth_interceptor_ts.tmpl", "src/app/_core/interceptors/auth.interceptor.ts", func() bool { return hasAuth }},
+		{"error_interceptor_ts.tmpl", "src/app/_core/interceptors/error.interceptor.ts", nil},
 
 		// Core - Models
-		{"templates/user_model_ts.tmpl", "src/app/_core/models/user.model.ts", nil},
-		{"templates/auth_model_ts.tmpl", "src/app/_core/models/auth.model.ts", func() bool { return hasAuth }},
-		{"templates/api_response_model_ts.tmpl", "src/app/_core/models/api-response.model.ts", nil},
+		{"user_model_ts.tmpl", "src/app/_core/models/user.model.ts", nil},
+		{"auth_model_ts.tmpl", "src/app/_core/models/auth.model.ts", func() bool { return hasAuth }},
+		{"api_response_model_ts.tmpl", "src/app/_core/models/api-response.model.ts", nil},
 
 		// Core - Constants & Enums
-		{"templates/api_constants_ts.tmpl", "src/app/_core/constants/api.constants.ts", nil},
-		{"templates/auth_enum_ts.tmpl", "src/app/_core/enums/auth.enum.ts", func() bool { return hasAuth }},
+		{"api_constants_ts.tmpl", "src/app/_core/constants/api.constants.ts", nil},
+		{"auth_enum_ts.tmpl", "src/app/_core/enums/auth.enum.ts", func() bool { return hasAuth }},
 
 		// Auth module
-		{"templates/auth_routes_ts.tmpl", "src/app/auth/auth.routes.ts", func() bool { return hasAuth }},
-		{"templates/sign_in_component_ts.tmpl", "src/app/auth/sign-in/sign-in.component.ts", func() bool { return hasAuth }},
-		{"templates/sign_in_component_html.tmpl", "src/app/auth/sign-in/sign-in.component.html", func() bool { return hasAuth }},
-		{"templates/sign_in_component_scss.tmpl", "src/app/auth/sign-in/sign-in.component.scss", func() bool { return hasAuth }},
-		{"templates/sign_up_component_ts.tmpl", "src/app/auth/sign-up/sign-up.component.ts", func() bool { return hasAuth }},
-		{"templates/sign_up_component_html.tmpl", "src/app/auth/sign-up/sign-up.component.html", func() bool { return hasAuth }},
-		{"templates/sign_up_component_scss.tmpl", "src/app/auth/sign-up/sign-up.component.scss", func() bool { return hasAuth }},
-		{"templates/reset_password_component_ts.tmpl", "src/app/auth/reset-password/reset-password.component.ts", func() bool { return hasAuth }},
-		{"templates/reset_password_component_html.tmpl", "src/app/auth/reset-password/reset-password.component.html", func() bool { return hasAuth }},
-		{"templates/reset_password_component_scss.tmpl", "src/app/auth/reset-password/reset-password.component.scss", func() bool { return hasAuth }},
+		{"auth_routes_ts.tmpl", "src/app/auth/auth.routes.ts", func() bool { return hasAuth }},
+		{"sign_in_component_ts.tmpl", "src/app/auth/sign-in/sign-in.component.ts", func() bool { return hasAuth }},
+		{"sign_in_component_html.tmpl", "src/app/auth/sign-in/sign-in.component.html", func() bool { return hasAuth }},
+		{"sign_in_component_scss.tmpl", "src/app/auth/sign-in/sign-in.component.scss", func() bool { return hasAuth }},
+		{"sign_up_component_ts.tmpl", "src/app/auth/sign-up/sign-up.component.ts", func() bool { return hasAuth }},
+		{"sign_up_component_html.tmpl", "src/app/auth/sign-up/sign-up.component.html", func() bool { return hasAuth }},
+		{"sign_up_component_scss.tmpl", "src/app/auth/sign-up/sign-up.component.scss", func() bool { return hasAuth }},
+		{"reset_password_component_ts.tmpl", "src/app/auth/reset-password/reset-password.component.ts", func() bool { return hasAuth }},
+		{"reset_password_component_html.tmpl", "src/app/auth/reset-password/reset-password.component.html", func() bool { return hasAuth }},
+		{"reset_password_component_scss.tmpl", "src/app/auth/reset-password/reset-password.component.scss", func() bool { return hasAuth }},
 
 		// State management
-		{"templates/auth_state_ts.tmpl", "src/app/_core/stores/auth/auth.state.ts", func() bool { return hasState && hasAuth }},
-		{"templates/auth_actions_ts.tmpl", "src/app/_core/stores/auth/auth.actions.ts", func() bool { return hasState && hasAuth }},
-		{"templates/user_state_ts.tmpl", "src/app/_core/stores/user/user.state.ts", func() bool { return hasState && hasAuth }},
-		{"templates/user_actions_ts.tmpl", "src/app/_core/stores/user/user.actions.ts", func() bool { return hasState && hasAuth }},
+		{"auth_state_ts.tmpl", "src/app/_core/stores/auth/auth.state.ts", func() bool { return hasState && hasAuth }},
+		{"auth_actions_ts.tmpl", "src/app/_core/stores/auth/auth.actions.ts", func() bool { return hasState && hasAuth }},
+		{"user_state_ts.tmpl", "src/app/_core/stores/user/user.state.ts", func() bool { return hasState && hasAuth }},
+		{"user_actions_ts.tmpl", "src/app/_core/stores/user/user.actions.ts", func() bool { return hasState && hasAuth }},
 
 		// Shared components
-		{"templates/button_component_ts.tmpl", "src/app/shared/components/button/button.component.ts", nil},
-		{"templates/button_component_html.tmpl", "src/app/shared/components/button/button.component.html", nil},
-		{"templates/button_component_scss.tmpl", "src/app/shared/components/button/button.component.scss", nil},
-		{"templates/input_component_ts.tmpl", "src/app/shared/components/input/input.component.ts", nil},
-		{"templates/input_component_html.tmpl", "src/app/shared/components/input/input.component.html", nil},
-		{"templates/input_component_scss.tmpl", "src/app/shared/components/input/input.component.scss", nil},
-		{"templates/loader_component_ts.tmpl", "src/app/shared/components/loader/loader.component.ts", nil},
-		{"templates/loader_component_html.tmpl", "src/app/shared/components/loader/loader.component.html", nil},
-		{"templates/loader_component_scss.tmpl", "src/app/shared/components/loader/loader.component.scss", nil},
-		{"templates/toast_service_ts.tmpl", "src/app/shared/services/toast.service.ts", nil},
-		{"templates/toast_component_ts.tmpl", "src/app/shared/components/toast/toast.component.ts", nil},
-		{"templates/toast_component_html.tmpl", "src/app/shared/components/toast/toast.component.html", nil},
-		{"templates/toast_component_scss.tmpl", "src/app/shared/components/toast/toast.component.scss", nil},
-		{"templates/modal_component_ts.tmpl", "src/app/shared/components/modal/modal.component.ts", nil},
-		{"templates/modal_component_html.tmpl", "src/app/shared/components/modal/modal.component.html", nil},
-		{"templates/modal_component_scss.tmpl", "src/app/shared/components/modal/modal.component.scss", nil},
-		{"templates/navbar_component_ts.tmpl", "src/app/shared/components/navbar/navbar.component.ts", nil},
-		{"templates/navbar_component_html.tmpl", "src/app/shared/components/navbar/navbar.component.html", nil},
-		{"templates/navbar_component_scss.tmpl", "src/app/shared/components/navbar/navbar.component.scss", nil},
-		{"templates/sidebar_component_ts.tmpl", "src/app/shared/components/sidebar/sidebar.component.ts", nil},
-		{"templates/sidebar_component_html.tmpl", "src/app/shared/components/sidebar/sidebar.component.html", nil},
-		{"templates/sidebar_component_scss.tmpl", "src/app/shared/components/sidebar/sidebar.component.scss", nil},
-		{"templates/click_outside_directive_ts.tmpl", "src/app/shared/directives/click-outside.directive.ts", nil},
+		{"button_component_ts.tmpl", "src/app/shared/components/button/button.component.ts", nil},
+		{"button_component_html.tmpl", "src/app/shared/components/button/button.component.html", nil},
+		{"button_component_scss.tmpl", "src/app/shared/components/button/button.component.scss", nil},
+		{"input_component_ts.tmpl", "src/app/shared/components/input/input.component.ts", nil},
+		{"input_component_html.tmpl", "src/app/shared/components/input/input.component.html", nil},
+		{"input_component_scss.tmpl", "src/app/shared/components/input/input.component.scss", nil},
+		{"loader_component_ts.tmpl", "src/app/shared/components/loader/loader.component.ts", nil},
+		{"loader_component_html.tmpl", "src/app/shared/components/loader/loader.component.html", nil},
+		{"loader_component_scss.tmpl", "src/app/shared/components/loader/loader.component.scss", nil},
+		{"toast_service_ts.tmpl", "src/app/shared/services/toast.service.ts", nil},
+		{"toast_component_ts.tmpl", "src/app/shared/components/toast/toast.component.ts", nil},
+		{"toast_component_html.tmpl", "src/app/shared/components/toast/toast.component.html", nil},
+		{"toast_component_scss.tmpl", "src/app/shared/components/toast/toast.component.scss", nil},
+		{"modal_component_ts.tmpl", "src/app/shared/components/modal/modal.component.ts", nil},
+		{"modal_component_html.tmpl", "src/app/shared/components/modal/modal.component.html", nil},
+		{"modal_component_scss.tmpl", "src/app/shared/components/modal/modal.component.scss", nil},
+		{"navbar_component_ts.tmpl", "src/app/shared/components/navbar/navbar.component.ts", nil},
+		{"navbar_component_html.tmpl", "src/app/shared/components/navbar/navbar.component.html", nil},
+		{"navbar_component_scss.tmpl", "src/app/shared/components/navbar/navbar.component.scss", nil},
+		{"sidebar_component_ts.tmpl", "src/app/shared/components/sidebar/sidebar.component.ts", nil},
+		{"sidebar_component_html.tmpl", "src/app/shared/components/sidebar/sidebar.component.html", nil},
+		{"sidebar_component_scss.tmpl", "src/app/shared/components/sidebar/sidebar.component.scss", nil},
+		{"click_outside_directive_ts.tmpl", "src/app/shared/directives/click-outside.directive.ts", nil},
 
 		// Layouts
-		{"templates/main_layout_component_ts.tmpl", "src/app/layouts/main-layout/main-layout.component.ts", nil},
-		{"templates/main_layout_component_html.tmpl", "src/app/layouts/main-layout/main-layout.component.html", nil},
-		{"templates/main_layout_component_scss.tmpl", "src/app/layouts/main-layout/main-layout.component.scss", nil},
-		{"templates/split_layout_component_ts.tmpl", "src/app/layouts/split-layout/split-layout.component.ts", func() bool { return hasAuth }},
-		{"templates/split_layout_component_html.tmpl", "src/app/layouts/split-layout/split-layout.component.html", func() bool { return hasAuth }},
-		{"templates/split_layout_component_scss.tmpl", "src/app/layouts/split-layout/split-layout.component.scss", func() bool { return hasAuth }},
+		{"main_layout_component_ts.tmpl", "src/app/layouts/main-layout/main-layout.component.ts", nil},
+		{"main_layout_component_html.tmpl", "src/app/layouts/main-layout/main-layout.component.html", nil},
+		{"main_layout_component_scss.tmpl", "src/app/layouts/main-layout/main-layout.component.scss", nil},
+		{"split_layout_component_ts.tmpl", "src/app/layouts/split-layout/split-layout.component.ts", func() bool { return hasAuth }},
+		{"split_layout_component_html.tmpl", "src/app/layouts/split-layout/split-layout.component.html", func() bool { return hasAuth }},
+		{"split_layout_component_scss.tmpl", "src/app/layouts/split-layout/split-layout.component.scss", func() bool { return hasAuth }},
 
 		// Dashboard
-		{"templates/dashboard_component_ts.tmpl", "src/app/dashboard/dashboard.component.ts", nil},
-		{"templates/dashboard_component_html.tmpl", "src/app/dashboard/dashboard.component.html", nil},
-		{"templates/dashboard_component_scss.tmpl", "src/app/dashboard/dashboard.component.scss", nil},
+		{"dashboard_component_ts.tmpl", "src/app/dashboard/dashboard.component.ts", nil},
+		{"dashboard_component_html.tmpl", "src/app/dashboard/dashboard.component.html", nil},
+		{"dashboard_component_scss.tmpl", "src/app/dashboard/dashboard.component.scss", nil},
 
 		// SCSS Design System
-		{"templates/variables_scss.tmpl", "src/styles/_variables.scss", nil},
-		{"templates/design_system_scss.tmpl", "src/styles/_design-system.scss", nil},
-		{"templates/typography_scss.tmpl", "src/styles/_typography.scss", nil},
-		{"templates/base_scss.tmpl", "src/styles/_base.scss", nil},
-		{"templates/utilities_scss.tmpl", "src/styles/_utilities.scss", nil},
-		{"templates/scrollbar_scss.tmpl", "src/styles/_scrollbar.scss", nil},
-		{"templates/styles_index_scss.tmpl", "src/styles/_index.scss", nil},
-		{"templates/button_styles_scss.tmpl", "src/styles/components/_button.scss", nil},
-		{"templates/card_styles_scss.tmpl", "src/styles/components/_card.scss", nil},
+		{"variables_scss.tmpl", "src/styles/_variables.scss", nil},
+		{"design_system_scss.tmpl", "src/styles/_design-system.scss", nil},
+		{"typography_scss.tmpl", "src/styles/_typography.scss", nil},
+		{"base_scss.tmpl", "src/styles/_base.scss", nil},
+		{"utilities_scss.tmpl", "src/styles/_utilities.scss", nil},
+		{"scrollbar_scss.tmpl", "src/styles/_scrollbar.scss", nil},
+		{"styles_index_scss.tmpl", "src/styles/_index.scss", nil},
+		{"button_styles_scss.tmpl", "src/styles/components/_button.scss", nil},
+		{"card_styles_scss.tmpl", "src/styles/components/_card.scss", nil},
 
 		// PWA
-		{"templates/ngsw_config_json.tmpl", "ngsw-config.json", func() bool { return hasPwa }},
-		{"templates/manifest_webmanifest.tmpl", "src/manifest.webmanifest", func() bool { return hasPwa }},
+		{"ngsw_config_json.tmpl", "ngsw-config.json", func() bool { return hasPwa }},
+		{"manifest_webmanifest.tmpl", "src/manifest.webmanifest", func() bool { return hasPwa }},
 
 		// i18n
-		{"templates/i18n_en_json.tmpl", "src/assets/i18n/en.json", func() bool { return hasI18n }},
+		{"i18n_en_json.tmpl", "src/assets/i18n/en.json", func() bool { return hasI18n }},
 	}
 }
 
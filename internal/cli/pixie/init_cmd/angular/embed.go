@@ -1,6 +1,13 @@
 package angular
 
-import "embed"
+import (
+	"embed"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
 
 //go:embed templates/*.tmpl
-var TemplateFS embed.FS
+var templateFS embed.FS
+
+// Templates holds the angular project templates.
+var Templates = shared.NewTemplatePack("angular", templateFS, "templates")
@@ -2,7 +2,6 @@ package expo
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/pixie-sh/errors-go"
@@ -16,6 +15,8 @@ type Options struct {
 	Name   string // Project name
 	Output string // Output directory
 	Force  bool   // Overwrite existing files
+
+	generation *shared.Generation // Records generated files in the project manifest; started by Run
 }
 
 // Cmd returns the expo init subcommand
@@ -65,7 +66,7 @@ Examples:
 
 	// Optional flags
 	cmd.Flags().String("output", ".", "Output directory")
-	cmd.Flags().Bool("force", false, "Force overwrite existing files")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
 
 	// Mark required flags
 	if err := cmd.MarkFlagRequired("name"); err != nil {
@@ -85,6 +86,12 @@ func Run(opts Options) error {
 	fmt.Printf("Initializing Expo mobile project: %s\n", opts.Name)
 	fmt.Printf("   Output: %s\n\n", opts.Output)
 
+	generation, err := shared.NewGeneration(opts.Output, "init expo", map[string]string{"name": opts.Name}, opts.Force)
+	if err != nil {
+		return err
+	}
+	opts.generation = generation
+
 	// Generate GitHub Actions workflows
 	fmt.Println("Generating GitHub Actions workflows...")
 	if err := generateGitHubActions(opts); err != nil {
@@ -97,6 +104,10 @@ func Run(opts Options) error {
 		return errors.Wrap(err, "failed to generate project structure")
 	}
 
+	if err := opts.generation.Finish(); err != nil {
+		return errors.Wrap(err, "failed to save generation manifest")
+	}
+
 	fmt.Printf("\nExpo mobile project initialized successfully!\n\n")
 	printNextSteps(opts)
 
@@ -107,7 +118,7 @@ func Run(opts Options) error {
 func generateGitHubActions(opts Options) error {
 	workflowsDir := filepath.Join(opts.Output, ".github", "workflows")
 
-	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
+	if err := shared.CreateDir(workflowsDir); err != nil {
 		return errors.Wrap(err, "failed to create workflows directory")
 	}
 
@@ -144,14 +155,8 @@ jobs:
 `
 
 	testsPath := filepath.Join(workflowsDir, "tests.yaml")
-	if err := shared.WriteFile(testsPath, []byte(testsContent), opts.Force); err != nil {
-		if !opts.Force {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", testsPath)
-		} else {
-			return errors.Wrap(err, "failed to write tests.yaml")
-		}
-	} else {
-		fmt.Printf("   Generated %s\n", testsPath)
+	if _, err := opts.generation.Write(testsPath, "expo:github_actions/tests.yaml", []byte(testsContent)); err != nil {
+		return errors.Wrap(err, "failed to write tests.yaml")
 	}
 
 	// Expo-specific build workflow
@@ -201,14 +206,8 @@ jobs:
 `
 
 	buildPath := filepath.Join(workflowsDir, "build.yaml")
-	if err := shared.WriteFile(buildPath, []byte(buildContent), opts.Force); err != nil {
-		if !opts.Force {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", buildPath)
-		} else {
-			return errors.Wrap(err, "failed to write build.yaml")
-		}
-	} else {
-		fmt.Printf("   Generated %s\n", buildPath)
+	if _, err := opts.generation.Write(buildPath, "expo:github_actions/build.yaml", []byte(buildContent)); err != nil {
+		return errors.Wrap(err, "failed to write build.yaml")
 	}
 
 	return nil
@@ -280,14 +279,8 @@ assets/              # Images, fonts, etc.
 `, opts.Name)
 
 	readmePath := filepath.Join(opts.Output, "README.md")
-	if err := shared.WriteFile(readmePath, []byte(readmeContent), opts.Force); err != nil {
-		if !opts.Force {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", readmePath)
-		} else {
-			return errors.Wrap(err, "failed to write README.md")
-		}
-	} else {
-		fmt.Printf("   Generated %s\n", readmePath)
+	if _, err := opts.generation.Write(readmePath, "expo:README.md", []byte(readmeContent)); err != nil {
+		return errors.Wrap(err, "failed to write README.md")
 	}
 
 	return nil
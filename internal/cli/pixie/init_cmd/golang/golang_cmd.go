@@ -2,16 +2,19 @@ package golang
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/pixie-sh/errors-go"
 	"github.com/spf13/cobra"
 
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/features"
+	genshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/shared"
 	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/models"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/msconfig"
 )
 
 // Options holds all options for the golang init command
@@ -23,6 +26,10 @@ type Options struct {
 	Force         bool     // Overwrite existing files
 	ProjectMS     string   // Custom name for the project microservice
 	WithCLI       bool     // Generate CLI tool
+
+	Runtime models.CLIConfig // Runtime values for generated configs; loaded by Run
+
+	generation *shared.Generation // Records generated files in the project manifest; started by Run
 }
 
 // MicroserviceConfig defines configuration for a microservice to generate
@@ -40,7 +47,8 @@ type DomainInfo struct {
 	NameCamel string
 }
 
-// TemplateData holds the data passed to templates
+// TemplateData holds the data passed to templates; see shared.TemplateFuncs for the
+// functions available to derive casings and plurals
 type TemplateData struct {
 	ProjectName         string
 	ProjectMS           string
@@ -156,7 +164,7 @@ Examples:
 	// Optional flags
 	cmd.Flags().String("output", ".", "Output directory")
 	cmd.Flags().StringSlice("microservices", []string{"authentication", "notifications", "project"}, "Microservices to generate")
-	cmd.Flags().Bool("force", false, "Force overwrite existing files")
+	cmd.Flags().Bool("force", false, "Overwrite existing files even when they have local edits")
 	cmd.Flags().String("project-ms", "project", "Custom name for the project microservice (default: project)")
 	cmd.Flags().Bool("with-cli", true, "Generate CLI tool for the project")
 
@@ -178,6 +186,23 @@ func Run(opts Options) error {
 		return errors.Wrap(err, "validation failed")
 	}
 
+	runtimeCfg, err := genshared.LoadRuntimeConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load runtime config")
+	}
+	opts.Runtime = runtimeCfg
+
+	opts.generation, err = shared.NewGeneration(opts.Output, "init golang", map[string]string{
+		"name":          opts.Name,
+		"module":        opts.Module,
+		"project-ms":    opts.ProjectMS,
+		"microservices": strings.Join(opts.Microservices, ","),
+		"with-cli":      strconv.FormatBool(opts.WithCLI),
+	}, opts.Force)
+	if err != nil {
+		return err
+	}
+
 	fmt.Printf("Initializing Go backend project: %s\n", opts.Name)
 	fmt.Printf("   Module: %s\n", opts.Module)
 	fmt.Printf("   Output: %s\n", opts.Output)
@@ -221,6 +246,10 @@ func Run(opts Options) error {
 		}
 	}
 
+	if err := opts.generation.Finish(); err != nil {
+		return errors.Wrap(err, "failed to save generation manifest")
+	}
+
 	fmt.Printf("\nGo backend project initialized successfully!\n\n")
 	printNextSteps(opts)
 
@@ -261,7 +290,7 @@ func generateGitHubActions(opts Options) error {
 	workflowsDir := filepath.Join(opts.Output, ".github", "workflows")
 
 	// Create workflows directory
-	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
+	if err := shared.CreateDir(workflowsDir); err != nil {
 		return errors.Wrap(err, "failed to create workflows directory")
 	}
 
@@ -276,32 +305,14 @@ func generateGitHubActions(opts Options) error {
 
 	// Generate tests.yaml
 	testsPath := filepath.Join(workflowsDir, "tests.yaml")
-	if !opts.Force && shared.FileExists(testsPath) {
-		fmt.Printf("   WARNING: Skipping %s (file exists, use --force to overwrite)\n", testsPath)
-	} else {
-		content, err := shared.RenderTemplate(shared.GitHubActionsTemplates, "templates/github_actions/tests.yaml.tmpl", data)
-		if err != nil {
-			return errors.Wrap(err, "failed to render tests.yaml template")
-		}
-		if err := shared.WriteFile(testsPath, content, opts.Force); err != nil {
-			return errors.Wrap(err, "failed to write tests.yaml")
-		}
-		fmt.Printf("   Generated %s\n", testsPath)
+	if _, err := opts.generation.WriteTemplate(shared.GitHubActionsTemplates, "tests.yaml.tmpl", testsPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate tests.yaml")
 	}
 
 	// Generate build.yaml
 	buildPath := filepath.Join(workflowsDir, "build.yaml")
-	if !opts.Force && shared.FileExists(buildPath) {
-		fmt.Printf("   WARNING: Skipping %s (file exists, use --force to overwrite)\n", buildPath)
-	} else {
-		content, err := shared.RenderTemplate(shared.GitHubActionsTemplates, "templates/github_actions/build.yaml.tmpl", data)
-		if err != nil {
-			return errors.Wrap(err, "failed to render build.yaml template")
-		}
-		if err := shared.WriteFile(buildPath, content, opts.Force); err != nil {
-			return errors.Wrap(err, "failed to write build.yaml")
-		}
-		fmt.Printf("   Generated %s\n", buildPath)
+	if _, err := opts.generation.WriteTemplate(shared.GitHubActionsTemplates, "build.yaml.tmpl", buildPath, data); err != nil {
+		return errors.Wrap(err, "failed to generate build.yaml")
 	}
 
 	return nil
@@ -412,6 +423,7 @@ func generateProjectInfrastructure(opts Options, domains []DomainInfo, configs [
 		},
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
+	applyRuntimeConfig(&data, opts)
 
 	projectTemplates := []struct {
 		templateFile string
@@ -460,14 +472,7 @@ func generateProjectInfrastructure(opts Options, domains []DomainInfo, configs [
 			continue
 		}
 
-		if !opts.Force && shared.FileExists(mapping.outputPath) {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", mapping.outputPath)
-			continue
-		}
-
-		fmt.Printf("   Generating %s\n", mapping.outputPath)
-
-		if err := generateFileFromTemplate(mapping.templateFile, mapping.outputPath, data); err != nil {
+		if err := generateFileFromTemplate(opts, mapping.templateFile, mapping.outputPath, data); err != nil {
 			return errors.Wrap(err, "failed to generate %s", mapping.outputPath)
 		}
 	}
@@ -477,12 +482,18 @@ func generateProjectInfrastructure(opts Options, domains []DomainInfo, configs [
 
 // generateMicroserviceFromConfig generates a microservice from its configuration
 func generateMicroserviceFromConfig(msConfig MicroserviceConfig, opts Options) error {
-	features := make(map[string]bool)
+	enabled := make(map[string]bool)
 	for _, f := range msConfig.Features {
-		features[f] = true
+		if err := features.Validate(f); err != nil {
+			return errors.Wrap(err, "invalid features of microservice %s", msConfig.Name)
+		}
+		enabled[f] = true
 	}
 
-	features = resolveFeatureDependencies(features)
+	enabled, err := features.Resolve(enabled)
+	if err != nil {
+		return errors.Wrap(err, "invalid features of microservice %s", msConfig.Name)
+	}
 
 	domainName := msConfig.Name
 	if len(msConfig.Domains) > 0 {
@@ -501,33 +512,17 @@ func generateMicroserviceFromConfig(msConfig MicroserviceConfig, opts Options) e
 		RepositoryNameCamel: shared.ToCamelCase(domainName),
 		EntityName:          domainName,
 		EntityNameCamel:     shared.ToCamelCase(domainName),
-		Features:            features,
+		Features:            enabled,
 		Port:                msConfig.Port,
 		MetricsPort:         msConfig.MetricsPort,
 		Timestamp:           time.Now().Format(time.RFC3339),
 		MigrationTimestamp:  fmt.Sprintf("%d", time.Now().Unix()),
-
-		DatabaseHost:                  "localhost",
-		DatabasePort:                  5432,
-		DatabaseName:                  "backend_system",
-		DatabaseUsername:              "postgres",
-		DatabasePassword:              "password",
-		DatabaseSSLMode:               "disable",
-		DatabaseMaxOpenConnections:    100,
-		DatabaseMaxIdleConnections:    10,
-		DatabaseConnectionMaxLifetime: "1h",
-
-		RedisHost:     "localhost",
-		RedisPort:     6379,
-		RedisPassword: "",
-		RedisDatabase: 0,
-
-		JWTSecretKey: "your-secret-key",
-		AdminAPIKey:  "admin-api-key",
 	}
+	applyRuntimeConfig(&data, opts)
+	reuseMigrationTimestamp(&data, opts)
 
 	fmt.Printf("   Domain: %s\n", data.DomainName)
-	fmt.Printf("   Features: %s\n", strings.Join(getEnabledFeatures(features), ", "))
+	fmt.Printf("   Features: %s\n", strings.Join(getEnabledFeatures(enabled), ", "))
 	fmt.Printf("   Port: %d (metrics: %d)\n", data.Port, data.MetricsPort)
 
 	switch data.ServiceName {
@@ -540,6 +535,21 @@ func generateMicroserviceFromConfig(msConfig MicroserviceConfig, opts Options) e
 	}
 }
 
+// reuseMigrationTimestamp keeps the timestamp of migrations generated by an earlier
+// run, so regenerating a project updates them instead of adding new ones.
+func reuseMigrationTimestamp(data *TemplateData, opts Options) {
+	dir := filepath.Join(opts.Output, "internal/domains", data.DomainName, data.DomainName+"_data_layer", data.DomainName+"_migrations")
+	suffix := "_create_" + data.DomainName + "_table.go"
+	if data.ServiceName == "authentication" {
+		dir = filepath.Join(opts.Output, "internal/domains/authentication/authentication_data_layer/auth_migrations")
+		suffix = "1_create_user_table.go"
+	}
+
+	if timestamp, ok := opts.generation.PreviousPrefix(dir, suffix); ok {
+		data.MigrationTimestamp = timestamp
+	}
+}
+
 // generateAuthenticationMicroservice generates auth microservice files
 func generateAuthenticationMicroservice(data TemplateData, opts Options) error {
 	basePath := opts.Output
@@ -590,25 +600,17 @@ func generateAuthenticationMicroservice(data TemplateData, opts Options) error {
 		{"auth_google_account_repository.go.tmpl", filepath.Join(domainPath, "authentication_data_layer/auth_repositories/google_account_repository.go")},
 		{"auth_google_accounts_migration.go.tmpl", filepath.Join(domainPath, fmt.Sprintf("authentication_data_layer/auth_migrations/%s4_add_google_accounts.go", data.MigrationTimestamp))},
 		{"auth_google_oauth_errors.go.tmpl", filepath.Join(basePath, "pkg/errors/google_oauth_errors.go")},
-		{"config.json.tmpl", filepath.Join(basePath, "misc/configs/ms_authentication.json")},
 		{"e2e_configuration.go.tmpl", filepath.Join(basePath, "internal/e2e_tests/e2e_ms_authentication_tests/configuration.go")},
 		{"e2e_bootstrap_test.go.tmpl", filepath.Join(basePath, "internal/e2e_tests/e2e_ms_authentication_tests/ms_authentication_bootstrap_test.go")},
 	}
 
 	for _, mapping := range templateMappings {
-		if !opts.Force && shared.FileExists(mapping.outputPath) {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", mapping.outputPath)
-			continue
-		}
-
-		fmt.Printf("   Generating %s\n", mapping.outputPath)
-
-		if err := generateFileFromTemplate(mapping.templateFile, mapping.outputPath, data); err != nil {
+		if err := generateFileFromTemplate(opts, mapping.templateFile, mapping.outputPath, data); err != nil {
 			return errors.Wrap(err, "failed to generate %s", mapping.outputPath)
 		}
 	}
 
-	return nil
+	return generateConfig(opts, filepath.Join(basePath, "misc/configs/ms_authentication.json"), data)
 }
 
 // generateNotificationsMicroservice generates notifications microservice files
@@ -648,25 +650,17 @@ func generateNotificationsMicroservice(data TemplateData, opts Options) error {
 		{"notif_adapter_activity_logs.go.tmpl", filepath.Join(adaptersPath, "activity_logs_adapter.go")},
 		{"notif_adapter_templates.go.tmpl", filepath.Join(adaptersPath, "templates_adapter.go")},
 		{"notif_models.go.tmpl", filepath.Join(modelsPath, "notifications_models.go")},
-		{"config.json.tmpl", filepath.Join(basePath, "misc/configs/ms_notifications.json")},
 		{"e2e_configuration.go.tmpl", filepath.Join(basePath, "internal/e2e_tests/e2e_ms_notifications_tests/configuration.go")},
 		{"e2e_bootstrap_test.go.tmpl", filepath.Join(basePath, "internal/e2e_tests/e2e_ms_notifications_tests/ms_notifications_bootstrap_test.go")},
 	}
 
 	for _, mapping := range templateMappings {
-		if !opts.Force && shared.FileExists(mapping.outputPath) {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", mapping.outputPath)
-			continue
-		}
-
-		fmt.Printf("   Generating %s\n", mapping.outputPath)
-
-		if err := generateFileFromTemplate(mapping.templateFile, mapping.outputPath, data); err != nil {
+		if err := generateFileFromTemplate(opts, mapping.templateFile, mapping.outputPath, data); err != nil {
 			return errors.Wrap(err, "failed to generate %s", mapping.outputPath)
 		}
 	}
 
-	return nil
+	return generateConfig(opts, filepath.Join(basePath, "misc/configs/ms_notifications.json"), data)
 }
 
 // generateGenericMicroservice generates files for a generic microservice
@@ -674,104 +668,80 @@ func generateGenericMicroservice(data TemplateData, opts Options) error {
 	templateMappings := []struct {
 		templateFile string
 		outputPath   string
-		condition    func() bool
 	}{
-		{"cmd_application.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("cmd/ms/ms_%s/application.go", data.ServiceName)), nil},
-		{"microservice.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/ms/ms_%s/microservice.go", data.ServiceName)), nil},
-		{"ms_registry.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/ms/ms_%s/registry.go", data.ServiceName)), nil},
-		{"http_controllers.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/ms/ms_%s/http_controllers.go", data.ServiceName)), nil},
-		{"http_bo_controllers.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/ms/ms_%s/http_bo_controllers.go", data.ServiceName)), func() bool { return data.Features["backoffice"] }},
-		{"business_layer.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_business_layer/%s_business_layer.go", data.DomainName, data.DomainName, data.DomainName)), nil},
-		{"data_layer.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_data_layer.go", data.DomainName, data.DomainName, data.DomainName)), func() bool { return data.Features["database"] }},
-		{"services.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_services/%s_service.go", data.DomainName, data.DomainName, data.DomainName)), nil},
-		{"domain_registry.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/registry.go", data.DomainName)), nil},
-		{"entities.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_entities/%s.go", data.DomainName, data.DomainName, data.DomainName, data.DomainName)), func() bool { return data.Features["database"] }},
-		{"repositories.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_repositories/%s_repository.go", data.DomainName, data.DomainName, data.DomainName, data.DomainName)), func() bool { return data.Features["database"] }},
-		{"entity_migration.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_migrations/%s_create_%s_table.go", data.DomainName, data.DomainName, data.DomainName, data.MigrationTimestamp, data.DomainName)), func() bool { return data.Features["database"] }},
-		{"domain_migrations.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_migrations/migrations.go", data.DomainName, data.DomainName, data.DomainName)), func() bool { return data.Features["database"] }},
-		{"models.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("pkg/models/%s/%s_models.go", data.DomainName, data.DomainName)), nil},
-		{"config.json.tmpl", filepath.Join(opts.Output, fmt.Sprintf("misc/configs/ms_%s.json", data.ServiceName)), nil},
-		{"e2e_configuration.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/e2e_tests/e2e_ms_%s_tests/configuration.go", data.ServiceName)), func() bool { return data.Features["e2e"] }},
-		{"e2e_bootstrap_test.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/e2e_tests/e2e_ms_%s_tests/ms_%s_bootstrap_test.go", data.ServiceName, data.ServiceName)), func() bool { return data.Features["e2e"] }},
+		{"cmd_application.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("cmd/ms/ms_%s/application.go", data.ServiceName))},
+		{"microservice.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/ms/ms_%s/microservice.go", data.ServiceName))},
+		{"ms_registry.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/ms/ms_%s/registry.go", data.ServiceName))},
+		{"http_controllers.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/ms/ms_%s/http_controllers.go", data.ServiceName))},
+		{"http_bo_controllers.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/ms/ms_%s/http_bo_controllers.go", data.ServiceName))},
+		{"business_layer.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_business_layer/%s_business_layer.go", data.DomainName, data.DomainName, data.DomainName))},
+		{"data_layer.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_data_layer.go", data.DomainName, data.DomainName, data.DomainName))},
+		{"services.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_services/%s_service.go", data.DomainName, data.DomainName, data.DomainName))},
+		{"domain_registry.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/registry.go", data.DomainName))},
+		{"entities.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_entities/%s.go", data.DomainName, data.DomainName, data.DomainName, data.DomainName))},
+		{"repositories.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_repositories/%s_repository.go", data.DomainName, data.DomainName, data.DomainName, data.DomainName))},
+		{"entity_migration.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_migrations/%s_create_%s_table.go", data.DomainName, data.DomainName, data.DomainName, data.MigrationTimestamp, data.DomainName))},
+		{"domain_migrations.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/domains/%s/%s_data_layer/%s_migrations/migrations.go", data.DomainName, data.DomainName, data.DomainName))},
+		{"models.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("pkg/models/%s/%s_models.go", data.DomainName, data.DomainName))},
+		{"e2e_configuration.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/e2e_tests/e2e_ms_%s_tests/configuration.go", data.ServiceName))},
+		{"e2e_bootstrap_test.go.tmpl", filepath.Join(opts.Output, fmt.Sprintf("internal/e2e_tests/e2e_ms_%s_tests/ms_%s_bootstrap_test.go", data.ServiceName, data.ServiceName))},
 	}
 
 	for _, mapping := range templateMappings {
-		if mapping.condition != nil && !mapping.condition() {
+		if !features.Includes(data.Features, Templates.Name+"/"+mapping.templateFile) {
 			continue
 		}
 
-		if !opts.Force && shared.FileExists(mapping.outputPath) {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", mapping.outputPath)
-			continue
-		}
-
-		fmt.Printf("   Generating %s\n", mapping.outputPath)
-
-		if err := generateFileFromTemplate(mapping.templateFile, mapping.outputPath, data); err != nil {
+		if err := generateFileFromTemplate(opts, mapping.templateFile, mapping.outputPath, data); err != nil {
 			return errors.Wrap(err, "failed to generate %s", mapping.outputPath)
 		}
 	}
 
-	return nil
+	return generateConfig(opts, filepath.Join(opts.Output, fmt.Sprintf("misc/configs/ms_%s.json", data.ServiceName)), data)
 }
 
-// generateFileFromTemplate generates a file from a template
-func generateFileFromTemplate(templateFile, outputPath string, data TemplateData) error {
-	templateContent, err := TemplateFS.ReadFile("templates/" + templateFile)
-	if err != nil {
-		return errors.Wrap(err, "template file not found: %s", templateFile)
-	}
-
-	tmpl, err := template.New(templateFile).Parse(string(templateContent))
-	if err != nil {
-		return errors.Wrap(err, "failed to parse template")
-	}
-
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return errors.Wrap(err, "failed to create output directory")
-	}
-
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return errors.Wrap(err, "failed to create output file")
-	}
-	defer func() { _ = file.Close() }()
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return errors.Wrap(err, "failed to execute template")
+// generateConfig writes the JSON config of a microservice, merging it into the config
+// already at outputPath
+func generateConfig(opts Options, outputPath string, data TemplateData) error {
+	config := msconfig.Options{Domain: data.DomainName, Features: data.Features}
+	if err := msconfig.Write(opts.generation, outputPath, config); err != nil {
+		return errors.Wrap(err, "failed to generate %s", outputPath)
 	}
 
 	return nil
 }
 
-// resolveFeatureDependencies resolves feature dependencies
-func resolveFeatureDependencies(features map[string]bool) map[string]bool {
-	dependencies := map[string][]string{
-		"auth":          {"tokens"},
-		"backoffice":    {"auth"},
-		"events":        {"cache"},
-		"notifications": {"events"},
-		"google_oauth":  {"auth"},
-	}
-
-	for {
-		added := false
-		for feature, deps := range dependencies {
-			if features[feature] {
-				for _, dep := range deps {
-					if !features[dep] {
-						features[dep] = true
-						added = true
-					}
-				}
-			}
-		}
-		if !added {
-			break
-		}
-	}
+// generateFileFromTemplate generates a file from a template and records it in the project manifest
+func generateFileFromTemplate(opts Options, templateFile, outputPath string, data TemplateData) error {
+	_, err := opts.generation.WriteTemplate(Templates, templateFile, outputPath, data)
+	return err
+}
 
-	return features
+// applyRuntimeConfig fills the database, redis and security template values from
+// opts.Runtime. The database name defaults to <project>_db.
+func applyRuntimeConfig(data *TemplateData, opts Options) {
+	runtimeCfg := opts.Runtime
+
+	data.DatabaseHost = runtimeCfg.Database.Host
+	data.DatabasePort = runtimeCfg.Database.Port
+	data.DatabaseName = runtimeCfg.Database.Name
+	if data.DatabaseName == "" {
+		data.DatabaseName = opts.Name + "_db"
+	}
+	data.DatabaseUsername = runtimeCfg.Database.Username
+	data.DatabasePassword = runtimeCfg.Database.Password
+	data.DatabaseSSLMode = runtimeCfg.Database.SSLMode
+	data.DatabaseMaxOpenConnections = runtimeCfg.Database.MaxOpenConnections
+	data.DatabaseMaxIdleConnections = runtimeCfg.Database.MaxIdleConnections
+	data.DatabaseConnectionMaxLifetime = runtimeCfg.Database.ConnectionMaxLifetime
+
+	data.RedisHost = runtimeCfg.Redis.Host
+	data.RedisPort = runtimeCfg.Redis.Port
+	data.RedisPassword = runtimeCfg.Redis.Password
+	data.RedisDatabase = runtimeCfg.Redis.Database
+
+	data.JWTSecretKey = runtimeCfg.Security.JWTSecretKey
+	data.AdminAPIKey = runtimeCfg.Security.AdminAPIKey
 }
 
 // getEnabledFeatures returns a list of enabled feature names
@@ -796,10 +766,11 @@ func printNextSteps(opts Options) {
 	fmt.Printf("2. Install dependencies:\n")
 	fmt.Printf("   go mod tidy\n\n")
 	fmt.Printf("3. Start infrastructure (Docker):\n")
+	fmt.Printf("   export DATABASE_PASSWORD=<choose a password>\n")
 	fmt.Printf("   docker-compose -f misc/dockerfiles/docker-compose.yaml up -d\n\n")
 	fmt.Printf("4. Configure environment:\n")
 	fmt.Printf("   cp .env.example .env\n")
-	fmt.Printf("   # Edit .env with your configuration\n\n")
+	fmt.Printf("   # Set DATABASE_PASSWORD, JWT_SECRET_KEY and ADMIN_API_KEY in .env (no defaults are shipped)\n\n")
 	fmt.Printf("5. Run the application:\n")
 	fmt.Printf("   make run MS=authentication\n\n")
 	fmt.Printf("6. Run tests:\n")
@@ -843,14 +814,7 @@ func generateCLITool(opts Options) error {
 	}
 
 	for _, mapping := range cliTemplates {
-		if !opts.Force && shared.FileExists(mapping.outputPath) {
-			fmt.Printf("   WARNING: Skipping %s (file exists)\n", mapping.outputPath)
-			continue
-		}
-
-		fmt.Printf("   Generating %s\n", mapping.outputPath)
-
-		if err := generateFileFromTemplate(mapping.templateFile, mapping.outputPath, data); err != nil {
+		if err := generateFileFromTemplate(opts, mapping.templateFile, mapping.outputPath, data); err != nil {
 			return errors.Wrap(err, "failed to generate %s", mapping.outputPath)
 		}
 	}
@@ -0,0 +1,192 @@
+package shared
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ' unchanged, '-' removed, '+' added
+	line string
+}
+
+// writeUnifiedDiff writes a unified diff turning from into to.
+func writeUnifiedDiff(out io.Writer, fromName, toName string, from, to []byte) {
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", fromName, toName)
+	for _, hunk := range hunks(ops) {
+		fromStart, fromCount, toStart, toCount := hunk.ranges()
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(fromStart, fromCount), hunkRange(toStart, toCount))
+		for _, op := range hunk.ops {
+			fmt.Fprintf(out, "%c%s\n", op.kind, op.line)
+		}
+	}
+}
+
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// diffLines returns the shortest edit script turning a into b (Myers' algorithm).
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		ops := make([]diffOp, 0, n+m)
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+func backtrack(trace [][]int, a, b []string, offset int) []diffOp {
+	x, y := len(a), len(b)
+	var reversed []diffOp
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, diffOp{'+', b[y-1]})
+				y--
+			} else {
+				reversed = append(reversed, diffOp{'-', a[x-1]})
+				x--
+			}
+		}
+	}
+
+	ops := make([]diffOp, len(reversed))
+	for i, op := range reversed {
+		ops[len(reversed)-1-i] = op
+	}
+
+	return ops
+}
+
+type hunk struct {
+	fromLine int // lines of the old file before this hunk
+	toLine   int // lines of the new file before this hunk
+	ops      []diffOp
+}
+
+func (h hunk) ranges() (int, int, int, int) {
+	fromCount, toCount := 0, 0
+	for _, op := range h.ops {
+		if op.kind != '+' {
+			fromCount++
+		}
+		if op.kind != '-' {
+			toCount++
+		}
+	}
+
+	return h.fromLine, fromCount, h.toLine, toCount
+}
+
+// hunks groups ops into hunks of changes with diffContext unchanged lines around them.
+func hunks(ops []diffOp) []hunk {
+	visible := make([]bool, len(ops))
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		for j := max(0, i-diffContext); j <= min(len(ops)-1, i+diffContext); j++ {
+			visible[j] = true
+		}
+	}
+
+	var result []hunk
+	fromLine, toLine := 0, 0
+	for i := 0; i < len(ops); {
+		if !visible[i] {
+			fromLine++
+			toLine++
+			i++
+			continue
+		}
+
+		current := hunk{fromLine: fromLine, toLine: toLine}
+		for ; i < len(ops) && visible[i]; i++ {
+			current.ops = append(current.ops, ops[i])
+			if ops[i].kind != '+' {
+				fromLine++
+			}
+			if ops[i].kind != '-' {
+				toLine++
+			}
+		}
+		result = append(result, current)
+	}
+
+	return result
+}
+
+// hunkRange formats a hunk range: the 1-based start line and the line count. An empty
+// range starts at the line before it, as in GNU diff.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
@@ -3,4 +3,7 @@ package shared
 import "embed"
 
 //go:embed templates/github_actions/*.tmpl
-var GitHubActionsTemplates embed.FS
+var githubActionsFS embed.FS
+
+// GitHubActionsTemplates holds the CI workflow templates shared by every stack.
+var GitHubActionsTemplates = NewTemplatePack("github_actions", githubActionsFS, "templates/github_actions")
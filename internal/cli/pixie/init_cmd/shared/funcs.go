@@ -0,0 +1,342 @@
+package shared
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// TemplateFuncs returns the functions available to every template:
+//
+//	camel "order_item"               orderItem
+//	pascal "order_item"              OrderItem
+//	snake "OrderItem"                order_item
+//	kebab "OrderItem"                order-item
+//	plural "category"                categories (irregulars such as person/people included)
+//	singular "categories"            category
+//	lowerFirst "OrderItem"           orderItem
+//	quote .Name                      "orders", Go-quoted
+//	indent 4 .Block                  .Block with every non-empty line indented by 4 spaces
+//	join ", " .List                  the elements of .List separated by ", "
+//	default "orders" .Name           .Name, or "orders" when .Name is empty
+//	hasFeature .Features "database"  whether the feature is enabled
+//
+// The case functions accept snake_case, kebab-case, camelCase, PascalCase and spaced
+// input. plural and singular inflect the last word and keep its case.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"camel":      Camel,
+		"pascal":     Pascal,
+		"snake":      Snake,
+		"kebab":      Kebab,
+		"plural":     Plural,
+		"singular":   Singular,
+		"lowerFirst": LowerFirst,
+		"quote":      quote,
+		"indent":     indent,
+		"join":       join,
+		"default":    defaultValue,
+		"hasFeature": hasFeature,
+	}
+}
+
+// Camel converts s to camelCase.
+func Camel(s string) string {
+	return LowerFirst(Pascal(s))
+}
+
+// Pascal converts s to PascalCase.
+func Pascal(s string) string {
+	var b strings.Builder
+	for _, word := range words(s) {
+		b.WriteString(upperFirst(strings.ToLower(word)))
+	}
+	return b.String()
+}
+
+// Snake converts s to snake_case.
+func Snake(s string) string {
+	return strings.ToLower(strings.Join(words(s), "_"))
+}
+
+// Kebab converts s to kebab-case.
+func Kebab(s string) string {
+	return strings.ToLower(strings.Join(words(s), "-"))
+}
+
+// LowerFirst lowercases the first letter of s.
+func LowerFirst(s string) string {
+	for i, r := range s {
+		return s[:i] + string(unicode.ToLower(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+func upperFirst(s string) string {
+	for i, r := range s {
+		return s[:i] + string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+// words splits s at separators and case changes. An uppercase run followed by a
+// lowercase letter ends before its last letter, so "HTTPServer" is HTTP, Server.
+func words(s string) []string {
+	runes := []rune(s)
+	var result []string
+	start := -1
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				result = append(result, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				result = append(result, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		result = append(result, string(runes[start:]))
+	}
+
+	return result
+}
+
+// irregularPlurals maps singular words to plurals the suffix rules get wrong.
+var irregularPlurals = map[string]string{
+	"person":     "people",
+	"child":      "children",
+	"man":        "men",
+	"woman":      "women",
+	"mouse":      "mice",
+	"goose":      "geese",
+	"tooth":      "teeth",
+	"foot":       "feet",
+	"ox":         "oxen",
+	"leaf":       "leaves",
+	"knife":      "knives",
+	"wife":       "wives",
+	"life":       "lives",
+	"half":       "halves",
+	"wolf":       "wolves",
+	"shelf":      "shelves",
+	"thief":      "thieves",
+	"hero":       "heroes",
+	"potato":     "potatoes",
+	"tomato":     "tomatoes",
+	"echo":       "echoes",
+	"quiz":       "quizzes",
+	"criterion":  "criteria",
+	"phenomenon": "phenomena",
+	"matrix":     "matrices",
+	"vertex":     "vertices",
+	"appendix":   "appendices",
+	"status":     "statuses",
+	"bus":        "buses",
+	"virus":      "viruses",
+	"campus":     "campuses",
+	"bonus":      "bonuses",
+	"alias":      "aliases",
+	"cache":      "caches",
+	"movie":      "movies",
+	"cookie":     "cookies",
+}
+
+// irregularSingulars is irregularPlurals inverted.
+var irregularSingulars = func() map[string]string {
+	singulars := make(map[string]string, len(irregularPlurals))
+	for singular, plural := range irregularPlurals {
+		singulars[plural] = singular
+	}
+	return singulars
+}()
+
+// uncountable words are their own plural.
+var uncountable = map[string]bool{
+	"data": true, "metadata": true, "media": true, "information": true, "info": true,
+	"equipment": true, "feedback": true, "news": true, "series": true, "species": true,
+	"software": true, "hardware": true, "audio": true, "analytics": true, "auth": true,
+	"money": true, "sheep": true, "fish": true, "deer": true, "staff": true,
+}
+
+// Plural returns the plural of s, inflecting its last word. A last word that is
+// already plural, such as orders, is kept.
+func Plural(s string) string {
+	return inflectLast(s, func(word string) string {
+		if uncountable[word] || irregularSingulars[word] != "" {
+			return word
+		}
+		if plural, ok := irregularPlurals[word]; ok {
+			return plural
+		}
+		if singular := Singular(word); singular != word && singular != "" {
+			return word
+		}
+
+		switch {
+		case strings.HasSuffix(word, "sis"):
+			return strings.TrimSuffix(word, "is") + "es"
+		case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+			return strings.TrimSuffix(word, "y") + "ies"
+		case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+			strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+			return word + "es"
+		default:
+			return word + "s"
+		}
+	})
+}
+
+// Singular returns the singular of s, inflecting its last word.
+func Singular(s string) string {
+	return inflectLast(s, func(word string) string {
+		if uncountable[word] || irregularPlurals[word] != "" {
+			return word
+		}
+		if singular, ok := irregularSingulars[word]; ok {
+			return singular
+		}
+
+		switch {
+		case strings.HasSuffix(word, "ies") && len(word) > 3:
+			return strings.TrimSuffix(word, "ies") + "y"
+		case strings.HasSuffix(word, "yses"):
+			return strings.TrimSuffix(word, "es") + "is"
+		case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+			strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+			return strings.TrimSuffix(word, "es")
+		case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+			return word
+		case strings.HasSuffix(word, "s"):
+			return strings.TrimSuffix(word, "s")
+		default:
+			return word
+		}
+	})
+}
+
+// inflectLast applies inflect to the lowercased last word of s and restores its case.
+func inflectLast(s string, inflect func(string) string) string {
+	runes := []rune(s)
+	end := len(runes)
+	start := end
+	for start > 0 && (unicode.IsLower(runes[start-1]) || unicode.IsDigit(runes[start-1])) {
+		start--
+	}
+	if start > 0 && unicode.IsUpper(runes[start-1]) {
+		start--
+		if start == end-1 {
+			// An all-uppercase last word, such as in "UserID".
+			for start > 0 && unicode.IsUpper(runes[start-1]) {
+				start--
+			}
+		}
+	}
+	if start == end {
+		return s
+	}
+
+	word := string(runes[start:])
+	lower := strings.ToLower(word)
+	inflected := inflect(lower)
+	switch {
+	case len(word) > 1 && strings.ToUpper(word) == word && strings.HasPrefix(inflected, lower):
+		// Acronyms keep a lowercase suffix, as in IDs and URLs.
+		inflected = word + inflected[len(lower):]
+	case len(word) > 1 && strings.ToUpper(word) == word:
+		inflected = strings.ToUpper(inflected)
+	case unicode.IsUpper(runes[start]):
+		inflected = upperFirst(inflected)
+	}
+
+	return string(runes[:start]) + inflected
+}
+
+func quote(value interface{}) string {
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+// indent prefixes every non-empty line of s with spaces, so generated code does not get
+// trailing whitespace.
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// join joins the elements of any slice with sep.
+func join(sep string, list interface{}) string {
+	if strs, ok := list.([]string); ok {
+		return strings.Join(strs, sep)
+	}
+
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return fmt.Sprint(list)
+	}
+
+	parts := make([]string, value.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(value.Index(i).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+// defaultValue returns value, or fallback when value is empty: nil, false, zero, or an
+// empty string, slice or map.
+func defaultValue(fallback, value interface{}) interface{} {
+	if value == nil {
+		return fallback
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return fallback
+		}
+	default:
+		if v.IsZero() {
+			return fallback
+		}
+	}
+
+	return value
+}
+
+// hasFeature reports whether name is enabled in features, a feature map such as
+// TemplateData.Features or a list of feature names.
+func hasFeature(features interface{}, name string) bool {
+	switch f := features.(type) {
+	case map[string]bool:
+		return f[name]
+	case []string:
+		for _, feature := range f {
+			if feature == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
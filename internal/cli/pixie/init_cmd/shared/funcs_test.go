@@ -0,0 +1,124 @@
+package shared
+
+import (
+	"testing"
+)
+
+func TestCaseFuncs(t *testing.T) {
+	tests := []struct {
+		in                          string
+		camel, pascal, snake, kebab string
+	}{
+		{"order_item", "orderItem", "OrderItem", "order_item", "order-item"},
+		{"order-item", "orderItem", "OrderItem", "order_item", "order-item"},
+		{"OrderItem", "orderItem", "OrderItem", "order_item", "order-item"},
+		{"orderItem", "orderItem", "OrderItem", "order_item", "order-item"},
+		{"order item", "orderItem", "OrderItem", "order_item", "order-item"},
+		{"HTTPServer", "httpServer", "HttpServer", "http_server", "http-server"},
+		{"user_id", "userId", "UserId", "user_id", "user-id"},
+		{"oauth2_token", "oauth2Token", "Oauth2Token", "oauth2_token", "oauth2-token"},
+		{"", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Camel(tt.in); got != tt.camel {
+				t.Errorf("Camel(%q) = %q, want %q", tt.in, got, tt.camel)
+			}
+			if got := Pascal(tt.in); got != tt.pascal {
+				t.Errorf("Pascal(%q) = %q, want %q", tt.in, got, tt.pascal)
+			}
+			if got := Snake(tt.in); got != tt.snake {
+				t.Errorf("Snake(%q) = %q, want %q", tt.in, got, tt.snake)
+			}
+			if got := Kebab(tt.in); got != tt.kebab {
+				t.Errorf("Kebab(%q) = %q, want %q", tt.in, got, tt.kebab)
+			}
+		})
+	}
+}
+
+func TestPluralSingular(t *testing.T) {
+	tests := []struct {
+		singular, plural string
+	}{
+		{"order", "orders"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"batch", "batches"},
+		{"status", "statuses"},
+		{"bus", "buses"},
+		{"news", "news"},
+		{"analysis", "analyses"},
+		{"person", "people"},
+		{"child", "children"},
+		{"knife", "knives"},
+		{"movie", "movies"},
+		{"cache", "caches"},
+		{"metadata", "metadata"},
+		{"order_item", "order_items"},
+		{"OrderItem", "OrderItems"},
+		{"orderCategory", "orderCategories"},
+		{"UserPerson", "UserPeople"},
+		{"UserID", "UserIDs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.singular, func(t *testing.T) {
+			if got := Plural(tt.singular); got != tt.plural {
+				t.Errorf("Plural(%q) = %q, want %q", tt.singular, got, tt.plural)
+			}
+			if got := Singular(tt.plural); got != tt.singular {
+				t.Errorf("Singular(%q) = %q, want %q", tt.plural, got, tt.singular)
+			}
+		})
+	}
+}
+
+func TestPluralKeepsPlurals(t *testing.T) {
+	for _, word := range []string{"people", "children", "statuses", "orders", "categories", "boxes", "analyses", "news", "OrderItems", "order_items"} {
+		if got := Plural(word); got != word {
+			t.Errorf("Plural(%q) = %q, want it unchanged", word, got)
+		}
+	}
+}
+
+func TestRenderTemplateFuncs(t *testing.T) {
+	data := map[string]interface{}{
+		"Name":     "order_item",
+		"Empty":    "",
+		"List":     []string{"a", "b"},
+		"Ports":    []int{80, 443},
+		"Block":    "a\n\nb",
+		"Features": map[string]bool{"database": true},
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{`{{pascal .Name | plural}}`, "OrderItems"},
+		{`{{camel .Name}} {{snake "OrderItem"}} {{kebab .Name}}`, "orderItem order_item order-item"},
+		{`{{lowerFirst "OrderItem"}}`, "orderItem"},
+		{`{{singular "categories"}}`, "category"},
+		{`{{quote .Name}}`, `"order_item"`},
+		{`{{indent 2 .Block}}`, "  a\n\n  b"},
+		{`{{join ", " .List}} {{join ":" .Ports}}`, "a, b 80:443"},
+		{`{{default "none" .Empty}} {{default "none" .Name}}`, "none order_item"},
+		{`{{if hasFeature .Features "database"}}db{{end}}{{if hasFeature .Features "cache"}}cache{{end}}`, "db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := renderTemplate("test", []byte(tt.template), data)
+			if err != nil {
+				t.Fatalf("renderTemplate() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("renderTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,12 @@
 package shared
 
 import (
+	"go/format"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
 
-	"embed"
-
 	"github.com/pixie-sh/errors-go"
 )
 
@@ -27,40 +26,49 @@ type ProjectConfig struct {
 	Timestamp string // Generation timestamp
 }
 
-// RenderTemplate renders a template from an embed.FS and returns the result as bytes
-func RenderTemplate(fs embed.FS, templateName string, data interface{}) ([]byte, error) {
-	// Read template content
-	content, err := fs.ReadFile(templateName)
+// renderTemplate parses content as the template name, with TemplateFuncs available,
+// and executes it with data.
+func renderTemplate(name string, content []byte, data interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs()).Parse(string(content))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to read template: %s", templateName)
+		return nil, errors.Wrap(err, "failed to parse template: %s", name)
 	}
 
-	// Parse template
-	tmpl, err := template.New(templateName).Parse(string(content))
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse template: %s", templateName)
-	}
-
-	// Execute template to buffer
 	var buf strings.Builder
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return nil, errors.Wrap(err, "failed to execute template: %s", templateName)
+		return nil, errors.Wrap(err, "failed to execute template: %s", name)
 	}
 
 	return []byte(buf.String()), nil
 }
 
-// WriteFile writes content to a file, creating directories as needed
+// FormatGo formats rendered Go source the way gofmt does. It returns an error when
+// content does not parse as Go.
+func FormatGo(content []byte) ([]byte, error) {
+	formatted, err := format.Source(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "generated Go source does not parse")
+	}
+
+	return formatted, nil
+}
+
+// WriteFile writes content to a file, creating directories as needed.
+// In DryRun and Diff mode the content is recorded in memory instead (see SetWriteMode).
 func WriteFile(path string, content []byte, force bool) error {
 	// Check if file exists
 	if !force && FileExists(path) {
 		return errors.New("file already exists (use --force to overwrite): %s", path)
 	}
 
+	if CurrentWriteMode() != WriteToDisk {
+		plan(path, content)
+		return nil
+	}
+
 	// Create directory structure
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return errors.Wrap(err, "failed to create directory: %s", dir)
+	if err := CreateDir(filepath.Dir(path)); err != nil {
+		return err
 	}
 
 	// Write file
@@ -71,19 +79,34 @@ func WriteFile(path string, content []byte, force bool) error {
 	return nil
 }
 
+// CreateDir creates a directory and its parents. It does nothing in DryRun and Diff mode.
+func CreateDir(path string) error {
+	if CurrentWriteMode() != WriteToDisk {
+		return nil
+	}
+
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return errors.Wrap(err, "failed to create directory: %s", path)
+	}
+	return nil
+}
+
 // CreateDirStructure creates a list of directories
 func CreateDirStructure(basePath string, dirs []string) error {
 	for _, dir := range dirs {
-		fullPath := filepath.Join(basePath, dir)
-		if err := os.MkdirAll(fullPath, 0755); err != nil {
-			return errors.Wrap(err, "failed to create directory: %s", fullPath)
+		if err := CreateDir(filepath.Join(basePath, dir)); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-// FileExists checks if a file exists
+// FileExists checks if a file exists. Files planned in DryRun or Diff mode count as existing.
 func FileExists(path string) bool {
+	if isPlanned(path) {
+		return true
+	}
+
 	_, err := os.Stat(path)
 	return !os.IsNotExist(err)
 }
@@ -0,0 +1,389 @@
+package shared
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pixie-sh/errors-go"
+
+	"github.com/pixie-sh/pixie-cli/internal/version"
+)
+
+const (
+	// ManifestDir holds the generation manifest and the base copies used for merging.
+	ManifestDir = ".pixie"
+	// ManifestFile is the manifest path relative to the project root.
+	ManifestFile = ManifestDir + "/manifest.json"
+	// SidecarSuffix is appended to a file when the new template output could not be merged.
+	SidecarSuffix = ".pixie-new"
+
+	baseDir = ManifestDir + "/base"
+)
+
+// Manifest records every file pixie generated in a project.
+type Manifest struct {
+	PixieVersion string                   `json:"pixie_version"`
+	Files        map[string]ManifestEntry `json:"files"`
+	// Upgrades lists the IDs of the structural upgrade steps applied to the project.
+	Upgrades []string `json:"upgrades,omitempty"`
+}
+
+// ManifestEntry describes one generated file. Checksum is the hash of the content as
+// generated, so a file whose current hash differs has been edited since. Inputs are the
+// generator's flag values keyed by flag name, so the generator can be replayed.
+type ManifestEntry struct {
+	Template     string            `json:"template"`
+	Generator    string            `json:"generator"`
+	PixieVersion string            `json:"pixie_version"`
+	Inputs       map[string]string `json:"inputs,omitempty"`
+	Checksum     string            `json:"checksum"`
+	GeneratedAt  string            `json:"generated_at"`
+}
+
+// LoadManifest reads the manifest of the project at root. A missing manifest is empty.
+func LoadManifest(root string) (*Manifest, error) {
+	manifest := &Manifest{Files: map[string]ManifestEntry{}}
+
+	content, err := os.ReadFile(filepath.Join(root, ManifestFile))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read %s", ManifestFile)
+	}
+
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse %s", ManifestFile)
+	}
+	if manifest.Files == nil {
+		manifest.Files = map[string]ManifestEntry{}
+	}
+
+	return manifest, nil
+}
+
+// SaveManifest writes manifest to the project at root.
+func SaveManifest(root string, manifest *Manifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode %s", ManifestFile)
+	}
+
+	return WriteFile(filepath.Join(root, ManifestFile), append(content, '\n'), true)
+}
+
+// BasePath returns where the base copy of the generated file key is kept.
+func BasePath(root, key string) string {
+	return filepath.Join(root, baseDir, filepath.FromSlash(key))
+}
+
+// Checksum returns the manifest checksum of content.
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Outcome is what a Generation did with one file.
+type Outcome string
+
+const (
+	Created     Outcome = "created"
+	Updated     Outcome = "updated"
+	Unchanged   Outcome = "unchanged"
+	Merged      Outcome = "merged"
+	Overwritten Outcome = "overwritten"
+	Sidecar     Outcome = "sidecar"
+)
+
+// FileOutcome is what a Generation did with the file at Path.
+type FileOutcome struct {
+	Path    string
+	Outcome Outcome
+}
+
+var (
+	outcomesMu sync.Mutex
+	outcomes   []FileOutcome
+)
+
+// Outcomes returns what every Generation did since ResetOutcomes, in write order.
+func Outcomes() []FileOutcome {
+	outcomesMu.Lock()
+	defer outcomesMu.Unlock()
+
+	return append([]FileOutcome(nil), outcomes...)
+}
+
+// ResetOutcomes discards the recorded outcomes.
+func ResetOutcomes() {
+	outcomesMu.Lock()
+	defer outcomesMu.Unlock()
+
+	outcomes = nil
+}
+
+// Generation writes the files of one generator run and records them in the manifest.
+//
+// Files the user never edited are updated in place. Edited files are merged with the
+// new template output using the base copy stored at generation time; when the merge
+// conflicts, or the file predates the manifest, the new output is written next to it
+// with the .pixie-new suffix. Force overwrites regardless.
+type Generation struct {
+	root      string
+	generator string
+	inputs    map[string]string
+	force     bool
+	manifest  *Manifest
+	bases     map[string][]byte
+	kept      bool // Keep changed the manifest
+}
+
+// NewGeneration starts a generator run for the project at root.
+func NewGeneration(root, generator string, inputs map[string]string, force bool) (*Generation, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve project root: %s", root)
+	}
+
+	manifest, err := LoadManifest(absRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Generation{
+		root:      absRoot,
+		generator: generator,
+		inputs:    inputs,
+		force:     force,
+		manifest:  manifest,
+		bases:     map[string][]byte{},
+	}, nil
+}
+
+// Write writes content, rendered from template, to path and reports what happened.
+// Go files are merged with mergeGo, other files with mergeLines.
+func (g *Generation) Write(path, template string, content []byte) (Outcome, error) {
+	if strings.HasSuffix(path, ".go") {
+		return g.WriteMerged(path, template, content, mergeGo)
+	}
+	return g.WriteMerged(path, template, content, mergeLines)
+}
+
+// MergeFunc merges into current, the file on disk, the changes from base, the content
+// last generated for it, to generated. base is nil when the file predates the manifest.
+// It reports false when the changes cannot be merged.
+type MergeFunc func(base, current, generated []byte) ([]byte, bool)
+
+// WriteMerged is Write for files whose local edits are merged with merge rather than
+// line by line, such as structured config files.
+func (g *Generation) WriteMerged(path, template string, content []byte, merge MergeFunc) (Outcome, error) {
+	key, tracked := g.key(path)
+	outcome, err := g.write(path, key, tracked, content, merge)
+	if err != nil {
+		return outcome, err
+	}
+
+	if tracked && outcome != Sidecar {
+		g.record(key, template, content)
+	}
+
+	outcomesMu.Lock()
+	outcomes = append(outcomes, FileOutcome{Path: path, Outcome: outcome})
+	outcomesMu.Unlock()
+
+	printOutcome(outcome, path)
+	return outcome, nil
+}
+
+// WriteTemplate renders the template name of pack with data and writes the result to
+// path. Template overrides are looked up in the project being generated.
+func (g *Generation) WriteTemplate(pack *TemplatePack, name, path string, data interface{}) (Outcome, error) {
+	content, resolved, err := pack.Render(g.root, name, data)
+	if err != nil {
+		return "", err
+	}
+
+	return g.Write(path, resolved.ID(), content)
+}
+
+// Keep records that this run generated path as it was last generated: the manifest
+// entry takes the generator and inputs of this run and keeps its checksum and base copy.
+// Generators use it for files the changed inputs do not affect, so that the file is
+// replayed with the current inputs. Files missing from the manifest are left out.
+func (g *Generation) Keep(path string) {
+	key, tracked := g.key(path)
+	entry, known := g.manifest.Files[key]
+	if !tracked || !known {
+		return
+	}
+
+	entry.Generator = g.generator
+	entry.Inputs = g.inputs
+	g.manifest.Files[key] = entry
+	g.kept = true
+}
+
+// PreviousPrefix returns what precedes suffix in the name of a file this project already
+// generated in dir. Generators use it to keep names that embed a timestamp, such as
+// migrations, stable across regeneration.
+func (g *Generation) PreviousPrefix(dir, suffix string) (string, bool) {
+	key, tracked := g.key(dir)
+	if !tracked {
+		return "", false
+	}
+
+	var prefixes []string
+	for file := range g.manifest.Files {
+		name := strings.TrimPrefix(file, key+"/")
+		if name == file || strings.Contains(name, "/") || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		prefixes = append(prefixes, strings.TrimSuffix(name, suffix))
+	}
+	if len(prefixes) == 0 {
+		return "", false
+	}
+
+	sort.Strings(prefixes)
+	return prefixes[0], true
+}
+
+// record stores content as the new base of key. The manifest entry is kept as is when
+// the generated content did not change, so regenerating does not churn the manifest.
+func (g *Generation) record(key, template string, content []byte) {
+	g.bases[key] = content
+
+	checksum := Checksum(content)
+	if entry, ok := g.manifest.Files[key]; ok && entry.Checksum == checksum && entry.Template == template {
+		return
+	}
+
+	g.manifest.Files[key] = ManifestEntry{
+		Template:     template,
+		Generator:    g.generator,
+		PixieVersion: version.Version,
+		Inputs:       g.inputs,
+		Checksum:     checksum,
+		GeneratedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func (g *Generation) write(path, key string, tracked bool, content []byte, merge MergeFunc) (Outcome, error) {
+	current, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Created, WriteFile(path, content, true)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read existing file: %s", path)
+	}
+
+	if string(current) == string(content) {
+		return Unchanged, nil
+	}
+	if g.force {
+		return Overwritten, WriteFile(path, content, true)
+	}
+
+	entry, known := g.manifest.Files[key]
+	if tracked && known && entry.Checksum == Checksum(current) {
+		return Updated, WriteFile(path, content, true)
+	}
+
+	var base []byte
+	if tracked && known {
+		base, _ = os.ReadFile(BasePath(g.root, key))
+	}
+	if merged, ok := merge(base, current, content); ok {
+		if string(merged) == string(current) {
+			return Unchanged, nil
+		}
+		return Merged, WriteFile(path, merged, true)
+	}
+
+	return Sidecar, WriteFile(path+SidecarSuffix, content, true)
+}
+
+// mergeLines is the MergeFunc of Write: a line-based three-way merge.
+func mergeLines(base, current, generated []byte) ([]byte, bool) {
+	if base == nil {
+		return nil, false
+	}
+
+	merged, ok := merge3(splitLines(base), splitLines(current), splitLines(generated))
+	if !ok {
+		return nil, false
+	}
+
+	return []byte(strings.Join(merged, "\n") + "\n"), true
+}
+
+// mergeGo is the MergeFunc of Write for Go files: mergeLines, rejecting a merge that
+// does not parse so that the new output goes to the sidecar instead of breaking the file.
+func mergeGo(base, current, generated []byte) ([]byte, bool) {
+	merged, ok := mergeLines(base, current, generated)
+	if !ok {
+		return nil, false
+	}
+	if _, err := FormatGo(merged); err != nil {
+		return nil, false
+	}
+
+	return merged, true
+}
+
+// key returns path relative to the project root, or false when path lies outside it.
+func (g *Generation) key(path string) (string, bool) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(g.root, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// Finish saves the manifest and the base copies of the files written by this run.
+// Nothing is saved in DryRun or Diff mode.
+func (g *Generation) Finish() error {
+	if CurrentWriteMode() != WriteToDisk || (len(g.bases) == 0 && !g.kept) {
+		return nil
+	}
+
+	for key, content := range g.bases {
+		if err := WriteFile(BasePath(g.root, key), content, true); err != nil {
+			return err
+		}
+	}
+
+	g.manifest.PixieVersion = version.Version
+	return SaveManifest(g.root, g.manifest)
+}
+
+func printOutcome(outcome Outcome, path string) {
+	switch outcome {
+	case Created:
+		fmt.Printf("   Generating %s\n", path)
+	case Updated:
+		fmt.Printf("   Updating %s (no local edits)\n", path)
+	case Unchanged:
+		fmt.Printf("   Unchanged %s\n", path)
+	case Merged:
+		fmt.Printf("   Merging %s (local edits kept)\n", path)
+	case Overwritten:
+		fmt.Printf("   Overwriting %s\n", path)
+	case Sidecar:
+		fmt.Printf("   WARNING: %s has local edits that could not be merged; new version written to %s%s\n", path, path, SidecarSuffix)
+	}
+}
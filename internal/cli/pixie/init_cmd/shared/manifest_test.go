@@ -0,0 +1,297 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// generate runs one generation writing content to rel under root.
+func generate(t *testing.T, root, rel, content string, force bool) Outcome {
+	t.Helper()
+
+	gen, err := NewGeneration(root, "test", map[string]string{"name": "orders"}, force)
+	if err != nil {
+		t.Fatalf("NewGeneration() error = %v", err)
+	}
+	outcome, err := gen.Write(filepath.Join(root, rel), "templates/test.tmpl", []byte(content))
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := gen.Finish(); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+
+	return outcome
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+
+	return string(content)
+}
+
+const generatedV1 = "package orders\n\n// header\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() {}\n"
+
+func TestGeneration_RecordsManifest(t *testing.T) {
+	root := t.TempDir()
+
+	if got := generate(t, root, "orders.go", generatedV1, false); got != Created {
+		t.Fatalf("outcome = %q, want %q", got, Created)
+	}
+
+	manifest, err := LoadManifest(root)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	entry, ok := manifest.Files["orders.go"]
+	if !ok {
+		t.Fatalf("manifest has no entry for orders.go: %+v", manifest.Files)
+	}
+	if entry.Checksum != Checksum([]byte(generatedV1)) {
+		t.Errorf("Checksum = %q, want checksum of generated content", entry.Checksum)
+	}
+	if entry.Template != "templates/test.tmpl" || entry.Generator != "test" || entry.Inputs["name"] != "orders" {
+		t.Errorf("entry = %+v, want template, generator and inputs recorded", entry)
+	}
+	if got := readFile(t, filepath.Join(root, baseDir, "orders.go")); got != generatedV1 {
+		t.Errorf("base copy = %q, want generated content", got)
+	}
+}
+
+func TestGeneration_Regenerate(t *testing.T) {
+	generatedV2 := strings.Replace(generatedV1, "func C() {}", "func C() { /* fixed */ }", 1)
+
+	tests := []struct {
+		name    string
+		edit    func(string) string
+		next    string
+		force   bool
+		want    Outcome
+		file    string
+		sidecar bool
+	}{
+		{
+			name: "untouched file is updated",
+			edit: func(s string) string { return s },
+			next: generatedV2,
+			want: Updated,
+			file: generatedV2,
+		},
+		{
+			name: "edited file is merged",
+			edit: func(s string) string { return strings.Replace(s, "func A() {}", "func A() { /* mine */ }", 1) },
+			next: generatedV2,
+			want: Merged,
+			file: strings.Replace(generatedV2, "func A() {}", "func A() { /* mine */ }", 1),
+		},
+		{
+			name: "edited file with unchanged template is kept",
+			edit: func(s string) string { return strings.Replace(s, "func A() {}", "func A() { /* mine */ }", 1) },
+			next: generatedV1,
+			want: Unchanged,
+			file: strings.Replace(generatedV1, "func A() {}", "func A() { /* mine */ }", 1),
+		},
+		{
+			name:    "conflicting edit gets a sidecar",
+			edit:    func(s string) string { return strings.Replace(s, "func C() {}", "func C() { /* mine */ }", 1) },
+			next:    generatedV2,
+			want:    Sidecar,
+			file:    strings.Replace(generatedV1, "func C() {}", "func C() { /* mine */ }", 1),
+			sidecar: true,
+		},
+		{
+			name:    "merge that does not parse gets a sidecar",
+			edit:    func(s string) string { return strings.Replace(s, "func A() {}", "func A() { /* mine */ }", 1) },
+			next:    strings.Replace(generatedV1, "func C() {}", "func C() {", 1),
+			want:    Sidecar,
+			file:    strings.Replace(generatedV1, "func A() {}", "func A() { /* mine */ }", 1),
+			sidecar: true,
+		},
+		{
+			name:  "force overwrites edits",
+			edit:  func(s string) string { return strings.Replace(s, "func C() {}", "func C() { /* mine */ }", 1) },
+			next:  generatedV2,
+			force: true,
+			want:  Overwritten,
+			file:  generatedV2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, "orders.go")
+			generate(t, root, "orders.go", generatedV1, false)
+			if err := os.WriteFile(path, []byte(tt.edit(generatedV1)), 0644); err != nil {
+				t.Fatalf("failed to edit file: %v", err)
+			}
+
+			if got := generate(t, root, "orders.go", tt.next, tt.force); got != tt.want {
+				t.Errorf("outcome = %q, want %q", got, tt.want)
+			}
+			if got := readFile(t, path); got != tt.file {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.file)
+			}
+
+			_, err := os.Stat(path + SidecarSuffix)
+			if hasSidecar := err == nil; hasSidecar != tt.sidecar {
+				t.Errorf("sidecar exists = %v, want %v", hasSidecar, tt.sidecar)
+			}
+			if tt.sidecar && readFile(t, path+SidecarSuffix) != tt.next {
+				t.Errorf("sidecar content does not match the new template output")
+			}
+		})
+	}
+}
+
+func TestGeneration_FileWithoutManifestGetsSidecar(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "orders.go")
+	if err := os.WriteFile(path, []byte("package orders // hand written\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if got := generate(t, root, "orders.go", generatedV1, false); got != Sidecar {
+		t.Errorf("outcome = %q, want %q", got, Sidecar)
+	}
+	if got := readFile(t, path); got != "package orders // hand written\n" {
+		t.Errorf("file = %q, want it untouched", got)
+	}
+}
+
+func TestGeneration_DryRunDoesNotSaveManifest(t *testing.T) {
+	withWriteMode(t, DryRun)
+	root := t.TempDir()
+
+	generate(t, root, "orders.go", generatedV1, false)
+
+	if _, err := os.Stat(filepath.Join(root, ManifestDir)); !os.IsNotExist(err) {
+		t.Errorf("%s was created in dry-run mode", ManifestDir)
+	}
+}
+
+func TestGeneration_PreviousPrefix(t *testing.T) {
+	root := t.TempDir()
+	generate(t, root, "migrations/1700000000_create_orders_table.go", "package migrations\n", false)
+
+	gen, err := NewGeneration(root, "test", nil, false)
+	if err != nil {
+		t.Fatalf("NewGeneration() error = %v", err)
+	}
+
+	dir := filepath.Join(root, "migrations")
+	if got, ok := gen.PreviousPrefix(dir, "_create_orders_table.go"); !ok || got != "1700000000" {
+		t.Errorf("PreviousPrefix() = %q, %v, want %q, true", got, ok, "1700000000")
+	}
+	if _, ok := gen.PreviousPrefix(dir, "_create_users_table.go"); ok {
+		t.Errorf("PreviousPrefix() found a prefix for a file that was never generated")
+	}
+	if _, ok := gen.PreviousPrefix(root, "_create_orders_table.go"); ok {
+		t.Errorf("PreviousPrefix() matched a file in a subdirectory")
+	}
+}
+
+func TestGeneration_Keep(t *testing.T) {
+	root := t.TempDir()
+	generate(t, root, "orders.go", generatedV1, false)
+
+	gen, err := NewGeneration(root, "test", map[string]string{"name": "orders", "features": "cache"}, false)
+	if err != nil {
+		t.Fatalf("NewGeneration() error = %v", err)
+	}
+	gen.Keep(filepath.Join(root, "orders.go"))
+	gen.Keep(filepath.Join(root, "unknown.go"))
+	if err := gen.Finish(); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+
+	manifest, err := LoadManifest(root)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	kept := manifest.Files["orders.go"]
+	if kept.Inputs["features"] != "cache" || kept.Checksum != Checksum([]byte(generatedV1)) {
+		t.Errorf("kept entry = %+v, want the new inputs and the previous checksum", kept)
+	}
+	if _, ok := manifest.Files["unknown.go"]; ok {
+		t.Errorf("Keep() recorded a file missing from the manifest")
+	}
+}
+
+func TestGeneration_WriteMerged(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "orders.json")
+	if err := os.WriteFile(path, []byte("hand written\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	var gotBase []byte
+	merge := func(base, current, generated []byte) ([]byte, bool) {
+		gotBase = base
+		return append(current, generated...), true
+	}
+
+	gen, err := NewGeneration(root, "test", nil, false)
+	if err != nil {
+		t.Fatalf("NewGeneration() error = %v", err)
+	}
+	outcome, err := gen.WriteMerged(path, "templates/test.tmpl", []byte("generated\n"), merge)
+	if err != nil {
+		t.Fatalf("WriteMerged() error = %v", err)
+	}
+	if err := gen.Finish(); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+
+	if outcome != Merged {
+		t.Errorf("outcome = %q, want %q", outcome, Merged)
+	}
+	if gotBase != nil {
+		t.Errorf("base = %q, want nil for a file missing from the manifest", gotBase)
+	}
+	if got := readFile(t, path); got != "hand written\ngenerated\n" {
+		t.Errorf("file = %q, want the merged content", got)
+	}
+	if got := readFile(t, filepath.Join(root, baseDir, "orders.json")); got != "generated\n" {
+		t.Errorf("base copy = %q, want the generated content", got)
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	base := []string{"a", "b", "c", "d", "e", "f"}
+
+	tests := []struct {
+		name   string
+		ours   []string
+		theirs []string
+		want   []string
+		ok     bool
+	}{
+		{"no changes", base, base, base, true},
+		{"only ours", []string{"a", "B", "c", "d", "e", "f"}, base, []string{"a", "B", "c", "d", "e", "f"}, true},
+		{"only theirs", base, []string{"a", "b", "c", "d", "e", "F"}, []string{"a", "b", "c", "d", "e", "F"}, true},
+		{"separate changes", []string{"a", "B", "c", "d", "e", "f"}, []string{"a", "b", "c", "d", "E", "f", "g"}, []string{"a", "B", "c", "d", "E", "f", "g"}, true},
+		{"same change on both sides", []string{"a", "X", "c", "d", "e", "f"}, []string{"a", "X", "c", "d", "e", "f"}, []string{"a", "X", "c", "d", "e", "f"}, true},
+		{"conflict", []string{"a", "X", "c", "d", "e", "f"}, []string{"a", "Y", "c", "d", "e", "f"}, nil, false},
+		{"adjacent changes conflict", []string{"a", "X", "c", "d", "e", "f"}, []string{"a", "b", "Y", "d", "e", "f"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := merge3(base, tt.ours, tt.theirs)
+			if ok != tt.ok {
+				t.Fatalf("merge3() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && !equalLines(got, tt.want) {
+				t.Errorf("merge3() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
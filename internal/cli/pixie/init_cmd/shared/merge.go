@@ -0,0 +1,120 @@
+package shared
+
+// edit replaces base[start:end] with lines.
+type edit struct {
+	start, end int
+	lines      []string
+}
+
+// edits returns the changes turning base into other as a list of non-overlapping edits.
+func edits(base, other []string) []edit {
+	var result []edit
+	var current *edit
+	position := 0
+
+	for _, op := range diffLines(base, other) {
+		if op.kind == ' ' {
+			if current != nil {
+				result = append(result, *current)
+				current = nil
+			}
+			position++
+			continue
+		}
+
+		if current == nil {
+			current = &edit{start: position, end: position}
+		}
+		if op.kind == '-' {
+			current.end++
+			position++
+		} else {
+			current.lines = append(current.lines, op.line)
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+
+	return result
+}
+
+// merge3 merges the changes from base to ours and from base to theirs. It returns
+// false when both sides changed the same or adjacent lines differently.
+func merge3(base, ours, theirs []string) ([]string, bool) {
+	oursEdits, theirsEdits := edits(base, ours), edits(base, theirs)
+
+	var merged []string
+	position, i, j := 0, 0, 0
+	for i < len(oursEdits) || j < len(theirsEdits) {
+		// Start a group with the earliest edit, then pull in every edit from either
+		// side that overlaps or touches it.
+		var groupOurs, groupTheirs []edit
+		var start, end int
+		if j >= len(theirsEdits) || (i < len(oursEdits) && oursEdits[i].start <= theirsEdits[j].start) {
+			start, end = oursEdits[i].start, oursEdits[i].end
+		} else {
+			start, end = theirsEdits[j].start, theirsEdits[j].end
+		}
+
+		for grew := true; grew; {
+			grew = false
+			if i < len(oursEdits) && oursEdits[i].start <= end {
+				end = max(end, oursEdits[i].end)
+				groupOurs = append(groupOurs, oursEdits[i])
+				i++
+				grew = true
+			}
+			if j < len(theirsEdits) && theirsEdits[j].start <= end {
+				end = max(end, theirsEdits[j].end)
+				groupTheirs = append(groupTheirs, theirsEdits[j])
+				j++
+				grew = true
+			}
+		}
+
+		merged = append(merged, base[position:start]...)
+		switch {
+		case len(groupTheirs) == 0:
+			merged = append(merged, applyEdits(base, start, end, groupOurs)...)
+		case len(groupOurs) == 0:
+			merged = append(merged, applyEdits(base, start, end, groupTheirs)...)
+		default:
+			oursResult := applyEdits(base, start, end, groupOurs)
+			theirsResult := applyEdits(base, start, end, groupTheirs)
+			if !equalLines(oursResult, theirsResult) {
+				return nil, false
+			}
+			merged = append(merged, oursResult...)
+		}
+		position = end
+	}
+
+	return append(merged, base[position:]...), true
+}
+
+// applyEdits returns base[start:end] with edits applied.
+func applyEdits(base []string, start, end int, edits []edit) []string {
+	var result []string
+	position := start
+	for _, e := range edits {
+		result = append(result, base[position:e.start]...)
+		result = append(result, e.lines...)
+		position = e.end
+	}
+
+	return append(result, base[position:end]...)
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
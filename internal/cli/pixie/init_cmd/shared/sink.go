@@ -0,0 +1,287 @@
+package shared
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+)
+
+// WriteMode selects what WriteFile does with generated content.
+type WriteMode int
+
+const (
+	// WriteToDisk writes generated files. This is the default.
+	WriteToDisk WriteMode = iota
+	// DryRun keeps generated files in memory and prints the planned file tree.
+	DryRun
+	// Diff keeps generated files in memory and prints unified diffs against the files on disk.
+	Diff
+)
+
+// PlannedFile is a file a generator would have written in DryRun or Diff mode.
+type PlannedFile struct {
+	Path    string
+	Content []byte
+	// Exists reports whether the file is already on disk and would be overwritten.
+	Exists bool
+}
+
+var (
+	sinkMu    sync.Mutex
+	writeMode WriteMode
+	planned   []PlannedFile
+)
+
+// SetWriteMode selects the write mode for the current command and discards any planned
+// files and recorded outcomes.
+func SetWriteMode(mode WriteMode) {
+	ResetOutcomes()
+
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+
+	writeMode = mode
+	planned = nil
+}
+
+// CurrentWriteMode returns the write mode set by the root command.
+func CurrentWriteMode() WriteMode {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+
+	return writeMode
+}
+
+// WriteModeFromCommand reads the --dry-run and --diff flags visible to cmd.
+// --diff takes precedence. Flags that are not defined are ignored.
+func WriteModeFromCommand(cmd *cobra.Command) WriteMode {
+	if flag := cmd.Flags().Lookup("diff"); flag != nil && flag.Value.String() == "true" {
+		return Diff
+	}
+	if flag := cmd.Flags().Lookup("dry-run"); flag != nil && flag.Value.String() == "true" {
+		return DryRun
+	}
+
+	return WriteToDisk
+}
+
+// PlannedFiles returns the files recorded since the write mode was last set, in write order.
+func PlannedFiles() []PlannedFile {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+
+	return append([]PlannedFile(nil), planned...)
+}
+
+func plan(path string, content []byte) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+
+	_, err := os.Stat(path)
+	file := PlannedFile{Path: path, Content: content, Exists: err == nil}
+	for i := range planned {
+		if planned[i].Path == path {
+			planned[i] = file
+			return
+		}
+	}
+	planned = append(planned, file)
+}
+
+func isPlanned(path string) bool {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+
+	for _, file := range planned {
+		if file.Path == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReadFile returns the content of path as the current command left it: the planned
+// content in DryRun and Diff mode when a generator planned the file, the file on disk
+// otherwise. Generators that edit existing files read them through ReadFile.
+func ReadFile(path string) ([]byte, error) {
+	if content, ok := plannedContent(path); ok {
+		return content, nil
+	}
+
+	return os.ReadFile(path)
+}
+
+func plannedContent(path string) ([]byte, bool) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+
+	for _, file := range planned {
+		if file.Path == path {
+			return file.Content, true
+		}
+	}
+
+	return nil, false
+}
+
+// WritePlan prints what the generators would have done: the planned file tree in
+// DryRun mode, unified diffs in Diff mode. It does nothing in WriteToDisk mode.
+func WritePlan(out io.Writer) error {
+	files := PlannedFiles()
+	switch CurrentWriteMode() {
+	case DryRun:
+		writeTree(out, files)
+	case Diff:
+		return writeDiffs(out, files)
+	}
+
+	return nil
+}
+
+func writeTree(out io.Writer, files []PlannedFile) {
+	fmt.Fprintln(out)
+	if len(files) == 0 {
+		fmt.Fprintln(out, "Dry run: no files would be written.")
+		return
+	}
+
+	paths := make([]string, 0, len(files))
+	overwrite := map[string]bool{}
+	for _, file := range files {
+		path := filepath.ToSlash(filepath.Clean(file.Path))
+		paths = append(paths, path)
+		overwrite[path] = file.Exists
+	}
+
+	root := commonDir(paths)
+	tree := &treeNode{}
+	for _, path := range paths {
+		rel := path
+		if root != "." {
+			rel = strings.TrimPrefix(strings.TrimPrefix(path, root), "/")
+		}
+		tree.add(strings.Split(rel, "/"), overwrite[path])
+	}
+
+	fmt.Fprintf(out, "Dry run: %d file(s) would be written (%d overwritten). No changes were made.\n\n", len(files), tree.overwrites())
+	fmt.Fprintf(out, "%s/\n", root)
+	tree.write(out, "")
+}
+
+// treeNode is a directory or file in the planned file tree.
+type treeNode struct {
+	children  map[string]*treeNode
+	overwrite bool
+}
+
+func (n *treeNode) add(parts []string, overwrite bool) {
+	if n.children == nil {
+		n.children = map[string]*treeNode{}
+	}
+	child, ok := n.children[parts[0]]
+	if !ok {
+		child = &treeNode{}
+		n.children[parts[0]] = child
+	}
+
+	if len(parts) == 1 {
+		child.overwrite = overwrite
+		return
+	}
+	child.add(parts[1:], overwrite)
+}
+
+func (n *treeNode) overwrites() int {
+	total := 0
+	for _, child := range n.children {
+		if child.overwrite {
+			total++
+		}
+		total += child.overwrites()
+	}
+
+	return total
+}
+
+func (n *treeNode) write(out io.Writer, indent string) {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		child := n.children[name]
+		branch, guide := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, guide = "└── ", "    "
+		}
+
+		switch {
+		case child.children != nil:
+			fmt.Fprintf(out, "%s%s%s/\n", indent, branch, name)
+			child.write(out, indent+guide)
+		case child.overwrite:
+			fmt.Fprintf(out, "%s%s%s (overwrite)\n", indent, branch, name)
+		default:
+			fmt.Fprintf(out, "%s%s%s\n", indent, branch, name)
+		}
+	}
+}
+
+// commonDir returns the deepest directory containing every path.
+func commonDir(paths []string) string {
+	dir := filepath.ToSlash(filepath.Dir(paths[0]))
+	for _, path := range paths[1:] {
+		for dir != "." && dir != "/" && !strings.HasPrefix(path, dir+"/") {
+			dir = filepath.ToSlash(filepath.Dir(dir))
+		}
+	}
+
+	return dir
+}
+
+func writeDiffs(out io.Writer, files []PlannedFile) error {
+	created, changed, unchanged := 0, 0, 0
+	seen := map[string]bool{}
+	for _, file := range files {
+		seen[file.Path] = true
+		if !file.Exists {
+			created++
+			writeUnifiedDiff(out, "/dev/null", file.Path, nil, file.Content)
+			continue
+		}
+
+		current, err := os.ReadFile(file.Path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read existing file: %s", file.Path)
+		}
+		if string(current) == string(file.Content) {
+			unchanged++
+			continue
+		}
+
+		changed++
+		writeUnifiedDiff(out, file.Path, file.Path, current, file.Content)
+	}
+
+	// A Generation leaves files whose content would not change unplanned, but reports
+	// them as Unchanged; count them too so the summary agrees with those lines.
+	for _, outcome := range Outcomes() {
+		if outcome.Outcome == Unchanged && !seen[outcome.Path] {
+			seen[outcome.Path] = true
+			unchanged++
+		}
+	}
+
+	fmt.Fprintf(out, "\nDiff: %d new, %d changed, %d unchanged file(s). No changes were made.\n", created, changed, unchanged)
+	return nil
+}
@@ -0,0 +1,156 @@
+package shared
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func withWriteMode(t *testing.T, mode WriteMode) {
+	t.Helper()
+
+	SetWriteMode(mode)
+	t.Cleanup(func() { SetWriteMode(WriteToDisk) })
+}
+
+func TestWriteFile_DryRunDoesNotTouchDisk(t *testing.T) {
+	withWriteMode(t, DryRun)
+	tmp := t.TempDir()
+	existing := filepath.Join(tmp, "go.mod")
+	if err := os.WriteFile(existing, []byte("module old\n"), 0644); err != nil {
+		t.Fatalf("failed to write go.mod: %v", err)
+	}
+
+	created := filepath.Join(tmp, "cmd", "ms", "ms_orders", "application.go")
+	if err := WriteFile(created, []byte("package main\n"), false); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := WriteFile(existing, []byte("module new\n"), true); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := CreateDir(filepath.Join(tmp, ".github")); err != nil {
+		t.Fatalf("CreateDir() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmp, "cmd")); !os.IsNotExist(err) {
+		t.Errorf("cmd directory was created in dry-run mode")
+	}
+	if _, err := os.Stat(filepath.Join(tmp, ".github")); !os.IsNotExist(err) {
+		t.Errorf(".github directory was created in dry-run mode")
+	}
+	if content, _ := os.ReadFile(existing); string(content) != "module old\n" {
+		t.Errorf("go.mod = %q, want it untouched", content)
+	}
+	if !FileExists(created) {
+		t.Errorf("FileExists(%q) = false, want true for a planned file", created)
+	}
+
+	files := PlannedFiles()
+	if len(files) != 2 {
+		t.Fatalf("PlannedFiles() = %d files, want 2", len(files))
+	}
+	if files[0].Exists || !files[1].Exists {
+		t.Errorf("Exists = %v, %v, want false, true", files[0].Exists, files[1].Exists)
+	}
+
+	var out bytes.Buffer
+	if err := WritePlan(&out); err != nil {
+		t.Fatalf("WritePlan() error = %v", err)
+	}
+	for _, want := range []string{
+		"2 file(s) would be written (1 overwritten)",
+		"├── cmd/",
+		"│   └── ms/",
+		"│       └── ms_orders/",
+		"│           └── application.go",
+		"└── go.mod (overwrite)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("plan output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWritePlan_Diff(t *testing.T) {
+	withWriteMode(t, Diff)
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "config.json")
+	if err := os.WriteFile(path, []byte("a\nb\nc\nd\ne\nf\ng\nh\n"), 0644); err != nil {
+		t.Fatalf("failed to write config.json: %v", err)
+	}
+
+	if err := WriteFile(path, []byte("a\nb\nc\nD\ne\nf\ng\nh\ni\n"), true); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := WriteFile(filepath.Join(tmp, "new.txt"), []byte("hello\n"), false); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := WritePlan(&out); err != nil {
+		t.Fatalf("WritePlan() error = %v", err)
+	}
+
+	want := "--- " + path + "\n+++ " + path + "\n" +
+		"@@ -1,8 +1,9 @@\n a\n b\n c\n-d\n+D\n e\n f\n g\n h\n+i\n" +
+		"--- /dev/null\n+++ " + filepath.Join(tmp, "new.txt") + "\n" +
+		"@@ -0,0 +1 @@\n+hello\n" +
+		"\nDiff: 1 new, 1 changed, 0 unchanged file(s). No changes were made.\n"
+	if out.String() != want {
+		t.Errorf("diff output =\n%s\nwant\n%s", out.String(), want)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "a\nb\nc\nd\ne\nf\ng\nh\n" {
+		t.Errorf("config.json was modified in diff mode")
+	}
+}
+
+func TestWritePlan_DiffCountsUnchangedOutcomes(t *testing.T) {
+	withWriteMode(t, Diff)
+	tmp := t.TempDir()
+	gen, err := NewGeneration(tmp, "test", nil, false)
+	if err != nil {
+		t.Fatalf("NewGeneration() error = %v", err)
+	}
+
+	same := filepath.Join(tmp, "same.go")
+	if err := os.WriteFile(same, []byte("package same\n"), 0644); err != nil {
+		t.Fatalf("failed to write same.go: %v", err)
+	}
+	if _, err := gen.Write(same, "same.go.tmpl", []byte("package same\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := gen.Write(filepath.Join(tmp, "new.go"), "new.go.tmpl", []byte("package new\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := WritePlan(&out); err != nil {
+		t.Fatalf("WritePlan() error = %v", err)
+	}
+	if want := "Diff: 1 new, 0 changed, 1 unchanged file(s)."; !strings.Contains(out.String(), want) {
+		t.Errorf("diff output missing %q:\n%s", want, out.String())
+	}
+}
+
+func TestDiffLines_SeparateHunks(t *testing.T) {
+	var from, to []string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a' + i))
+		from = append(from, line)
+		to = append(to, line)
+	}
+	to[1] = "B"
+	to[18] = "S"
+
+	var out bytes.Buffer
+	writeUnifiedDiff(&out, "old", "new", []byte(strings.Join(from, "\n")+"\n"), []byte(strings.Join(to, "\n")+"\n"))
+
+	if got := strings.Count(out.String(), "@@ -"); got != 2 {
+		t.Errorf("hunks = %d, want 2:\n%s", got, out.String())
+	}
+	if !strings.Contains(out.String(), "@@ -1,5 +1,5 @@") || !strings.Contains(out.String(), "@@ -16,5 +16,5 @@") {
+		t.Errorf("unexpected hunk headers:\n%s", out.String())
+	}
+}
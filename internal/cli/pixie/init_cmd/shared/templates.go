@@ -0,0 +1,158 @@
+package shared
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// TemplatesDir is where project and user template overrides live, relative to the
+// project root and the home directory respectively.
+const TemplatesDir = ManifestDir + "/templates"
+
+// Template layers, in lookup order.
+const (
+	ProjectLayer = "project"
+	UserLayer    = "user"
+	BuiltinLayer = "builtin"
+)
+
+// TemplatePack is a named set of templates compiled into pixie. Any template can be
+// overridden by a file of the same name under <layer>/.pixie/templates/<pack>/, looked
+// up in the project first, then in the user's home directory.
+type TemplatePack struct {
+	Name     string
+	embedded embed.FS
+	dir      string
+}
+
+// NewTemplatePack returns the pack name serving the templates found under dir in embedded.
+func NewTemplatePack(name string, embedded embed.FS, dir string) *TemplatePack {
+	return &TemplatePack{Name: name, embedded: embedded, dir: dir}
+}
+
+// ResolvedTemplate is a template together with the layer it was loaded from.
+type ResolvedTemplate struct {
+	Pack    string
+	Name    string
+	Layer   string
+	Path    string // File path for project and user layers; empty for built-in templates
+	Content []byte
+}
+
+// ID returns the pack-qualified template name, as recorded in the manifest.
+func (t ResolvedTemplate) ID() string {
+	return t.Pack + "/" + t.Name
+}
+
+// Names returns the names of the built-in templates of the pack, sorted.
+func (p *TemplatePack) Names() ([]string, error) {
+	var names []string
+	err := fs.WalkDir(p.embedded, p.dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(name, ".tmpl") && !strings.HasPrefix(path.Base(name), ".") {
+			names = append(names, strings.TrimPrefix(name, p.dir+"/"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list templates of pack %s", p.Name)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Builtin returns the built-in content of the template name.
+func (p *TemplatePack) Builtin(name string) ([]byte, error) {
+	content, err := p.embedded.ReadFile(path.Join(p.dir, name))
+	if err != nil {
+		return nil, errors.Wrap(err, "no built-in template %s/%s", p.Name, name)
+	}
+
+	return content, nil
+}
+
+// Resolve looks name up in the project at projectRoot, then in the user's home
+// directory, then among the built-in templates. An empty projectRoot skips the
+// project layer.
+func (p *TemplatePack) Resolve(projectRoot, name string) (ResolvedTemplate, error) {
+	resolved := ResolvedTemplate{Pack: p.Name, Name: name}
+
+	for _, layer := range OverrideLayers(projectRoot) {
+		file := filepath.Join(layer.Dir, p.Name, filepath.FromSlash(name))
+		content, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return resolved, errors.Wrap(err, "failed to read template override: %s", file)
+		}
+
+		resolved.Layer, resolved.Path, resolved.Content = layer.Name, file, content
+		return resolved, nil
+	}
+
+	content, err := p.Builtin(name)
+	if err != nil {
+		return resolved, err
+	}
+
+	resolved.Layer, resolved.Content = BuiltinLayer, content
+	return resolved, nil
+}
+
+// Render resolves name for the project at projectRoot and executes it with data.
+// The output of Go templates (*.go.tmpl) is formatted with FormatGo when it parses.
+func (p *TemplatePack) Render(projectRoot, name string, data interface{}) ([]byte, ResolvedTemplate, error) {
+	resolved, err := p.Resolve(projectRoot, name)
+	if err != nil {
+		return nil, resolved, err
+	}
+
+	content, err := renderTemplate(resolved.ID(), resolved.Content, data)
+	if err != nil {
+		if resolved.Layer != BuiltinLayer {
+			err = errors.Wrap(err, "template override %s", resolved.Path)
+		}
+		return content, resolved, err
+	}
+
+	// Go templates leave gofmt's alignment to the generator. Output that does not parse
+	// is kept as rendered, so that the compiler reports the problem in the written file.
+	if strings.HasSuffix(name, ".go.tmpl") {
+		if formatted, err := FormatGo(content); err == nil {
+			content = formatted
+		}
+	}
+
+	return content, resolved, nil
+}
+
+// TemplateLayer is a directory of template overrides.
+type TemplateLayer struct {
+	Name string
+	Dir  string
+}
+
+// OverrideLayers returns the override directories in lookup order. The project layer
+// is left out when projectRoot is empty, the user layer when there is no home directory.
+func OverrideLayers(projectRoot string) []TemplateLayer {
+	var layers []TemplateLayer
+	if projectRoot != "" {
+		layers = append(layers, TemplateLayer{Name: ProjectLayer, Dir: filepath.Join(projectRoot, TemplatesDir)})
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		layers = append(layers, TemplateLayer{Name: UserLayer, Dir: filepath.Join(home, TemplatesDir)})
+	}
+
+	return layers
+}
@@ -0,0 +1,124 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeOverride(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, TemplatesDir, "github_actions", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create override directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write override: %v", err)
+	}
+
+	return path
+}
+
+func TestTemplatePackResolve(t *testing.T) {
+	builtin, err := GitHubActionsTemplates.Builtin("tests.yaml.tmpl")
+	if err != nil {
+		t.Fatalf("Builtin() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		project   string
+		user      string
+		wantLayer string
+		want      string
+	}{
+		{"builtin", "", "", BuiltinLayer, string(builtin)},
+		{"user overrides builtin", "", "user {{.ProjectName}}", UserLayer, "user {{.ProjectName}}"},
+		{"project overrides user", "project {{.ProjectName}}", "user {{.ProjectName}}", ProjectLayer, "project {{.ProjectName}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, project := t.TempDir(), t.TempDir()
+			t.Setenv("HOME", home)
+			if tt.user != "" {
+				writeOverride(t, home, "tests.yaml.tmpl", tt.user)
+			}
+			if tt.project != "" {
+				writeOverride(t, project, "tests.yaml.tmpl", tt.project)
+			}
+
+			resolved, err := GitHubActionsTemplates.Resolve(project, "tests.yaml.tmpl")
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if resolved.Layer != tt.wantLayer {
+				t.Errorf("Layer = %q, want %q", resolved.Layer, tt.wantLayer)
+			}
+			if string(resolved.Content) != tt.want {
+				t.Errorf("Content = %q, want %q", resolved.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplatePackNames(t *testing.T) {
+	names, err := GitHubActionsTemplates.Names()
+	if err != nil {
+		t.Fatalf("Names() error = %v", err)
+	}
+
+	want := []string{"build.yaml.tmpl", "tests.yaml.tmpl"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("Names() = %v, want %v", names, want)
+	}
+}
+
+func TestGenerationWriteTemplateUsesProjectOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	writeOverride(t, root, "tests.yaml.tmpl", "name: {{.ProjectName}} tests\n")
+
+	gen, err := NewGeneration(root, "test", nil, false)
+	if err != nil {
+		t.Fatalf("NewGeneration() error = %v", err)
+	}
+	path := filepath.Join(root, ".github/workflows/tests.yaml")
+	if _, err := gen.WriteTemplate(GitHubActionsTemplates, "tests.yaml.tmpl", path, map[string]string{"ProjectName": "demo"}); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+
+	if got := readFile(t, path); got != "name: demo tests\n" {
+		t.Errorf("generated file = %q, want the override rendered", got)
+	}
+	if got := gen.manifest.Files[".github/workflows/tests.yaml"].Template; got != "github_actions/tests.yaml.tmpl" {
+		t.Errorf("manifest template = %q, want %q", got, "github_actions/tests.yaml.tmpl")
+	}
+}
+
+func TestTemplatePackRenderFormatsGo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	writeOverride(t, root, "entity.go.tmpl", "package {{.Package}}\n\ntype Entity struct {\n\tID int64\n\tName string\n}\n")
+	writeOverride(t, root, "broken.go.tmpl", "package {{.Package}}\n\nfunc (\n")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"entity.go.tmpl", "package orders\n\ntype Entity struct {\n\tID   int64\n\tName string\n}\n"},
+		{"broken.go.tmpl", "package orders\n\nfunc (\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := GitHubActionsTemplates.Render(root, tt.name, map[string]string{"Package": "orders"})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,42 +1,194 @@
 package models
 
-// CLIConfig holds the CLI configuration loaded from JSON
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// CLIConfig holds the runtime configuration written into generated projects.
+// It is the runtime: section of the project config file; see config.Load.
 type CLIConfig struct {
-	Database DatabaseConfig `json:"database"`
-	Redis    RedisConfig    `json:"redis"`
-	Security SecurityConfig `json:"security"`
+	Database    DatabaseConfig    `json:"database" yaml:"database"`
+	Redis       RedisConfig       `json:"redis" yaml:"redis"`
+	Security    SecurityConfig    `json:"security" yaml:"security"`
+	Environment EnvironmentConfig `json:"environment" yaml:"environment"`
 }
 
 // DatabaseConfig holds database connection settings
 type DatabaseConfig struct {
-	Host                  string `json:"host"`
-	Port                  int    `json:"port"`
-	Name                  string `json:"name"`
-	Username              string `json:"username"`
-	Password              string `json:"password"`
-	SSLMode               string `json:"ssl_mode"`
-	MaxOpenConnections    int    `json:"max_open_connections"`
-	MaxIdleConnections    int    `json:"max_idle_connections"`
-	ConnectionMaxLifetime string `json:"connection_max_lifetime"`
+	Host                  string `json:"host" yaml:"host"`
+	Port                  int    `json:"port" yaml:"port"`
+	Name                  string `json:"name" yaml:"name"`
+	Username              string `json:"username" yaml:"username"`
+	Password              string `json:"password" yaml:"password"`
+	SSLMode               string `json:"ssl_mode" yaml:"ssl_mode"`
+	MaxOpenConnections    int    `json:"max_open_connections" yaml:"max_open_connections"`
+	MaxIdleConnections    int    `json:"max_idle_connections" yaml:"max_idle_connections"`
+	ConnectionMaxLifetime string `json:"connection_max_lifetime" yaml:"connection_max_lifetime"`
 }
 
 // RedisConfig holds Redis connection settings
 type RedisConfig struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	Password string `json:"password"`
-	Database int    `json:"database"`
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Password string `json:"password" yaml:"password"`
+	Database int    `json:"database" yaml:"database"`
 }
 
 // SecurityConfig holds security-related settings
 type SecurityConfig struct {
-	JWTSecretKey string `json:"jwt_secret_key"`
-	AdminAPIKey  string `json:"admin_api_key"`
+	JWTSecretKey string `json:"jwt_secret_key" yaml:"jwt_secret_key"`
+	AdminAPIKey  string `json:"admin_api_key" yaml:"admin_api_key"`
 }
 
 // EnvironmentConfig holds environment-specific settings
 type EnvironmentConfig struct {
-	Environment string `json:"environment"`
-	Debug       bool   `json:"debug"`
-	LogLevel    string `json:"log_level"`
+	Environment string `json:"environment" yaml:"environment"`
+	Debug       bool   `json:"debug" yaml:"debug"`
+	LogLevel    string `json:"log_level" yaml:"log_level"`
+}
+
+// Environments lists the accepted values of EnvironmentConfig.Environment.
+var Environments = []string{"development", "test", "staging", "production"}
+
+// LogLevels lists the accepted values of EnvironmentConfig.LogLevel.
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// DefaultCLIConfig returns the runtime defaults. Secrets are left empty on purpose:
+// they must come from the project config or the environment, never from a placeholder.
+func DefaultCLIConfig() CLIConfig {
+	return CLIConfig{
+		Database: DatabaseConfig{
+			Host:                  "localhost",
+			Port:                  5432,
+			Username:              "postgres",
+			SSLMode:               "disable",
+			MaxOpenConnections:    100,
+			MaxIdleConnections:    10,
+			ConnectionMaxLifetime: "1h",
+		},
+		Redis: RedisConfig{
+			Host: "localhost",
+			Port: 6379,
+		},
+		Environment: EnvironmentConfig{
+			Environment: "development",
+			LogLevel:    "info",
+		},
+	}
+}
+
+// IsDevelopment reports whether secrets may be left empty.
+func (c CLIConfig) IsDevelopment() bool {
+	switch c.Environment.Environment {
+	case "", "development", "test":
+		return true
+	default:
+		return false
+	}
+}
+
+// ConnectionMaxLifetimeDuration parses Database.ConnectionMaxLifetime.
+func (c DatabaseConfig) ConnectionMaxLifetimeDuration() (time.Duration, error) {
+	lifetime, err := time.ParseDuration(c.ConnectionMaxLifetime)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid connection_max_lifetime %q", c.ConnectionMaxLifetime)
+	}
+
+	return lifetime, nil
+}
+
+// Problem is a runtime configuration rule broken by the value of Key.
+type Problem struct {
+	Key     string
+	Message string
+}
+
+// Problems checks port ranges, pool sizes, durations and enumerations, and requires
+// secrets outside development.
+func (c CLIConfig) Problems() []Problem {
+	var problems []Problem
+	add := func(key, format string, args ...any) {
+		problems = append(problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !validPort(c.Database.Port) {
+		add("runtime.database.port", "must be between 1 and 65535, got %d", c.Database.Port)
+	}
+	if !validPort(c.Redis.Port) {
+		add("runtime.redis.port", "must be between 1 and 65535, got %d", c.Redis.Port)
+	}
+	if c.Redis.Database < 0 || c.Redis.Database > 15 {
+		add("runtime.redis.database", "must be between 0 and 15, got %d", c.Redis.Database)
+	}
+	if c.Database.MaxOpenConnections < 0 {
+		add("runtime.database.max_open_connections", "must not be negative")
+	}
+	if c.Database.MaxIdleConnections < 0 {
+		add("runtime.database.max_idle_connections", "must not be negative")
+	}
+	if c.Database.MaxOpenConnections > 0 && c.Database.MaxIdleConnections > c.Database.MaxOpenConnections {
+		add("runtime.database.max_idle_connections", "must not exceed max_open_connections (%d), got %d",
+			c.Database.MaxOpenConnections, c.Database.MaxIdleConnections)
+	}
+	if lifetime, err := c.Database.ConnectionMaxLifetimeDuration(); err != nil {
+		add("runtime.database.connection_max_lifetime", "must be a duration such as 30m or 1h, got %q", c.Database.ConnectionMaxLifetime)
+	} else if lifetime < 0 {
+		add("runtime.database.connection_max_lifetime", "must not be negative")
+	}
+	if !contains(Environments, c.Environment.Environment) {
+		add("runtime.environment.environment", "must be one of %s, got %q", strings.Join(Environments, ", "), c.Environment.Environment)
+	}
+	if !contains(LogLevels, c.Environment.LogLevel) {
+		add("runtime.environment.log_level", "must be one of %s, got %q", strings.Join(LogLevels, ", "), c.Environment.LogLevel)
+	}
+
+	if !c.IsDevelopment() {
+		secrets := []struct {
+			key   string
+			value string
+		}{
+			{"runtime.database.password", c.Database.Password},
+			{"runtime.security.jwt_secret_key", c.Security.JWTSecretKey},
+			{"runtime.security.admin_api_key", c.Security.AdminAPIKey},
+		}
+		for _, secret := range secrets {
+			if secret.value == "" {
+				add(secret.key, "is required in %s", c.Environment.Environment)
+			}
+		}
+	}
+
+	return problems
+}
+
+// Validate reports the Problems of c in a single error.
+func (c CLIConfig) Validate() error {
+	problems := c.Problems()
+	if len(problems) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Key + " " + problem.Message
+	}
+	return errors.New("invalid runtime configuration: %s", strings.Join(messages, "; "))
+}
+
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,250 @@
+// Package msconfig builds the JSON config of a generated microservice, the file under
+// the configs directory its application loads at startup.
+//
+// The config is built as data rather than rendered from a text template, so that the
+// sections a feature contributes can be added or left out without breaking the JSON.
+// Values keep the placeholders the config loader resolves: ${env.NAME} reads an
+// environment variable and ${#ref.name} the member name of the top-level "#ref" object.
+package msconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/features"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/wiring"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// Template is the ID the config is recorded under in the project manifest.
+const Template = "msconfig/config.json"
+
+// Options describes the microservice a config is built for.
+type Options struct {
+	Domain   string
+	Features map[string]bool
+}
+
+// section is a top-level member of the config. It is written unless a feature declares
+// it in the registry and that feature is disabled.
+type section struct {
+	name  string // "<domain>" stands for the domain name
+	value func(opts Options) interface{}
+}
+
+var sections = []section{
+	{name: "#ref", value: refs},
+	{name: "listen_addr", value: constant("${env.LISTEN_ADDR}")},
+	{name: "listen_metrics_addr", value: constant("${env.METRICS_LISTEN_ADDR}")},
+	{name: "http_server_bundle", value: httpServerBundle},
+	{name: "<domain>_business_layer", value: domainBusinessLayer},
+	{name: "authorization_gates_bundle", value: authorizationGatesBundle},
+	{name: "token_services_bundle", value: tokenServicesBundle},
+	{name: "google_auth_business_layer", value: googleAuthBusinessLayer},
+}
+
+// Build returns the config of the microservice described by opts.
+func Build(opts Options) *Object {
+	config := NewObject()
+	for _, s := range sections {
+		if !features.IncludesConfig(opts.Features, s.name) {
+			continue
+		}
+		config.Set(strings.ReplaceAll(s.name, "<domain>", opts.Domain), s.value(opts))
+	}
+
+	return config
+}
+
+// Marshal encodes config as indented JSON.
+func Marshal(config *Object) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(config); err != nil {
+		return nil, errors.Wrap(err, "failed to encode config")
+	}
+
+	return buf.Bytes(), nil
+}
+
+var refPlaceholder = regexp.MustCompile(`\$\{#ref\.([A-Za-z0-9_.-]+)\}`)
+
+// Validate checks that content is a JSON object whose ${#ref.x} placeholders all
+// resolve to a member of its "#ref" object. Dotted names resolve nested members.
+func Validate(content []byte) error {
+	var config map[string]interface{}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return errors.Wrap(err, "config is not a valid JSON object")
+	}
+
+	refs, _ := config["#ref"].(map[string]interface{})
+	var unresolved []string
+	walk("", config, func(path, value string) {
+		for _, match := range refPlaceholder.FindAllStringSubmatch(value, -1) {
+			if !resolves(refs, match[1]) {
+				unresolved = append(unresolved, fmt.Sprintf("%s (in %s)", match[0], path))
+			}
+		}
+	})
+	if len(unresolved) > 0 {
+		return errors.New("unresolved #ref placeholders: %s", strings.Join(unresolved, ", "))
+	}
+
+	return nil
+}
+
+// Write builds the config described by opts and writes it to path. A config that
+// already exists keeps its values: only the members it is missing are added to it.
+func Write(gen *initshared.Generation, path string, opts Options) error {
+	content, err := Marshal(Build(opts))
+	if err != nil {
+		return err
+	}
+	if err := Validate(content); err != nil {
+		return errors.Wrap(err, "generated config %s is invalid", path)
+	}
+
+	if _, err := gen.WriteMerged(path, Template, content, merge); err != nil {
+		return err
+	}
+
+	// Edits kept by the merge can leave placeholders dangling
+	written, err := initshared.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read %s", path)
+	}
+	if err := Validate(written); err != nil {
+		fmt.Printf("   WARNING: %s: %v\n", path, err)
+	}
+
+	return nil
+}
+
+func merge(base, current, generated []byte) ([]byte, bool) {
+	merged, err := wiring.MergeJSON(current, base, generated)
+	return merged, err == nil
+}
+
+// walk calls visit with every string of value and its dotted path.
+func walk(path string, value interface{}, visit func(path, value string)) {
+	switch v := value.(type) {
+	case string:
+		visit(path, v)
+	case []interface{}:
+		for i, element := range v {
+			walk(fmt.Sprintf("%s[%d]", path, i), element, visit)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			walk(child, v[key], visit)
+		}
+	}
+}
+
+func resolves(refs map[string]interface{}, name string) bool {
+	var current interface{} = refs
+	for _, part := range strings.Split(name, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if current, ok = object[part]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func constant(value interface{}) func(Options) interface{} {
+	return func(Options) interface{} { return value }
+}
+
+func refs(Options) interface{} {
+	return NewObject().
+		Set("database_orm", NewObject().
+			Set("driver", "gorm_db_driver").
+			Set("values", NewObject().
+				Set("driver", "psql_db_driver").
+				Set("dsn", "host=${env.DB_HOST} user=${env.DB_USERNAME} password=${env.DB_PASSWORD} dbname=${env.DB_NAME} port=${env.DB_PORT} sslmode=${env.DB_SSLMODE}"))).
+		Set("token_service", NewObject().
+			Set("validity_in_seconds_token", 5259492).
+			Set("token_private_key", "jwt_pvt_key").
+			Set("token_public_key", "jwt_pub_key")).
+		Set("redis_address", "${env.REDIS_HOST}:${env.REDIS_PORT}").
+		Set("redis_password", "${env.REDIS_PASSWORD}").
+		Set("cors_origins", "${env.CORS_ORIGINS}").
+		Set("authorization_api_key", "${env.AUTH_KEY}")
+}
+
+func httpServerBundle(Options) interface{} {
+	return NewObject().
+		Set("cors_configuration", NewObject().
+			Set("allow_origins", "${#ref.cors_origins}").
+			Set("allow_methods", "*").
+			Set("allow_headers", "Content-Type,X-Requested-With,Authorization").
+			Set("allow_credentials", false).
+			Set("expose_headers", "X-Request-ID,Recaptcha-Token").
+			Set("max_age", 0))
+}
+
+func domainBusinessLayer(opts Options) interface{} {
+	dataLayer := func() *Object {
+		return NewObject().Set("database_orm", "${#ref.database_orm}")
+	}
+
+	return NewObject().
+		Set(opts.Domain+"_data_layer", dataLayer()).
+		Set(opts.Domain+"_service", NewObject().
+			Set(opts.Domain+"_data_layer", dataLayer()))
+}
+
+func authorizationGatesBundle(Options) interface{} {
+	return NewObject().
+		Set("jwt_header_key", "authorization").
+		Set("authorization_gate", NewObject().
+			Set("value", "${#ref.authorization_api_key}").
+			Set("header", "X-AuthKey"))
+}
+
+func tokenServicesBundle(Options) interface{} {
+	return NewObject().
+		Set("token_service", "${#ref.token_service}").
+		Set("token_cache", NewObject().
+			Set("address", "${#ref.redis_address}").
+			Set("db", 7))
+}
+
+func googleAuthBusinessLayer(Options) interface{} {
+	dataLayer := func() *Object {
+		return NewObject().Set("auth_data_layer", NewObject().Set("database_orm", "${#ref.database_orm}"))
+	}
+
+	return NewObject().
+		Set("auth_data_layer", NewObject().Set("database_orm", "${#ref.database_orm}")).
+		Set("google_auth_service", dataLayer().
+			Set("google_oauth_api", NewObject().
+				Set("client_id", "${env.GOOGLE_OAUTH_CLIENT_ID}").
+				Set("client_secret", "${env.GOOGLE_OAUTH_CLIENT_SECRET}").
+				Set("redirect_uri", "${env.GOOGLE_OAUTH_REDIRECT_URI}").
+				Set("scopes", []string{"openid", "email", "profile"}).
+				Set("state_secret", "${env.GOOGLE_OAUTH_STATE_SECRET}"))).
+		Set("auth_login_service", dataLayer())
+}
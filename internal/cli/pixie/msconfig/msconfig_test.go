@@ -0,0 +1,185 @@
+package msconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		features map[string]bool
+		want     []string
+	}{
+		{
+			name:     "no features",
+			features: map[string]bool{},
+			want:     []string{"#ref", "listen_addr", "listen_metrics_addr", "http_server_bundle"},
+		},
+		{
+			name:     "database",
+			features: map[string]bool{"database": true},
+			want:     []string{"#ref", "listen_addr", "listen_metrics_addr", "http_server_bundle", "orders_business_layer"},
+		},
+		{
+			name:     "auth",
+			features: map[string]bool{"auth": true, "tokens": true},
+			want:     []string{"#ref", "listen_addr", "listen_metrics_addr", "http_server_bundle", "authorization_gates_bundle", "token_services_bundle"},
+		},
+		{
+			name:     "every section",
+			features: map[string]bool{"database": true, "auth": true, "google_oauth": true},
+			want:     []string{"#ref", "listen_addr", "listen_metrics_addr", "http_server_bundle", "orders_business_layer", "authorization_gates_bundle", "token_services_bundle", "google_auth_business_layer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Build(Options{Domain: "orders", Features: tt.features})
+			if got := config.Keys(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Build() keys = %v, want %v", got, tt.want)
+			}
+
+			content, err := Marshal(config)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if err := Validate(content); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	config := NewObject().
+		Set("listen_addr", "${env.LISTEN_ADDR}").
+		Set("bundle", NewObject().Set("db", 7).Set("enabled", false)).
+		Set("listen_addr", "${env.ADDR}")
+
+	got, err := Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	want := `{
+  "listen_addr": "${env.ADDR}",
+  "bundle": {
+    "db": 7,
+    "enabled": false
+  }
+}
+`
+	if string(got) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "resolved",
+			content: `{"#ref": {"orm": {"driver": "gorm"}, "addr": "${env.ADDR}"}, "a": "${#ref.orm}", "b": ["${#ref.addr}", "${#ref.orm.driver}"]}`,
+		},
+		{
+			name:    "unresolved",
+			content: `{"#ref": {"orm": {}}, "bundle": {"cache": "${#ref.redis_address}"}, "list": ["${#ref.orm.driver}"]}`,
+			wantErr: "${#ref.redis_address} (in bundle.cache), ${#ref.orm.driver} (in list[0])",
+		},
+		{
+			name:    "no refs",
+			content: `{"a": "${#ref.orm}"}`,
+			wantErr: "${#ref.orm} (in a)",
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"a": 1,}`,
+			wantErr: "not a valid JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "misc", "configs", "ms_orders.json")
+
+	write := func(features map[string]bool) {
+		t.Helper()
+
+		gen, err := initshared.NewGeneration(root, "test", nil, false)
+		if err != nil {
+			t.Fatalf("NewGeneration() error = %v", err)
+		}
+		if err := Write(gen, path, Options{Domain: "orders", Features: features}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := gen.Finish(); err != nil {
+			t.Fatalf("Finish() error = %v", err)
+		}
+	}
+	read := func() map[string]interface{} {
+		t.Helper()
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		var config map[string]interface{}
+		if err := json.Unmarshal(content, &config); err != nil {
+			t.Fatalf("config is not valid JSON: %v\n%s", err, content)
+		}
+		return config
+	}
+
+	write(map[string]bool{"database": true})
+
+	// Edit the config: change a value, add a key, remove a generated one
+	config := read()
+	config["listen_addr"] = ":7000"
+	config["custom_bundle"] = map[string]interface{}{"enabled": true}
+	delete(config, "listen_metrics_addr")
+	edited, _ := json.MarshalIndent(config, "", "  ")
+	if err := os.WriteFile(path, edited, 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	write(map[string]bool{"database": true, "auth": true})
+
+	config = read()
+	if config["listen_addr"] != ":7000" || config["custom_bundle"] == nil {
+		t.Errorf("Write() lost the local edits: %v", config)
+	}
+	if _, ok := config["listen_metrics_addr"]; ok {
+		t.Errorf("Write() restored a member removed by the user")
+	}
+	for _, key := range []string{"orders_business_layer", "authorization_gates_bundle", "token_services_bundle"} {
+		if _, ok := config[key]; !ok {
+			t.Errorf("Write() did not add %s", key)
+		}
+	}
+}
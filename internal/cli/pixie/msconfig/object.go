@@ -0,0 +1,72 @@
+package msconfig
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Object is a JSON object that keeps its members in the order they were set, so the
+// generated config reads top-down like the hand-written ones.
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewObject returns an empty object.
+func NewObject() *Object {
+	return &Object{values: map[string]interface{}{}}
+}
+
+// Set sets the member key to value, keeping the position of an existing member.
+func (o *Object) Set(key string, value interface{}) *Object {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+
+	return o
+}
+
+// Get returns the value of the member key.
+func (o *Object) Get(key string) (interface{}, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// Keys returns the member names, in order.
+func (o *Object) Keys() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// MarshalJSON encodes the object with its members in order. Placeholders such as
+// ${#ref.x} are written as they are, without HTML escaping.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := encode(&buf, key); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := encode(&buf, o.values[key]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, value interface{}) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1) // Encode's newline
+
+	return nil
+}
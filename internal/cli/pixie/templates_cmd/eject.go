@@ -0,0 +1,106 @@
+package templates_cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+func ejectCmd() *cobra.Command {
+	var user, force bool
+
+	cmd := &cobra.Command{
+		Use:   "eject <pack>/<name> | <name> | <pack>",
+		Short: "Copy a built-in template into the project or user layer for editing",
+		Long: `Copy a built-in template to .pixie/templates/<pack>/<name> under the project
+root, or under your home directory with --user, where it overrides the
+built-in one. Naming a pack ejects all of its templates.
+
+Existing overrides are kept unless --force is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := layerDir(user)
+			if err != nil {
+				return err
+			}
+
+			ejected, err := Eject(dir, args[0], force)
+			for _, path := range ejected {
+				fmt.Fprintf(cmd.OutOrStdout(), "Ejected %s\n", path)
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&user, "user", false, "Eject into ~/.pixie/templates instead of the project")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace existing overrides")
+
+	return cmd
+}
+
+// layerDir returns the override directory of the project, or of the user.
+func layerDir(user bool) (string, error) {
+	if user {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to find home directory")
+		}
+		return filepath.Join(home, initshared.TemplatesDir), nil
+	}
+
+	cfg, err := config.Load(config.CurrentOptions())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load pixie config file")
+	}
+
+	return filepath.Join(cfg.Root, initshared.TemplatesDir), nil
+}
+
+// Eject copies the built-in templates named by ref into the override directory dir
+// and returns the paths written. ref is a pack name or a template reference.
+func Eject(dir, ref string, force bool) ([]string, error) {
+	type template struct {
+		pack *initshared.TemplatePack
+		name string
+	}
+
+	var templates []template
+	if pack, ok := findPack(ref); ok {
+		names, err := pack.Names()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			templates = append(templates, template{pack, name})
+		}
+	} else {
+		pack, name, err := resolveName(ref)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template{pack, name})
+	}
+
+	var written []string
+	for _, t := range templates {
+		content, err := t.pack.Builtin(t.name)
+		if err != nil {
+			return written, err
+		}
+
+		path := filepath.Join(dir, t.pack.Name, filepath.FromSlash(t.name))
+		if err := initshared.WriteFile(path, content, force); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
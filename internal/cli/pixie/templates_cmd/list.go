@@ -0,0 +1,122 @@
+package templates_cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+func listCmd() *cobra.Command {
+	var overridden bool
+
+	cmd := &cobra.Command{
+		Use:   "list [pack]",
+		Short: "Show every template and the layer it is loaded from",
+		Long: `Show every template, optionally of one pack, with the layer that wins the
+lookup (project, user or builtin) and the override file it is loaded from.
+Override files that match no built-in template are reported, since no
+generator will ever read them.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selected := packs
+			if len(args) == 1 {
+				pack, ok := findPack(args[0])
+				if !ok {
+					return errors.New("unknown template pack %q", args[0])
+				}
+				selected = []*initshared.TemplatePack{pack}
+			}
+
+			cfg, err := config.Load(config.CurrentOptions())
+			if err != nil {
+				return errors.Wrap(err, "failed to load pixie config file")
+			}
+
+			templates, unused, err := List(cfg.Root, selected)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, "TEMPLATE\tLAYER\tPATH")
+			for _, template := range templates {
+				if overridden && template.Layer == initshared.BuiltinLayer {
+					continue
+				}
+				fmt.Fprintf(writer, "%s\t%s\t%s\n", template.ID(), template.Layer, template.Path)
+			}
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+
+			for _, path := range unused {
+				fmt.Fprintf(out, "\nWARNING: %s overrides no built-in template and is ignored\n", path)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&overridden, "overridden", false, "Only show templates loaded from the project or user layer")
+
+	return cmd
+}
+
+// List resolves every template of packs for the project at projectRoot. It also
+// returns the override files under the pack directories that match no template.
+func List(projectRoot string, packs []*initshared.TemplatePack) ([]initshared.ResolvedTemplate, []string, error) {
+	var templates []initshared.ResolvedTemplate
+	var unused []string
+
+	for _, pack := range packs {
+		names, err := pack.Names()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		known := map[string]bool{}
+		for _, name := range names {
+			known[name] = true
+			template, err := pack.Resolve(projectRoot, name)
+			if err != nil {
+				return nil, nil, err
+			}
+			templates = append(templates, template)
+		}
+
+		for _, layer := range initshared.OverrideLayers(projectRoot) {
+			dir := filepath.Join(layer.Dir, pack.Name)
+			err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if os.IsNotExist(err) && path == dir {
+					return filepath.SkipDir
+				}
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+				if rel, err := filepath.Rel(dir, path); err == nil && !known[filepath.ToSlash(rel)] {
+					unused = append(unused, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "failed to read template overrides in %s", dir)
+			}
+		}
+	}
+
+	sort.Strings(unused)
+	return templates, unused, nil
+}
@@ -0,0 +1,104 @@
+package templates_cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/scaffold"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/angular"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/golang"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// packs lists every template pack that can be overridden.
+var packs = []*initshared.TemplatePack{
+	golang.Templates,
+	angular.Templates,
+	scaffold.Templates,
+	initshared.GitHubActionsTemplates,
+}
+
+// TemplatesCmd returns the templates parent command with all subcommands
+func TemplatesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "templates",
+		Short: "List and customize the templates used by the generators",
+		Long: `List and customize the templates used by init and generate.
+
+Templates are grouped in packs (golang, angular, scaffold, github_actions)
+and looked up in three layers, first match wins:
+  1. project  .pixie/templates/<pack>/<name> under the project root
+  2. user     ~/.pixie/templates/<pack>/<name>
+  3. builtin  the templates compiled into pixie
+
+Available subcommands:
+  list   - Show every template and the layer it is loaded from
+  eject  - Copy a built-in template into the project or user layer for editing
+
+Examples:
+  # Which templates does this project override?
+  pixie templates list
+
+  # Customize the generated services for this project
+  pixie templates eject scaffold/services.go.tmpl
+
+  # Customize every Angular template for all your projects
+  pixie templates eject angular --user
+`,
+	}
+
+	cmd.AddCommand(listCmd())
+	cmd.AddCommand(ejectCmd())
+
+	return cmd
+}
+
+// findPack returns the pack called name.
+func findPack(name string) (*initshared.TemplatePack, bool) {
+	for _, pack := range packs {
+		if pack.Name == name {
+			return pack, true
+		}
+	}
+
+	return nil, false
+}
+
+// resolveName turns a template reference into a pack and template name. A reference
+// is <pack>/<name> or a bare name that only one pack has; ".tmpl" may be left off.
+func resolveName(ref string) (*initshared.TemplatePack, string, error) {
+	if !strings.HasSuffix(ref, ".tmpl") {
+		ref += ".tmpl"
+	}
+
+	if packName, name, ok := strings.Cut(ref, "/"); ok {
+		if pack, ok := findPack(packName); ok {
+			if _, err := pack.Builtin(name); err != nil {
+				return nil, "", errors.New("pack %s has no template %s (see pixie templates list %s)", packName, name, packName)
+			}
+			return pack, name, nil
+		}
+	}
+
+	var matches []string
+	var match *initshared.TemplatePack
+	for _, pack := range packs {
+		if _, err := pack.Builtin(ref); err == nil {
+			matches = append(matches, pack.Name+"/"+ref)
+			match = pack
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, "", errors.New("no template named %s (see pixie templates list)", strings.TrimSuffix(ref, ".tmpl"))
+	case 1:
+		return match, ref, nil
+	default:
+		sort.Strings(matches)
+		return nil, "", errors.New("%s is ambiguous; use one of: %s", ref, strings.Join(matches, ", "))
+	}
+}
@@ -0,0 +1,102 @@
+package templates_cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/scaffold"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+func TestResolveName(t *testing.T) {
+	tests := []struct {
+		ref      string
+		wantPack string
+		wantName string
+		wantErr  string
+	}{
+		{ref: "scaffold/services.go.tmpl", wantPack: "scaffold", wantName: "services.go.tmpl"},
+		{ref: "scaffold/services.go", wantPack: "scaffold", wantName: "services.go.tmpl"},
+		{ref: "tests.yaml", wantPack: "github_actions", wantName: "tests.yaml.tmpl"},
+		{ref: "services.go.tmpl", wantErr: "ambiguous"},
+		{ref: "scaffold/nope.go.tmpl", wantErr: "has no template"},
+		{ref: "nope", wantErr: "no template named nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			pack, name, err := resolveName(tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveName() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveName() error = %v", err)
+			}
+			if pack.Name != tt.wantPack || name != tt.wantName {
+				t.Errorf("resolveName() = %s, %s, want %s, %s", pack.Name, name, tt.wantPack, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestEjectAndList(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	dir := filepath.Join(root, initshared.TemplatesDir)
+
+	written, err := Eject(dir, "scaffold/services.go", false)
+	if err != nil {
+		t.Fatalf("Eject() error = %v", err)
+	}
+	want := filepath.Join(dir, "scaffold", "services.go.tmpl")
+	if len(written) != 1 || written[0] != want {
+		t.Fatalf("Eject() = %v, want [%s]", written, want)
+	}
+	builtin, _ := scaffold.Templates.Builtin("services.go.tmpl")
+	if content, _ := os.ReadFile(want); string(content) != string(builtin) {
+		t.Errorf("ejected template does not match the built-in one")
+	}
+
+	if _, err := Eject(dir, "scaffold/services.go", false); err == nil {
+		t.Errorf("Eject() over an existing override error = nil, want an error")
+	}
+
+	stray := filepath.Join(dir, "scaffold", "servics.go.tmpl")
+	if err := os.WriteFile(stray, []byte("typo"), 0644); err != nil {
+		t.Fatalf("failed to write stray override: %v", err)
+	}
+
+	templates, unused, err := List(root, []*initshared.TemplatePack{scaffold.Templates})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	for _, template := range templates {
+		wantLayer := initshared.BuiltinLayer
+		if template.Name == "services.go.tmpl" {
+			wantLayer = initshared.ProjectLayer
+		}
+		if template.Layer != wantLayer {
+			t.Errorf("%s layer = %q, want %q", template.ID(), template.Layer, wantLayer)
+		}
+	}
+	if len(unused) != 1 || unused[0] != stray {
+		t.Errorf("unused = %v, want [%s]", unused, stray)
+	}
+}
+
+func TestEjectPack(t *testing.T) {
+	dir := t.TempDir()
+
+	written, err := Eject(dir, "github_actions", false)
+	if err != nil {
+		t.Fatalf("Eject() error = %v", err)
+	}
+	if len(written) != 2 {
+		t.Errorf("Eject() wrote %v, want both github_actions templates", written)
+	}
+}
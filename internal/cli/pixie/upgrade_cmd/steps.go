@@ -0,0 +1,167 @@
+package upgrade_cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+// Step is a structural change to generated projects that re-rendering templates cannot
+// express, such as a moved directory. Pending steps run once per project, in order,
+// before the generators are replayed.
+type Step struct {
+	ID          string // Recorded in the manifest once applied; never change it
+	Version     string // Pixie version that introduced the step
+	Description string
+	Apply       func(p *Project) error
+}
+
+// steps lists every upgrade step in the order it must be applied. Append new steps at
+// the end and never remove one, since projects record the IDs they have applied.
+// Projects generated after a step was added run it too, so a step must leave a project
+// that already has the new layout alone, as Move does.
+var steps = []Step{}
+
+// PendingSteps returns the steps not yet applied to the project described by manifest.
+func PendingSteps(manifest *initshared.Manifest) []Step {
+	applied := map[string]bool{}
+	for _, id := range manifest.Upgrades {
+		applied[id] = true
+	}
+
+	var pending []Step
+	for _, step := range steps {
+		if !applied[step.ID] {
+			pending = append(pending, step)
+		}
+	}
+
+	return pending
+}
+
+// Project is a generated project being upgraded.
+type Project struct {
+	Root     string
+	Module   string // Go module path from go.mod; empty for other stacks
+	Manifest *initshared.Manifest
+}
+
+// Move moves the file or directory from to the path to, both relative to the project
+// root, and carries the manifest entries and base copies along. In Go projects, imports
+// of the moved packages are rewritten. A source that does not exist is not an error,
+// so a step can run on projects that never had the directory.
+func (p *Project) Move(from, to string) error {
+	from, to = filepath.ToSlash(filepath.Clean(from)), filepath.ToSlash(filepath.Clean(to))
+	source, target := filepath.Join(p.Root, from), filepath.Join(p.Root, to)
+
+	if _, err := os.Stat(source); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(target); err == nil {
+		return errors.New("cannot move %s: %s already exists", from, to)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return errors.Wrap(err, "failed to create directory for %s", to)
+	}
+	if err := os.Rename(source, target); err != nil {
+		return errors.Wrap(err, "failed to move %s to %s", from, to)
+	}
+
+	var keys []string
+	for key := range p.Manifest.Files {
+		if key == from || strings.HasPrefix(key, from+"/") {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		moved := to + strings.TrimPrefix(key, from)
+		p.Manifest.Files[moved] = p.Manifest.Files[key]
+		delete(p.Manifest.Files, key)
+
+		base := initshared.BasePath(p.Root, key)
+		if _, err := os.Stat(base); err != nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(initshared.BasePath(p.Root, moved)), 0755); err != nil {
+			return errors.Wrap(err, "failed to create directory for base copy of %s", moved)
+		}
+		if err := os.Rename(base, initshared.BasePath(p.Root, moved)); err != nil {
+			return errors.Wrap(err, "failed to move base copy of %s", key)
+		}
+	}
+
+	if p.Module == "" {
+		return nil
+	}
+
+	return p.rewriteImports(p.Module+"/"+from, p.Module+"/"+to)
+}
+
+// rewriteImports replaces imports of the package oldPath, and of packages below it,
+// with newPath in every Go file of the project, base copies included. Files that were
+// untouched before keep matching their manifest checksum.
+func (p *Project) rewriteImports(oldPath, newPath string) error {
+	replacer := strings.NewReplacer(`"`+oldPath+`"`, `"`+newPath+`"`, `"`+oldPath+`/`, `"`+newPath+`/`)
+
+	return filepath.WalkDir(p.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "vendor", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read %s", path)
+		}
+		rewritten := replacer.Replace(string(content))
+		if rewritten == string(content) {
+			return nil
+		}
+
+		if rel, err := filepath.Rel(p.Root, path); err == nil {
+			key := filepath.ToSlash(rel)
+			if entry, ok := p.Manifest.Files[key]; ok && entry.Checksum == initshared.Checksum(content) {
+				entry.Checksum = initshared.Checksum([]byte(rewritten))
+				p.Manifest.Files[key] = entry
+			}
+		}
+
+		if err := os.WriteFile(path, []byte(rewritten), 0644); err != nil {
+			return errors.Wrap(err, "failed to write %s", path)
+		}
+		return nil
+	})
+}
+
+// readModule returns the module path declared in the go.mod at root, or "" without one.
+func readModule(root string) string {
+	content, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,220 @@
+package upgrade_cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/spf13/cobra"
+
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/config"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/fromopenapi"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/generate_cmd/scaffold"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/angular"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/expo"
+	"github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/golang"
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+	"github.com/pixie-sh/pixie-cli/internal/version"
+)
+
+// generators builds the command of every generator recorded in manifests, keyed by the
+// generator name the manifest stores.
+var generators = map[string]func() *cobra.Command{
+	"init golang":           golang.Cmd,
+	"init angular":          angular.Cmd,
+	"init expo":             expo.Cmd,
+	"generate microservice": scaffold.MicroserviceCmd,
+	"generate domain":       scaffold.DomainCmd,
+	"generate entity":       scaffold.EntityCmd,
+	"generate service":      scaffold.ServiceCmd,
+	"generate repository":   scaffold.RepositoryCmd,
+	"generate resource":     scaffold.ResourceCmd,
+	"generate event":        scaffold.EventCmd,
+	"generate permission":   scaffold.PermissionCmd,
+	"generate gate":         scaffold.GateCmd,
+	"generate from-openapi": fromopenapi.FromOpenAPICmd,
+}
+
+// UpgradeCmd returns the upgrade command.
+func UpgradeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Migrate a generated project to the templates of this pixie version",
+		Long: `Migrate a generated project to the templates of this pixie version.
+
+Upgrade reads .pixie/manifest.json and:
+  1. applies the structural upgrade steps the project has not had yet,
+     such as moved directories;
+  2. replays every recorded generator with its recorded inputs.
+
+Replaying compares the old template output, kept in .pixie/base, with the
+new one and applies the difference to your files. Files you never edited
+are replaced; edited files are merged. When a merge conflicts, your file is
+left alone, the new version is written next to it as <file>.pixie-new and
+the command exits non-zero.
+
+Examples:
+  # Upgrade the current project
+  pixie upgrade
+
+  # Review the changes first
+  pixie upgrade --diff`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(config.CurrentOptions())
+			if err != nil {
+				return errors.Wrap(err, "failed to load pixie config file")
+			}
+
+			result, err := Upgrade(cfg.Root, cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+
+			writeSummary(cmd.OutOrStdout(), result)
+			if len(result.Conflicts) > 0 {
+				cmd.SilenceUsage = true
+				return errors.New("upgrade left %d conflict(s) to resolve", len(result.Conflicts))
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// Result is what Upgrade did.
+type Result struct {
+	Steps     []Step
+	Outcomes  map[initshared.Outcome]int
+	Conflicts []string // Files whose new version was written to a .pixie-new sidecar
+	Skipped   []string // Recorded generators this pixie version does not know
+}
+
+// Upgrade applies the pending upgrade steps to the project at root and replays its
+// recorded generators. Steps are only listed, not applied, in DryRun and Diff mode.
+func Upgrade(root string, out io.Writer) (Result, error) {
+	result := Result{Outcomes: map[initshared.Outcome]int{}}
+
+	manifest, err := initshared.LoadManifest(root)
+	if err != nil {
+		return result, err
+	}
+	if len(manifest.Files) == 0 {
+		return result, errors.New("no %s found in %s; only projects generated with a manifest can be upgraded", initshared.ManifestFile, root)
+	}
+
+	fmt.Fprintf(out, "Upgrading %s from pixie %s to %s\n\n", root, manifest.PixieVersion, version.Version)
+
+	project := &Project{Root: root, Module: readModule(root), Manifest: manifest}
+	result.Steps = PendingSteps(manifest)
+	for _, step := range result.Steps {
+		if initshared.CurrentWriteMode() != initshared.WriteToDisk {
+			fmt.Fprintf(out, "Would apply upgrade step %s (%s): %s\n", step.ID, step.Version, step.Description)
+			continue
+		}
+
+		fmt.Fprintf(out, "Applying upgrade step %s (%s): %s\n", step.ID, step.Version, step.Description)
+		if err := step.Apply(project); err != nil {
+			return result, errors.Wrap(err, "upgrade step %s failed", step.ID)
+		}
+		manifest.Upgrades = append(manifest.Upgrades, step.ID)
+		if err := initshared.SaveManifest(root, manifest); err != nil {
+			return result, err
+		}
+	}
+
+	initshared.ResetOutcomes()
+	for _, inv := range invocations(manifest, root) {
+		newCmd, ok := generators[inv.generator]
+		if !ok {
+			result.Skipped = append(result.Skipped, inv.generator)
+			continue
+		}
+
+		fmt.Fprintf(out, "\nReplaying pixie %s %s\n", inv.generator, strings.Join(inv.args, " "))
+		cmd := newCmd()
+		cmd.SetArgs(inv.args)
+		cmd.SetOut(out)
+		cmd.SetErr(out)
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		if err := cmd.Execute(); err != nil {
+			return result, errors.Wrap(err, "failed to replay %s", inv.generator)
+		}
+	}
+
+	for _, outcome := range initshared.Outcomes() {
+		result.Outcomes[outcome.Outcome]++
+		if outcome.Outcome == initshared.Sidecar {
+			result.Conflicts = append(result.Conflicts, outcome.Path)
+		}
+	}
+
+	return result, nil
+}
+
+// invocation is one recorded generator run.
+type invocation struct {
+	generator string
+	args      []string
+}
+
+// invocations returns the distinct generator runs recorded in manifest as command-line
+// arguments, init runs first. Init runs write to root, where the manifest lives.
+func invocations(manifest *initshared.Manifest, root string) []invocation {
+	seen := map[string]bool{}
+	var result []invocation
+	for _, entry := range manifest.Files {
+		var args []string
+		for name, value := range entry.Inputs {
+			if value != "" {
+				args = append(args, fmt.Sprintf("--%s=%s", name, value))
+			}
+		}
+		if strings.HasPrefix(entry.Generator, "init ") {
+			args = append(args, "--output="+root)
+		}
+		sort.Strings(args)
+
+		id := entry.Generator + " " + strings.Join(args, " ")
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, invocation{generator: entry.Generator, args: args})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		iInit, jInit := strings.HasPrefix(result[i].generator, "init "), strings.HasPrefix(result[j].generator, "init ")
+		if iInit != jInit {
+			return iInit
+		}
+		if result[i].generator != result[j].generator {
+			return result[i].generator < result[j].generator
+		}
+		return strings.Join(result[i].args, " ") < strings.Join(result[j].args, " ")
+	})
+
+	return result
+}
+
+func writeSummary(out io.Writer, result Result) {
+	fmt.Fprintf(out, "\nUpgrade summary: %d step(s), %d updated, %d merged, %d created, %d unchanged, %d conflict(s)\n",
+		len(result.Steps),
+		result.Outcomes[initshared.Updated]+result.Outcomes[initshared.Overwritten],
+		result.Outcomes[initshared.Merged],
+		result.Outcomes[initshared.Created],
+		result.Outcomes[initshared.Unchanged],
+		len(result.Conflicts))
+
+	for _, generator := range result.Skipped {
+		fmt.Fprintf(out, "   WARNING: %s is not known to this pixie version and was not replayed\n", generator)
+	}
+	for _, path := range result.Conflicts {
+		fmt.Fprintf(out, "   CONFLICT %s: resolve against %s%s, then delete it\n", path, path, initshared.SidecarSuffix)
+	}
+}
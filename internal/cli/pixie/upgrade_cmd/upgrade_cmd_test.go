@@ -0,0 +1,237 @@
+package upgrade_cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	initshared "github.com/pixie-sh/pixie-cli/internal/cli/pixie/init_cmd/shared"
+)
+
+const templateV1 = "package orders\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() {}\n"
+
+// useFakeGenerator registers "generate fake", which writes *template to orders.go
+// under root, in place of the real generators.
+func useFakeGenerator(t *testing.T, root string, template *string) {
+	t.Helper()
+
+	previous := generators
+	t.Cleanup(func() { generators = previous })
+
+	generators = map[string]func() *cobra.Command{
+		"generate fake": func() *cobra.Command {
+			cmd := &cobra.Command{
+				Use: "fake",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					name, _ := cmd.Flags().GetString("name")
+					gen, err := initshared.NewGeneration(root, "generate fake", map[string]string{"name": name}, false)
+					if err != nil {
+						return err
+					}
+					if _, err := gen.Write(filepath.Join(root, name+".go"), "templates/fake.tmpl", []byte(*template)); err != nil {
+						return err
+					}
+					return gen.Finish()
+				},
+			}
+			cmd.Flags().String("name", "", "")
+			return cmd
+		},
+	}
+}
+
+func runFake(t *testing.T, name string) {
+	t.Helper()
+
+	cmd := generators["generate fake"]()
+	cmd.SetArgs([]string{"--name=" + name})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+
+	return string(content)
+}
+
+func TestUpgrade_AppliesTemplateChanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		edit      string // Replaces editLine in the generated file
+		editLine  string
+		want      string
+		conflicts int
+	}{
+		{
+			name:     "untouched file is updated",
+			editLine: "func A() {}",
+			edit:     "func A() {}",
+			want:     strings.Replace(templateV1, "func C() {}", "func C() { /* fixed */ }", 1),
+		},
+		{
+			name:     "edited file is merged",
+			editLine: "func A() {}",
+			edit:     "func A() { /* mine */ }",
+			want:     "package orders\n\nfunc A() { /* mine */ }\n\nfunc B() {}\n\nfunc C() { /* fixed */ }\n",
+		},
+		{
+			name:      "conflicting edit is reported",
+			editLine:  "func C() {}",
+			edit:      "func C() { /* mine */ }",
+			want:      strings.Replace(templateV1, "func C() {}", "func C() { /* mine */ }", 1),
+			conflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			template := templateV1
+			useFakeGenerator(t, root, &template)
+			runFake(t, "orders")
+
+			path := filepath.Join(root, "orders.go")
+			writeFile(t, path, strings.Replace(readFile(t, path), tt.editLine, tt.edit, 1))
+
+			template = strings.Replace(templateV1, "func C() {}", "func C() { /* fixed */ }", 1)
+			result, err := Upgrade(root, io.Discard)
+			if err != nil {
+				t.Fatalf("Upgrade() error = %v", err)
+			}
+
+			if got := readFile(t, path); got != tt.want {
+				t.Errorf("orders.go =\n%s\nwant\n%s", got, tt.want)
+			}
+			if len(result.Conflicts) != tt.conflicts {
+				t.Errorf("Conflicts = %v, want %d", result.Conflicts, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestUpgrade_AppliesPendingStepsOnce(t *testing.T) {
+	root := t.TempDir()
+	template := templateV1
+	useFakeGenerator(t, root, &template)
+	runFake(t, "orders")
+
+	applied := 0
+	previous := steps
+	t.Cleanup(func() { steps = previous })
+	steps = []Step{{
+		ID:          "count",
+		Version:     "v1.0.0",
+		Description: "counts its runs",
+		Apply:       func(p *Project) error { applied++; return nil },
+	}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := Upgrade(root, io.Discard); err != nil {
+			t.Fatalf("Upgrade() error = %v", err)
+		}
+	}
+
+	if applied != 1 {
+		t.Errorf("step applied %d times, want 1", applied)
+	}
+	manifest, err := initshared.LoadManifest(root)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if !reflect.DeepEqual(manifest.Upgrades, []string{"count"}) {
+		t.Errorf("Upgrades = %v, want [count]", manifest.Upgrades)
+	}
+}
+
+func TestUpgrade_RequiresManifest(t *testing.T) {
+	if _, err := Upgrade(t.TempDir(), io.Discard); err == nil {
+		t.Fatal("Upgrade() error = nil, want an error without a manifest")
+	}
+}
+
+func TestInvocations(t *testing.T) {
+	manifest := &initshared.Manifest{Files: map[string]initshared.ManifestEntry{
+		"a.go":    {Generator: "generate domain", Inputs: map[string]string{"domain": "orders", "features": "database"}},
+		"b.go":    {Generator: "generate domain", Inputs: map[string]string{"features": "database", "domain": "orders"}},
+		"c.go":    {Generator: "generate microservice", Inputs: map[string]string{"name": "payments", "output": ""}},
+		"go.mod":  {Generator: "init golang", Inputs: map[string]string{"name": "demo"}},
+		"unknown": {Generator: "generate domain", Inputs: map[string]string{"domain": "billing"}},
+	}}
+
+	want := []invocation{
+		{generator: "init golang", args: []string{"--name=demo", "--output=/project"}},
+		{generator: "generate domain", args: []string{"--domain=billing"}},
+		{generator: "generate domain", args: []string{"--domain=orders", "--features=database"}},
+		{generator: "generate microservice", args: []string{"--name=payments"}},
+	}
+	if got := invocations(manifest, "/project"); !reflect.DeepEqual(got, want) {
+		t.Errorf("invocations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestProjectMove(t *testing.T) {
+	root := t.TempDir()
+	moved := "package orders\n"
+	importer := "package main\n\nimport (\n\t\"example.com/demo/internal/domain/orders\"\n\t\"example.com/demo/internal/domain/orders/orders_entities\"\n\t\"example.com/demo/internal/domainx\"\n)\n"
+	writeFile(t, filepath.Join(root, "internal/domain/orders/orders.go"), moved)
+	writeFile(t, filepath.Join(root, "cmd/main.go"), importer)
+	writeFile(t, initshared.BasePath(root, "internal/domain/orders/orders.go"), moved)
+
+	manifest := &initshared.Manifest{Files: map[string]initshared.ManifestEntry{
+		"internal/domain/orders/orders.go": {Checksum: initshared.Checksum([]byte(moved))},
+		"cmd/main.go":                      {Checksum: initshared.Checksum([]byte(importer))},
+	}}
+	project := &Project{Root: root, Module: "example.com/demo", Manifest: manifest}
+
+	if err := project.Move("internal/domain", "internal/domains"); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(root, "internal/domains/orders/orders.go")); got != moved {
+		t.Errorf("moved file = %q, want %q", got, moved)
+	}
+	if got := readFile(t, initshared.BasePath(root, "internal/domains/orders/orders.go")); got != moved {
+		t.Errorf("moved base copy = %q, want %q", got, moved)
+	}
+	if _, ok := manifest.Files["internal/domains/orders/orders.go"]; !ok {
+		t.Errorf("manifest entry not moved: %v", manifest.Files)
+	}
+
+	wantImporter := strings.NewReplacer(
+		`"example.com/demo/internal/domain/orders"`, `"example.com/demo/internal/domains/orders"`,
+		`"example.com/demo/internal/domain/orders/orders_entities"`, `"example.com/demo/internal/domains/orders/orders_entities"`,
+	).Replace(importer)
+	if got := readFile(t, filepath.Join(root, "cmd/main.go")); got != wantImporter {
+		t.Errorf("imports not rewritten:\n%s\nwant\n%s", got, wantImporter)
+	}
+	if got := manifest.Files["cmd/main.go"].Checksum; got != initshared.Checksum([]byte(wantImporter)) {
+		t.Errorf("checksum of untouched importer not updated")
+	}
+
+	if err := project.Move("internal/domain", "internal/domains"); err != nil {
+		t.Errorf("Move() of a missing source error = %v, want nil", err)
+	}
+}